@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/alerting/notify"
+)
+
+// alertsFilter are the query parameters of the alerts and alert groups endpoints.
+type alertsFilter struct {
+	active    bool
+	silenced  bool
+	inhibited bool
+	filter    []string
+	receiver  string
+}
+
+func parseAlertsFilter(q url.Values) (alertsFilter, error) {
+	f := alertsFilter{
+		filter:   q["filter"],
+		receiver: q.Get("receiver"),
+	}
+	var err error
+	if f.active, err = parseBool(q, "active"); err != nil {
+		return f, err
+	}
+	if f.silenced, err = parseBool(q, "silenced"); err != nil {
+		return f, err
+	}
+	if f.inhibited, err = parseBool(q, "inhibited"); err != nil {
+		return f, err
+	}
+	// unprocessed is accepted for compatibility, alerts are always processed when they are listed.
+	if _, err = parseBool(q, "unprocessed"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// parseBool parses the boolean query parameter. Missing parameters default to true.
+func parseBool(q url.Values, name string) (bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return true, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for query parameter %q", v, name)
+	}
+	return b, nil
+}
+
+func (h *Handler) getAlerts(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	f, err := parseAlertsFilter(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	alerts, err := am.GetAlerts(f.active, f.silenced, f.inhibited, f.filter, f.receiver)
+	if err != nil {
+		h.writeError(w, alertsErrorStatus(err), err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, alerts)
+}
+
+func (h *Handler) getAlertGroups(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	f, err := parseAlertsFilter(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	groups, err := am.GetAlertGroups(f.active, f.silenced, f.inhibited, f.filter, f.receiver)
+	if err != nil {
+		h.writeError(w, alertsErrorStatus(err), err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, groups)
+}
+
+func (h *Handler) postAlerts(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	var alerts notify.PostableAlerts
+	if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+		h.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse alerts: %w", err))
+		return
+	}
+	if err := am.PutAlerts(alerts); err != nil {
+		var validationErr *notify.AlertValidationError
+		if errors.As(err, &validationErr) {
+			h.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, nil)
+}
+
+func alertsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, notify.ErrGetAlertsBadPayload), errors.Is(err, notify.ErrGetAlertGroupsBadPayload):
+		return http.StatusBadRequest
+	case errors.Is(err, notify.ErrGetAlertsUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
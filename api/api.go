@@ -0,0 +1,135 @@
+// Package api serves the Alertmanager v2 HTTP API on top of a notify.GrafanaAlertmanager,
+// so that the standard Alertmanager tooling, such as amtool, can be used with it.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/alerting/notify"
+)
+
+// Prefix is the path prefix of all the endpoints of the Alertmanager v2 API.
+const Prefix = "/api/v2"
+
+var (
+	// ErrTenantNotFound is returned by a TenantResolver when the tenant of the request does not have an Alertmanager.
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantUnauthorized is returned by a TenantResolver when the tenant of the request cannot be identified.
+	ErrTenantUnauthorized = errors.New("tenant unauthorized")
+)
+
+// Alertmanager is the subset of the methods of notify.GrafanaAlertmanager that are served by the API.
+type Alertmanager interface {
+	Ready() bool
+	GetStatus() notify.AlertmanagerStatus
+	GetReceivers() []models.Receiver
+	GetAlerts(active, silenced, inhibited bool, filter []string, receivers string) (notify.GettableAlerts, error)
+	GetAlertGroups(active, silenced, inhibited bool, filter []string, receivers string) (notify.AlertGroups, error)
+	PutAlerts(alerts notify.PostableAlerts) error
+	ListSilences(filter []string) (notify.GettableSilences, error)
+	GetSilence(silenceID string) (notify.GettableSilence, error)
+	CreateSilence(ps *notify.PostableSilence) (string, error)
+	DeleteSilence(silenceID string) error
+}
+
+var _ Alertmanager = (*notify.GrafanaAlertmanager)(nil)
+
+// TenantResolver returns the Alertmanager of the tenant that made the request.
+// It returns ErrTenantUnauthorized if the tenant cannot be identified, and ErrTenantNotFound if it has no Alertmanager.
+type TenantResolver func(r *http.Request) (Alertmanager, error)
+
+// SingleTenant returns a TenantResolver that serves the same Alertmanager to all requests.
+func SingleTenant(am Alertmanager) TenantResolver {
+	return func(*http.Request) (Alertmanager, error) {
+		return am, nil
+	}
+}
+
+// TenantFromHeader returns a TenantResolver that reads the tenant from the header, e.g. X-Scope-OrgID,
+// and looks up its Alertmanager with the lookup function.
+func TenantFromHeader(header string, lookup func(tenant string) (Alertmanager, error)) TenantResolver {
+	return func(r *http.Request) (Alertmanager, error) {
+		tenant := r.Header.Get(header)
+		if tenant == "" {
+			return nil, ErrTenantUnauthorized
+		}
+		return lookup(tenant)
+	}
+}
+
+// Handler serves the Alertmanager v2 API under Prefix.
+type Handler struct {
+	resolve TenantResolver
+	logger  log.Logger
+	mux     *http.ServeMux
+}
+
+// NewHandler returns a Handler that serves the Alertmanager of the tenant returned by resolve.
+func NewHandler(resolve TenantResolver, logger log.Logger) *Handler {
+	h := &Handler{
+		resolve: resolve,
+		logger:  logger,
+		mux:     http.NewServeMux(),
+	}
+
+	h.handle(http.MethodGet, "/status", h.getStatus)
+	h.handle(http.MethodGet, "/receivers", h.getReceivers)
+	h.handle(http.MethodGet, "/alerts", h.getAlerts)
+	h.handle(http.MethodPost, "/alerts", h.postAlerts)
+	h.handle(http.MethodGet, "/alerts/groups", h.getAlertGroups)
+	h.handle(http.MethodGet, "/silences", h.getSilences)
+	h.handle(http.MethodPost, "/silences", h.postSilences)
+	h.handle(http.MethodGet, "/silence/{silenceID}", h.getSilence)
+	h.handle(http.MethodDelete, "/silence/{silenceID}", h.deleteSilence)
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// handle registers the handler for the method and the path under Prefix. The handler is called with the Alertmanager of the tenant.
+func (h *Handler) handle(method, path string, fn func(w http.ResponseWriter, r *http.Request, am Alertmanager)) {
+	h.mux.HandleFunc(method+" "+Prefix+path, func(w http.ResponseWriter, r *http.Request) {
+		am, err := h.resolve(r)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrTenantUnauthorized):
+				h.writeError(w, http.StatusUnauthorized, err)
+			case errors.Is(err, ErrTenantNotFound):
+				h.writeError(w, http.StatusNotFound, err)
+			default:
+				h.writeError(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		fn(w, r, am)
+	})
+}
+
+// writeJSON writes the value as the JSON body of the response.
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		level.Error(h.logger).Log("msg", "failed to write response", "err", err)
+	}
+}
+
+// writeError writes the error as a JSON string, the format of errors in the Alertmanager v2 API.
+func (h *Handler) writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		level.Error(h.logger).Log("msg", "failed to serve request", "status", status, "err", err)
+	}
+	h.writeJSON(w, status, err.Error())
+}
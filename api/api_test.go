@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/client"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/alertgroup"
+	"github.com/prometheus/alertmanager/api/v2/client/general"
+	"github.com/prometheus/alertmanager/api/v2/client/receiver"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/alerting/notify"
+)
+
+type fakeAlertmanager struct {
+	ready    bool
+	alerts   notify.GettableAlerts
+	posted   notify.PostableAlerts
+	silences map[string]*notify.PostableSilence
+	deleted  []string
+
+	// filters records the filters of the requests.
+	filters [][]string
+}
+
+func newFakeAlertmanager() *fakeAlertmanager {
+	return &fakeAlertmanager{ready: true, silences: map[string]*notify.PostableSilence{}}
+}
+
+func (f *fakeAlertmanager) Ready() bool { return f.ready }
+
+func (f *fakeAlertmanager) GetStatus() notify.AlertmanagerStatus {
+	original := `{"route":{"receiver":"default"}}`
+	uptime := strfmt.DateTime(time.Now())
+	status := amv2.ClusterStatusStatusReady
+	return notify.AlertmanagerStatus{
+		AlertmanagerStatus: amv2.AlertmanagerStatus{
+			Cluster:     &amv2.ClusterStatus{Status: &status, Peers: []*amv2.PeerStatus{}},
+			Config:      &amv2.AlertmanagerConfig{Original: &original},
+			Uptime:      &uptime,
+			VersionInfo: &amv2.VersionInfo{Branch: ptr(""), BuildDate: ptr(""), BuildUser: ptr(""), GoVersion: ptr(""), Revision: ptr(""), Version: ptr("")},
+		},
+		Ready:  f.ready,
+		Alerts: len(f.alerts),
+	}
+}
+
+func (f *fakeAlertmanager) GetReceivers() []models.Receiver {
+	return []models.Receiver{{Name: "default"}, {Name: "team-a"}}
+}
+
+func (f *fakeAlertmanager) GetAlerts(active, silenced, inhibited bool, filter []string, _ string) (notify.GettableAlerts, error) {
+	f.filters = append(f.filters, filter)
+	if !f.ready {
+		return nil, notify.ErrGetAlertsUnavailable
+	}
+	if len(filter) > 0 && filter[0] == "invalid" {
+		return nil, fmt.Errorf("bad matcher: %w", notify.ErrGetAlertsBadPayload)
+	}
+	if !active || !silenced || !inhibited {
+		return notify.GettableAlerts{}, nil
+	}
+	return f.alerts, nil
+}
+
+func (f *fakeAlertmanager) GetAlertGroups(_, _, _ bool, filter []string, receivers string) (notify.AlertGroups, error) {
+	f.filters = append(f.filters, filter)
+	return notify.AlertGroups{{
+		Labels:   amv2.LabelSet{"alertname": "test"},
+		Receiver: &amv2.Receiver{Name: &receivers},
+		Alerts:   f.alerts,
+	}}, nil
+}
+
+func (f *fakeAlertmanager) PutAlerts(alerts notify.PostableAlerts) error {
+	for _, a := range alerts {
+		if len(a.Labels) == 0 {
+			return &notify.AlertValidationError{Alerts: alerts, Errors: []error{errors.New("at least one label pair required")}}
+		}
+	}
+	f.posted = append(f.posted, alerts...)
+	return nil
+}
+
+func (f *fakeAlertmanager) ListSilences(filter []string) (notify.GettableSilences, error) {
+	f.filters = append(f.filters, filter)
+	res := notify.GettableSilences{}
+	for id, s := range f.silences {
+		id := id
+		state := amv2.SilenceStatusStateActive
+		res = append(res, &notify.GettableSilence{ID: &id, Silence: s.Silence, Status: &amv2.SilenceStatus{State: &state}})
+	}
+	return res, nil
+}
+
+func (f *fakeAlertmanager) GetSilence(silenceID string) (notify.GettableSilence, error) {
+	s, ok := f.silences[silenceID]
+	if !ok {
+		return notify.GettableSilence{}, notify.ErrSilenceNotFound
+	}
+	state := amv2.SilenceStatusStateActive
+	return notify.GettableSilence{ID: &silenceID, Silence: s.Silence, Status: &amv2.SilenceStatus{State: &state}}, nil
+}
+
+func (f *fakeAlertmanager) CreateSilence(ps *notify.PostableSilence) (string, error) {
+	if time.Time(*ps.EndsAt).Before(time.Now()) {
+		return "", fmt.Errorf("end time can't be in the past: %w", notify.ErrCreateSilenceBadPayload)
+	}
+	id := ps.ID
+	if id == "" {
+		id = fmt.Sprintf("silence-%d", len(f.silences)+1)
+	}
+	f.silences[id] = ps
+	return id, nil
+}
+
+func (f *fakeAlertmanager) DeleteSilence(silenceID string) error {
+	if _, ok := f.silences[silenceID]; !ok {
+		return notify.ErrSilenceNotFound
+	}
+	delete(f.silences, silenceID)
+	f.deleted = append(f.deleted, silenceID)
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// newClient returns the client of the Alertmanager v2 API used by amtool.
+func newClient(t *testing.T, h http.Handler) *client.AlertmanagerAPI {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return client.NewHTTPClientWithConfig(strfmt.Default, client.DefaultTransportConfig().WithHost(u.Host).WithSchemes([]string{"http"}))
+}
+
+func TestHandler(t *testing.T) {
+	am := newFakeAlertmanager()
+	fp := "fingerprint"
+	now := strfmt.DateTime(time.Now())
+	am.alerts = notify.GettableAlerts{{
+		Alert:       amv2.Alert{Labels: amv2.LabelSet{"alertname": "test"}},
+		Annotations: amv2.LabelSet{},
+		Fingerprint: &fp,
+		Receivers:   []*amv2.Receiver{{Name: ptr("default")}},
+		StartsAt:    &now,
+		EndsAt:      &now,
+		UpdatedAt:   &now,
+		Status: &amv2.AlertStatus{
+			State:       ptr(amv2.AlertStatusStateActive),
+			SilencedBy:  []string{},
+			InhibitedBy: []string{},
+		},
+	}}
+	c := newClient(t, NewHandler(SingleTenant(am), log.NewNopLogger()))
+	ctx := context.Background()
+
+	t.Run("status", func(t *testing.T) {
+		res, err := c.General.GetStatus(general.NewGetStatusParamsWithContext(ctx))
+		require.NoError(t, err)
+		require.Equal(t, `{"route":{"receiver":"default"}}`, *res.Payload.Config.Original)
+		require.Equal(t, amv2.ClusterStatusStatusReady, *res.Payload.Cluster.Status)
+	})
+
+	t.Run("receivers", func(t *testing.T) {
+		res, err := c.Receiver.GetReceivers(receiver.NewGetReceiversParamsWithContext(ctx))
+		require.NoError(t, err)
+		require.Len(t, res.Payload, 2)
+		require.Equal(t, "default", *res.Payload[0].Name)
+		require.Equal(t, "team-a", *res.Payload[1].Name)
+	})
+
+	t.Run("alerts", func(t *testing.T) {
+		res, err := c.Alert.GetAlerts(alert.NewGetAlertsParamsWithContext(ctx).WithFilter([]string{"alertname=test", "team=a"}))
+		require.NoError(t, err)
+		require.Len(t, res.Payload, 1)
+		require.Equal(t, fp, *res.Payload[0].Fingerprint)
+		require.Equal(t, []string{"alertname=test", "team=a"}, am.filters[len(am.filters)-1])
+
+		res, err = c.Alert.GetAlerts(alert.NewGetAlertsParamsWithContext(ctx).WithSilenced(ptr(false)))
+		require.NoError(t, err)
+		require.Empty(t, res.Payload)
+
+		_, err = c.Alert.GetAlerts(alert.NewGetAlertsParamsWithContext(ctx).WithFilter([]string{"invalid"}))
+		require.Error(t, err)
+		var badRequest *alert.GetAlertsBadRequest
+		require.ErrorAs(t, err, &badRequest)
+	})
+
+	t.Run("post alerts", func(t *testing.T) {
+		_, err := c.Alert.PostAlerts(alert.NewPostAlertsParamsWithContext(ctx).WithAlerts(amv2.PostableAlerts{{
+			Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "posted"}},
+		}}))
+		require.NoError(t, err)
+		require.Len(t, am.posted, 1)
+		require.Equal(t, amv2.LabelSet{"alertname": "posted"}, am.posted[0].Labels)
+	})
+
+	t.Run("alert groups", func(t *testing.T) {
+		res, err := c.Alertgroup.GetAlertGroups(alertgroup.NewGetAlertGroupsParamsWithContext(ctx).WithReceiver(ptr("default")))
+		require.NoError(t, err)
+		require.Len(t, res.Payload, 1)
+		require.Equal(t, "default", *res.Payload[0].Receiver.Name)
+		require.Len(t, res.Payload[0].Alerts, 1)
+	})
+
+	t.Run("silences", func(t *testing.T) {
+		startsAt := strfmt.DateTime(time.Now())
+		endsAt := strfmt.DateTime(time.Now().Add(time.Hour))
+		created, err := c.Silence.PostSilences(silence.NewPostSilencesParamsWithContext(ctx).WithSilence(&amv2.PostableSilence{
+			Silence: amv2.Silence{
+				Comment:   ptr("comment"),
+				CreatedBy: ptr("amtool"),
+				StartsAt:  &startsAt,
+				EndsAt:    &endsAt,
+				Matchers: amv2.Matchers{{
+					Name:    ptr("alertname"),
+					Value:   ptr("test"),
+					IsEqual: ptr(true),
+					IsRegex: ptr(false),
+				}},
+			},
+		}))
+		require.NoError(t, err)
+		id := created.Payload.SilenceID
+		require.NotEmpty(t, id)
+
+		list, err := c.Silence.GetSilences(silence.NewGetSilencesParamsWithContext(ctx))
+		require.NoError(t, err)
+		require.Len(t, list.Payload, 1)
+		require.Equal(t, id, *list.Payload[0].ID)
+
+		got, err := c.Silence.GetSilence(silence.NewGetSilenceParamsWithContext(ctx).WithSilenceID(strfmt.UUID(id)))
+		require.NoError(t, err)
+		require.Equal(t, "comment", *got.Payload.Comment)
+
+		_, err = c.Silence.DeleteSilence(silence.NewDeleteSilenceParamsWithContext(ctx).WithSilenceID(strfmt.UUID(id)))
+		require.NoError(t, err)
+		require.Equal(t, []string{id}, am.deleted)
+
+		_, err = c.Silence.GetSilence(silence.NewGetSilenceParamsWithContext(ctx).WithSilenceID(strfmt.UUID(id)))
+		var notFound *silence.GetSilenceNotFound
+		require.ErrorAs(t, err, &notFound)
+	})
+
+	t.Run("invalid silences", func(t *testing.T) {
+		past := strfmt.DateTime(time.Now().Add(-time.Hour))
+		_, err := c.Silence.PostSilences(silence.NewPostSilencesParamsWithContext(ctx).WithSilence(&amv2.PostableSilence{
+			Silence: amv2.Silence{
+				Comment:   ptr("comment"),
+				CreatedBy: ptr("amtool"),
+				StartsAt:  &past,
+				EndsAt:    &past,
+				Matchers:  amv2.Matchers{{Name: ptr("alertname"), Value: ptr("test"), IsRegex: ptr(false)}},
+			},
+		}))
+		var badRequest *silence.PostSilencesBadRequest
+		require.ErrorAs(t, err, &badRequest)
+		require.Contains(t, badRequest.Payload, "end time can't be in the past")
+	})
+}
+
+func TestHandler_Tenants(t *testing.T) {
+	tenants := map[string]Alertmanager{"1": newFakeAlertmanager()}
+	notReady := newFakeAlertmanager()
+	notReady.ready = false
+	tenants["2"] = notReady
+
+	h := NewHandler(TenantFromHeader("X-Scope-OrgID", func(tenant string) (Alertmanager, error) {
+		am, ok := tenants[tenant]
+		if !ok {
+			return nil, ErrTenantNotFound
+		}
+		return am, nil
+	}), log.NewNopLogger())
+
+	tests := []struct {
+		name   string
+		tenant string
+		path   string
+		status int
+	}{
+		{name: "missing tenant", path: "/api/v2/status", status: http.StatusUnauthorized},
+		{name: "unknown tenant", tenant: "3", path: "/api/v2/status", status: http.StatusNotFound},
+		{name: "known tenant", tenant: "1", path: "/api/v2/alerts", status: http.StatusOK},
+		{name: "not ready", tenant: "2", path: "/api/v2/alerts", status: http.StatusServiceUnavailable},
+		{name: "invalid query parameter", tenant: "1", path: "/api/v2/alerts?active=maybe", status: http.StatusBadRequest},
+		{name: "unknown path", tenant: "1", path: "/api/v2/unknown", status: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.tenant != "" {
+				r.Header.Set("X-Scope-OrgID", tc.tenant)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
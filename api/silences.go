@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/grafana/alerting/notify"
+)
+
+// postSilencesResponse is the response of the silences endpoint when a silence is created or updated.
+type postSilencesResponse struct {
+	SilenceID string `json:"silenceID"`
+}
+
+func (h *Handler) getSilences(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	silences, err := am.ListSilences(r.URL.Query()["filter"])
+	if err != nil {
+		if errors.Is(err, notify.ErrListSilencesBadPayload) {
+			h.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, silences)
+}
+
+func (h *Handler) postSilences(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	var ps notify.PostableSilence
+	if err := json.NewDecoder(r.Body).Decode(&ps); err != nil {
+		h.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse silence: %w", err))
+		return
+	}
+	if err := ps.Validate(strfmt.Default); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := am.CreateSilence(&ps)
+	if err != nil {
+		if errors.Is(err, notify.ErrCreateSilenceBadPayload) {
+			h.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, postSilencesResponse{SilenceID: id})
+}
+
+func (h *Handler) getSilence(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	silence, err := am.GetSilence(r.PathValue("silenceID"))
+	if err != nil {
+		if errors.Is(err, notify.ErrSilenceNotFound) {
+			h.writeError(w, http.StatusNotFound, err)
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, silence)
+}
+
+func (h *Handler) deleteSilence(w http.ResponseWriter, r *http.Request, am Alertmanager) {
+	if err := am.DeleteSilence(r.PathValue("silenceID")); err != nil {
+		if errors.Is(err, notify.ErrSilenceNotFound) {
+			h.writeError(w, http.StatusNotFound, err)
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, nil)
+}
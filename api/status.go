@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// getStatus serves the status of the Alertmanager. Its fields are a superset of the AlertmanagerStatus of the v2 API.
+func (h *Handler) getStatus(w http.ResponseWriter, _ *http.Request, am Alertmanager) {
+	h.writeJSON(w, http.StatusOK, am.GetStatus())
+}
+
+func (h *Handler) getReceivers(w http.ResponseWriter, _ *http.Request, am Alertmanager) {
+	receivers := am.GetReceivers()
+	res := make([]*amv2.Receiver, 0, len(receivers))
+	for _, r := range receivers {
+		name := r.Name
+		res = append(res, &amv2.Receiver{Name: &name})
+	}
+	h.writeJSON(w, http.StatusOK, res)
+}
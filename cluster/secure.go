@@ -0,0 +1,399 @@
+package cluster
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/client_golang/prometheus"
+	commoncfg "github.com/prometheus/common/config"
+	"github.com/prometheus/exporter-toolkit/web"
+)
+
+// DefaultKeyReloadInterval is the default interval between the loads of the keys of a SecurePeer.
+const DefaultKeyReloadInterval = time.Minute
+
+const (
+	// sealedVersion is the version of the format of the sealed messages:
+	// version (1 byte) | key ID (4 bytes) | nonce (12 bytes) | ciphertext.
+	sealedVersion  = 1
+	keyIDSize      = 4
+	sealedOverhead = 1 + keyIDSize
+)
+
+// Keys are the keys of the peers of a cluster.
+type Keys struct {
+	// Active is the symmetric key that encrypts the messages of the states sent to the other peers. It must be 16, 24
+	// or 32 bytes long, to select AES-128, AES-192 or AES-256. If empty, the messages are not encrypted, and the
+	// messages received that are not encrypted are merged too. It requires TLS, so that the membership of the cluster is authenticated too.
+	Active []byte
+	// Accepted are the other symmetric keys that decrypt the messages received from the other peers, in addition
+	// to the active key. To rotate the active key without interruption, first add the new key to the accepted keys
+	// of all peers, then make it the active key, and finally remove the previous key from the accepted keys.
+	Accepted [][]byte
+	// TLS is the certificate of the peer for mutual TLS on the TCP transport. If nil, the transport does not use TLS.
+	// It is only loaded when the peer is created.
+	TLS *TLSCertificate
+}
+
+// TLSCertificate is the certificate of a peer for mutual TLS. All peers must have a certificate signed by the CA,
+// valid for both server and client authentication.
+type TLSCertificate struct {
+	// CA is the PEM encoded certificate of the CA that signs the certificates of the peers.
+	CA string
+	// Cert is the PEM encoded certificate of the peer.
+	Cert string
+	// Key is the PEM encoded private key of the peer.
+	Key string
+	// ServerName is the name that the certificates of the other peers must be valid for. If empty, it is the host
+	// of their address.
+	ServerName string
+}
+
+// KeyLoader loads the keys of the peer. It is called when the peer is created, then periodically in the background
+// to pick up the rotated keys, with a context that expires after the reload interval.
+type KeyLoader func(ctx context.Context) (Keys, error)
+
+// SecureOptions configures a SecurePeer. The fields other than Keys and KeyReloadInterval are the arguments of Create.
+type SecureOptions struct {
+	BindAddr               string
+	AdvertiseAddr          string
+	KnownPeers             []string
+	WaitIfEmpty            bool
+	PushPullInterval       time.Duration
+	GossipInterval         time.Duration
+	TCPTimeout             time.Duration
+	ProbeTimeout           time.Duration
+	ProbeInterval          time.Duration
+	AllowInsecureAdvertise bool
+	Label                  string
+
+	// Keys loads the keys of the peer. It is required.
+	Keys KeyLoader
+	// KeyReloadInterval is the interval between the loads of the keys, and the timeout of each load.
+	// Defaults to DefaultKeyReloadInterval.
+	KeyReloadInterval time.Duration
+}
+
+// SecurePeer is a Peer whose gossip is encrypted and authenticated.
+//
+// With a TLS certificate, all the traffic between the peers, including the membership and probe messages, goes
+// through the TLS transport, which requires mutual TLS. Peers without a certificate signed by the CA cannot join
+// the cluster, and cannot change the positions of the peers.
+//
+// In addition, the messages of the states are encrypted with AES-GCM using the active key, and decrypted with any of
+// the accepted keys. Messages that cannot be decrypted, such as the messages of peers without the keys, are not
+// merged. This protects the states from the peers with a certificate of the CA but without the keys. An active key
+// requires a TLS certificate, as the membership of the cluster would not be authenticated otherwise.
+//
+// Without a TLS certificate and an active key, only accepted keys can be set, to enable the encryption without
+// interruption, and the traffic is neither encrypted nor authenticated.
+//
+// The keys only encrypt the payloads of the states: they are not the SecretKey or the Keyring of memberlist, as Create
+// does not expose the configuration of memberlist. The messages of the memberlist protocol itself, such as the
+// membership, probe and push/pull messages other than the states, are only protected by the TLS transport.
+type SecurePeer struct {
+	*Peer
+	keyring *keyring
+
+	stopOnce   sync.Once
+	stopReload chan struct{}
+	reloadDone chan struct{}
+}
+
+// CreateSecure creates a SecurePeer. It fails if the keys cannot be loaded.
+func CreateSecure(l log.Logger, reg prometheus.Registerer, opts SecureOptions) (*SecurePeer, error) {
+	if opts.Keys == nil {
+		return nil, errors.New("keys loader must be set")
+	}
+	if opts.KeyReloadInterval <= 0 {
+		opts.KeyReloadInterval = DefaultKeyReloadInterval
+	}
+	keys, err := opts.Keys(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keys: %w", err)
+	}
+	kr := &keyring{
+		load:     opts.Keys,
+		interval: opts.KeyReloadInterval,
+		logger:   l,
+		tls:      keys.TLS != nil,
+	}
+	if err := kr.set(keys); err != nil {
+		return nil, err
+	}
+	if keys.TLS == nil {
+		level.Warn(l).Log("msg", "No TLS certificate, the traffic of the cluster is neither encrypted nor authenticated")
+	} else if len(keys.Active) == 0 {
+		level.Warn(l).Log("msg", "No active key, the messages of the states are not encrypted")
+	}
+
+	var tlsConfig *cluster.TLSTransportConfig
+	if keys.TLS != nil {
+		tlsConfig = keys.TLS.transportConfig()
+	}
+	p, err := Create(
+		l,
+		reg,
+		opts.BindAddr,
+		opts.AdvertiseAddr,
+		opts.KnownPeers,
+		opts.WaitIfEmpty,
+		opts.PushPullInterval,
+		opts.GossipInterval,
+		opts.TCPTimeout,
+		opts.ProbeTimeout,
+		opts.ProbeInterval,
+		tlsConfig,
+		opts.AllowInsecureAdvertise,
+		opts.Label,
+	)
+	if err != nil {
+		return nil, err
+	}
+	sp := &SecurePeer{
+		Peer:       p,
+		keyring:    kr,
+		stopReload: make(chan struct{}),
+		reloadDone: make(chan struct{}),
+	}
+	go func() {
+		kr.run(sp.stopReload)
+		close(sp.reloadDone)
+	}()
+	return sp, nil
+}
+
+// Leave stops reloading the keys and leaves the cluster.
+func (p *SecurePeer) Leave(timeout time.Duration) error {
+	p.stopOnce.Do(func() {
+		close(p.stopReload)
+		<-p.reloadDone
+	})
+	return p.Peer.Leave(timeout)
+}
+
+// AddState adds the state to the peer. The messages of the state are encrypted before they are sent,
+// and decrypted before they are merged.
+func (p *SecurePeer) AddState(key string, s State, reg prometheus.Registerer) ClusterChannel {
+	c := p.Peer.AddState(key, &sealedState{State: s, keyring: p.keyring, key: key}, reg)
+	return &sealedChannel{ClusterChannel: c, keyring: p.keyring, key: key}
+}
+
+// transportConfig returns the configuration of the TLS transport, which requires and verifies the certificates
+// of the other peers.
+func (c *TLSCertificate) transportConfig() *cluster.TLSTransportConfig {
+	return &cluster.TLSTransportConfig{
+		TLSServerConfig: &web.TLSConfig{
+			TLSCert:       c.Cert,
+			TLSKey:        commoncfg.Secret(c.Key),
+			ClientCAsText: c.CA,
+			ClientAuth:    "RequireAndVerifyClientCert",
+		},
+		TLSClientConfig: &commoncfg.TLSConfig{
+			CA:         c.CA,
+			Cert:       c.Cert,
+			Key:        commoncfg.Secret(c.Key),
+			ServerName: c.ServerName,
+		},
+	}
+}
+
+// keyring encrypts and decrypts the messages with the keys, which it reloads periodically in the background.
+type keyring struct {
+	load     KeyLoader
+	interval time.Duration
+	logger   log.Logger
+	// tls is true if the transport uses mutual TLS, which the active keys require.
+	tls bool
+
+	mtx      sync.Mutex
+	active   cipher.AEAD
+	activeID [keyIDSize]byte
+	accepted map[[keyIDSize]byte]cipher.AEAD
+}
+
+// reload loads the keys and replaces the keys of the keyring.
+func (k *keyring) reload(ctx context.Context) (Keys, error) {
+	keys, err := k.load(ctx)
+	if err != nil {
+		return Keys{}, fmt.Errorf("failed to load keys: %w", err)
+	}
+	return keys, k.set(keys)
+}
+
+// set replaces the keys of the keyring.
+func (k *keyring) set(keys Keys) error {
+	accepted := make(map[[keyIDSize]byte]cipher.AEAD, len(keys.Accepted)+1)
+	var active cipher.AEAD
+	var activeID [keyIDSize]byte
+	if len(keys.Active) > 0 {
+		if !k.tls {
+			return errors.New("an active key requires the TLS certificate of the peer, which is only loaded when the peer is created")
+		}
+		var err error
+		if active, err = newAEAD(keys.Active); err != nil {
+			return fmt.Errorf("invalid active key: %w", err)
+		}
+		activeID = keyID(keys.Active)
+		accepted[activeID] = active
+	}
+	for i, key := range keys.Accepted {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return fmt.Errorf("invalid accepted key %d: %w", i, err)
+		}
+		accepted[keyID(key)] = aead
+	}
+
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	k.active = active
+	k.activeID = activeID
+	k.accepted = accepted
+	return nil
+}
+
+// run reloads the keys every reload interval, until stopc is closed. Each load times out after the reload interval,
+// and the messages are sealed and opened with the current keys meanwhile. If the keys cannot be loaded, the current
+// keys are kept until the next attempt.
+func (k *keyring) run(stopc <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopc:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+			loadCtx, loadCancel := context.WithTimeout(ctx, k.interval)
+			if _, err := k.reload(loadCtx); err != nil && ctx.Err() == nil {
+				level.Warn(k.logger).Log("msg", "Failed to reload the keys of the cluster, keeping the previous keys", "err", err)
+			}
+			loadCancel()
+		}
+	}
+}
+
+// seal encrypts the message of the state with the active key. The message is returned as is if there is no active key.
+func (k *keyring) seal(state string, msg []byte) ([]byte, error) {
+	k.mtx.Lock()
+	aead, id := k.active, k.activeID
+	k.mtx.Unlock()
+	if aead == nil {
+		return msg, nil
+	}
+
+	b := make([]byte, sealedOverhead+aead.NonceSize(), sealedOverhead+aead.NonceSize()+len(msg)+aead.Overhead())
+	b[0] = sealedVersion
+	copy(b[1:sealedOverhead], id[:])
+	nonce := b[sealedOverhead:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The name of the state is authenticated, so that the message of a state cannot be merged into another state.
+	return aead.Seal(b, nonce, msg, []byte(state)), nil
+}
+
+// open decrypts the message of the state with the accepted key it was encrypted with. Without an active key,
+// messages that are not encrypted are returned as is, so that the encryption can be enabled without interruption
+// by adding the key to the accepted keys of all peers before making it the active key.
+func (k *keyring) open(state string, b []byte) ([]byte, error) {
+	k.mtx.Lock()
+	active, accepted := k.active, k.accepted
+	k.mtx.Unlock()
+
+	if len(b) >= sealedOverhead && b[0] == sealedVersion {
+		var id [keyIDSize]byte
+		copy(id[:], b[1:sealedOverhead])
+		if aead, ok := accepted[id]; ok {
+			b = b[sealedOverhead:]
+			if len(b) < aead.NonceSize() {
+				return nil, errors.New("message is too short")
+			}
+			msg, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(state))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt message: %w", err)
+			}
+			return msg, nil
+		}
+	}
+	if active == nil {
+		return b, nil
+	}
+	return nil, errors.New("message is not encrypted with an accepted key")
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyID identifies the key in the sealed messages, without revealing it.
+func keyID(key []byte) [keyIDSize]byte {
+	var id [keyIDSize]byte
+	sum := sha256.Sum256(key)
+	copy(id[:], sum[:keyIDSize])
+	return id
+}
+
+type sealedState struct {
+	State
+	keyring *keyring
+	key     string
+}
+
+// MarshalBinary implements the State interface.
+func (s *sealedState) MarshalBinary() ([]byte, error) {
+	b, err := s.State.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return s.keyring.seal(s.key, b)
+}
+
+// Merge implements the State interface.
+func (s *sealedState) Merge(b []byte) error {
+	msg, err := s.keyring.open(s.key, b)
+	if err != nil {
+		return err
+	}
+	return s.State.Merge(msg)
+}
+
+type sealedChannel struct {
+	ClusterChannel
+	keyring *keyring
+	key     string
+}
+
+// Broadcast implements the ClusterChannel interface. The message is dropped if it cannot be encrypted.
+func (c *sealedChannel) Broadcast(b []byte) {
+	msg, err := c.keyring.seal(c.key, b)
+	if err != nil {
+		level.Warn(c.keyring.logger).Log("msg", "Failed to encrypt the message of the state, dropping it", "key", c.key, "err", err)
+		return
+	}
+	c.ClusterChannel.Broadcast(msg)
+}
@@ -0,0 +1,409 @@
+package cluster
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey1 = []byte("0123456789abcdef0123456789abcdef")
+	testKey2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func newTestKeyring(t *testing.T, keys Keys) *keyring {
+	t.Helper()
+	k := &keyring{
+		load:     func(context.Context) (Keys, error) { return keys, nil },
+		interval: time.Hour,
+		logger:   log.NewNopLogger(),
+		tls:      true,
+	}
+	_, err := k.reload(context.Background())
+	require.NoError(t, err)
+	return k
+}
+
+func TestKeyring(t *testing.T) {
+	msg := []byte("state")
+
+	t.Run("sealed messages are opened with the active or accepted keys", func(t *testing.T) {
+		old := newTestKeyring(t, Keys{Active: testKey1})
+		sealed, err := old.seal("silences:1", msg)
+		require.NoError(t, err)
+		require.NotContains(t, string(sealed), string(msg))
+
+		rotated := newTestKeyring(t, Keys{Active: testKey2, Accepted: [][]byte{testKey1}})
+		opened, err := rotated.open("silences:1", sealed)
+		require.NoError(t, err)
+		require.Equal(t, msg, opened)
+
+		sealed, err = rotated.seal("silences:1", msg)
+		require.NoError(t, err)
+		_, err = old.open("silences:1", sealed)
+		require.Error(t, err)
+	})
+
+	t.Run("sealed messages are bound to their state", func(t *testing.T) {
+		k := newTestKeyring(t, Keys{Active: testKey1})
+		sealed, err := k.seal("silences:1", msg)
+		require.NoError(t, err)
+		_, err = k.open("notificationlog:1", sealed)
+		require.Error(t, err)
+
+		sealed[len(sealed)-1] ^= 1
+		_, err = k.open("silences:1", sealed)
+		require.Error(t, err)
+	})
+
+	t.Run("plain messages are only accepted without an active key", func(t *testing.T) {
+		k := newTestKeyring(t, Keys{Active: testKey1})
+		_, err := k.open("silences:1", msg)
+		require.Error(t, err)
+
+		migrating := newTestKeyring(t, Keys{Accepted: [][]byte{testKey1}})
+		sealed, err := migrating.seal("silences:1", msg)
+		require.NoError(t, err)
+		require.Equal(t, msg, sealed)
+		opened, err := migrating.open("silences:1", msg)
+		require.NoError(t, err)
+		require.Equal(t, msg, opened)
+
+		sealed, err = k.seal("silences:1", msg)
+		require.NoError(t, err)
+		opened, err = migrating.open("silences:1", sealed)
+		require.NoError(t, err)
+		require.Equal(t, msg, opened)
+	})
+
+	t.Run("invalid keys are rejected", func(t *testing.T) {
+		k := &keyring{load: func(context.Context) (Keys, error) { return Keys{Active: []byte("short")}, nil }, tls: true}
+		_, err := k.reload(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("active keys require TLS", func(t *testing.T) {
+		k := &keyring{load: func(context.Context) (Keys, error) { return Keys{Active: testKey1}, nil }}
+		_, err := k.reload(context.Background())
+		require.ErrorContains(t, err, "an active key requires the TLS certificate of the peer")
+
+		// Accepted keys do not, so that the encryption can be enabled after TLS.
+		k.load = func(context.Context) (Keys, error) { return Keys{Accepted: [][]byte{testKey1}}, nil }
+		_, err = k.reload(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("keys are reloaded periodically in the background", func(t *testing.T) {
+		var (
+			mtx     sync.Mutex
+			keys    = Keys{Active: testKey1}
+			loadErr error
+		)
+		k := &keyring{
+			load: func(context.Context) (Keys, error) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				return keys, loadErr
+			},
+			interval: 10 * time.Millisecond,
+			logger:   log.NewNopLogger(),
+			tls:      true,
+		}
+		_, err := k.reload(context.Background())
+		require.NoError(t, err)
+		first, err := k.seal("silences:1", msg)
+		require.NoError(t, err)
+
+		stopc, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			k.run(stopc)
+			close(done)
+		}()
+		t.Cleanup(func() {
+			close(stopc)
+			<-done
+		})
+
+		// The previous keys are kept if the keys cannot be loaded.
+		mtx.Lock()
+		keys, loadErr = Keys{Active: testKey2}, errors.New("unavailable")
+		mtx.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		sealed, err := k.seal("silences:1", msg)
+		require.NoError(t, err)
+		require.Equal(t, first[:sealedOverhead], sealed[:sealedOverhead])
+
+		mtx.Lock()
+		loadErr = nil
+		mtx.Unlock()
+		require.Eventually(t, func() bool {
+			_, err := k.open("silences:1", first)
+			return err != nil
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("slow key loads do not block the messages", func(t *testing.T) {
+		loading, release := make(chan bool, 1), make(chan struct{})
+		k := newTestKeyring(t, Keys{Active: testKey1})
+		k.interval = 10 * time.Millisecond
+		k.load = func(ctx context.Context) (Keys, error) {
+			_, hasDeadline := ctx.Deadline()
+			select {
+			case loading <- hasDeadline:
+			default:
+			}
+			<-release
+			return Keys{}, errors.New("unavailable")
+		}
+
+		stopc, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			k.run(stopc)
+			close(done)
+		}()
+		// The load times out.
+		require.True(t, <-loading)
+
+		// The messages are sealed and opened with the current keys while the load is in progress.
+		sealed, err := k.seal("silences:1", msg)
+		require.NoError(t, err)
+		opened, err := k.open("silences:1", sealed)
+		require.NoError(t, err)
+		require.Equal(t, msg, opened)
+
+		close(release)
+		close(stopc)
+		<-done
+	})
+}
+
+// testCA issues the certificates of the peers.
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+}
+
+func (ca *testCA) issue(t *testing.T) *TLSCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "peer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return &TLSCertificate{
+		CA:   ca.certPEM,
+		Cert: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		Key:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+// setState is a state of values, merged by union.
+type setState struct {
+	mtx    sync.Mutex
+	values map[string]struct{}
+}
+
+func newSetState() *setState {
+	return &setState{values: map[string]struct{}{}}
+}
+
+func (s *setState) MarshalBinary() ([]byte, error) {
+	return json.Marshal(s.Values())
+}
+
+func (s *setState) Merge(b []byte) error {
+	var values []string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, v := range values {
+		s.values[v] = struct{}{}
+	}
+	return nil
+}
+
+func (s *setState) Add(c ClusterChannel, v string) {
+	s.mtx.Lock()
+	s.values[v] = struct{}{}
+	s.mtx.Unlock()
+	b, _ := json.Marshal([]string{v})
+	c.Broadcast(b)
+}
+
+func (s *setState) Values() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	res := make([]string, 0, len(s.values))
+	for v := range s.values {
+		res = append(res, v)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func newTestSecurePeer(t *testing.T, keys Keys, knownPeers ...string) *SecurePeer {
+	t.Helper()
+	p, err := CreateSecure(log.NewNopLogger(), prometheus.NewRegistry(), SecureOptions{
+		BindAddr:         "127.0.0.1:0",
+		KnownPeers:       knownPeers,
+		PushPullInterval: 100 * time.Millisecond,
+		GossipInterval:   50 * time.Millisecond,
+		TCPTimeout:       time.Second,
+		ProbeTimeout:     100 * time.Millisecond,
+		ProbeInterval:    200 * time.Millisecond,
+		Keys:             func(context.Context) (Keys, error) { return keys, nil },
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, p.Leave(time.Second))
+	})
+	return p
+}
+
+func TestSecurePeer(t *testing.T) {
+	ca := newTestCA(t)
+	keys := func() Keys {
+		return Keys{Active: testKey1, TLS: ca.issue(t)}
+	}
+
+	a := newTestSecurePeer(t, keys())
+	stateA := newSetState()
+	chA := a.AddState("state", stateA, prometheus.NewRegistry())
+	require.NoError(t, a.Join(0, 0))
+
+	b := newTestSecurePeer(t, keys(), a.Self().Address())
+	stateB := newSetState()
+	chB := b.AddState("state", stateB, prometheus.NewRegistry())
+	require.NoError(t, b.Join(0, 0))
+	require.Eventually(t, func() bool {
+		return a.ClusterSize() == 2 && b.ClusterSize() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The states are replicated between the peers with the keys.
+	stateA.Add(chA, "1")
+	stateB.Add(chB, "2")
+	require.Eventually(t, func() bool {
+		return len(stateA.Values()) == 2 && len(stateB.Values()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	t.Run("peers without a certificate of the CA cannot join", func(t *testing.T) {
+		c, err := CreateSecure(log.NewNopLogger(), prometheus.NewRegistry(), SecureOptions{
+			BindAddr:   "127.0.0.1:0",
+			KnownPeers: []string{a.Self().Address()},
+			TCPTimeout: time.Second,
+			Keys: func(context.Context) (Keys, error) {
+				return Keys{Active: testKey1, TLS: newTestCA(t).issue(t)}, nil
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, c.Leave(time.Second)) })
+		require.Error(t, c.Join(0, 0))
+		require.Equal(t, 2, a.ClusterSize())
+	})
+
+	t.Run("peers without TLS cannot join", func(t *testing.T) {
+		c, err := CreateSecure(log.NewNopLogger(), prometheus.NewRegistry(), SecureOptions{
+			BindAddr:   "127.0.0.1:0",
+			KnownPeers: []string{a.Self().Address()},
+			TCPTimeout: time.Second,
+			Keys: func(context.Context) (Keys, error) {
+				return Keys{}, nil
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, c.Leave(time.Second)) })
+		require.Error(t, c.Join(0, 0))
+		require.Equal(t, 2, a.ClusterSize())
+	})
+
+	t.Run("states are not merged from peers with other keys", func(t *testing.T) {
+		c := newTestSecurePeer(t, Keys{Active: testKey2, TLS: ca.issue(t)}, a.Self().Address())
+		stateC := newSetState()
+		chC := c.AddState("state", stateC, prometheus.NewRegistry())
+		require.NoError(t, c.Join(0, 0))
+		require.Eventually(t, func() bool {
+			return a.ClusterSize() == 3
+		}, 5*time.Second, 10*time.Millisecond)
+
+		stateC.Add(chC, "3")
+		stateA.Add(chA, "4")
+		require.Eventually(t, func() bool {
+			return len(stateB.Values()) == 3
+		}, 5*time.Second, 10*time.Millisecond)
+		// Let the peers gossip and push and pull their states.
+		time.Sleep(500 * time.Millisecond)
+		require.Equal(t, []string{"1", "2", "4"}, stateA.Values())
+		require.Equal(t, []string{"1", "2", "4"}, stateB.Values())
+		require.Equal(t, []string{"3"}, stateC.Values())
+	})
+}
+
+func TestCreateSecure_KeysRequired(t *testing.T) {
+	_, err := CreateSecure(log.NewNopLogger(), prometheus.NewRegistry(), SecureOptions{BindAddr: "127.0.0.1:0"})
+	require.Error(t, err)
+
+	_, err = CreateSecure(log.NewNopLogger(), prometheus.NewRegistry(), SecureOptions{
+		BindAddr: "127.0.0.1:0",
+		Keys: func(context.Context) (Keys, error) {
+			return Keys{}, errors.New("unavailable")
+		},
+	})
+	require.Error(t, err)
+
+	_, err = CreateSecure(log.NewNopLogger(), prometheus.NewRegistry(), SecureOptions{
+		BindAddr: "127.0.0.1:0",
+		Keys: func(context.Context) (Keys, error) {
+			return Keys{Active: testKey1}, nil
+		},
+	})
+	require.ErrorContains(t, err, "an active key requires the TLS certificate of the peer")
+}
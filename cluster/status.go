@@ -0,0 +1,216 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClusterPeer is implemented by the peers that can report their membership.
+type ClusterPeer = cluster.ClusterPeer //nolint:revive
+
+// StatePeer is a peer that replicates states, such as a Peer or a store-backed peer.
+type StatePeer interface {
+	AddState(string, State, prometheus.Registerer) ClusterChannel
+	Position() int
+	WaitReady(context.Context) error
+}
+
+// PeerStatus is the membership and the state synchronization of a peer, for introspection.
+type PeerStatus struct {
+	// Name is the name of the peer in the cluster. It is empty if the peer does not report its membership.
+	Name string `json:"name,omitempty"`
+	// Status is the status of the peer, such as "ready" or "settling". It is empty if the peer does not report
+	// its membership.
+	Status   string         `json:"status,omitempty"`
+	Position int            `json:"position"`
+	Peers    []MemberStatus `json:"peers,omitempty"`
+	States   []StateStatus  `json:"states"`
+}
+
+// MemberStatus is a member of the cluster.
+type MemberStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+}
+
+// StateStatus is the synchronization of a state with the other peers. Messages are sent when the state is broadcast,
+// or marshaled for a push of the full state to the other peers. They are received when a broadcast or the full state
+// of another peer is merged.
+type StateStatus struct {
+	Key                   string `json:"key"`
+	MessagesSent          uint64 `json:"messagesSent"`
+	MessagesSentBytes     uint64 `json:"messagesSentBytes"`
+	MessagesReceived      uint64 `json:"messagesReceived"`
+	MessagesReceivedBytes uint64 `json:"messagesReceivedBytes"`
+	// SendFailures is the number of times the full state could not be marshaled.
+	SendFailures uint64 `json:"sendFailures"`
+	// ReceiveFailures is the number of messages that could not be merged.
+	ReceiveFailures uint64 `json:"receiveFailures"`
+	// LastPush is the last time the full state was marshaled for a push.
+	LastPush time.Time `json:"lastPush"`
+	// LastPull is the last time a message was merged.
+	LastPull time.Time `json:"lastPull"`
+}
+
+// NewPeerStatus returns the status of the peer with the states. The membership is reported if the peer
+// implements ClusterPeer.
+func NewPeerStatus(peer StatePeer, states []StateStatus) PeerStatus {
+	s := PeerStatus{
+		Position: peer.Position(),
+		States:   states,
+	}
+	if p, ok := peer.(ClusterPeer); ok {
+		s.Name = p.Name()
+		s.Status = p.Status()
+		for _, m := range p.Peers() {
+			s.Peers = append(s.Peers, MemberStatus{Name: m.Name(), Address: m.Address()})
+		}
+		sort.Slice(s.Peers, func(i, j int) bool { return s.Peers[i].Name < s.Peers[j].Name })
+	}
+	return s
+}
+
+// StateTracker records the synchronization of the states added to peers through it.
+type StateTracker struct {
+	mtx    sync.Mutex
+	states map[string]*StateStatus
+	now    func() time.Time
+}
+
+// NewStateTracker returns a StateTracker without states.
+func NewStateTracker() *StateTracker {
+	return &StateTracker{states: map[string]*StateStatus{}, now: time.Now}
+}
+
+// AddState adds the state to the peer, recording the messages of the state and of its channel.
+func (t *StateTracker) AddState(peer StatePeer, key string, s State, reg prometheus.Registerer) ClusterChannel {
+	t.mtx.Lock()
+	if _, ok := t.states[key]; !ok {
+		t.states[key] = &StateStatus{Key: key}
+	}
+	t.mtx.Unlock()
+	c := peer.AddState(key, &trackedState{State: s, tracker: t, key: key}, reg)
+	return &trackedChannel{ClusterChannel: c, tracker: t, key: key}
+}
+
+// States returns the status of the states, sorted by key.
+func (t *StateTracker) States() []StateStatus {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	res := make([]StateStatus, 0, len(t.states))
+	for _, s := range t.states {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+func (t *StateTracker) update(key string, f func(s *StateStatus, now time.Time)) {
+	now := t.now()
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if s, ok := t.states[key]; ok {
+		f(s, now)
+	}
+}
+
+type trackedState struct {
+	State
+	tracker *StateTracker
+	key     string
+}
+
+// MarshalBinary implements the State interface.
+func (s *trackedState) MarshalBinary() ([]byte, error) {
+	b, err := s.State.MarshalBinary()
+	s.tracker.update(s.key, func(st *StateStatus, now time.Time) {
+		if err != nil {
+			st.SendFailures++
+			return
+		}
+		st.MessagesSent++
+		st.MessagesSentBytes += uint64(len(b))
+		st.LastPush = now
+	})
+	return b, err
+}
+
+// Merge implements the State interface.
+func (s *trackedState) Merge(b []byte) error {
+	err := s.State.Merge(b)
+	s.tracker.update(s.key, func(st *StateStatus, now time.Time) {
+		if err != nil {
+			st.ReceiveFailures++
+			return
+		}
+		st.MessagesReceived++
+		st.MessagesReceivedBytes += uint64(len(b))
+		st.LastPull = now
+	})
+	return err
+}
+
+type trackedChannel struct {
+	ClusterChannel
+	tracker *StateTracker
+	key     string
+}
+
+// Broadcast implements the ClusterChannel interface.
+func (c *trackedChannel) Broadcast(b []byte) {
+	c.ClusterChannel.Broadcast(b)
+	c.tracker.update(c.key, func(st *StateStatus, _ time.Time) {
+		st.MessagesSent++
+		st.MessagesSentBytes += uint64(len(b))
+	})
+}
+
+// IntrospectablePeer wraps a peer to record the synchronization of its states, for introspection.
+type IntrospectablePeer struct {
+	StatePeer
+	tracker *StateTracker
+}
+
+// NewIntrospectablePeer returns an IntrospectablePeer wrapping the peer.
+func NewIntrospectablePeer(peer StatePeer) *IntrospectablePeer {
+	return &IntrospectablePeer{StatePeer: peer, tracker: NewStateTracker()}
+}
+
+// AddState adds the state to the wrapped peer, recording its synchronization.
+func (p *IntrospectablePeer) AddState(key string, s State, reg prometheus.Registerer) ClusterChannel {
+	return p.tracker.AddState(p.StatePeer, key, s, reg)
+}
+
+// ClusterStatus returns the membership of the peer and the synchronization of all its states.
+func (p *IntrospectablePeer) ClusterStatus() PeerStatus {
+	return NewPeerStatus(p.StatePeer, p.tracker.States())
+}
+
+// Name implements the ClusterPeer interface. It is empty if the wrapped peer does not report its membership.
+func (p *IntrospectablePeer) Name() string {
+	if cp, ok := p.StatePeer.(ClusterPeer); ok {
+		return cp.Name()
+	}
+	return ""
+}
+
+// Status implements the ClusterPeer interface. It is empty if the wrapped peer does not report its membership.
+func (p *IntrospectablePeer) Status() string {
+	if cp, ok := p.StatePeer.(ClusterPeer); ok {
+		return cp.Status()
+	}
+	return ""
+}
+
+// Peers implements the ClusterPeer interface. It is empty if the wrapped peer does not report its membership.
+func (p *IntrospectablePeer) Peers() []ClusterMember {
+	if cp, ok := p.StatePeer.(ClusterPeer); ok {
+		return cp.Peers()
+	}
+	return nil
+}
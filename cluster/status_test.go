@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeState struct {
+	data     []byte
+	mergeErr error
+}
+
+func (s *fakeState) MarshalBinary() ([]byte, error) {
+	return s.data, nil
+}
+
+func (s *fakeState) Merge(b []byte) error {
+	return s.mergeErr
+}
+
+type fakeChannel struct {
+	broadcasts [][]byte
+}
+
+func (c *fakeChannel) Broadcast(b []byte) {
+	c.broadcasts = append(c.broadcasts, b)
+}
+
+type fakeMember struct {
+	name, address string
+}
+
+func (m fakeMember) Name() string    { return m.name }
+func (m fakeMember) Address() string { return m.address }
+
+type fakePeer struct {
+	states   map[string]State
+	channels map[string]*fakeChannel
+}
+
+func newFakePeer() *fakePeer {
+	return &fakePeer{states: map[string]State{}, channels: map[string]*fakeChannel{}}
+}
+
+func (p *fakePeer) AddState(key string, s State, _ prometheus.Registerer) ClusterChannel {
+	p.states[key] = s
+	p.channels[key] = &fakeChannel{}
+	return p.channels[key]
+}
+
+func (p *fakePeer) Position() int                   { return 1 }
+func (p *fakePeer) WaitReady(context.Context) error { return nil }
+
+type fakeClusterPeer struct {
+	*fakePeer
+}
+
+func (p *fakeClusterPeer) Name() string   { return "b" }
+func (p *fakeClusterPeer) Status() string { return "ready" }
+func (p *fakeClusterPeer) Peers() []ClusterMember {
+	return []ClusterMember{fakeMember{"b", "10.0.0.2:9094"}, fakeMember{"a", "10.0.0.1:9094"}}
+}
+
+func TestStateTracker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewStateTracker()
+	tracker.now = func() time.Time { return now }
+	peer := newFakePeer()
+
+	silences := &fakeState{data: []byte("silences")}
+	nflog := &fakeState{data: []byte("nflog"), mergeErr: errors.New("invalid")}
+	c := tracker.AddState(peer, "silences:1", silences, nil)
+	tracker.AddState(peer, "notificationlog:1", nflog, nil)
+
+	c.Broadcast([]byte("update"))
+	require.Equal(t, [][]byte{[]byte("update")}, peer.channels["silences:1"].broadcasts)
+	_, err := peer.states["silences:1"].MarshalBinary()
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	require.NoError(t, peer.states["silences:1"].Merge([]byte("remote")))
+	require.Error(t, peer.states["notificationlog:1"].Merge([]byte("remote")))
+
+	require.Equal(t, []StateStatus{
+		{
+			Key:             "notificationlog:1",
+			ReceiveFailures: 1,
+		},
+		{
+			Key:                   "silences:1",
+			MessagesSent:          2,
+			MessagesSentBytes:     uint64(len("update") + len("silences")),
+			MessagesReceived:      1,
+			MessagesReceivedBytes: uint64(len("remote")),
+			LastPush:              now.Add(-time.Minute),
+			LastPull:              now,
+		},
+	}, tracker.States())
+}
+
+func TestIntrospectablePeer(t *testing.T) {
+	t.Run("without membership", func(t *testing.T) {
+		p := NewIntrospectablePeer(newFakePeer())
+		p.AddState("silences:1", &fakeState{}, nil)
+		require.Equal(t, PeerStatus{
+			Position: 1,
+			States:   []StateStatus{{Key: "silences:1"}},
+		}, p.ClusterStatus())
+	})
+
+	t.Run("with membership", func(t *testing.T) {
+		p := NewIntrospectablePeer(&fakeClusterPeer{newFakePeer()})
+		p.AddState("silences:1", &fakeState{}, nil)
+		require.Equal(t, PeerStatus{
+			Name:     "b",
+			Status:   "ready",
+			Position: 1,
+			Peers:    []MemberStatus{{Name: "a", Address: "10.0.0.1:9094"}, {Name: "b", Address: "10.0.0.2:9094"}},
+			States:   []StateStatus{{Key: "silences:1"}},
+		}, p.ClusterStatus())
+		require.Equal(t, "b", p.Name())
+		require.Len(t, p.Peers(), 2)
+	})
+}
@@ -0,0 +1,143 @@
+package storepeer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	statesDir = "states"
+	leasesDir = "leases"
+)
+
+// FSStore is a Store in a directory of the file system, which can be shared by the peers on the same host,
+// or through a shared volume. Files are replaced atomically, so that peers never read partial states.
+type FSStore struct {
+	dir string
+}
+
+var _ Store = (*FSStore)(nil)
+
+// NewFSStore returns a FSStore in the directory, which is created if it does not exist.
+func NewFSStore(dir string) (*FSStore, error) {
+	for _, d := range []string{filepath.Join(dir, statesDir), filepath.Join(dir, leasesDir)} {
+		if err := os.MkdirAll(d, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create store directory: %w", err)
+		}
+	}
+	return &FSStore{dir: dir}, nil
+}
+
+// PutState implements the Store interface.
+func (s *FSStore) PutState(_ context.Context, peer, key string, state []byte) error {
+	dir := filepath.Join(s.dir, statesDir, encodeName(key))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(dir, encodeName(peer)), state)
+}
+
+// GetStates implements the Store interface.
+func (s *FSStore) GetStates(_ context.Context, key string) (map[string][]byte, error) {
+	files, err := readDir(filepath.Join(s.dir, statesDir, encodeName(key)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read states: %w", err)
+	}
+	return files, nil
+}
+
+// PutLease implements the Store interface.
+func (s *FSStore) PutLease(_ context.Context, peer string, expiresAt time.Time) error {
+	b, err := expiresAt.MarshalText()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dir, leasesDir, encodeName(peer)), b)
+}
+
+// DeleteLease implements the Store interface.
+func (s *FSStore) DeleteLease(_ context.Context, peer string) error {
+	err := os.Remove(filepath.Join(s.dir, leasesDir, encodeName(peer)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete lease: %w", err)
+	}
+	return nil
+}
+
+// GetLeases implements the Store interface.
+func (s *FSStore) GetLeases(_ context.Context) (map[string]time.Time, error) {
+	files, err := readDir(filepath.Join(s.dir, leasesDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leases: %w", err)
+	}
+	leases := make(map[string]time.Time, len(files))
+	for peer, b := range files {
+		var expiresAt time.Time
+		if err := expiresAt.UnmarshalText(b); err != nil {
+			return nil, fmt.Errorf("invalid lease of peer %s: %w", peer, err)
+		}
+		leases[peer] = expiresAt
+	}
+	return leases, nil
+}
+
+// encodeName encodes a name of peer or key into a file name, as they may contain characters invalid in file names.
+func encodeName(name string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(name))
+}
+
+// readDir returns the contents of the files of the directory by decoded name. Temporary files are skipped.
+func readDir(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string][]byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]byte, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name, err := base64.RawURLEncoding.DecodeString(e.Name())
+		if err != nil {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res[string(name)] = b
+	}
+	return res, nil
+}
+
+// writeFileAtomic writes the file through a temporary file renamed over it.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package storepeer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFSStore(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	states, err := s.GetStates(ctx, "silences:1")
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	require.NoError(t, s.PutState(ctx, "peer/a", "silences:1", []byte("a")))
+	require.NoError(t, s.PutState(ctx, "peer/b", "silences:1", []byte("b")))
+	require.NoError(t, s.PutState(ctx, "peer/a", "silences:1", []byte("a2")))
+	require.NoError(t, s.PutState(ctx, "peer/a", "notificationlog:1", []byte("n")))
+	states, err = s.GetStates(ctx, "silences:1")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"peer/a": []byte("a2"), "peer/b": []byte("b")}, states)
+
+	// Temporary files of writes in progress are skipped.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, statesDir, encodeName("silences:1"), ".tmp-123"), []byte("partial"), 0o600))
+	states, err = s.GetStates(ctx, "silences:1")
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+
+	expiresAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.PutLease(ctx, "peer/a", expiresAt))
+	require.NoError(t, s.PutLease(ctx, "peer/b", expiresAt.Add(time.Minute)))
+	leases, err := s.GetLeases(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Time{"peer/a": expiresAt, "peer/b": expiresAt.Add(time.Minute)}, leases)
+
+	require.NoError(t, s.DeleteLease(ctx, "peer/a"))
+	require.NoError(t, s.DeleteLease(ctx, "peer/a"))
+	leases, err = s.GetLeases(ctx)
+	require.NoError(t, err)
+	require.Len(t, leases, 1)
+}
@@ -0,0 +1,392 @@
+// Package storepeer implements a peer that replicates the states of the Alertmanager through a shared Store,
+// for the environments where the peers cannot gossip with each other.
+package storepeer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alerting/cluster"
+)
+
+const (
+	// DefaultSyncInterval is the default interval between the synchronizations with the store.
+	DefaultSyncInterval = 5 * time.Second
+	// DefaultMinSyncInterval is the default minimum interval between the synchronizations triggered by broadcasts.
+	DefaultMinSyncInterval = 500 * time.Millisecond
+	// defaultLeaseIntervals is the default duration of the leases, in sync intervals.
+	defaultLeaseIntervals = 3
+)
+
+// Options configures a Peer.
+type Options struct {
+	// Name is the name of the peer, which must be unique among the peers sharing the store.
+	Name string
+	// Store is the store shared by the peers.
+	Store Store
+	// SyncInterval is the interval between the synchronizations with the store. Defaults to DefaultSyncInterval.
+	SyncInterval time.Duration
+	// MinSyncInterval is the minimum interval between the synchronizations triggered by broadcasts, so that bursts of
+	// broadcasts are written to the store at once. Defaults to DefaultMinSyncInterval, or SyncInterval if it is shorter.
+	MinSyncInterval time.Duration
+	// LeaseDuration is the duration of the lease of the peer, renewed on every synchronization.
+	// Defaults to three sync intervals.
+	LeaseDuration time.Duration
+	Logger        log.Logger
+}
+
+// Peer replicates the states through the store instead of gossip. On every synchronization, it renews its lease,
+// writes its states that changed since the previous synchronization, and merges the states of the other peers
+// that changed. The changes are written as full states, so a peer only has to read the store to catch up.
+//
+// The position of the peer is its index among the names of the peers with a lease that has not expired.
+type Peer struct {
+	name            string
+	store           Store
+	syncInterval    time.Duration
+	minSyncInterval time.Duration
+	leaseDuration   time.Duration
+	logger          log.Logger
+	now             func() time.Time
+
+	mtx      sync.Mutex
+	states   map[string]cluster.State
+	dirty    map[string]struct{}
+	merged   map[string]map[string][]byte // the last merged state by key and peer
+	members  []string
+	position int
+
+	started  bool
+	stopOnce sync.Once
+
+	changed chan struct{}
+	ready   chan struct{}
+	stopc   chan struct{}
+	done    chan struct{}
+}
+
+// New returns a Peer. It does not synchronize with the store until it is started.
+func New(opts Options) (*Peer, error) {
+	if opts.Name == "" {
+		return nil, errors.New("peer name must be set")
+	}
+	if opts.Store == nil {
+		return nil, errors.New("store must be set")
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = DefaultSyncInterval
+	}
+	if opts.MinSyncInterval <= 0 {
+		opts.MinSyncInterval = min(DefaultMinSyncInterval, opts.SyncInterval)
+	}
+	if opts.LeaseDuration <= 0 {
+		opts.LeaseDuration = defaultLeaseIntervals * opts.SyncInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.NewNopLogger()
+	}
+	return &Peer{
+		name:            opts.Name,
+		store:           opts.Store,
+		syncInterval:    opts.SyncInterval,
+		minSyncInterval: opts.MinSyncInterval,
+		leaseDuration:   opts.LeaseDuration,
+		logger:          log.With(opts.Logger, "component", "storepeer", "peer", opts.Name),
+		now:             time.Now,
+		states:          map[string]cluster.State{},
+		dirty:           map[string]struct{}{},
+		merged:          map[string]map[string][]byte{},
+		changed:         make(chan struct{}, 1),
+		ready:           make(chan struct{}),
+		stopc:           make(chan struct{}),
+		done:            make(chan struct{}),
+	}, nil
+}
+
+// Start starts synchronizing with the store, until the peer is stopped.
+func (p *Peer) Start() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+	go p.run()
+}
+
+// Stop stops synchronizing with the store, after writing the states that changed, and deletes the lease of the peer
+// so that the other peers do not wait for it to expire to update their position.
+func (p *Peer) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopc)
+		p.mtx.Lock()
+		started := p.started
+		p.mtx.Unlock()
+		if started {
+			<-p.done
+		}
+	})
+	err := p.pushStates(ctx)
+	return errors.Join(err, p.store.DeleteLease(ctx, p.name))
+}
+
+// AddState implements the ClusterPeer interface. The state is written to the store whenever it is broadcast.
+func (p *Peer) AddState(key string, state cluster.State, _ prometheus.Registerer) cluster.ClusterChannel {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.states[key] = state
+	p.dirty[key] = struct{}{}
+	return &channel{peer: p, key: key}
+}
+
+// Position implements the ClusterPeer interface.
+func (p *Peer) Position() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.position
+}
+
+// Name implements the cluster.ClusterPeer interface.
+func (p *Peer) Name() string {
+	return p.name
+}
+
+// Status implements the cluster.ClusterPeer interface.
+func (p *Peer) Status() string {
+	if p.Ready() {
+		return "ready"
+	}
+	return "settling"
+}
+
+// Peers implements the cluster.ClusterPeer interface. The peers have no address, as they only share the store.
+func (p *Peer) Peers() []cluster.ClusterMember {
+	members := p.Members()
+	res := make([]cluster.ClusterMember, 0, len(members))
+	for _, name := range members {
+		res = append(res, member(name))
+	}
+	return res
+}
+
+// member is a peer sharing the store.
+type member string
+
+// Name implements the cluster.ClusterMember interface.
+func (m member) Name() string { return string(m) }
+
+// Address implements the cluster.ClusterMember interface.
+func (m member) Address() string { return "" }
+
+// Members returns the names of the peers with a lease that has not expired, as of the last synchronization.
+func (p *Peer) Members() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]string(nil), p.members...)
+}
+
+// WaitReady implements the ClusterPeer interface. It returns when the first full synchronization with the store
+// completed, or when the context is done.
+func (p *Peer) WaitReady(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.ready:
+		return nil
+	}
+}
+
+// Ready returns true if the first full synchronization with the store completed.
+func (p *Peer) Ready() bool {
+	select {
+	case <-p.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Peer) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.syncInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stopc:
+			cancel()
+		case <-p.done:
+		}
+	}()
+
+	for {
+		if err := p.sync(ctx); err != nil && ctx.Err() == nil {
+			level.Warn(p.logger).Log("msg", "Failed to synchronize with the store", "err", err)
+		}
+		synced := time.Now()
+		select {
+		case <-p.stopc:
+			return
+		case <-ticker.C:
+		case <-p.changed:
+			// Write the changes without waiting for the next synchronization, but no sooner than the minimum interval
+			// after the previous one, so that the broadcasts in between are written at once. The states of the other
+			// peers are read too, which is cheap as they are only merged if they changed.
+			if wait := p.minSyncInterval - time.Since(synced); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-p.stopc:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
+
+// sync renews the lease, writes the states that changed, merges the states of the other peers that changed, and
+// updates the members of the cluster. The members and the position of the peer are updated even if some states
+// could not be written or merged, so that a single state does not stop the peers from sending notifications.
+// The peer is only ready once all the states were read from the store, so that it does not send the notifications
+// that are silenced or were already sent by the other peers; the states that cannot be merged do not delay it.
+// The errors of the states are joined.
+func (p *Peer) sync(ctx context.Context) error {
+	now := p.now()
+	var errs []error
+	if err := p.store.PutLease(ctx, p.name, now.Add(p.leaseDuration)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to renew lease: %w", err))
+	}
+	errs = append(errs, p.pushStates(ctx))
+	read, err := p.pullStates(ctx)
+	errs = append(errs, err)
+
+	leases, err := p.store.GetLeases(ctx)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to read leases: %w", err))...)
+	}
+	members := make([]string, 0, len(leases))
+	for peer, expiresAt := range leases {
+		if expiresAt.After(now) || peer == p.name {
+			members = append(members, peer)
+		}
+	}
+	if _, ok := leases[p.name]; !ok {
+		// The lease could not be renewed, the peer is still a member until it stops.
+		members = append(members, p.name)
+	}
+	sort.Strings(members)
+
+	p.mtx.Lock()
+	p.members = members
+	p.position = sort.SearchStrings(members, p.name)
+	p.mtx.Unlock()
+
+	if read && !p.Ready() {
+		level.Info(p.logger).Log("msg", "First synchronization with the store completed", "members", len(members))
+		close(p.ready)
+	}
+	return errors.Join(errs...)
+}
+
+// pushStates writes the states that changed to the store.
+func (p *Peer) pushStates(ctx context.Context) error {
+	p.mtx.Lock()
+	dirty := make(map[string]cluster.State, len(p.dirty))
+	for key := range p.dirty {
+		dirty[key] = p.states[key]
+	}
+	p.dirty = map[string]struct{}{}
+	p.mtx.Unlock()
+
+	var errs []error
+	for key, state := range dirty {
+		b, err := state.MarshalBinary()
+		if err == nil {
+			err = p.store.PutState(ctx, p.name, key, b)
+		}
+		if err != nil {
+			// Write it again on the next synchronization.
+			p.markDirty(key)
+			errs = append(errs, fmt.Errorf("failed to write state %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pullStates merges the states of the other peers that changed since they were last merged. It returns whether all
+// the states were read from the store, even if some of them could not be merged.
+func (p *Peer) pullStates(ctx context.Context) (bool, error) {
+	p.mtx.Lock()
+	states := make(map[string]cluster.State, len(p.states))
+	for key, state := range p.states {
+		states[key] = state
+	}
+	p.mtx.Unlock()
+
+	read := true
+	var errs []error
+	for key, state := range states {
+		all, err := p.store.GetStates(ctx, key)
+		if err != nil {
+			read = false
+			errs = append(errs, fmt.Errorf("failed to read state %s: %w", key, err))
+			continue
+		}
+		for peer, b := range all {
+			if peer == p.name {
+				continue
+			}
+			p.mtx.Lock()
+			prev := p.merged[key][peer]
+			p.mtx.Unlock()
+			if prev != nil && bytes.Equal(prev, b) {
+				continue
+			}
+			if err := state.Merge(b); err != nil {
+				errs = append(errs, fmt.Errorf("failed to merge state %s of peer %s: %w", key, peer, err))
+				continue
+			}
+			p.mtx.Lock()
+			if p.merged[key] == nil {
+				p.merged[key] = map[string][]byte{}
+			}
+			p.merged[key][peer] = b
+			p.mtx.Unlock()
+		}
+	}
+	return read, errors.Join(errs...)
+}
+
+func (p *Peer) markDirty(key string) {
+	p.mtx.Lock()
+	p.dirty[key] = struct{}{}
+	p.mtx.Unlock()
+}
+
+// channel marks the state of the key as changed on broadcasts. The broadcast message is not used,
+// as the full state is written to the store.
+type channel struct {
+	peer *Peer
+	key  string
+}
+
+// Broadcast implements the cluster.ClusterChannel interface.
+func (c *channel) Broadcast([]byte) {
+	c.peer.markDirty(c.key)
+	select {
+	case c.peer.changed <- struct{}{}:
+	default:
+	}
+}
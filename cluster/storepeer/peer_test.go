@@ -0,0 +1,313 @@
+package storepeer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/cluster"
+	"github.com/grafana/alerting/notify"
+)
+
+var _ notify.ClusterPeer = (*Peer)(nil)
+var _ cluster.ClusterPeer = (*Peer)(nil)
+
+// setState is a cluster.State of a set of strings.
+type setState struct {
+	mtx    sync.Mutex
+	values map[string]struct{}
+}
+
+func newSetState(values ...string) *setState {
+	s := &setState{values: map[string]struct{}{}}
+	for _, v := range values {
+		s.values[v] = struct{}{}
+	}
+	return s
+}
+
+func (s *setState) MarshalBinary() ([]byte, error) {
+	return json.Marshal(s.Values())
+}
+
+func (s *setState) Merge(b []byte) error {
+	var values []string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, v := range values {
+		s.values[v] = struct{}{}
+	}
+	return nil
+}
+
+func (s *setState) Values() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	res := make([]string, 0, len(s.values))
+	for v := range s.values {
+		res = append(res, v)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (s *setState) Add(c cluster.ClusterChannel, v string) {
+	s.mtx.Lock()
+	s.values[v] = struct{}{}
+	s.mtx.Unlock()
+	c.Broadcast(nil)
+}
+
+func newTestPeer(t *testing.T, name string, store Store) *Peer {
+	t.Helper()
+	p, err := New(Options{Name: name, Store: store, SyncInterval: 10 * time.Millisecond, LeaseDuration: time.Second})
+	require.NoError(t, err)
+	return p
+}
+
+func TestPeer(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+
+	a := newTestPeer(t, "a", store)
+	stateA := newSetState("1")
+	chA := a.AddState("state", stateA, nil)
+
+	// The peer is not ready until the first synchronization.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, a.WaitReady(ctx), context.DeadlineExceeded)
+	require.Equal(t, "settling", a.Status())
+
+	a.Start()
+	require.NoError(t, a.WaitReady(context.Background()))
+	require.Equal(t, 0, a.Position())
+	require.Equal(t, []string{"a"}, a.Members())
+	require.Equal(t, "a", a.Name())
+	require.Equal(t, "ready", a.Status())
+	require.Len(t, a.Peers(), 1)
+	require.Equal(t, "a", a.Peers()[0].Name())
+
+	// A new peer catches up with the states in the store on its first synchronization.
+	b := newTestPeer(t, "b", store)
+	stateB := newSetState("2")
+	chB := b.AddState("state", stateB, nil)
+	b.Start()
+	require.NoError(t, b.WaitReady(context.Background()))
+	require.Equal(t, []string{"1", "2"}, stateB.Values())
+	require.Equal(t, 1, b.Position())
+	require.Equal(t, []string{"a", "b"}, b.Members())
+
+	require.Eventually(t, func() bool {
+		return len(stateA.Values()) == 2 && len(a.Members()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Broadcast changes are replicated.
+	stateA.Add(chA, "3")
+	stateB.Add(chB, "4")
+	require.Eventually(t, func() bool {
+		return len(stateA.Values()) == 4 && len(stateB.Values()) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Stopped peers leave the membership.
+	require.NoError(t, a.Stop(context.Background()))
+	require.Eventually(t, func() bool {
+		return b.Position() == 0 && len(b.Members()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, b.Stop(context.Background()))
+}
+
+func TestPeer_ExpiredLeases(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.PutLease(context.Background(), "0-expired", time.Now().Add(-time.Second)))
+	require.NoError(t, store.PutLease(context.Background(), "1-alive", time.Now().Add(time.Hour)))
+
+	p := newTestPeer(t, "2-peer", store)
+	p.Start()
+	t.Cleanup(func() { require.NoError(t, p.Stop(context.Background())) })
+	require.NoError(t, p.WaitReady(context.Background()))
+	require.Equal(t, []string{"1-alive", "2-peer"}, p.Members())
+	require.Equal(t, 1, p.Position())
+}
+
+// unmergeableState is a cluster.State whose Merge always fails.
+type unmergeableState struct{}
+
+func (unmergeableState) MarshalBinary() ([]byte, error) { return []byte("state"), nil }
+
+func (unmergeableState) Merge([]byte) error { return errors.New("merge failed") }
+
+func TestPeer_MergeFailure(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+
+	a := newTestPeer(t, "a", store)
+	a.AddState("state", newSetState("1"), nil)
+	a.AddState("unmergeable", unmergeableState{}, nil)
+	require.NoError(t, a.sync(context.Background()))
+
+	b := newTestPeer(t, "b", store)
+	stateB := newSetState()
+	b.AddState("state", stateB, nil)
+	b.AddState("unmergeable", unmergeableState{}, nil)
+
+	// The other states are merged, and the peer is ready with its position even though a state could not be merged.
+	err = b.sync(context.Background())
+	require.ErrorContains(t, err, "failed to merge state unmergeable of peer a: merge failed")
+	require.True(t, b.Ready())
+	require.Equal(t, []string{"a", "b"}, b.Members())
+	require.Equal(t, 1, b.Position())
+	require.Equal(t, []string{"1"}, stateB.Values())
+}
+
+func TestPeer_Silences(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+
+	newSilences := func(p *Peer) *silence.Silences {
+		s, err := silence.New(silence.Options{Retention: time.Hour})
+		require.NoError(t, err)
+		c := p.AddState("silences:1", s, nil)
+		s.SetBroadcast(c.Broadcast)
+		p.Start()
+		t.Cleanup(func() { require.NoError(t, p.Stop(context.Background())) })
+		require.NoError(t, p.WaitReady(context.Background()))
+		return s
+	}
+	a := newSilences(newTestPeer(t, "a", store))
+	b := newSilences(newTestPeer(t, "b", store))
+
+	now := time.Now()
+	sil := &silencepb.Silence{
+		Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a", Type: silencepb.Matcher_EQUAL}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "user",
+		Comment:   "comment",
+	}
+	require.NoError(t, a.Set(sil))
+
+	require.Eventually(t, func() bool {
+		_, err := b.QueryOne(silence.QIDs(sil.Id))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNew(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+	_, err = New(Options{Store: store})
+	require.Error(t, err)
+	_, err = New(Options{Name: "a"})
+	require.Error(t, err)
+
+	p, err := New(Options{Name: "a", Store: store})
+	require.NoError(t, err)
+	require.Equal(t, DefaultSyncInterval, p.syncInterval)
+	require.Equal(t, 3*DefaultSyncInterval, p.leaseDuration)
+	require.Equal(t, DefaultMinSyncInterval, p.minSyncInterval)
+	// Stopping a peer that was not started does not block.
+	require.NoError(t, p.Stop(context.Background()))
+}
+
+// failingReadStore is a Store whose GetStates fails while fail is set.
+type failingReadStore struct {
+	Store
+	mtx  sync.Mutex
+	fail bool
+}
+
+func (s *failingReadStore) setFail(fail bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.fail = fail
+}
+
+func (s *failingReadStore) GetStates(ctx context.Context, key string) (map[string][]byte, error) {
+	s.mtx.Lock()
+	fail := s.fail
+	s.mtx.Unlock()
+	if fail {
+		return nil, errors.New("read failed")
+	}
+	return s.Store.GetStates(ctx, key)
+}
+
+func TestPeer_ReadFailure(t *testing.T) {
+	fsStore, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+	a := newTestPeer(t, "a", fsStore)
+	a.AddState("state", newSetState("1"), nil)
+	require.NoError(t, a.sync(context.Background()))
+
+	store := &failingReadStore{Store: fsStore, fail: true}
+	b := newTestPeer(t, "b", store)
+	stateB := newSetState()
+	b.AddState("state", stateB, nil)
+	b.Start()
+	t.Cleanup(func() { require.NoError(t, b.Stop(context.Background())) })
+
+	// The peer is not ready until the states of the other peers are read, but it is a member.
+	require.Eventually(t, func() bool { return len(b.Members()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, b.WaitReady(ctx), context.DeadlineExceeded)
+	require.Empty(t, stateB.Values())
+
+	store.setFail(false)
+	require.NoError(t, b.WaitReady(context.Background()))
+	require.Equal(t, []string{"1"}, stateB.Values())
+}
+
+// countingStore is a Store that counts the states it reads.
+type countingStore struct {
+	Store
+	reads atomic.Int64
+}
+
+func (s *countingStore) GetStates(ctx context.Context, key string) (map[string][]byte, error) {
+	s.reads.Add(1)
+	return s.Store.GetStates(ctx, key)
+}
+
+func TestPeer_BroadcastBurst(t *testing.T) {
+	fsStore, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+	store := &countingStore{Store: fsStore}
+	p, err := New(Options{Name: "a", Store: store, SyncInterval: time.Hour, MinSyncInterval: 100 * time.Millisecond})
+	require.NoError(t, err)
+	state := newSetState()
+	ch := p.AddState("state", state, nil)
+	p.Start()
+	t.Cleanup(func() { require.NoError(t, p.Stop(context.Background())) })
+	require.NoError(t, p.WaitReady(context.Background()))
+
+	// The broadcasts of a burst are written with a few synchronizations, instead of one each.
+	start := time.Now()
+	for i := 0; i < 300; i++ {
+		state.Add(ch, strconv.Itoa(i))
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+	require.Eventually(t, func() bool {
+		all, err := fsStore.GetStates(context.Background(), "state")
+		return err == nil && len(all["a"]) > 0 && strings.Contains(string(all["a"]), `"299"`)
+	}, 5*time.Second, 10*time.Millisecond)
+	maxSyncs := int64(elapsed/(100*time.Millisecond)) + 3
+	require.LessOrEqual(t, store.reads.Load(), maxSyncs)
+}
@@ -0,0 +1,20 @@
+package storepeer
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a store shared by the peers, through which they replicate their states and maintain their membership.
+type Store interface {
+	// PutState stores the full state of the key of the peer, replacing the previous one.
+	PutState(ctx context.Context, peer, key string, state []byte) error
+	// GetStates returns the full states of the key of all peers, by peer.
+	GetStates(ctx context.Context, key string) (map[string][]byte, error)
+	// PutLease stores the lease of the peer, which is a member until the lease expires.
+	PutLease(ctx context.Context, peer string, expiresAt time.Time) error
+	// DeleteLease deletes the lease of the peer.
+	DeleteLease(ctx context.Context, peer string) error
+	// GetLeases returns the expiry of the leases of all peers, by peer, including expired leases.
+	GetLeases(ctx context.Context) (map[string]time.Time, error)
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -26,6 +28,8 @@ type Config struct {
 	MuteTimeIntervals []config.MuteTimeInterval `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty"`
 	TimeIntervals     []config.TimeInterval     `yaml:"time_intervals,omitempty" json:"time_intervals,omitempty"`
 	Templates         []string                  `yaml:"templates,omitempty" json:"templates,omitempty"`
+	// Enrichments are lookup tables used to add labels and annotations to alerts before they are routed.
+	Enrichments []EnrichmentTable `yaml:"enrichments,omitempty" json:"enrichments,omitempty"`
 }
 
 // A Route is a node that contains definitions of how to handle alerts. This is modified
@@ -50,10 +54,51 @@ type Route struct {
 	GroupWait      *model.Duration `yaml:"group_wait,omitempty" json:"group_wait,omitempty"`
 	GroupInterval  *model.Duration `yaml:"group_interval,omitempty" json:"group_interval,omitempty"`
 	RepeatInterval *model.Duration `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"`
+	// RepeatBackoff spaces successive repeat notifications of a group further apart. It is inherited by child routes.
+	RepeatBackoff *RepeatBackoff `yaml:"repeat_backoff,omitempty" json:"repeat_backoff,omitempty"`
 
 	Provenance Provenance `yaml:"provenance,omitempty" json:"provenance,omitempty"`
 }
 
+// RepeatBackoff configures the growth of the repeat interval of an aggregation group. The n-th repeat notification
+// of a group is sent after repeat_interval * multiplier^(n-1), up to max_repeat_interval. The count of repeat
+// notifications resets when the firing alerts of the group change.
+type RepeatBackoff struct {
+	Multiplier        float64         `yaml:"multiplier" json:"multiplier"`
+	MaxRepeatInterval *model.Duration `yaml:"max_repeat_interval,omitempty" json:"max_repeat_interval,omitempty"`
+}
+
+// Interval returns the repeat interval to wait after the given number of repeat notifications.
+func (b *RepeatBackoff) Interval(repeatInterval time.Duration, repeats uint64) time.Duration {
+	if b == nil {
+		return repeatInterval
+	}
+	var maxInterval time.Duration
+	if b.MaxRepeatInterval != nil {
+		maxInterval = time.Duration(*b.MaxRepeatInterval)
+	}
+	interval := float64(repeatInterval) * math.Pow(b.Multiplier, float64(repeats))
+	if maxInterval > 0 && interval > float64(maxInterval) {
+		return maxInterval
+	}
+	// Guard against overflows for very large number of repeats without a maximum.
+	if interval > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(interval)
+}
+
+// Validate returns an error if the backoff is invalid.
+func (b *RepeatBackoff) Validate() error {
+	if b.Multiplier < 1 {
+		return fmt.Errorf("repeat_backoff multiplier must be greater than or equal to 1")
+	}
+	if b.MaxRepeatInterval != nil && *b.MaxRepeatInterval <= 0 {
+		return fmt.Errorf("repeat_backoff max_repeat_interval must be greater than zero")
+	}
+	return nil
+}
+
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Route. This is a copy of alertmanager's upstream except it removes validation on the label key.
 func (r *Route) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type plain Route
@@ -174,6 +219,17 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 		tiNames[ti.Name] = struct{}{}
 	}
+
+	enrichmentNames := make(map[string]struct{}, len(c.Enrichments))
+	for i := range c.Enrichments {
+		if err := c.Enrichments[i].Validate(); err != nil {
+			return err
+		}
+		if _, ok := enrichmentNames[c.Enrichments[i].Name]; ok {
+			return fmt.Errorf("enrichment table %q is not unique", c.Enrichments[i].Name)
+		}
+		enrichmentNames[c.Enrichments[i].Name] = struct{}{}
+	}
 	return checkTimeInterval(c.Route, tiNames)
 }
 
@@ -294,6 +350,32 @@ func (c *PostableApiAlertingConfig) Validate() error {
 		}
 	}
 
+	return c.validateIntegrationTimeIntervals()
+}
+
+// validateIntegrationTimeIntervals checks that all time intervals referenced by Grafana integrations exist.
+func (c *PostableApiAlertingConfig) validateIntegrationTimeIntervals() error {
+	tiNames := make(map[string]struct{}, len(c.TimeIntervals)+len(c.MuteTimeIntervals))
+	for _, mt := range c.MuteTimeIntervals {
+		tiNames[mt.Name] = struct{}{}
+	}
+	for _, ti := range c.TimeIntervals {
+		tiNames[ti.Name] = struct{}{}
+	}
+	for _, r := range c.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			for _, name := range gr.MuteTimeIntervals {
+				if _, ok := tiNames[name]; !ok {
+					return fmt.Errorf("undefined mute time interval %q used in integration %q of receiver %q", name, gr.Name, r.Name)
+				}
+			}
+			for _, name := range gr.ActiveTimeIntervals {
+				if _, ok := tiNames[name]; !ok {
+					return fmt.Errorf("undefined active time interval %q used in integration %q of receiver %q", name, gr.Name, r.Name)
+				}
+			}
+		}
+	}
 	return nil
 }
 
@@ -390,6 +472,9 @@ type PostableGrafanaReceiver struct {
 	Name                  string            `json:"name" yaml:"name"`
 	Type                  string            `json:"type" yaml:"type"`
 	DisableResolveMessage bool              `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+	SplitByAlert          bool              `json:"splitByAlert,omitempty" yaml:"splitByAlert,omitempty"`
+	MuteTimeIntervals     []string          `json:"muteTimeIntervals,omitempty" yaml:"muteTimeIntervals,omitempty"`
+	ActiveTimeIntervals   []string          `json:"activeTimeIntervals,omitempty" yaml:"activeTimeIntervals,omitempty"`
 	Settings              RawMessage        `json:"settings,omitempty" yaml:"settings,omitempty"`
 	SecureSettings        map[string]string `json:"secureSettings,omitempty" yaml:"secureSettings,omitempty"`
 }
@@ -1019,6 +1019,71 @@ func Test_ConfigUnmashaling(t *testing.T) {
 	}
 }
 
+func Test_PostableApiAlertingConfig_IntegrationTimeIntervals(t *testing.T) {
+	input := func(mute, active string) string {
+		return `
+			{
+			  "route": {
+				"receiver": "pushover"
+			  },
+			  "time_intervals": [
+				{
+				  "name": "day",
+				  "time_intervals": [{"times": [{"start_time": "08:00", "end_time": "22:00"}]}]
+				}
+			  ],
+			  "receivers": [
+				{
+				  "name": "pushover",
+				  "grafana_managed_receiver_configs": [
+					{
+					  "uid": "uid",
+					  "name": "pushover integration",
+					  "type": "pushover",
+					  "muteTimeIntervals": [` + mute + `],
+					  "activeTimeIntervals": [` + active + `],
+					  "settings": {}
+					}
+				  ]
+				}
+			  ]
+			}`
+	}
+
+	for _, tc := range []struct {
+		desc   string
+		mute   string
+		active string
+		err    string
+	}{
+		{
+			desc:   "defined time intervals",
+			active: `"day"`,
+		},
+		{
+			desc: "undefined mute time interval",
+			mute: `"night"`,
+			err:  `undefined mute time interval "night" used in integration "pushover integration" of receiver "pushover"`,
+		},
+		{
+			desc:   "undefined active time interval",
+			active: `"night"`,
+			err:    `undefined active time interval "night" used in integration "pushover integration" of receiver "pushover"`,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var out PostableApiAlertingConfig
+			err := json.Unmarshal([]byte(input(tc.mute, tc.active)), &out)
+			if tc.err == "" {
+				require.NoError(t, err)
+				require.Equal(t, []string{"day"}, out.Receivers[0].GrafanaManagedReceivers[0].ActiveTimeIntervals)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
 func Test_ReceiverCompatibility(t *testing.T) {
 	for _, tc := range []struct {
 		desc     string
@@ -1,6 +1,7 @@
 package definition
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,27 @@ const groupByAll = "..."
 
 // ValidateChild normalizes a possibly nested Route r, and returns errors if r is invalid.
 func (r *Route) ValidateChild() error {
+	if issues := r.validateNode(""); len(issues) > 0 {
+		return errors.New(issues[0].Message)
+	}
+
+	// Routes are a self-referential structure.
+	if r.Routes != nil {
+		for _, child := range r.Routes {
+			err := child.ValidateChild()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNode normalizes the Route r without its child routes, and returns all of its problems. The paths of the
+// problems are prefixed with path.
+func (r *Route) validateNode(path string) []ValidationIssue {
+	var report ValidationReport
 	r.GroupBy = nil
 	r.GroupByAll = false
 	for _, l := range r.GroupByStr {
@@ -23,53 +45,93 @@ func (r *Route) ValidateChild() error {
 	}
 
 	if len(r.GroupBy) > 0 && r.GroupByAll {
-		return fmt.Errorf("cannot have wildcard group_by (`...`) and other other labels at the same time")
+		report.AddError(joinPath(path, "group_by"), "cannot have wildcard group_by (`...`) and other other labels at the same time")
 	}
 
 	groupBy := map[model.LabelName]struct{}{}
 
 	for _, ln := range r.GroupBy {
 		if _, ok := groupBy[ln]; ok {
-			return fmt.Errorf("duplicated label %q in group_by, %s %s", ln, r.Receiver, r.GroupBy)
+			report.AddError(joinPath(path, "group_by"), "duplicated label %q in group_by, %s %s", ln, r.Receiver, r.GroupBy)
+			continue
 		}
 		groupBy[ln] = struct{}{}
 	}
 
 	if r.GroupInterval != nil && time.Duration(*r.GroupInterval) == time.Duration(0) {
-		return fmt.Errorf("group_interval cannot be zero")
+		report.AddError(joinPath(path, "group_interval"), "group_interval cannot be zero")
 	}
 	if r.RepeatInterval != nil && time.Duration(*r.RepeatInterval) == time.Duration(0) {
-		return fmt.Errorf("repeat_interval cannot be zero")
+		report.AddError(joinPath(path, "repeat_interval"), "repeat_interval cannot be zero")
 	}
-
-	// Routes are a self-referential structure.
-	if r.Routes != nil {
-		for _, child := range r.Routes {
-			err := child.ValidateChild()
-			if err != nil {
-				return err
-			}
+	if r.RepeatBackoff != nil {
+		if err := r.RepeatBackoff.Validate(); err != nil {
+			report.AddError(joinPath(path, "repeat_backoff"), "%s", err)
+		} else if r.RepeatInterval != nil && r.RepeatBackoff.MaxRepeatInterval != nil && *r.RepeatBackoff.MaxRepeatInterval < *r.RepeatInterval {
+			report.AddError(joinPath(path, "repeat_backoff.max_repeat_interval"), "repeat_backoff max_repeat_interval cannot be less than repeat_interval")
 		}
 	}
-
-	return nil
+	return report.Issues
 }
 
 // Validate normalizes a Route r, and returns errors if r is an invalid root route. Root routes must satisfy a few additional conditions.
 func (r *Route) Validate() error {
+	if issues := r.validateRoot(""); len(issues) > 0 {
+		return errors.New(issues[0].Message)
+	}
+	if err := r.ValidateChild(); err != nil {
+		return err
+	}
+	if issues := r.validateRepeatBackoffs("", defaultRepeatInterval, nil); len(issues) > 0 {
+		return errors.New(issues[0].Message)
+	}
+	return nil
+}
+
+// defaultRepeatInterval is the repeat interval of the routes when neither they nor their parents set one.
+// It is the default of the Alertmanager, see dispatch.DefaultRouteOpts.
+const defaultRepeatInterval = model.Duration(4 * time.Hour)
+
+// validateRepeatBackoffs returns the problems of the repeat backoffs of the Route r and its child routes with their
+// inherited repeat interval and repeat backoff. The routes that set both are validated by validateNode.
+func (r *Route) validateRepeatBackoffs(path string, repeatInterval model.Duration, backoff *RepeatBackoff) []ValidationIssue {
+	var report ValidationReport
+	ownInterval, ownBackoff := r.RepeatInterval != nil, r.RepeatBackoff != nil
+	if ownInterval {
+		repeatInterval = *r.RepeatInterval
+	}
+	if ownBackoff {
+		backoff = r.RepeatBackoff
+	}
+	if ownInterval != ownBackoff && backoff != nil && backoff.MaxRepeatInterval != nil && *backoff.MaxRepeatInterval < repeatInterval {
+		if ownBackoff {
+			report.AddError(joinPath(path, "repeat_backoff.max_repeat_interval"), "repeat_backoff max_repeat_interval cannot be less than the inherited repeat_interval %s", repeatInterval)
+		} else {
+			report.AddError(joinPath(path, "repeat_interval"), "repeat_interval cannot be greater than the max_repeat_interval %s of the inherited repeat_backoff", *backoff.MaxRepeatInterval)
+		}
+	}
+	for i, child := range r.Routes {
+		report.Issues = append(report.Issues, child.validateRepeatBackoffs(indexPath(joinPath(path, "routes"), i), repeatInterval, backoff)...)
+	}
+	return report.Issues
+}
+
+// validateRoot returns the problems of the Route r as a root route, without the problems of any route.
+func (r *Route) validateRoot(path string) []ValidationIssue {
+	var report ValidationReport
 	if len(r.Receiver) == 0 {
-		return fmt.Errorf("root route must specify a default receiver")
+		report.AddError(joinPath(path, "receiver"), "root route must specify a default receiver")
 	}
 	if len(r.Match) > 0 || len(r.MatchRE) > 0 {
-		return fmt.Errorf("root route must not have any matchers")
+		report.AddError(path, "root route must not have any matchers")
 	}
 	if len(r.MuteTimeIntervals) > 0 {
-		return fmt.Errorf("root route must not have any mute time intervals")
+		report.AddError(joinPath(path, "mute_time_intervals"), "root route must not have any mute time intervals")
 	}
 	if len(r.ActiveTimeIntervals) > 0 {
-		return fmt.Errorf("root route must not have any active time intervals")
+		report.AddError(joinPath(path, "active_time_intervals"), "root route must not have any active time intervals")
 	}
-	return r.ValidateChild()
+	return report.Issues
 }
 
 func (r *Route) ValidateReceivers(receivers map[string]struct{}) error {
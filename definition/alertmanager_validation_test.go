@@ -1,7 +1,9 @@
 package definition
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -10,6 +12,9 @@ import (
 
 func TestValidateRoutes(t *testing.T) {
 	zero := model.Duration(0)
+	hour := model.Duration(time.Hour)
+	minute := model.Duration(time.Minute)
+	day := model.Duration(24 * time.Hour)
 
 	type testCase struct {
 		desc   string
@@ -42,6 +47,14 @@ func TestValidateRoutes(t *testing.T) {
 					},
 				},
 			},
+			{
+				desc: "repeat backoff",
+				route: Route{
+					Receiver:       "foo",
+					RepeatInterval: &minute,
+					RepeatBackoff:  &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &hour},
+				},
+			},
 		}
 
 		for _, c := range cases {
@@ -95,6 +108,31 @@ func TestValidateRoutes(t *testing.T) {
 				},
 				expMsg: "cannot have wildcard",
 			},
+			{
+				desc: "repeat backoff multiplier less than one",
+				route: Route{
+					Receiver:      "foo",
+					RepeatBackoff: &RepeatBackoff{Multiplier: 0.5},
+				},
+				expMsg: "repeat_backoff multiplier must be greater than or equal to 1",
+			},
+			{
+				desc: "repeat backoff zero max repeat interval",
+				route: Route{
+					Receiver:      "foo",
+					RepeatBackoff: &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &zero},
+				},
+				expMsg: "repeat_backoff max_repeat_interval must be greater than zero",
+			},
+			{
+				desc: "repeat backoff max repeat interval less than repeat interval",
+				route: Route{
+					Receiver:       "foo",
+					RepeatInterval: &hour,
+					RepeatBackoff:  &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &minute},
+				},
+				expMsg: "repeat_backoff max_repeat_interval cannot be less than repeat_interval",
+			},
 			{
 				desc: "valid with nested invalid",
 				route: Route{
@@ -193,6 +231,43 @@ func TestValidateRoutes(t *testing.T) {
 				},
 				expMsg: "must specify a default receiver",
 			},
+			{
+				desc: "repeat backoff max repeat interval less than inherited repeat interval",
+				route: Route{
+					Receiver:       "foo",
+					RepeatInterval: &hour,
+					Routes: []*Route{
+						{
+							Receiver:      "bar",
+							RepeatBackoff: &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &minute},
+						},
+					},
+				},
+				expMsg: "repeat_backoff max_repeat_interval cannot be less than the inherited repeat_interval 1h",
+			},
+			{
+				desc: "repeat backoff max repeat interval less than default repeat interval",
+				route: Route{
+					Receiver:      "foo",
+					RepeatBackoff: &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &hour},
+				},
+				expMsg: "repeat_backoff max_repeat_interval cannot be less than the inherited repeat_interval 4h",
+			},
+			{
+				desc: "repeat interval greater than max repeat interval of inherited repeat backoff",
+				route: Route{
+					Receiver:       "foo",
+					RepeatInterval: &minute,
+					RepeatBackoff:  &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &hour},
+					Routes: []*Route{
+						{
+							Receiver:       "bar",
+							RepeatInterval: &day,
+						},
+					},
+				},
+				expMsg: "repeat_interval cannot be greater than the max_repeat_interval 1h of the inherited repeat_backoff",
+			},
 			{
 				desc: "exact matchers present",
 				route: Route{
@@ -265,3 +340,25 @@ func TestValidateRoutes(t *testing.T) {
 		}
 	})
 }
+
+func TestRepeatBackoffInterval(t *testing.T) {
+	maxInterval := model.Duration(10 * time.Hour)
+	b := &RepeatBackoff{Multiplier: 2, MaxRepeatInterval: &maxInterval}
+
+	require.Equal(t, time.Hour, b.Interval(time.Hour, 0))
+	require.Equal(t, 2*time.Hour, b.Interval(time.Hour, 1))
+	require.Equal(t, 8*time.Hour, b.Interval(time.Hour, 3))
+	require.Equal(t, 10*time.Hour, b.Interval(time.Hour, 4))
+	require.Equal(t, 10*time.Hour, b.Interval(time.Hour, 1000))
+
+	t.Run("without maximum", func(t *testing.T) {
+		b := &RepeatBackoff{Multiplier: 2}
+		require.Equal(t, 16*time.Hour, b.Interval(time.Hour, 4))
+		require.Equal(t, time.Duration(math.MaxInt64), b.Interval(time.Hour, 1000))
+	})
+
+	t.Run("nil backoff", func(t *testing.T) {
+		var b *RepeatBackoff
+		require.Equal(t, time.Hour, b.Interval(time.Hour, 5))
+	})
+}
@@ -0,0 +1,132 @@
+package definition
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// EnrichmentAnnotationPrefix marks a column of an enrichment table as an annotation rather than a label.
+// For example, the column "annotation:runbook_url" adds the annotation "runbook_url".
+const EnrichmentAnnotationPrefix = "annotation:"
+
+// EnrichmentTable is a static lookup table used to add labels and annotations to incoming alerts.
+// Each row of the table is a set of columns. Columns named in Keys are matched against the labels of the alert,
+// the remaining columns are added to the alert as labels, or as annotations when prefixed with EnrichmentAnnotationPrefix.
+// Rows can be defined inline with Entries, or as CSV (the first line is the header) or JSON (an array of objects).
+type EnrichmentTable struct {
+	Name string `yaml:"name" json:"name"`
+	// Keys are the labels of the alert used to look up a row.
+	Keys []string `yaml:"keys" json:"keys"`
+	// RegexKeys is the subset of Keys whose values are anchored regular expressions instead of exact values.
+	RegexKeys []string `yaml:"regex_keys,omitempty" json:"regex_keys,omitempty"`
+	// Override controls whether labels and annotations that already exist on the alert are replaced.
+	Override bool `yaml:"override,omitempty" json:"override,omitempty"`
+
+	Entries []map[string]string `yaml:"entries,omitempty" json:"entries,omitempty"`
+	CSV     string              `yaml:"csv,omitempty" json:"csv,omitempty"`
+	JSON    string              `yaml:"json,omitempty" json:"json,omitempty"`
+}
+
+// Rows returns all rows of the table. Inline entries come first, followed by the CSV and the JSON rows.
+func (t *EnrichmentTable) Rows() ([]map[string]string, error) {
+	rows := make([]map[string]string, 0, len(t.Entries))
+	rows = append(rows, t.Entries...)
+
+	if strings.TrimSpace(t.CSV) != "" {
+		r := csv.NewReader(strings.NewReader(t.CSV))
+		r.TrimLeadingSpace = true
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse csv: %w", err)
+		}
+		header := records[0]
+		for i, record := range records[1:] {
+			if len(record) != len(header) {
+				return nil, fmt.Errorf("csv row %d has %d columns, expected %d", i+1, len(record), len(header))
+			}
+			row := make(map[string]string, len(header))
+			for j, column := range header {
+				row[strings.TrimSpace(column)] = record[j]
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if strings.TrimSpace(t.JSON) != "" {
+		var jsonRows []map[string]string
+		if err := json.Unmarshal([]byte(t.JSON), &jsonRows); err != nil {
+			return nil, fmt.Errorf("failed to parse json: %w", err)
+		}
+		rows = append(rows, jsonRows...)
+	}
+
+	return rows, nil
+}
+
+// IsRegexKey returns true if the key is declared in RegexKeys.
+func (t *EnrichmentTable) IsRegexKey(key string) bool {
+	for _, k := range t.RegexKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the table is invalid. It parses all rows, so it also catches malformed CSV and JSON.
+func (t *EnrichmentTable) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("missing name in enrichment table")
+	}
+	if len(t.Keys) == 0 {
+		return fmt.Errorf("enrichment table %q must have at least one key", t.Name)
+	}
+	keys := make(map[string]struct{}, len(t.Keys))
+	for _, k := range t.Keys {
+		if k == "" {
+			return fmt.Errorf("enrichment table %q has an empty key", t.Name)
+		}
+		if _, ok := keys[k]; ok {
+			return fmt.Errorf("enrichment table %q has duplicated key %q", t.Name, k)
+		}
+		keys[k] = struct{}{}
+	}
+	for _, k := range t.RegexKeys {
+		if _, ok := keys[k]; !ok {
+			return fmt.Errorf("regex key %q of enrichment table %q is not one of its keys", k, t.Name)
+		}
+	}
+
+	rows, err := t.Rows()
+	if err != nil {
+		return fmt.Errorf("invalid enrichment table %q: %w", t.Name, err)
+	}
+	for i, row := range rows {
+		for _, k := range t.Keys {
+			v, ok := row[k]
+			if !ok {
+				return fmt.Errorf("row %d of enrichment table %q is missing key %q", i, t.Name, k)
+			}
+			if t.IsRegexKey(k) {
+				if _, err := regexp.Compile("^(?:" + v + ")$"); err != nil {
+					return fmt.Errorf("row %d of enrichment table %q has invalid regex for key %q: %w", i, t.Name, k, err)
+				}
+			}
+		}
+		for column := range row {
+			if column == "" || column == EnrichmentAnnotationPrefix {
+				return fmt.Errorf("row %d of enrichment table %q has a column without name", i, t.Name)
+			}
+			// The columns are added to alerts that are already validated, so they must be valid label and annotation names.
+			if !model.LabelName(strings.TrimPrefix(column, EnrichmentAnnotationPrefix)).IsValid() {
+				return fmt.Errorf("row %d of enrichment table %q has invalid column name %q", i, t.Name, column)
+			}
+		}
+	}
+	return nil
+}
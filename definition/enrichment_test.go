@@ -0,0 +1,147 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnrichmentTable_Rows(t *testing.T) {
+	table := EnrichmentTable{
+		Name:    "services",
+		Keys:    []string{"service"},
+		Entries: []map[string]string{{"service": "api", "team": "backend"}},
+		CSV:     "service, team, annotation:runbook_url\nweb, frontend, http://runbooks/web\n",
+		JSON:    `[{"service": "db", "team": "dba"}]`,
+	}
+
+	rows, err := table.Rows()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"service": "api", "team": "backend"},
+		{"service": "web", "team": "frontend", "annotation:runbook_url": "http://runbooks/web"},
+		{"service": "db", "team": "dba"},
+	}, rows)
+}
+
+func TestEnrichmentTable_Validate(t *testing.T) {
+	cases := []struct {
+		name   string
+		table  EnrichmentTable
+		expErr string
+	}{
+		{
+			name:  "valid table",
+			table: EnrichmentTable{Name: "t", Keys: []string{"service"}, Entries: []map[string]string{{"service": "api", "team": "a"}}},
+		},
+		{
+			name:   "missing name",
+			table:  EnrichmentTable{Keys: []string{"service"}},
+			expErr: "missing name in enrichment table",
+		},
+		{
+			name:   "missing keys",
+			table:  EnrichmentTable{Name: "t"},
+			expErr: `enrichment table "t" must have at least one key`,
+		},
+		{
+			name:   "duplicated keys",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service", "service"}},
+			expErr: `enrichment table "t" has duplicated key "service"`,
+		},
+		{
+			name:   "regex key that is not a key",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, RegexKeys: []string{"env"}},
+			expErr: `regex key "env" of enrichment table "t" is not one of its keys`,
+		},
+		{
+			name:   "row missing a key",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, Entries: []map[string]string{{"team": "a"}}},
+			expErr: `row 0 of enrichment table "t" is missing key "service"`,
+		},
+		{
+			name:   "invalid regex",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, RegexKeys: []string{"service"}, Entries: []map[string]string{{"service": "(api"}}},
+			expErr: `row 0 of enrichment table "t" has invalid regex for key "service"`,
+		},
+		{
+			name:   "invalid label column",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, CSV: "service,team-name\napi,a\n"},
+			expErr: `row 0 of enrichment table "t" has invalid column name "team-name"`,
+		},
+		{
+			name:   "invalid annotation column",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, Entries: []map[string]string{{"service": "api", "annotation:runbook.url": "a"}}},
+			expErr: `row 0 of enrichment table "t" has invalid column name "annotation:runbook.url"`,
+		},
+		{
+			name:   "csv with wrong number of columns",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, CSV: "service,team\napi\n"},
+			expErr: `invalid enrichment table "t": failed to parse csv`,
+		},
+		{
+			name:   "invalid json",
+			table:  EnrichmentTable{Name: "t", Keys: []string{"service"}, JSON: `{"service": "api"}`},
+			expErr: `invalid enrichment table "t": failed to parse json`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.table.Validate()
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestConfig_Enrichments(t *testing.T) {
+	t.Run("valid enrichments are loaded", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, yaml.Unmarshal([]byte(`
+route:
+  receiver: default
+enrichments:
+  - name: services
+    keys: [service]
+    entries:
+      - service: api
+        team: backend
+`), &cfg))
+		require.Len(t, cfg.Enrichments, 1)
+		require.Equal(t, "services", cfg.Enrichments[0].Name)
+	})
+
+	t.Run("invalid column names should error", func(t *testing.T) {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(`
+route:
+  receiver: default
+enrichments:
+  - name: services
+    keys: [service]
+    csv: |
+      service,team-name
+      api,backend
+`), &cfg)
+		require.EqualError(t, err, `row 0 of enrichment table "services" has invalid column name "team-name"`)
+	})
+
+	t.Run("duplicated names should error", func(t *testing.T) {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(`
+route:
+  receiver: default
+enrichments:
+  - name: services
+    keys: [service]
+  - name: services
+    keys: [service]
+`), &cfg)
+		require.EqualError(t, err, `enrichment table "services" is not unique`)
+	})
+}
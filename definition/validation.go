@@ -0,0 +1,325 @@
+package definition
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the severity of a ValidationIssue.
+type Severity string
+
+const (
+	// SeverityError is the severity of the problems that make a configuration invalid.
+	SeverityError Severity = "error"
+	// SeverityWarning is the severity of the problems that do not make a configuration invalid, such as the use of deprecated fields.
+	SeverityWarning Severity = "warning"
+)
+
+// ValidationIssue is a problem of a configuration.
+type ValidationIssue struct {
+	// Path is the JSON path of the problem in the configuration, such as receivers[3].grafana_managed_receiver_configs[1].settings.url.
+	// It is empty if the problem is in the whole configuration.
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// ValidationReport is the list of all the problems of a configuration.
+type ValidationReport struct {
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// AddError adds a problem that makes the configuration invalid.
+func (r *ValidationReport) AddError(path string, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{Path: path, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// AddWarning adds a problem that does not make the configuration invalid.
+func (r *ValidationReport) AddWarning(path string, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{Path: path, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasErrors returns true if the report has problems that make the configuration invalid.
+func (r *ValidationReport) HasErrors() bool {
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the problems that make the configuration invalid as an error, or nil if there is none.
+func (r *ValidationReport) Err() error {
+	var errs []error
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			errs = append(errs, errors.New(i.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAll parses a slice of bytes (json/yaml) into a configuration like Load, but instead of stopping at the
+// first problem it validates the whole configuration and reports all the problems, including warnings such as
+// the use of deprecated fields. The configuration contains all the parts that could be parsed.
+func ValidateAll(rawCfg []byte) (*PostableApiAlertingConfig, *ValidationReport) {
+	report := &ValidationReport{}
+	cfg := &PostableApiAlertingConfig{}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(rawCfg, &doc); err != nil {
+		report.AddError("", "%s", err)
+		return cfg, report
+	}
+	root := &doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root.Kind != 0 && root.Kind != yaml.DocumentNode && root.Kind != yaml.MappingNode {
+		report.AddError("", "configuration must be an object")
+		return cfg, report
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		switch key {
+		case "global":
+			if err := value.Decode(&cfg.Global); err != nil {
+				report.AddError(key, "%s", err)
+			}
+		case "route":
+			cfg.Route = decodeRoute(value, key, report)
+		case "inhibit_rules":
+			cfg.InhibitRules = decodeSequence[config.InhibitRule](value, key, report)
+		case "mute_time_intervals":
+			report.AddWarning(key, "mute_time_intervals is deprecated, use time_intervals instead")
+			cfg.MuteTimeIntervals = decodeSequence[config.MuteTimeInterval](value, key, report)
+		case "time_intervals":
+			cfg.TimeIntervals = decodeSequence[config.TimeInterval](value, key, report)
+		case "templates":
+			cfg.Templates = decodeSequence[string](value, key, report)
+		case "enrichments":
+			cfg.Enrichments = decodeSequence[EnrichmentTable](value, key, report)
+		case "receivers":
+			cfg.Receivers = decodeSequence[*PostableApiReceiver](value, key, report)
+		}
+	}
+
+	cfg.validateAll(report)
+	return cfg, report
+}
+
+// decodeSequence decodes each element of the sequence node separately, so that an invalid element does not prevent
+// the others from being validated. The elements are kept even if they cannot be decoded entirely, so that
+// they can still be referenced.
+func decodeSequence[T any](node *yaml.Node, path string, report *ValidationReport) []T {
+	if node.Kind != yaml.SequenceNode {
+		if node.Tag != "!!null" {
+			report.AddError(path, "must be a list")
+		}
+		return nil
+	}
+	res := make([]T, 0, len(node.Content))
+	for i, n := range node.Content {
+		var v T
+		if err := n.Decode(&v); err != nil {
+			report.AddError(indexPath(path, i), "%s", err)
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+// decodeRoute decodes the route node and its child routes separately, without validating them.
+func decodeRoute(node *yaml.Node, path string, report *ValidationReport) *Route {
+	if node.Kind != yaml.MappingNode {
+		report.AddError(path, "route must be an object")
+		return nil
+	}
+	var children *yaml.Node
+	withoutChildren := *node
+	withoutChildren.Content = make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "routes" {
+			children = node.Content[i+1]
+			continue
+		}
+		withoutChildren.Content = append(withoutChildren.Content, node.Content[i], node.Content[i+1])
+	}
+
+	type plain Route
+	r := &Route{}
+	if err := withoutChildren.Decode((*plain)(r)); err != nil {
+		report.AddError(path, "%s", err)
+	}
+	if children == nil || children.Tag == "!!null" {
+		return r
+	}
+	if children.Kind != yaml.SequenceNode {
+		report.AddError(joinPath(path, "routes"), "must be a list")
+		return r
+	}
+	for i, child := range children.Content {
+		if c := decodeRoute(child, indexPath(joinPath(path, "routes"), i), report); c != nil {
+			r.Routes = append(r.Routes, c)
+		}
+	}
+	return r
+}
+
+// validateAll adds all the problems of the configuration to the report. It is the counterpart of the validation
+// of Config.UnmarshalYAML and PostableApiAlertingConfig.Validate that does not stop at the first problem.
+func (c *PostableApiAlertingConfig) validateAll(report *ValidationReport) {
+	tiNames := make(map[string]struct{}, len(c.MuteTimeIntervals)+len(c.TimeIntervals))
+	for i, mt := range c.MuteTimeIntervals {
+		if mt.Name == "" {
+			// The missing names are reported when the time intervals are decoded.
+			continue
+		}
+		if _, ok := tiNames[mt.Name]; ok {
+			report.AddError(joinPath(indexPath("mute_time_intervals", i), "name"), "mute time interval %q is not unique", mt.Name)
+		}
+		tiNames[mt.Name] = struct{}{}
+	}
+	for i, ti := range c.TimeIntervals {
+		if ti.Name == "" {
+			continue
+		}
+		if _, ok := tiNames[ti.Name]; ok {
+			report.AddError(joinPath(indexPath("time_intervals", i), "name"), "time interval %q is not unique", ti.Name)
+		}
+		tiNames[ti.Name] = struct{}{}
+	}
+
+	for i, r := range c.InhibitRules {
+		path := indexPath("inhibit_rules", i)
+		for _, key := range []struct {
+			name, replacement string
+			used              bool
+		}{
+			{"source_match", "source_matchers", len(r.SourceMatch) > 0},
+			{"source_match_re", "source_matchers", len(r.SourceMatchRE) > 0},
+			{"target_match", "target_matchers", len(r.TargetMatch) > 0},
+			{"target_match_re", "target_matchers", len(r.TargetMatchRE) > 0},
+		} {
+			if key.used {
+				report.AddWarning(joinPath(path, key.name), "%s is deprecated, use %s instead", key.name, key.replacement)
+			}
+		}
+	}
+
+	enrichmentNames := make(map[string]struct{}, len(c.Enrichments))
+	for i := range c.Enrichments {
+		path := indexPath("enrichments", i)
+		if err := c.Enrichments[i].Validate(); err != nil {
+			report.AddError(path, "%s", err)
+		}
+		if _, ok := enrichmentNames[c.Enrichments[i].Name]; ok {
+			report.AddError(joinPath(path, "name"), "enrichment table %q is not unique", c.Enrichments[i].Name)
+		}
+		enrichmentNames[c.Enrichments[i].Name] = struct{}{}
+	}
+
+	receivers := make(map[string]struct{}, len(c.Receivers))
+	var hasGrafReceivers, hasAMReceivers bool
+	for i, r := range c.Receivers {
+		if r == nil {
+			continue
+		}
+		receivers[r.Name] = struct{}{}
+		switch r.Type() {
+		case GrafanaReceiverType:
+			hasGrafReceivers = true
+		case AlertmanagerReceiverType:
+			hasAMReceivers = true
+		}
+		for j, gr := range r.GrafanaManagedReceivers {
+			if gr == nil {
+				continue
+			}
+			path := indexPath(joinPath(indexPath("receivers", i), "grafana_managed_receiver_configs"), j)
+			for k, name := range gr.MuteTimeIntervals {
+				if _, ok := tiNames[name]; !ok {
+					report.AddError(indexPath(joinPath(path, "muteTimeIntervals"), k), "undefined mute time interval %q used in integration %q of receiver %q", name, gr.Name, r.Name)
+				}
+			}
+			for k, name := range gr.ActiveTimeIntervals {
+				if _, ok := tiNames[name]; !ok {
+					report.AddError(indexPath(joinPath(path, "activeTimeIntervals"), k), "undefined active time interval %q used in integration %q of receiver %q", name, gr.Name, r.Name)
+				}
+			}
+		}
+	}
+	if hasGrafReceivers && hasAMReceivers {
+		report.AddError("receivers", "cannot mix Alertmanager & Grafana receiver types")
+	}
+
+	if c.Route == nil {
+		report.AddError("route", "no route provided in config")
+		return
+	}
+	report.Issues = append(report.Issues, c.Route.validateRoot("route")...)
+	if c.Route.Continue {
+		report.AddError("route.continue", "cannot have continue in root route")
+	}
+	c.Route.validateAll("route", false, receivers, tiNames, report)
+	report.Issues = append(report.Issues, c.Route.validateRepeatBackoffs("route", defaultRepeatInterval, nil)...)
+}
+
+// validateAll adds all the problems of the route and its child routes to the report. The receivers of autogenerated
+// routes are not validated.
+func (r *Route) validateAll(path string, autogenerated bool, receivers, timeIntervals map[string]struct{}, report *ValidationReport) {
+	report.Issues = append(report.Issues, r.validateNode(path)...)
+
+	if len(r.Match) > 0 {
+		report.AddWarning(joinPath(path, "match"), "match is deprecated, use object_matchers instead")
+	}
+	if len(r.MatchRE) > 0 {
+		report.AddWarning(joinPath(path, "match_re"), "match_re is deprecated, use object_matchers instead")
+	}
+
+	autogenerated = autogenerated || isAutogeneratedRoot(&config.Route{Matchers: append(append(config.Matchers{}, r.Matchers...), r.ObjectMatchers...)})
+	if r.Receiver != "" && !autogenerated {
+		if _, ok := receivers[r.Receiver]; !ok {
+			report.AddError(joinPath(path, "receiver"), "unexpected receiver (%s) is undefined", r.Receiver)
+		}
+	}
+	for i, name := range r.MuteTimeIntervals {
+		if _, ok := timeIntervals[name]; !ok {
+			report.AddError(indexPath(joinPath(path, "mute_time_intervals"), i), "undefined mute time interval %q used in route", name)
+		}
+	}
+	for i, name := range r.ActiveTimeIntervals {
+		if _, ok := timeIntervals[name]; !ok {
+			report.AddError(indexPath(joinPath(path, "active_time_intervals"), i), "undefined active time interval %q used in route", name)
+		}
+	}
+
+	for i, child := range r.Routes {
+		child.validateAll(indexPath(joinPath(path, "routes"), i), autogenerated, receivers, timeIntervals, report)
+	}
+}
+
+// joinPath returns the JSON path of the key in the object at path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// indexPath returns the JSON path of the element at index i in the array at path.
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
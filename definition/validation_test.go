@@ -0,0 +1,159 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAll(t *testing.T) {
+	t.Run("valid configuration has no issues", func(t *testing.T) {
+		cfg, report := ValidateAll([]byte(`
+route:
+  receiver: default
+  routes:
+  - receiver: team
+    object_matchers: [["team", "=", "a"]]
+    mute_time_intervals: [weekends]
+receivers:
+- name: default
+- name: team
+time_intervals:
+- name: weekends
+  time_intervals:
+  - weekdays: [saturday, sunday]
+`))
+		require.Empty(t, report.Issues)
+		require.False(t, report.HasErrors())
+		require.NoError(t, report.Err())
+		require.Equal(t, "team", cfg.Route.Routes[0].Receiver)
+	})
+
+	t.Run("reports all the problems", func(t *testing.T) {
+		cfg, report := ValidateAll([]byte(`
+route:
+  receiver: default
+  continue: true
+  group_by: [a, a]
+  routes:
+  - receiver: missing
+    group_interval: 0s
+    repeat_interval: 0s
+    match:
+      team: a
+  - receiver: team
+    mute_time_intervals: [weekends, holidays]
+    routes:
+    - receiver: team
+      match_re:
+        team: b.*
+      active_time_intervals: [nights]
+inhibit_rules:
+- source_match:
+    severity: critical
+  target_matchers: [severity="warning"]
+mute_time_intervals:
+- name: weekends
+time_intervals:
+- name: weekends
+- time_intervals: []
+receivers:
+- name: default
+- name: team
+  grafana_managed_receiver_configs:
+  - uid: a
+    name: a
+    type: email
+    muteTimeIntervals: [weekends, unknown]
+`))
+		require.True(t, report.HasErrors())
+		require.Equal(t, []ValidationIssue{
+			{Path: "mute_time_intervals", Severity: SeverityWarning, Message: "mute_time_intervals is deprecated, use time_intervals instead"},
+			{Path: "time_intervals[1]", Severity: SeverityError, Message: "missing name in time interval"},
+			{Path: "time_intervals[0].name", Severity: SeverityError, Message: `time interval "weekends" is not unique`},
+			{Path: "inhibit_rules[0].source_match", Severity: SeverityWarning, Message: "source_match is deprecated, use source_matchers instead"},
+			{Path: "receivers[1].grafana_managed_receiver_configs[0].muteTimeIntervals[1]", Severity: SeverityError, Message: `undefined mute time interval "unknown" used in integration "a" of receiver "team"`},
+			{Path: "route.continue", Severity: SeverityError, Message: "cannot have continue in root route"},
+			{Path: "route.group_by", Severity: SeverityError, Message: `duplicated label "a" in group_by, default [a a]`},
+			{Path: "route.routes[0].group_interval", Severity: SeverityError, Message: "group_interval cannot be zero"},
+			{Path: "route.routes[0].repeat_interval", Severity: SeverityError, Message: "repeat_interval cannot be zero"},
+			{Path: "route.routes[0].match", Severity: SeverityWarning, Message: "match is deprecated, use object_matchers instead"},
+			{Path: "route.routes[0].receiver", Severity: SeverityError, Message: "unexpected receiver (missing) is undefined"},
+			{Path: "route.routes[1].mute_time_intervals[1]", Severity: SeverityError, Message: `undefined mute time interval "holidays" used in route`},
+			{Path: "route.routes[1].routes[0].match_re", Severity: SeverityWarning, Message: "match_re is deprecated, use object_matchers instead"},
+			{Path: "route.routes[1].routes[0].active_time_intervals[0]", Severity: SeverityError, Message: `undefined active time interval "nights" used in route`},
+		}, report.Issues)
+		require.ErrorContains(t, report.Err(), "route.routes[0].receiver: unexpected receiver (missing) is undefined")
+		require.ErrorContains(t, report.Err(), "time_intervals[1]: missing name in time interval")
+
+		// The configuration contains the parts that could be parsed.
+		require.Len(t, cfg.Route.Routes, 2)
+		require.Len(t, cfg.Route.Routes[1].Routes, 1)
+		require.Len(t, cfg.Receivers, 2)
+
+		// Load stops at the first problem.
+		_, err := Load([]byte(`route: {receiver: default, group_by: [a, a]}`))
+		require.EqualError(t, err, `duplicated label "a" in group_by, default [a a]`)
+	})
+
+	t.Run("root route", func(t *testing.T) {
+		_, report := ValidateAll([]byte(`
+route:
+  match:
+    a: b
+  mute_time_intervals: [weekends]
+time_intervals:
+- name: weekends
+`))
+		require.Equal(t, []ValidationIssue{
+			{Path: "route.receiver", Severity: SeverityError, Message: "root route must specify a default receiver"},
+			{Path: "route", Severity: SeverityError, Message: "root route must not have any matchers"},
+			{Path: "route.mute_time_intervals", Severity: SeverityError, Message: "root route must not have any mute time intervals"},
+			{Path: "route.match", Severity: SeverityWarning, Message: "match is deprecated, use object_matchers instead"},
+		}, report.Issues)
+
+		_, report = ValidateAll([]byte(`receivers: [{name: default}]`))
+		require.Equal(t, []ValidationIssue{
+			{Path: "route", Severity: SeverityError, Message: "no route provided in config"},
+		}, report.Issues)
+	})
+
+	t.Run("repeat backoffs with inherited repeat interval", func(t *testing.T) {
+		_, report := ValidateAll([]byte(`
+route:
+  receiver: default
+  repeat_interval: 2h
+  routes:
+  - receiver: default
+    repeat_backoff: {multiplier: 2, max_repeat_interval: 1h}
+  - receiver: default
+    repeat_interval: 10m
+    repeat_backoff: {multiplier: 2, max_repeat_interval: 1h}
+    routes:
+    - receiver: default
+      repeat_interval: 3h
+receivers:
+- name: default
+`))
+		require.Equal(t, []ValidationIssue{
+			{Path: "route.routes[0].repeat_backoff.max_repeat_interval", Severity: SeverityError, Message: "repeat_backoff max_repeat_interval cannot be less than the inherited repeat_interval 2h"},
+			{Path: "route.routes[1].routes[0].repeat_interval", Severity: SeverityError, Message: "repeat_interval cannot be greater than the max_repeat_interval 1h of the inherited repeat_backoff"},
+		}, report.Issues)
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		_, report := ValidateAll([]byte(`route: [`))
+		require.True(t, report.HasErrors())
+		require.Equal(t, "", report.Issues[0].Path)
+
+		_, report = ValidateAll([]byte(`[]`))
+		require.Equal(t, []ValidationIssue{{Path: "", Severity: SeverityError, Message: "configuration must be an object"}}, report.Issues)
+
+		_, report = ValidateAll([]byte(`{"route": {"receiver": "default", "routes": {}}, "receivers": {}}`))
+		require.Equal(t, []ValidationIssue{
+			{Path: "route.routes", Severity: SeverityError, Message: "must be a list"},
+			{Path: "receivers", Severity: SeverityError, Message: "must be a list"},
+			{Path: "route.receiver", Severity: SeverityError, Message: "unexpected receiver (default) is undefined"},
+		}, report.Issues)
+	})
+}
@@ -0,0 +1,192 @@
+// Package envelope encrypts secrets, such as the secure settings of receivers, with envelope encryption:
+// each secret is encrypted with its own data key, which is encrypted with a key encryption key of a KMS.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Version is the version of the format of the encrypted secrets, which is stored in their first byte:
+//
+//	version (1 byte) | key ID length (1 byte) | key ID | encrypted data key length (2 bytes) | encrypted data key | nonce (12 bytes) | ciphertext
+//
+// The key ID is the ID of the key encryption key that encrypts the data key. The secret is encrypted with the data key
+// using AES-256-GCM, and the header is authenticated with it.
+const Version = 1
+
+const dataKeySize = 32
+
+var (
+	// ErrUnsupportedVersion is returned when decrypting a secret that is not in the format of Version, for example because
+	// it was not encrypted by an Envelope.
+	ErrUnsupportedVersion = errors.New("unsupported envelope version")
+	// ErrKeyNotFound is returned by a KMS when the key encryption key does not exist.
+	ErrKeyNotFound = errors.New("key encryption key not found")
+)
+
+// KMS encrypts the data keys with its key encryption keys.
+type KMS interface {
+	// ActiveKeyID returns the ID of the key encryption key that encrypts the new data keys.
+	ActiveKeyID(ctx context.Context) (string, error)
+	// Encrypt encrypts the data key with the key encryption key. It returns ErrKeyNotFound if the key does not exist.
+	Encrypt(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	// Decrypt decrypts the data key with the key encryption key. It returns ErrKeyNotFound if the key does not exist.
+	Decrypt(ctx context.Context, keyID string, encryptedKey []byte) ([]byte, error)
+}
+
+// Envelope encrypts and decrypts secrets with the keys of a KMS.
+type Envelope struct {
+	kms KMS
+}
+
+// New returns an Envelope that encrypts the data keys with the KMS.
+func New(kms KMS) *Envelope {
+	return &Envelope{kms: kms}
+}
+
+// Encrypt encrypts the secret with a new data key, encrypted with the active key encryption key of the KMS.
+func (e *Envelope) Encrypt(ctx context.Context, secret []byte) ([]byte, error) {
+	keyID, err := e.kms.ActiveKeyID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active key: %w", err)
+	}
+	if len(keyID) == 0 || len(keyID) > math.MaxUint8 {
+		return nil, fmt.Errorf("invalid key ID %q", keyID)
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	encryptedKey, err := e.kms.Encrypt(ctx, keyID, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt data key with key %s: %w", keyID, err)
+	}
+	if len(encryptedKey) > math.MaxUint16 {
+		return nil, errors.New("encrypted data key is too long")
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 4+len(keyID)+len(encryptedKey))
+	header = append(header, Version, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(encryptedKey)))
+	header = append(header, encryptedKey...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	b := make([]byte, 0, len(header)+len(nonce)+len(secret)+aead.Overhead())
+	b = append(b, header...)
+	b = append(b, nonce...)
+	return aead.Seal(b, nonce, secret, header), nil
+}
+
+// Decrypt decrypts a secret encrypted by Encrypt. It returns ErrUnsupportedVersion if the secret is not in the format
+// of Version.
+func (e *Envelope) Decrypt(ctx context.Context, payload []byte) ([]byte, error) {
+	p, err := parse(payload)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := e.kms.Decrypt(ctx, p.keyID, p.encryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with key %s: %w", p.keyID, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.ciphertext) < aead.NonceSize() {
+		return nil, errors.New("encrypted secret is too short")
+	}
+	secret, err := aead.Open(nil, p.ciphertext[:aead.NonceSize()], p.ciphertext[aead.NonceSize():], p.header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Rotate re-encrypts the secret with the active key encryption key of the KMS, if it was encrypted with another key.
+// It returns whether the secret was re-encrypted.
+func (e *Envelope) Rotate(ctx context.Context, payload []byte) ([]byte, bool, error) {
+	keyID, err := KeyID(payload)
+	if err != nil {
+		return nil, false, err
+	}
+	active, err := e.kms.ActiveKeyID(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get active key: %w", err)
+	}
+	if keyID == active {
+		return payload, false, nil
+	}
+	secret, err := e.Decrypt(ctx, payload)
+	if err != nil {
+		return nil, false, err
+	}
+	res, err := e.Encrypt(ctx, secret)
+	if err != nil {
+		return nil, false, err
+	}
+	return res, true, nil
+}
+
+// KeyID returns the ID of the key encryption key of the secret encrypted by Encrypt.
+func KeyID(payload []byte) (string, error) {
+	p, err := parse(payload)
+	if err != nil {
+		return "", err
+	}
+	return p.keyID, nil
+}
+
+type parsed struct {
+	header       []byte
+	keyID        string
+	encryptedKey []byte
+	ciphertext   []byte
+}
+
+var errMalformed = errors.New("malformed encrypted secret")
+
+func parse(payload []byte) (parsed, error) {
+	if len(payload) == 0 || payload[0] != Version {
+		return parsed{}, ErrUnsupportedVersion
+	}
+	if len(payload) < 2 {
+		return parsed{}, errMalformed
+	}
+	keyEnd := 2 + int(payload[1])
+	if len(payload) < keyEnd+2 {
+		return parsed{}, errMalformed
+	}
+	headerEnd := keyEnd + 2 + int(binary.BigEndian.Uint16(payload[keyEnd:]))
+	if len(payload) < headerEnd {
+		return parsed{}, errMalformed
+	}
+	return parsed{
+		header:       payload[:headerEnd],
+		keyID:        string(payload[2:keyEnd]),
+		encryptedKey: payload[keyEnd+2 : headerEnd],
+		ciphertext:   payload[headerEnd:],
+	}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,118 @@
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKey(t *testing.T, id string) string {
+	t.Helper()
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return id + ":" + base64.StdEncoding.EncodeToString(key) + "\n"
+}
+
+func writeKeys(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	// Make sure that the modification is detected, even with a coarse modification time.
+	later := time.Now().Add(time.Duration(len(content)) * time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys")
+	key1 := newTestKey(t, "1")
+	writeKeys(t, path, "# keys\n"+key1)
+	kms, err := NewFileKMS(path)
+	require.NoError(t, err)
+	e := New(kms)
+
+	secret := []byte("password")
+	encrypted, err := e.Encrypt(ctx, secret)
+	require.NoError(t, err)
+	require.Equal(t, byte(Version), encrypted[0])
+	require.NotContains(t, string(encrypted), string(secret))
+	keyID, err := KeyID(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "1", keyID)
+
+	decrypted, err := e.Decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+
+	t.Run("secrets are authenticated", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(tampered)-1] ^= 1
+		_, err := e.Decrypt(ctx, tampered)
+		require.Error(t, err)
+
+		tampered = append([]byte{}, encrypted...)
+		tampered[5] ^= 1
+		_, err = e.Decrypt(ctx, tampered)
+		require.Error(t, err)
+
+		_, err = e.Decrypt(ctx, []byte("password"))
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+		_, err = e.Decrypt(ctx, encrypted[:3])
+		require.Error(t, err)
+	})
+
+	t.Run("secrets are re-encrypted with the active key", func(t *testing.T) {
+		same, ok, err := e.Rotate(ctx, encrypted)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, encrypted, same)
+
+		writeKeys(t, path, key1+newTestKey(t, "2"))
+		rotated, ok, err := e.Rotate(ctx, encrypted)
+		require.NoError(t, err)
+		require.True(t, ok)
+		keyID, err := KeyID(rotated)
+		require.NoError(t, err)
+		require.Equal(t, "2", keyID)
+		decrypted, err := e.Decrypt(ctx, rotated)
+		require.NoError(t, err)
+		require.Equal(t, secret, decrypted)
+
+		// The previous key is no longer needed once the secrets are re-encrypted.
+		writeKeys(t, path, newTestKey(t, "3"))
+		_, err = e.Decrypt(ctx, encrypted)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
+
+func TestNewFileKMS(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "no keys", content: "# no keys\n", err: "no keys"},
+		{name: "missing ID", content: ":YWJj\n", err: "line 1"},
+		{name: "invalid base64", content: "1:???\n", err: "invalid key 1"},
+		{name: "short key", content: "1:YWJj\n", err: "must be 32 bytes long"},
+		{name: "duplicate key", content: newTestKey(t, "1") + newTestKey(t, "1"), err: "duplicate key 1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(dir, c.name)
+			writeKeys(t, path, c.content)
+			_, err := NewFileKMS(path)
+			require.ErrorContains(t, err, c.err)
+		})
+	}
+
+	_, err := NewFileKMS(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
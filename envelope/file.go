@@ -0,0 +1,150 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileKMS is a KMS whose key encryption keys are stored in a local file, with one key per line:
+//
+//	# Comments and empty lines are ignored.
+//	2024-01:<base64 encoded key>
+//	2024-06:<base64 encoded key>
+//
+// Keys must be 32 bytes long. The last key is the active key, so keys are rotated by appending a new key to the file.
+// The previous keys must be kept until all secrets are re-encrypted with the new key. The file is read again when it
+// is modified.
+type FileKMS struct {
+	path string
+
+	mtx     sync.Mutex
+	modTime time.Time
+	size    int64
+	active  string
+	keys    map[string]cipher.AEAD
+}
+
+// NewFileKMS returns a FileKMS that reads the keys from the file. It fails if the file cannot be read, or has no keys.
+func NewFileKMS(path string) (*FileKMS, error) {
+	k := &FileKMS{path: path}
+	if _, _, err := k.load(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ActiveKeyID implements the KMS interface.
+func (k *FileKMS) ActiveKeyID(_ context.Context) (string, error) {
+	active, _, err := k.load()
+	return active, err
+}
+
+// Encrypt implements the KMS interface.
+func (k *FileKMS) Encrypt(_ context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	aead, err := k.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(dataKey)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *FileKMS) Decrypt(_ context.Context, keyID string, encryptedKey []byte) ([]byte, error) {
+	aead, err := k.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedKey) < aead.NonceSize() {
+		return nil, errors.New("encrypted data key is too short")
+	}
+	return aead.Open(nil, encryptedKey[:aead.NonceSize()], encryptedKey[aead.NonceSize():], []byte(keyID))
+}
+
+func (k *FileKMS) key(keyID string) (cipher.AEAD, error) {
+	_, keys, err := k.load()
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return aead, nil
+}
+
+// load returns the keys, and reads them again from the file if it was modified.
+func (k *FileKMS) load() (string, map[string]cipher.AEAD, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read keys: %w", err)
+	}
+	if k.keys != nil && info.ModTime().Equal(k.modTime) && info.Size() == k.size {
+		return k.active, k.keys, nil
+	}
+	b, err := os.ReadFile(k.path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read keys: %w", err)
+	}
+	active, keys, err := parseKeys(b)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid keys file %s: %w", k.path, err)
+	}
+	k.modTime, k.size, k.active, k.keys = info.ModTime(), info.Size(), active, keys
+	return active, keys, nil
+}
+
+func parseKeys(b []byte) (string, map[string]cipher.AEAD, error) {
+	var active string
+	keys := map[string]cipher.AEAD{}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok || id == "" {
+			return "", nil, fmt.Errorf("line %d: expected <id>:<base64 encoded key>", n)
+		}
+		if _, ok := keys[id]; ok {
+			return "", nil, fmt.Errorf("line %d: duplicate key %s", n, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return "", nil, fmt.Errorf("line %d: invalid key %s: %w", n, id, err)
+		}
+		if len(key) != dataKeySize {
+			return "", nil, fmt.Errorf("line %d: key %s must be %d bytes long", n, id, dataKeySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return "", nil, fmt.Errorf("line %d: invalid key %s: %w", n, id, err)
+		}
+		keys[id] = aead
+		active = id
+	}
+	if err := s.Err(); err != nil {
+		return "", nil, err
+	}
+	if active == "" {
+		return "", nil, errors.New("no keys")
+	}
+	return active, keys, nil
+}
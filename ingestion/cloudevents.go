@@ -0,0 +1,108 @@
+package ingestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+const (
+	CloudEventsSourceName = "cloudevents"
+
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsResolved    = "resolved"
+)
+
+// CloudEventsAdapter converts CloudEvents 1.0 in structured or batched JSON mode. The data of the event can carry
+// "labels", "annotations", "status" ("firing" or "resolved") and "generatorURL". The type of the event is used as
+// the alert name unless the data provides an alertname label. The event id is not used as a label so that
+// repeated events about the same problem are deduplicated.
+type CloudEventsAdapter struct{}
+
+func NewCloudEventsAdapter() *CloudEventsAdapter {
+	return &CloudEventsAdapter{}
+}
+
+func (c *CloudEventsAdapter) Name() string {
+	return CloudEventsSourceName
+}
+
+type cloudEvent struct {
+	SpecVersion string         `json:"specversion"`
+	ID          string         `json:"id"`
+	Source      string         `json:"source"`
+	Type        string         `json:"type"`
+	Subject     string         `json:"subject"`
+	Time        string         `json:"time"`
+	Data        cloudEventData `json:"data"`
+}
+
+type cloudEventData struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	Status       string            `json:"status"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+// Convert implements the Adapter interface.
+func (c *CloudEventsAdapter) Convert(payload []byte) (amv2.PostableAlerts, error) {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return nil, ErrEmptyPayload
+	}
+
+	var events []cloudEvent
+	if payload[0] == '[' {
+		if err := json.Unmarshal(payload, &events); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CloudEvents batch: %w", err)
+		}
+	} else {
+		var e cloudEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CloudEvent: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	alerts := make(amv2.PostableAlerts, 0, len(events))
+	for i, e := range events {
+		if e.SpecVersion != cloudEventsSpecVersion {
+			return nil, fmt.Errorf("event %d: unsupported specversion %q", i, e.SpecVersion)
+		}
+		if e.Type == "" || e.Source == "" {
+			return nil, fmt.Errorf("event %d: type and source are required", i)
+		}
+		t, err := parseTime(e.Time)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: invalid time: %w", i, err)
+		}
+
+		a := newAlert(c.Name())
+		a.Labels[AlertNameLabel] = e.Type
+		a.Labels["cloudevent_source"] = e.Source
+		setIfNotEmpty(a.Labels, "cloudevent_subject", e.Subject)
+		for k, v := range e.Data.Labels {
+			name := labelName(k)
+			if name == "" || name == SourceLabel {
+				continue
+			}
+			setIfNotEmpty(a.Labels, name, v)
+		}
+		for k, v := range e.Data.Annotations {
+			if name := labelName(k); name != "" {
+				setIfNotEmpty(a.Annotations, name, v)
+			}
+		}
+		if e.Data.GeneratorURL != "" {
+			a.GeneratorURL = strfmt.URI(e.Data.GeneratorURL)
+		}
+
+		setStatus(a, e.Data.Status != cloudEventsResolved, t)
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
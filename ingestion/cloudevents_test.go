@@ -0,0 +1,58 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudEventsAdapter(t *testing.T) {
+	a := NewCloudEventsAdapter()
+
+	t.Run("structured event", func(t *testing.T) {
+		alerts, err := a.Convert([]byte(`{
+			"specversion": "1.0",
+			"id": "e-1",
+			"source": "/payments",
+			"type": "com.example.payment.failed",
+			"subject": "order-42",
+			"time": "2023-11-14T22:13:20Z",
+			"data": {"labels": {"severity": "critical", "alert_source": "ignored", "team-name": "payments"}, "annotations": {"summary": "Payment failed", "runbook.url": "https://example.com/runbook"}, "generatorURL": "https://example.com"}
+		}`))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, amv2.LabelSet{
+			SourceLabel:          CloudEventsSourceName,
+			AlertNameLabel:       "com.example.payment.failed",
+			"cloudevent_source":  "/payments",
+			"cloudevent_subject": "order-42",
+			"severity":           "critical",
+			"team_name":          "payments",
+		}, alerts[0].Labels)
+		require.Equal(t, amv2.LabelSet{"summary": "Payment failed", "runbook_url": "https://example.com/runbook"}, alerts[0].Annotations)
+		require.Equal(t, "https://example.com", alerts[0].GeneratorURL.String())
+		require.True(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC).Equal(time.Time(alerts[0].StartsAt)))
+	})
+
+	t.Run("batch with a resolved event and an alertname label", func(t *testing.T) {
+		alerts, err := a.Convert([]byte(`[
+			{"specversion": "1.0", "id": "1", "source": "/a", "type": "t"},
+			{"specversion": "1.0", "id": "2", "source": "/a", "type": "t", "time": "2023-11-14T22:13:20Z", "data": {"status": "resolved", "labels": {"alertname": "Custom"}}}
+		]`))
+		require.NoError(t, err)
+		require.Len(t, alerts, 2)
+		require.Equal(t, "t", alerts[0].Labels[AlertNameLabel])
+		require.True(t, time.Time(alerts[0].EndsAt).IsZero())
+		require.Equal(t, "Custom", alerts[1].Labels[AlertNameLabel])
+		require.True(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC).Equal(time.Time(alerts[1].EndsAt)))
+	})
+
+	t.Run("invalid events return errors", func(t *testing.T) {
+		_, err := a.Convert([]byte(`{"specversion": "0.3", "source": "/a", "type": "t"}`))
+		require.ErrorContains(t, err, "unsupported specversion")
+		_, err = a.Convert([]byte(`{"specversion": "1.0", "type": "t"}`))
+		require.ErrorContains(t, err, "type and source are required")
+	})
+}
@@ -0,0 +1,123 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+const (
+	CloudWatchSourceName = "cloudwatch"
+
+	snsTypeNotification             = "Notification"
+	snsTypeSubscriptionConfirmation = "SubscriptionConfirmation"
+
+	cloudWatchStateAlarm = "ALARM"
+)
+
+// SubscriptionConfirmationError is returned when the SNS message is a subscription confirmation rather than a notification.
+// The caller is expected to confirm the subscription by visiting SubscribeURL.
+type SubscriptionConfirmationError struct {
+	TopicArn     string
+	SubscribeURL string
+}
+
+func (e SubscriptionConfirmationError) Error() string {
+	return fmt.Sprintf("subscription to topic %s must be confirmed", e.TopicArn)
+}
+
+// CloudWatchAdapter converts CloudWatch alarm state changes delivered by SNS HTTP(S) subscriptions.
+// Alarms in the ALARM state are firing, alarms in any other state are resolved.
+type CloudWatchAdapter struct{}
+
+func NewCloudWatchAdapter() *CloudWatchAdapter {
+	return &CloudWatchAdapter{}
+}
+
+func (c *CloudWatchAdapter) Name() string {
+	return CloudWatchSourceName
+}
+
+type snsMessage struct {
+	Type         string `json:"Type"`
+	MessageID    string `json:"MessageId"`
+	TopicArn     string `json:"TopicArn"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type cloudWatchAlarm struct {
+	AlarmName        string            `json:"AlarmName"`
+	AlarmDescription string            `json:"AlarmDescription"`
+	AWSAccountID     string            `json:"AWSAccountId"`
+	NewStateValue    string            `json:"NewStateValue"`
+	NewStateReason   string            `json:"NewStateReason"`
+	StateChangeTime  string            `json:"StateChangeTime"`
+	Region           string            `json:"Region"`
+	AlarmArn         string            `json:"AlarmArn"`
+	Trigger          cloudWatchTrigger `json:"Trigger"`
+}
+
+type cloudWatchTrigger struct {
+	MetricName string                `json:"MetricName"`
+	Namespace  string                `json:"Namespace"`
+	Dimensions []cloudWatchDimension `json:"Dimensions"`
+}
+
+type cloudWatchDimension struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Convert implements the Adapter interface. The payload is the body of the SNS HTTP(S) request.
+func (c *CloudWatchAdapter) Convert(payload []byte) (amv2.PostableAlerts, error) {
+	if len(payload) == 0 {
+		return nil, ErrEmptyPayload
+	}
+
+	var msg snsMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SNS message: %w", err)
+	}
+
+	switch msg.Type {
+	case snsTypeNotification:
+	case snsTypeSubscriptionConfirmation:
+		return nil, SubscriptionConfirmationError{TopicArn: msg.TopicArn, SubscribeURL: msg.SubscribeURL}
+	default:
+		return nil, fmt.Errorf("unsupported SNS message type %q", msg.Type)
+	}
+
+	var alarm cloudWatchAlarm
+	if err := json.Unmarshal([]byte(msg.Message), &alarm); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CloudWatch alarm: %w", err)
+	}
+	if alarm.AlarmName == "" {
+		return nil, fmt.Errorf("CloudWatch alarm has no name")
+	}
+
+	t, err := parseTime(alarm.StateChangeTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid StateChangeTime: %w", err)
+	}
+
+	a := newAlert(c.Name())
+	a.Labels[AlertNameLabel] = alarm.AlarmName
+	setIfNotEmpty(a.Labels, "aws_account_id", alarm.AWSAccountID)
+	setIfNotEmpty(a.Labels, "region", alarm.Region)
+	setIfNotEmpty(a.Labels, "namespace", alarm.Trigger.Namespace)
+	setIfNotEmpty(a.Labels, "metric_name", alarm.Trigger.MetricName)
+	for _, d := range alarm.Trigger.Dimensions {
+		setIfNotEmpty(a.Labels, "dimension_"+labelName(d.Name), d.Value)
+	}
+
+	setIfNotEmpty(a.Annotations, "summary", alarm.NewStateReason)
+	setIfNotEmpty(a.Annotations, "description", alarm.AlarmDescription)
+	setIfNotEmpty(a.Annotations, "alarm_arn", alarm.AlarmArn)
+	setIfNotEmpty(a.Annotations, "state", alarm.NewStateValue)
+
+	setStatus(a, alarm.NewStateValue == cloudWatchStateAlarm, t)
+
+	return amv2.PostableAlerts{a}, nil
+}
@@ -0,0 +1,83 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func snsPayload(t *testing.T, alarm string) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]string{
+		"Type":      "Notification",
+		"MessageId": "0c2d1b33-3f2b-5a0b-9f3a-6a2d0d0b3a30",
+		"TopicArn":  "arn:aws:sns:eu-west-1:123456789012:alerts",
+		"Message":   alarm,
+	})
+	require.NoError(t, err)
+	return b
+}
+
+func TestCloudWatchAdapter(t *testing.T) {
+	a := NewCloudWatchAdapter()
+	alarm := `{
+		"AlarmName": "HighCPU",
+		"AlarmDescription": "CPU above 90%",
+		"AWSAccountId": "123456789012",
+		"NewStateValue": "__STATE__",
+		"NewStateReason": "Threshold Crossed",
+		"StateChangeTime": "2023-11-14T22:13:20.000+0000",
+		"Region": "EU (Ireland)",
+		"AlarmArn": "arn:aws:cloudwatch:eu-west-1:123456789012:alarm:HighCPU",
+		"Trigger": {"MetricName": "CPUUtilization", "Namespace": "AWS/EC2", "Dimensions": [{"name": "InstanceId", "value": "i-123"}, {"name": "availability-zone", "value": "eu-west-1a"}]}
+	}`
+	changed := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	expLabels := amv2.LabelSet{
+		SourceLabel:                   CloudWatchSourceName,
+		AlertNameLabel:                "HighCPU",
+		"aws_account_id":              "123456789012",
+		"region":                      "EU (Ireland)",
+		"namespace":                   "AWS/EC2",
+		"metric_name":                 "CPUUtilization",
+		"dimension_InstanceId":        "i-123",
+		"dimension_availability_zone": "eu-west-1a",
+	}
+
+	t.Run("alarm state is firing", func(t *testing.T) {
+		alerts, err := a.Convert(snsPayload(t, strings.ReplaceAll(alarm, "__STATE__", "ALARM")))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, expLabels, alerts[0].Labels)
+		require.Equal(t, "Threshold Crossed", alerts[0].Annotations["summary"])
+		require.True(t, changed.Equal(time.Time(alerts[0].StartsAt)))
+		require.True(t, time.Time(alerts[0].EndsAt).IsZero())
+	})
+
+	t.Run("ok state is resolved with the same labels", func(t *testing.T) {
+		alerts, err := a.Convert(snsPayload(t, strings.ReplaceAll(alarm, "__STATE__", "OK")))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, expLabels, alerts[0].Labels)
+		require.True(t, changed.Equal(time.Time(alerts[0].EndsAt)))
+	})
+
+	t.Run("subscription confirmation returns a typed error", func(t *testing.T) {
+		_, err := a.Convert([]byte(`{"Type": "SubscriptionConfirmation", "TopicArn": "arn", "SubscribeURL": "https://sns/confirm"}`))
+		var confirmErr SubscriptionConfirmationError
+		require.ErrorAs(t, err, &confirmErr)
+		require.Equal(t, "https://sns/confirm", confirmErr.SubscribeURL)
+	})
+
+	t.Run("invalid payloads return errors", func(t *testing.T) {
+		_, err := a.Convert(nil)
+		require.ErrorIs(t, err, ErrEmptyPayload)
+		_, err = a.Convert([]byte(`{"Type": "UnsubscribeConfirmation"}`))
+		require.ErrorContains(t, err, "unsupported SNS message type")
+		_, err = a.Convert(snsPayload(t, `not json`))
+		require.ErrorContains(t, err, "failed to unmarshal CloudWatch alarm")
+	})
+}
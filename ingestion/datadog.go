@@ -0,0 +1,111 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+const (
+	DatadogSourceName = "datadog"
+
+	datadogTransitionRecovered = "Recovered"
+)
+
+// datadogTitlePrefix matches the prefix Datadog adds to the title of monitor events, for example "[Triggered on {host:a}] ".
+var datadogTitlePrefix = regexp.MustCompile(`^\[[^\]]*\]\s*`)
+
+// DatadogAdapter converts notifications sent by the Datadog webhooks integration. The webhook must use a custom payload
+// with the following fields, named after the Datadog variables they are populated from:
+//
+//	{
+//	  "alert_id": "$ALERT_ID",
+//	  "title": "$EVENT_TITLE",
+//	  "body": "$EVENT_MSG",
+//	  "transition": "$ALERT_TRANSITION",
+//	  "priority": "$ALERT_PRIORITY",
+//	  "hostname": "$HOSTNAME",
+//	  "scope": "$ALERT_SCOPE",
+//	  "tags": "$TAGS",
+//	  "date": "$DATE_POSIX",
+//	  "link": "$LINK"
+//	}
+//
+// Notifications with the "Recovered" transition are resolved, every other transition is firing.
+type DatadogAdapter struct{}
+
+func NewDatadogAdapter() *DatadogAdapter {
+	return &DatadogAdapter{}
+}
+
+func (d *DatadogAdapter) Name() string {
+	return DatadogSourceName
+}
+
+type datadogEvent struct {
+	AlertID    string `json:"alert_id"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	Transition string `json:"transition"`
+	Priority   string `json:"priority"`
+	Hostname   string `json:"hostname"`
+	Scope      string `json:"scope"`
+	Tags       string `json:"tags"`
+	Date       string `json:"date"`
+	Link       string `json:"link"`
+}
+
+// Convert implements the Adapter interface.
+func (d *DatadogAdapter) Convert(payload []byte) (amv2.PostableAlerts, error) {
+	if len(payload) == 0 {
+		return nil, ErrEmptyPayload
+	}
+
+	var e datadogEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Datadog event: %w", err)
+	}
+
+	name := datadogTitlePrefix.ReplaceAllString(e.Title, "")
+	if name == "" {
+		return nil, fmt.Errorf("Datadog event has no title")
+	}
+
+	t, err := parseTime(e.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %w", err)
+	}
+
+	a := newAlert(d.Name())
+	a.Labels[AlertNameLabel] = name
+	setIfNotEmpty(a.Labels, "alert_id", e.AlertID)
+	setIfNotEmpty(a.Labels, "host", e.Hostname)
+	setIfNotEmpty(a.Labels, "priority", strings.ToLower(e.Priority))
+	setIfNotEmpty(a.Labels, "scope", e.Scope)
+	for _, tag := range strings.Split(e.Tags, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(tag), ":")
+		if !ok || k == "" {
+			continue
+		}
+		k = labelName(k)
+		if _, exists := a.Labels[k]; exists {
+			continue
+		}
+		setIfNotEmpty(a.Labels, k, v)
+	}
+
+	setIfNotEmpty(a.Annotations, "summary", e.Title)
+	setIfNotEmpty(a.Annotations, "description", e.Body)
+	setIfNotEmpty(a.Annotations, "transition", e.Transition)
+	if e.Link != "" {
+		a.GeneratorURL = strfmt.URI(e.Link)
+	}
+
+	setStatus(a, e.Transition != datadogTransitionRecovered, t)
+
+	return amv2.PostableAlerts{a}, nil
+}
@@ -0,0 +1,63 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatadogAdapter(t *testing.T) {
+	a := NewDatadogAdapter()
+	payload := func(transition string) []byte {
+		return []byte(`{
+			"alert_id": "123",
+			"title": "[` + transition + ` on {host:web-1}] CPU is high",
+			"body": "CPU is above 90%",
+			"transition": "` + transition + `",
+			"priority": "P1",
+			"hostname": "web-1",
+			"scope": "host:web-1",
+			"tags": "env:prod, service:web, standalone, availability-zone:us-east-1a, kube.namespace:default",
+			"date": "1700000000000",
+			"link": "https://app.datadoghq.com/event/123"
+		}`)
+	}
+	expLabels := amv2.LabelSet{
+		SourceLabel:    DatadogSourceName,
+		AlertNameLabel: "CPU is high",
+		"alert_id":     "123",
+		"host":         "web-1",
+		"priority":     "p1",
+		"scope":        "host:web-1",
+		"env":          "prod",
+		"service":      "web",
+		// Tag names are sanitized into valid label names.
+		"availability_zone": "us-east-1a",
+		"kube_namespace":    "default",
+	}
+
+	t.Run("triggered notification is firing", func(t *testing.T) {
+		alerts, err := a.Convert(payload("Triggered"))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, expLabels, alerts[0].Labels)
+		require.Equal(t, "CPU is above 90%", alerts[0].Annotations["description"])
+		require.True(t, time.UnixMilli(1700000000000).Equal(time.Time(alerts[0].StartsAt)))
+		require.True(t, time.Time(alerts[0].EndsAt).IsZero())
+	})
+
+	t.Run("recovered notification is resolved with the same labels", func(t *testing.T) {
+		alerts, err := a.Convert(payload("Recovered"))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, expLabels, alerts[0].Labels)
+		require.True(t, time.UnixMilli(1700000000000).Equal(time.Time(alerts[0].EndsAt)))
+	})
+
+	t.Run("missing title returns an error", func(t *testing.T) {
+		_, err := a.Convert([]byte(`{"transition": "Triggered"}`))
+		require.ErrorContains(t, err, "no title")
+	})
+}
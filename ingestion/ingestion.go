@@ -0,0 +1,103 @@
+package ingestion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+const (
+	// SourceLabel is the label set by every adapter with the name of the adapter that produced the alert.
+	SourceLabel = "alert_source"
+	// AlertNameLabel is the name of the alert.
+	AlertNameLabel = "alertname"
+)
+
+var (
+	ErrEmptyPayload = errors.New("empty payload")
+)
+
+// Adapter converts a third-party payload into alerts that can be passed to the Alertmanager.
+type Adapter interface {
+	// Name returns the name of the adapter. It is used as value of SourceLabel.
+	Name() string
+	// Convert parses the payload and returns the alerts it describes. Firing alerts have a zero EndsAt, resolved ones have EndsAt set.
+	Convert(payload []byte) (amv2.PostableAlerts, error)
+}
+
+// newAlert returns a PostableAlert with the source label of the adapter already set.
+func newAlert(source string) *amv2.PostableAlert {
+	return &amv2.PostableAlert{
+		Alert: amv2.Alert{
+			Labels: amv2.LabelSet{SourceLabel: source},
+		},
+		Annotations: amv2.LabelSet{},
+	}
+}
+
+// setStatus sets StartsAt and EndsAt of the alert according to whether it is firing or resolved at the time t.
+func setStatus(a *amv2.PostableAlert, firing bool, t time.Time) {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	if firing {
+		a.StartsAt = strfmt.DateTime(t)
+		return
+	}
+	a.EndsAt = strfmt.DateTime(t)
+}
+
+// setIfNotEmpty sets the key in the label set only if the value is not empty.
+func setIfNotEmpty(ls amv2.LabelSet, key, value string) {
+	if value != "" {
+		ls[key] = value
+	}
+}
+
+// labelName returns the name with the characters that are not allowed in label names replaced with underscores,
+// and prefixed with an underscore if it starts with a digit. Tags and dimensions such as "availability-zone" or
+// "kube.namespace" would otherwise make the whole alert invalid.
+func labelName(name string) string {
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// parseTime parses a timestamp in RFC3339 format, or as a number of seconds or milliseconds since the Unix epoch.
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		// Timestamps in seconds will only have 13 digits in the year 33658.
+		if n >= 1e12 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-0700", "2006-01-02T15:04:05-0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format %q", s)
+}
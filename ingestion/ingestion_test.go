@@ -0,0 +1,58 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	cases := []struct {
+		input  string
+		exp    time.Time
+		expErr bool
+	}{
+		{input: "", exp: time.Time{}},
+		{input: "1700000000", exp: time.Unix(1700000000, 0).UTC()},
+		{input: "1700000000123", exp: time.UnixMilli(1700000000123).UTC()},
+		{input: "2023-11-14T22:13:20Z", exp: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{input: "2023-11-14T22:13:20.000+0000", exp: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)},
+		{input: "yesterday", expErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parseTime(tc.input)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.exp.Equal(got), "expected %s, got %s", tc.exp, got)
+		})
+	}
+}
+
+func TestLabelName(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"service":              "service",
+		"AutoScalingGroupName": "AutoScalingGroupName",
+		"availability-zone":    "availability_zone",
+		"kube.namespace":       "kube_namespace",
+		"1st":                  "_1st",
+		"zone/2":               "zone_2",
+		"équipe":               "_quipe",
+	}
+	for input, exp := range cases {
+		t.Run(input, func(t *testing.T) {
+			got := labelName(input)
+			require.Equal(t, exp, got)
+			if got != "" {
+				require.True(t, model.LabelName(got).IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// DefaultResolvedValues are the values of the status field that mark an alert as resolved when JSONPathMapping.ResolvedValues is empty.
+var DefaultResolvedValues = []string{"resolved", "ok", "recovered", "inactive"}
+
+// JSONPathMapping describes how to build alerts from an arbitrary JSON document.
+// The supported JSONPath syntax is the root ($), child names (.name or ['name']), array indexes ([0], [-1]) and wildcards ([*] or .*).
+type JSONPathMapping struct {
+	// Name is used as value of SourceLabel.
+	Name string `json:"name" yaml:"name"`
+	// Alerts is the path to the alerts in the document. Every value it selects is converted into an alert. Defaults to the root of the document.
+	Alerts string `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+	// Labels maps label names to paths, relative to each alert. It must contain alertname unless StaticLabels does.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// StaticLabels are added to every alert. Labels from the document take precedence.
+	StaticLabels map[string]string `json:"static_labels,omitempty" yaml:"static_labels,omitempty"`
+	// Annotations maps annotation names to paths, relative to each alert.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Status is the path to the status of the alert. Alerts without status are firing.
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	// ResolvedValues are the values of Status, compared case-insensitively, that mark the alert as resolved.
+	ResolvedValues []string `json:"resolved_values,omitempty" yaml:"resolved_values,omitempty"`
+	StartsAt       string   `json:"starts_at,omitempty" yaml:"starts_at,omitempty"`
+	EndsAt         string   `json:"ends_at,omitempty" yaml:"ends_at,omitempty"`
+	GeneratorURL   string   `json:"generator_url,omitempty" yaml:"generator_url,omitempty"`
+}
+
+// JSONPathAdapter converts arbitrary JSON documents into alerts using a JSONPathMapping.
+type JSONPathAdapter struct {
+	name           string
+	alerts         jsonPath
+	labels         map[string]jsonPath
+	staticLabels   map[string]string
+	annotations    map[string]jsonPath
+	status         jsonPath
+	resolvedValues []string
+	startsAt       jsonPath
+	endsAt         jsonPath
+	generatorURL   jsonPath
+}
+
+// NewJSONPathAdapter compiles the paths of the mapping. It returns an error if any of them is invalid.
+func NewJSONPathAdapter(m JSONPathMapping) (*JSONPathAdapter, error) {
+	if m.Name == "" {
+		return nil, fmt.Errorf("mapping must have a name")
+	}
+	_, hasName := m.Labels[AlertNameLabel]
+	_, hasStaticName := m.StaticLabels[AlertNameLabel]
+	if !hasName && !hasStaticName {
+		return nil, fmt.Errorf("mapping %q must define the %s label", m.Name, AlertNameLabel)
+	}
+
+	compile := func(field, p string) (jsonPath, error) {
+		if p == "" {
+			return nil, nil
+		}
+		jp, err := compileJSONPath(p)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q: invalid path for %s: %w", m.Name, field, err)
+		}
+		return jp, nil
+	}
+
+	var err error
+	a := &JSONPathAdapter{
+		name:           m.Name,
+		labels:         make(map[string]jsonPath, len(m.Labels)),
+		staticLabels:   m.StaticLabels,
+		annotations:    make(map[string]jsonPath, len(m.Annotations)),
+		resolvedValues: m.ResolvedValues,
+	}
+	if len(a.resolvedValues) == 0 {
+		a.resolvedValues = DefaultResolvedValues
+	}
+	alertsPath := m.Alerts
+	if alertsPath == "" {
+		alertsPath = "$"
+	}
+	if a.alerts, err = compile("alerts", alertsPath); err != nil {
+		return nil, err
+	}
+	for k, p := range m.Labels {
+		if a.labels[k], err = compile("label "+k, p); err != nil {
+			return nil, err
+		}
+	}
+	for k, p := range m.Annotations {
+		if a.annotations[k], err = compile("annotation "+k, p); err != nil {
+			return nil, err
+		}
+	}
+	if a.status, err = compile("status", m.Status); err != nil {
+		return nil, err
+	}
+	if a.startsAt, err = compile("starts_at", m.StartsAt); err != nil {
+		return nil, err
+	}
+	if a.endsAt, err = compile("ends_at", m.EndsAt); err != nil {
+		return nil, err
+	}
+	if a.generatorURL, err = compile("generator_url", m.GeneratorURL); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (j *JSONPathAdapter) Name() string {
+	return j.name
+}
+
+// Convert implements the Adapter interface.
+func (j *JSONPathAdapter) Convert(payload []byte) (amv2.PostableAlerts, error) {
+	if len(payload) == 0 {
+		return nil, ErrEmptyPayload
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	elements := j.alerts.eval(doc)
+	alerts := make(amv2.PostableAlerts, 0, len(elements))
+	for i, el := range elements {
+		a := newAlert(j.name)
+		for k, v := range j.staticLabels {
+			setIfNotEmpty(a.Labels, k, v)
+		}
+		for k, p := range j.labels {
+			setIfNotEmpty(a.Labels, k, p.first(el))
+		}
+		for k, p := range j.annotations {
+			setIfNotEmpty(a.Annotations, k, p.first(el))
+		}
+		if u := j.generatorURL.first(el); u != "" {
+			a.GeneratorURL = strfmt.URI(u)
+		}
+
+		startsAt, err := parseTime(j.startsAt.first(el))
+		if err != nil {
+			return nil, fmt.Errorf("alert %d: invalid starts_at: %w", i, err)
+		}
+		endsAt, err := parseTime(j.endsAt.first(el))
+		if err != nil {
+			return nil, fmt.Errorf("alert %d: invalid ends_at: %w", i, err)
+		}
+
+		a.StartsAt = strfmt.DateTime(startsAt)
+		a.EndsAt = strfmt.DateTime(endsAt)
+		if j.isResolved(j.status.first(el)) && endsAt.IsZero() {
+			a.EndsAt = strfmt.DateTime(time.Now())
+		}
+
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
+
+func (j *JSONPathAdapter) isResolved(status string) bool {
+	for _, v := range j.resolvedValues {
+		if strings.EqualFold(v, status) {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonPathSegmentKind int
+
+const (
+	jsonPathField jsonPathSegmentKind = iota
+	jsonPathIndex
+	jsonPathWildcard
+)
+
+type jsonPathSegment struct {
+	kind  jsonPathSegmentKind
+	field string
+	index int
+}
+
+// jsonPath is a compiled JSONPath expression.
+type jsonPath []jsonPathSegment
+
+func compileJSONPath(p string) (jsonPath, error) {
+	p = strings.TrimSpace(p)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("path %q must start with $", p)
+	}
+	// A non-nil empty path selects the root of the document.
+	path := jsonPath{}
+	rest := p[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			path = append(path, jsonPathSegment{kind: jsonPathWildcard})
+			rest = rest[2:]
+		case rest[0] == '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %q has an empty field name", p)
+			}
+			path = append(path, jsonPathSegment{kind: jsonPathField, field: rest[:end]})
+			rest = rest[end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("path %q has an unterminated bracket", p)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				path = append(path, jsonPathSegment{kind: jsonPathWildcard})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, jsonPathSegment{kind: jsonPathField, field: inner[1 : len(inner)-1]})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q has an invalid index %q", p, inner)
+				}
+				path = append(path, jsonPathSegment{kind: jsonPathIndex, index: idx})
+			}
+		default:
+			return nil, fmt.Errorf("path %q has an unexpected character %q", p, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// eval returns all the values selected by the path. It returns nil for a nil path.
+func (p jsonPath) eval(doc interface{}) []interface{} {
+	if p == nil {
+		return nil
+	}
+	current := []interface{}{doc}
+	for _, seg := range p {
+		var next []interface{}
+		for _, v := range current {
+			switch seg.kind {
+			case jsonPathField:
+				if m, ok := v.(map[string]interface{}); ok {
+					if child, ok := m[seg.field]; ok {
+						next = append(next, child)
+					}
+				}
+			case jsonPathIndex:
+				if arr, ok := v.([]interface{}); ok {
+					idx := seg.index
+					if idx < 0 {
+						idx += len(arr)
+					}
+					if idx >= 0 && idx < len(arr) {
+						next = append(next, arr[idx])
+					}
+				}
+			case jsonPathWildcard:
+				switch c := v.(type) {
+				case []interface{}:
+					next = append(next, c...)
+				case map[string]interface{}:
+					// Iterate in key order so that the output is deterministic.
+					keys := make([]string, 0, len(c))
+					for k := range c {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, c[k])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// first returns the first value selected by the path as a string, or an empty string if nothing is selected.
+func (p jsonPath) first(doc interface{}) string {
+	values := p.eval(doc)
+	if len(values) == 0 {
+		return ""
+	}
+	switch v := values[0].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,97 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b":         []interface{}{"x", "y", "z"},
+			"with.dots": 1.5,
+		},
+		"list": []interface{}{
+			map[string]interface{}{"n": "1"},
+			map[string]interface{}{"n": "2"},
+		},
+	}
+
+	cases := []struct {
+		path   string
+		exp    []interface{}
+		expErr string
+	}{
+		{path: "$", exp: []interface{}{doc}},
+		{path: "$.a.b[0]", exp: []interface{}{"x"}},
+		{path: "$.a.b[-1]", exp: []interface{}{"z"}},
+		{path: "$.a['with.dots']", exp: []interface{}{1.5}},
+		{path: `$["a"].b[*]`, exp: []interface{}{"x", "y", "z"}},
+		{path: "$.list[*].n", exp: []interface{}{"1", "2"}},
+		{path: "$.list.*.n", exp: []interface{}{"1", "2"}},
+		{path: "$.missing.n", exp: nil},
+		{path: "a.b", expErr: "must start with $"},
+		{path: "$.a[", expErr: "unterminated bracket"},
+		{path: "$.a[x]", expErr: "invalid index"},
+		{path: "$..a", expErr: "empty field name"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			p, err := compileJSONPath(tc.path)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, p.eval(doc))
+		})
+	}
+}
+
+func TestJSONPathAdapter(t *testing.T) {
+	a, err := NewJSONPathAdapter(JSONPathMapping{
+		Name:   "custom",
+		Alerts: "$.incidents[*]",
+		Labels: map[string]string{
+			"alertname": "$.check",
+			"host":      "$.target.host",
+			"code":      "$.code",
+		},
+		StaticLabels: map[string]string{"team": "ops"},
+		Annotations:  map[string]string{"summary": "$.message"},
+		Status:       "$.state",
+		StartsAt:     "$.opened",
+		GeneratorURL: "$.url",
+	})
+	require.NoError(t, err)
+
+	alerts, err := a.Convert([]byte(`{"incidents": [
+		{"check": "disk", "target": {"host": "db-1"}, "code": 507, "message": "Disk full", "state": "open", "opened": 1700000000, "url": "https://monitor/1"},
+		{"check": "ping", "target": {"host": "db-2"}, "state": "RESOLVED"}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+
+	require.Equal(t, amv2.LabelSet{SourceLabel: "custom", AlertNameLabel: "disk", "host": "db-1", "code": "507", "team": "ops"}, alerts[0].Labels)
+	require.Equal(t, amv2.LabelSet{"summary": "Disk full"}, alerts[0].Annotations)
+	require.Equal(t, "https://monitor/1", alerts[0].GeneratorURL.String())
+	require.True(t, time.Unix(1700000000, 0).Equal(time.Time(alerts[0].StartsAt)))
+	require.True(t, time.Time(alerts[0].EndsAt).IsZero())
+
+	require.Equal(t, amv2.LabelSet{SourceLabel: "custom", AlertNameLabel: "ping", "host": "db-2", "team": "ops"}, alerts[1].Labels)
+	require.False(t, time.Time(alerts[1].EndsAt).IsZero())
+
+	t.Run("mapping without alertname is invalid", func(t *testing.T) {
+		_, err := NewJSONPathAdapter(JSONPathMapping{Name: "custom", Labels: map[string]string{"host": "$.host"}})
+		require.ErrorContains(t, err, "must define the alertname label")
+	})
+
+	t.Run("mapping with invalid path is invalid", func(t *testing.T) {
+		_, err := NewJSONPathAdapter(JSONPathMapping{Name: "custom", Labels: map[string]string{"alertname": "check"}})
+		require.ErrorContains(t, err, "invalid path for label alertname")
+	})
+}
@@ -0,0 +1,156 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+const (
+	ZabbixSourceName = "zabbix"
+
+	zabbixDateLayout = "2006.01.02 15:04:05"
+	// zabbixValueProblem is the value of {EVENT.VALUE} for problem events. Recovery events have the value "0".
+	zabbixValueProblem = "1"
+)
+
+// ZabbixAdapter converts events sent by a Zabbix webhook media type. The media type must post a JSON object
+// with the following parameters, named after the Zabbix macros they are populated from:
+//
+//	event_id       {EVENT.ID}
+//	event_name     {EVENT.NAME}
+//	event_value    {EVENT.VALUE}
+//	event_severity {EVENT.SEVERITY}
+//	event_date     {EVENT.DATE}
+//	event_time     {EVENT.TIME}
+//	recovery_date  {EVENT.RECOVERY.DATE}
+//	recovery_time  {EVENT.RECOVERY.TIME}
+//	event_tags     {EVENT.TAGSJSON}
+//	host_name      {HOST.NAME}
+//	trigger_id     {TRIGGER.ID}
+//	trigger_name   {TRIGGER.NAME}
+//	trigger_description {TRIGGER.DESCRIPTION}
+//	event_url      link to the event in the Zabbix frontend
+//
+// Dates are interpreted in the location of the adapter, UTC by default.
+type ZabbixAdapter struct {
+	location *time.Location
+}
+
+func NewZabbixAdapter(location *time.Location) *ZabbixAdapter {
+	if location == nil {
+		location = time.UTC
+	}
+	return &ZabbixAdapter{location: location}
+}
+
+func (z *ZabbixAdapter) Name() string {
+	return ZabbixSourceName
+}
+
+type zabbixEvent struct {
+	EventID            string     `json:"event_id"`
+	EventName          string     `json:"event_name"`
+	EventValue         string     `json:"event_value"`
+	EventSeverity      string     `json:"event_severity"`
+	EventDate          string     `json:"event_date"`
+	EventTime          string     `json:"event_time"`
+	RecoveryDate       string     `json:"recovery_date"`
+	RecoveryTime       string     `json:"recovery_time"`
+	EventTags          zabbixTags `json:"event_tags"`
+	HostName           string     `json:"host_name"`
+	TriggerID          string     `json:"trigger_id"`
+	TriggerName        string     `json:"trigger_name"`
+	TriggerDescription string     `json:"trigger_description"`
+	EventURL           string     `json:"event_url"`
+}
+
+type zabbixTag struct {
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// zabbixTags accepts {EVENT.TAGSJSON} both as a JSON array and as a string containing the JSON array,
+// which is how webhook parameters are usually forwarded.
+type zabbixTags []zabbixTag
+
+func (t *zabbixTags) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		b = []byte(s)
+	}
+	var tags []zabbixTag
+	if err := json.Unmarshal(b, &tags); err != nil {
+		return err
+	}
+	*t = tags
+	return nil
+}
+
+// Convert implements the Adapter interface.
+func (z *ZabbixAdapter) Convert(payload []byte) (amv2.PostableAlerts, error) {
+	if len(payload) == 0 {
+		return nil, ErrEmptyPayload
+	}
+
+	var e zabbixEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Zabbix event: %w", err)
+	}
+
+	name := e.TriggerName
+	if name == "" {
+		name = e.EventName
+	}
+	if name == "" {
+		return nil, fmt.Errorf("Zabbix event has neither trigger_name nor event_name")
+	}
+
+	firing := e.EventValue == "" || e.EventValue == zabbixValueProblem
+	date, clock := e.EventDate, e.EventTime
+	if !firing && e.RecoveryDate != "" {
+		date, clock = e.RecoveryDate, e.RecoveryTime
+	}
+	var t time.Time
+	if date != "" {
+		var err error
+		t, err = time.ParseInLocation(zabbixDateLayout, strings.TrimSpace(date+" "+clock), z.location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event date: %w", err)
+		}
+	}
+
+	a := newAlert(z.Name())
+	a.Labels[AlertNameLabel] = name
+	setIfNotEmpty(a.Labels, "host", e.HostName)
+	setIfNotEmpty(a.Labels, "trigger_id", e.TriggerID)
+	setIfNotEmpty(a.Labels, "severity", strings.ToLower(e.EventSeverity))
+	for _, tag := range e.EventTags {
+		if tag.Tag == "" {
+			continue
+		}
+		k := labelName(tag.Tag)
+		if _, ok := a.Labels[k]; ok {
+			continue
+		}
+		setIfNotEmpty(a.Labels, k, tag.Value)
+	}
+
+	setIfNotEmpty(a.Annotations, "summary", e.EventName)
+	setIfNotEmpty(a.Annotations, "description", e.TriggerDescription)
+	setIfNotEmpty(a.Annotations, "event_id", e.EventID)
+	if e.EventURL != "" {
+		a.GeneratorURL = strfmt.URI(e.EventURL)
+	}
+
+	setStatus(a, firing, t)
+
+	return amv2.PostableAlerts{a}, nil
+}
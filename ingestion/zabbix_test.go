@@ -0,0 +1,76 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZabbixAdapter(t *testing.T) {
+	a := NewZabbixAdapter(nil)
+
+	t.Run("problem event is firing", func(t *testing.T) {
+		alerts, err := a.Convert([]byte(`{
+			"event_id": "42",
+			"event_name": "High CPU on web-1",
+			"event_value": "1",
+			"event_severity": "High",
+			"event_date": "2023.11.14",
+			"event_time": "22:13:20",
+			"event_tags": "[{\"tag\": \"service\", \"value\": \"web\"}, {\"tag\": \"kube.namespace\", \"value\": \"default\"}]",
+			"host_name": "web-1",
+			"trigger_id": "1001",
+			"trigger_name": "High CPU",
+			"event_url": "https://zabbix/tr_events.php?eventid=42"
+		}`))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, amv2.LabelSet{
+			SourceLabel:      ZabbixSourceName,
+			AlertNameLabel:   "High CPU",
+			"host":           "web-1",
+			"trigger_id":     "1001",
+			"severity":       "high",
+			"service":        "web",
+			"kube_namespace": "default",
+		}, alerts[0].Labels)
+		require.Equal(t, "High CPU on web-1", alerts[0].Annotations["summary"])
+		require.Equal(t, "https://zabbix/tr_events.php?eventid=42", alerts[0].GeneratorURL.String())
+		require.True(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC).Equal(time.Time(alerts[0].StartsAt)))
+		require.True(t, time.Time(alerts[0].EndsAt).IsZero())
+	})
+
+	t.Run("recovery event is resolved at the recovery time", func(t *testing.T) {
+		alerts, err := a.Convert([]byte(`{
+			"event_value": "0",
+			"event_date": "2023.11.14",
+			"event_time": "22:13:20",
+			"recovery_date": "2023.11.14",
+			"recovery_time": "23:00:00",
+			"event_tags": [{"tag": "service", "value": "web"}],
+			"host_name": "web-1",
+			"trigger_id": "1001",
+			"trigger_name": "High CPU"
+		}`))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Equal(t, "web", alerts[0].Labels["service"])
+		require.True(t, time.Date(2023, 11, 14, 23, 0, 0, 0, time.UTC).Equal(time.Time(alerts[0].EndsAt)))
+	})
+
+	t.Run("dates use the location of the adapter", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		alerts, err := NewZabbixAdapter(loc).Convert([]byte(`{"trigger_name": "a", "event_date": "2023.11.14", "event_time": "22:13:20"}`))
+		require.NoError(t, err)
+		require.True(t, time.Date(2023, 11, 14, 20, 13, 20, 0, time.UTC).Equal(time.Time(alerts[0].StartsAt)))
+	})
+
+	t.Run("invalid payloads return errors", func(t *testing.T) {
+		_, err := a.Convert([]byte(`{}`))
+		require.ErrorContains(t, err, "neither trigger_name nor event_name")
+		_, err = a.Convert([]byte(`{"trigger_name": "a", "event_date": "14/11/2023"}`))
+		require.ErrorContains(t, err, "invalid event date")
+	})
+}
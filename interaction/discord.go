@@ -0,0 +1,113 @@
+package interaction
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Types of the interactions and of the responses, https://discord.com/developers/docs/interactions/receiving-and-responding.
+const (
+	discordInteractionPing             = 1
+	discordInteractionMessageComponent = 3
+
+	discordResponsePong          = 1
+	discordResponseUpdateMessage = 7
+)
+
+// DiscordConfig configures the interactions endpoint of a Discord application.
+type DiscordConfig struct {
+	// PublicKey is the public key of the application, used to verify the interactions.
+	PublicKey ed25519.PublicKey
+}
+
+// discordInteraction is an interaction, https://discord.com/developers/docs/interactions/receiving-and-responding#interaction-object.
+type discordInteraction struct {
+	Type int `json:"type"`
+	Data struct {
+		CustomID string `json:"custom_id"`
+	} `json:"data"`
+	// Member is set in guilds, and User in direct messages.
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User    *discordUser `json:"user"`
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+}
+
+type discordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+func (h *Handler) handleDiscord(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformDiscord, err)
+		return
+	}
+	if err := verifyDiscordSignature(h.cfg.Discord.PublicKey, r.Header, body); err != nil {
+		h.writeError(w, http.StatusUnauthorized, PlatformDiscord, err)
+		return
+	}
+	var interaction discordInteraction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformDiscord, fmt.Errorf("failed to parse interaction: %w", err))
+		return
+	}
+	switch interaction.Type {
+	case discordInteractionPing:
+		h.writeJSON(w, http.StatusOK, map[string]interface{}{"type": discordResponsePong})
+		return
+	case discordInteractionMessageComponent:
+	default:
+		h.writeError(w, http.StatusBadRequest, PlatformDiscord, fmt.Errorf("unsupported interaction type %d", interaction.Type))
+		return
+	}
+	action, err := decodeAction(interaction.Data.CustomID, "")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformDiscord, err)
+		return
+	}
+
+	var user discordUser
+	if interaction.Member != nil {
+		user = interaction.Member.User
+	} else if interaction.User != nil {
+		user = *interaction.User
+	}
+	status := h.execute(r.Context(), callback{platform: PlatformDiscord, user: user.Username, action: action})
+
+	// Update the original message with the status, and remove the buttons.
+	content := status
+	if interaction.Message.Content != "" {
+		content = interaction.Message.Content + "\n\n" + status
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"type": discordResponseUpdateMessage,
+		"data": map[string]interface{}{
+			"content":    content,
+			"components": []interface{}{},
+		},
+	})
+}
+
+// verifyDiscordSignature verifies the signature of an interaction, https://discord.com/developers/docs/interactions/overview#setting-up-an-endpoint-validating-security-request-headers.
+func verifyDiscordSignature(key ed25519.PublicKey, header http.Header, body []byte) error {
+	sig, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+	ts := header.Get("X-Signature-Timestamp")
+	if ts == "" {
+		return fmt.Errorf("%w: missing timestamp", ErrUnauthorized)
+	}
+	if !ed25519.Verify(key, append([]byte(ts), body...), sig) {
+		return fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+	return nil
+}
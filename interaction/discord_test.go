@@ -0,0 +1,52 @@
+package interaction
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+func TestHandleDiscord(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	am := newTestAlertmanager()
+	h := newTestHandler(t, am, Config{Discord: &DiscordConfig{PublicKey: publicKey}})
+
+	request := func(key ed25519.PrivateKey, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/discord", bytes.NewReader([]byte(body)))
+		r.Header.Set("X-Signature-Timestamp", "1727784000")
+		r.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(key, []byte("1727784000"+body))))
+		return r
+	}
+
+	t.Run("rejects invalid signatures", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request(otherKey, `{"type":1}`))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("responds to pings", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request(privateKey, `{"type":1}`))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"type":1}`, w.Body.String())
+	})
+
+	t.Run("silences the alerts and updates the message", func(t *testing.T) {
+		action := receivers.Action{Kind: receivers.ActionSilence, GroupID: "01234567", Fingerprints: []string{"aaaa", "bbbb"}}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request(privateKey, `{"type":3,"data":{"custom_id":"`+action.Encode()+`"},"member":{"user":{"id":"1","username":"user"}},"message":{"content":"[FIRING:2]"}}`))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, am.silences, 2)
+		require.JSONEq(t, `{"type":7,"data":{"content":"[FIRING:2]\n\nSilenced 2 alert(s) until 01 Oct 24 13:00 UTC by user","components":[]}}`, w.Body.String())
+	})
+}
@@ -0,0 +1,126 @@
+package interaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/alerting/receivers/googlechat"
+)
+
+// TokenVerifier verifies the bearer token of a callback, such as the JWT that Google Chat and the Bot Framework
+// sign for the app. It returns an error if the token is invalid.
+type TokenVerifier func(ctx context.Context, token string) error
+
+// GoogleChatConfig configures the callbacks of a Google Chat app.
+type GoogleChatConfig struct {
+	// VerifyToken verifies the bearer token of the events, https://developers.google.com/workspace/chat/verify-requests-from-chat.
+	VerifyToken TokenVerifier
+}
+
+// googleChatEvent is a CARD_CLICKED event of a Google Chat app.
+type googleChatEvent struct {
+	Type   string `json:"type"`
+	Action struct {
+		ActionMethodName string `json:"actionMethodName"`
+		Parameters       []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"parameters"`
+	} `json:"action"`
+	User struct {
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	Message struct {
+		Cards []googleChatCard `json:"cards"`
+	} `json:"message"`
+}
+
+type googleChatCard struct {
+	Header   json.RawMessage `json:"header,omitempty"`
+	Sections []struct {
+		Widgets []map[string]json.RawMessage `json:"widgets"`
+	} `json:"sections"`
+}
+
+func (h *Handler) handleGoogleChat(w http.ResponseWriter, r *http.Request) {
+	if err := h.cfg.GoogleChat.VerifyToken(r.Context(), bearerToken(r)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, PlatformGoogleChat, fmt.Errorf("%w: %w", ErrUnauthorized, err))
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformGoogleChat, err)
+		return
+	}
+	var event googleChatEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformGoogleChat, fmt.Errorf("failed to parse event: %w", err))
+		return
+	}
+	if event.Type != "CARD_CLICKED" {
+		h.writeJSON(w, http.StatusOK, struct{}{})
+		return
+	}
+	var value string
+	for _, p := range event.Action.Parameters {
+		if p.Key == googlechat.ActionParameterKey {
+			value = p.Value
+		}
+	}
+	action, err := decodeAction(value, event.Action.ActionMethodName)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformGoogleChat, err)
+		return
+	}
+
+	status := h.execute(r.Context(), callback{platform: PlatformGoogleChat, user: event.User.DisplayName, action: action})
+
+	// Update the original message: the buttons of the actions are replaced with the status.
+	cards := event.Message.Cards
+	for i := range cards {
+		for j := range cards[i].Sections {
+			widgets := cards[i].Sections[j].Widgets[:0]
+			for _, widget := range cards[i].Sections[j].Widgets {
+				if isActionsWidget(widget) {
+					text, _ := json.Marshal(map[string]interface{}{"text": status})
+					widget = map[string]json.RawMessage{"textParagraph": text}
+				}
+				widgets = append(widgets, widget)
+			}
+			cards[i].Sections[j].Widgets = widgets
+		}
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"actionResponse": map[string]string{"type": "UPDATE_MESSAGE"},
+		"cards":          cards,
+	})
+}
+
+// isActionsWidget returns true if the widget has the buttons of the interactive actions.
+func isActionsWidget(widget map[string]json.RawMessage) bool {
+	var buttons []struct {
+		TextButton struct {
+			OnClick struct {
+				Action json.RawMessage `json:"action"`
+			} `json:"onClick"`
+		} `json:"textButton"`
+	}
+	if err := json.Unmarshal(widget["buttons"], &buttons); err != nil {
+		return false
+	}
+	for _, b := range buttons {
+		if b.TextButton.OnClick.Action != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the bearer token of the Authorization header of the request.
+func bearerToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
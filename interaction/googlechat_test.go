@@ -0,0 +1,68 @@
+package interaction
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+// verifyTestToken accepts the token "valid".
+func verifyTestToken(_ context.Context, token string) error {
+	if token != "valid" {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+func TestHandleGoogleChat(t *testing.T) {
+	am := newTestAlertmanager()
+	h := newTestHandler(t, am, Config{GoogleChat: &GoogleChatConfig{VerifyToken: verifyTestToken}})
+
+	action := receivers.Action{Kind: receivers.ActionSilence, GroupID: "01234567", Fingerprints: []string{"bbbb"}}
+	event := `{
+		"type": "CARD_CLICKED",
+		"action": {"actionMethodName": "silence", "parameters": [{"key": "action", "value": "` + action.Encode() + `"}]},
+		"user": {"displayName": "User"},
+		"message": {"cards": [{"header": {"title": "[FIRING:1]"}, "sections": [{"widgets": [
+			{"textParagraph": {"text": "message"}},
+			{"buttons": [{"textButton": {"text": "OPEN IN GRAFANA", "onClick": {"openLink": {"url": "http://localhost"}}}}]},
+			{"buttons": [{"textButton": {"text": "SILENCE", "onClick": {"action": {"actionMethodName": "silence"}}}}]}
+		]}]}]}
+	}`
+
+	request := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/googlechat", strings.NewReader(event))
+		r.Header.Set("Authorization", "Bearer "+token)
+		return r
+	}
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("invalid"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, am.silences)
+	})
+
+	t.Run("silences the alerts and updates the message", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("valid"))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, am.silences, 1)
+		require.Equal(t, "User", *am.silences[0].CreatedBy)
+		require.JSONEq(t, `{
+			"actionResponse": {"type": "UPDATE_MESSAGE"},
+			"cards": [{"header": {"title": "[FIRING:1]"}, "sections": [{"widgets": [
+				{"textParagraph": {"text": "message"}},
+				{"buttons": [{"textButton": {"text": "OPEN IN GRAFANA", "onClick": {"openLink": {"url": "http://localhost"}}}}]},
+				{"textParagraph": {"text": "Silenced 1 alert(s) until 01 Oct 24 13:00 UTC by User"}}
+			]}]}]
+		}`, w.Body.String())
+	})
+}
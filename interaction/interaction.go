@@ -0,0 +1,318 @@
+// Package interaction handles the callbacks of the interactive actions of the notifications, such as the buttons
+// to silence and acknowledge alerts in Slack, Telegram, Discord, Google Chat and Microsoft Teams.
+// It verifies the callbacks of each platform, executes the action, and updates the original message.
+package interaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+)
+
+// maxBodySize limits the size of the callbacks.
+const maxBodySize = 1 << 20
+
+// DefaultSilenceDuration is the duration of the silences created by the silence action.
+const DefaultSilenceDuration = time.Hour
+
+// Platforms of the callbacks.
+const (
+	PlatformSlack      = "slack"
+	PlatformTelegram   = "telegram"
+	PlatformDiscord    = "discord"
+	PlatformGoogleChat = "googlechat"
+	PlatformTeams      = "teams"
+)
+
+var (
+	// ErrUnauthorized is returned when the signature or the token of a callback is invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrAcknowledgeNotSupported is returned by the acknowledge action when there is no Acknowledger.
+	ErrAcknowledgeNotSupported = errors.New("acknowledging alerts is not supported")
+)
+
+// Alertmanager is the subset of the methods of notify.GrafanaAlertmanager that are used by the actions.
+type Alertmanager interface {
+	GetAlerts(active, silenced, inhibited bool, filter []string, receivers string) (notify.GettableAlerts, error)
+	CreateSilence(ps *notify.PostableSilence) (string, error)
+}
+
+var _ Alertmanager = (*notify.GrafanaAlertmanager)(nil)
+
+// Acknowledgement is an acknowledgement of the alerts of a notification by a user.
+type Acknowledgement struct {
+	// GroupID identifies the aggregation group of the notification, see receivers.Action.
+	GroupID      string
+	Fingerprints []string
+	User         string
+	Platform     string
+}
+
+// Acknowledger records acknowledgements. Acknowledgements are not part of the Alertmanager, so they are only
+// supported if the caller provides an Acknowledger.
+type Acknowledger interface {
+	Acknowledge(ctx context.Context, ack Acknowledgement) error
+}
+
+// Config configures the Handler. The callbacks of a platform are only served if its configuration is set.
+type Config struct {
+	// SilenceDuration is the duration of the silences created by the silence action. Defaults to DefaultSilenceDuration.
+	SilenceDuration time.Duration
+	// Acknowledger records the acknowledgements. If nil, the acknowledge action fails.
+	Acknowledger Acknowledger
+	// Client is used to update the original messages. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	Slack      *SlackConfig
+	Telegram   *TelegramConfig
+	Discord    *DiscordConfig
+	GoogleChat *GoogleChatConfig
+	Teams      *TeamsConfig
+}
+
+// Handler serves the callbacks of the platforms at /slack, /telegram, /discord, /googlechat and /teams.
+// It is meant to be mounted under a prefix with http.StripPrefix.
+type Handler struct {
+	am     Alertmanager
+	cfg    Config
+	logger log.Logger
+	mux    *http.ServeMux
+	now    func() time.Time
+}
+
+// NewHandler returns a Handler that executes the actions on the Alertmanager.
+// It returns an error if the configuration of a platform cannot verify its callbacks.
+func NewHandler(am Alertmanager, cfg Config, logger log.Logger) (*Handler, error) {
+	if cfg.SilenceDuration <= 0 {
+		cfg.SilenceDuration = DefaultSilenceDuration
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	h := &Handler{
+		am:     am,
+		cfg:    cfg,
+		logger: logger,
+		mux:    http.NewServeMux(),
+		now:    time.Now,
+	}
+
+	if cfg.Slack != nil {
+		if cfg.Slack.SigningSecret == "" {
+			return nil, errors.New("slack: signing secret is required")
+		}
+		h.mux.HandleFunc("POST /"+PlatformSlack, h.handleSlack)
+	}
+	if cfg.Telegram != nil {
+		if cfg.Telegram.SecretToken == "" {
+			return nil, errors.New("telegram: secret token is required")
+		}
+		h.mux.HandleFunc("POST /"+PlatformTelegram, h.handleTelegram)
+	}
+	if cfg.Discord != nil {
+		if len(cfg.Discord.PublicKey) == 0 {
+			return nil, errors.New("discord: public key is required")
+		}
+		h.mux.HandleFunc("POST /"+PlatformDiscord, h.handleDiscord)
+	}
+	if cfg.GoogleChat != nil {
+		if cfg.GoogleChat.VerifyToken == nil {
+			return nil, errors.New("googlechat: token verification is required")
+		}
+		h.mux.HandleFunc("POST /"+PlatformGoogleChat, h.handleGoogleChat)
+	}
+	if cfg.Teams != nil {
+		if cfg.Teams.VerifyToken == nil {
+			return nil, errors.New("teams: token verification is required")
+		}
+		h.mux.HandleFunc("POST /"+PlatformTeams, h.handleTeams)
+	}
+
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// callback is a click on the button of an action.
+type callback struct {
+	platform string
+	user     string
+	action   receivers.Action
+}
+
+// execute executes the action of the callback, and returns the status to show in the original message.
+// Failures are also reported in the status, since the platforms expect a successful response.
+func (h *Handler) execute(ctx context.Context, cb callback) string {
+	logger := log.With(h.logger, "platform", cb.platform, "action", cb.action.Kind, "group", cb.action.GroupID, "user", cb.user)
+	var (
+		status string
+		err    error
+	)
+	switch cb.action.Kind {
+	case receivers.ActionSilence:
+		status, err = h.silence(cb)
+	case receivers.ActionAcknowledge:
+		status, err = h.acknowledge(ctx, cb)
+	default:
+		err = fmt.Errorf("%w: unknown kind %q", receivers.ErrInvalidAction, cb.action.Kind)
+	}
+	if err != nil {
+		level.Warn(logger).Log("msg", "Failed to execute action", "err", err)
+		return fmt.Sprintf("Failed to %s alerts: %s", strings.ToLower(cb.action.Label()), err)
+	}
+	level.Info(logger).Log("msg", "Executed action", "status", status)
+	return status
+}
+
+// silence silences the alerts of the action that are still in the Alertmanager. Each alert is silenced
+// with the equality matchers of all its labels, so that only the alerts of the notification are silenced.
+func (h *Handler) silence(cb callback) (string, error) {
+	alerts, err := h.am.GetAlerts(true, true, true, nil, "")
+	if err != nil {
+		return "", err
+	}
+	byFingerprint := make(map[string]*amv2.GettableAlert, len(alerts))
+	for _, a := range alerts {
+		if a.Fingerprint != nil {
+			byFingerprint[*a.Fingerprint] = a
+		}
+	}
+
+	now := h.now()
+	endsAt := now.Add(h.cfg.SilenceDuration)
+	silenced := 0
+	for _, fp := range cb.action.Fingerprints {
+		a, ok := byFingerprint[fp]
+		if !ok {
+			continue
+		}
+		_, err := h.am.CreateSilence(&notify.PostableSilence{Silence: amv2.Silence{
+			Comment:   ptr(fmt.Sprintf("Silenced from %s", cb.platform)),
+			CreatedBy: ptr(cb.user),
+			StartsAt:  ptr(strfmt.DateTime(now)),
+			EndsAt:    ptr(strfmt.DateTime(endsAt)),
+			Matchers:  equalMatchers(a.Labels),
+		}})
+		if err != nil {
+			return "", err
+		}
+		silenced++
+	}
+	if silenced == 0 {
+		return "The alerts are no longer firing", nil
+	}
+	return fmt.Sprintf("Silenced %d alert(s) until %s by %s", silenced, endsAt.UTC().Format(time.RFC822), cb.user), nil
+}
+
+func (h *Handler) acknowledge(ctx context.Context, cb callback) (string, error) {
+	if h.cfg.Acknowledger == nil {
+		return "", ErrAcknowledgeNotSupported
+	}
+	err := h.cfg.Acknowledger.Acknowledge(ctx, Acknowledgement{
+		GroupID:      cb.action.GroupID,
+		Fingerprints: cb.action.Fingerprints,
+		User:         cb.user,
+		Platform:     cb.platform,
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Acknowledged by %s", cb.user), nil
+}
+
+// equalMatchers returns the equality matchers of the labels, sorted by name.
+func equalMatchers(labels amv2.LabelSet) amv2.Matchers {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	matchers := make(amv2.Matchers, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, &amv2.Matcher{
+			Name:    ptr(name),
+			Value:   ptr(labels[name]),
+			IsEqual: ptr(true),
+			IsRegex: ptr(false),
+		})
+	}
+	return matchers
+}
+
+// decodeAction decodes the action of a callback, and checks that it has the expected kind if any.
+func decodeAction(s string, kind string) (receivers.Action, error) {
+	a, err := receivers.DecodeAction(s)
+	if err != nil {
+		return receivers.Action{}, err
+	}
+	if kind != "" && kind != string(a.Kind) {
+		return receivers.Action{}, fmt.Errorf("%w: kind %q does not match %q", receivers.ErrInvalidAction, a.Kind, kind)
+	}
+	return a, nil
+}
+
+// readBody reads the body of the callback, which is needed as is to verify its signature.
+func readBody(r *http.Request) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+}
+
+// writeJSON writes the value as the JSON body of the response.
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		level.Error(h.logger).Log("msg", "failed to write response", "err", err)
+	}
+}
+
+// writeError writes the error of a callback that could not be verified or parsed.
+func (h *Handler) writeError(w http.ResponseWriter, status int, platform string, err error) {
+	level.Warn(h.logger).Log("msg", "Rejected callback", "platform", platform, "status", status, "err", err)
+	http.Error(w, err.Error(), status)
+}
+
+// postJSON sends the value as JSON to the URL, to update the original message.
+func (h *Handler) postJSON(ctx context.Context, url string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,133 @@
+package interaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+)
+
+type fakeAlertmanager struct {
+	alerts   notify.GettableAlerts
+	silences []*notify.PostableSilence
+	err      error
+}
+
+func (f *fakeAlertmanager) GetAlerts(bool, bool, bool, []string, string) (notify.GettableAlerts, error) {
+	return f.alerts, f.err
+}
+
+func (f *fakeAlertmanager) CreateSilence(ps *notify.PostableSilence) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.silences = append(f.silences, ps)
+	return "silence-id", nil
+}
+
+type fakeAcknowledger struct {
+	acks []Acknowledgement
+}
+
+func (f *fakeAcknowledger) Acknowledge(_ context.Context, ack Acknowledgement) error {
+	f.acks = append(f.acks, ack)
+	return nil
+}
+
+var testNow = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestAlertmanager returns an Alertmanager with the alerts a (fingerprint aaaa) and b (fingerprint bbbb).
+func newTestAlertmanager() *fakeAlertmanager {
+	return &fakeAlertmanager{alerts: notify.GettableAlerts{
+		{Fingerprint: ptr("aaaa"), Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "a", "severity": "critical"}}},
+		{Fingerprint: ptr("bbbb"), Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "b"}}},
+	}}
+}
+
+func newTestHandler(t *testing.T, am Alertmanager, cfg Config) *Handler {
+	t.Helper()
+	h, err := NewHandler(am, cfg, log.NewNopLogger())
+	require.NoError(t, err)
+	h.now = func() time.Time { return testNow }
+	return h
+}
+
+func TestNewHandler(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"slack":      {Slack: &SlackConfig{}},
+		"telegram":   {Telegram: &TelegramConfig{}},
+		"discord":    {Discord: &DiscordConfig{}},
+		"googlechat": {GoogleChat: &GoogleChatConfig{}},
+		"teams":      {Teams: &TeamsConfig{}},
+	} {
+		t.Run(name+" requires verification", func(t *testing.T) {
+			_, err := NewHandler(&fakeAlertmanager{}, cfg, log.NewNopLogger())
+			require.ErrorContains(t, err, name)
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	action := func(kind receivers.ActionKind, fingerprints ...string) receivers.Action {
+		return receivers.Action{Kind: kind, GroupID: "01234567", Fingerprints: fingerprints}
+	}
+
+	t.Run("silence creates a silence per firing alert", func(t *testing.T) {
+		am := newTestAlertmanager()
+		h := newTestHandler(t, am, Config{SilenceDuration: 2 * time.Hour})
+
+		status := h.execute(context.Background(), callback{platform: PlatformSlack, user: "user", action: action(receivers.ActionSilence, "aaaa", "cccc")})
+		require.Equal(t, "Silenced 1 alert(s) until 01 Oct 24 14:00 UTC by user", status)
+		require.Len(t, am.silences, 1)
+		s := am.silences[0]
+		require.Equal(t, "user", *s.CreatedBy)
+		require.Equal(t, "Silenced from slack", *s.Comment)
+		require.Equal(t, testNow, time.Time(*s.StartsAt))
+		require.Equal(t, testNow.Add(2*time.Hour), time.Time(*s.EndsAt))
+		require.Equal(t, equalMatchers(amv2.LabelSet{"alertname": "a", "severity": "critical"}), s.Matchers)
+		require.Equal(t, "alertname", *s.Matchers[0].Name)
+		require.True(t, *s.Matchers[0].IsEqual)
+		require.False(t, *s.Matchers[0].IsRegex)
+	})
+
+	t.Run("silence does nothing when the alerts are gone", func(t *testing.T) {
+		am := newTestAlertmanager()
+		h := newTestHandler(t, am, Config{})
+
+		status := h.execute(context.Background(), callback{platform: PlatformSlack, user: "user", action: action(receivers.ActionSilence, "cccc")})
+		require.Equal(t, "The alerts are no longer firing", status)
+		require.Empty(t, am.silences)
+	})
+
+	t.Run("silence reports errors", func(t *testing.T) {
+		am := newTestAlertmanager()
+		am.err = errors.New("boom")
+		h := newTestHandler(t, am, Config{})
+
+		status := h.execute(context.Background(), callback{platform: PlatformSlack, user: "user", action: action(receivers.ActionSilence, "aaaa")})
+		require.Equal(t, "Failed to silence alerts: boom", status)
+	})
+
+	t.Run("acknowledge uses the Acknowledger", func(t *testing.T) {
+		ack := &fakeAcknowledger{}
+		h := newTestHandler(t, newTestAlertmanager(), Config{Acknowledger: ack})
+
+		status := h.execute(context.Background(), callback{platform: PlatformTeams, user: "user", action: action(receivers.ActionAcknowledge, "aaaa", "bbbb")})
+		require.Equal(t, "Acknowledged by user", status)
+		require.Equal(t, []Acknowledgement{{GroupID: "01234567", Fingerprints: []string{"aaaa", "bbbb"}, User: "user", Platform: PlatformTeams}}, ack.acks)
+	})
+
+	t.Run("acknowledge fails without Acknowledger", func(t *testing.T) {
+		h := newTestHandler(t, newTestAlertmanager(), Config{})
+
+		status := h.execute(context.Background(), callback{platform: PlatformTeams, user: "user", action: action(receivers.ActionAcknowledge, "aaaa")})
+		require.Equal(t, "Failed to acknowledge alerts: "+ErrAcknowledgeNotSupported.Error(), status)
+	})
+}
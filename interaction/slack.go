@@ -0,0 +1,136 @@
+package interaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/alerting/receivers/slack"
+)
+
+// slackMaxRequestAge is the maximum age of the timestamp of a callback, to prevent replay attacks.
+const slackMaxRequestAge = 5 * time.Minute
+
+// SlackConfig configures the callbacks of the interactive components of a Slack app.
+type SlackConfig struct {
+	// SigningSecret is the signing secret of the Slack app, used to verify the callbacks.
+	SigningSecret string
+}
+
+// slackInteraction is the payload of a block_actions callback, https://api.slack.com/reference/interaction-payloads/block-actions.
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		BlockID  string `json:"block_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+	Message     struct {
+		Text        string                   `json:"text"`
+		Blocks      []map[string]interface{} `json:"blocks"`
+		Attachments []json.RawMessage        `json:"attachments"`
+	} `json:"message"`
+}
+
+func (h *Handler) handleSlack(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformSlack, err)
+		return
+	}
+	if err := verifySlackSignature(h.cfg.Slack.SigningSecret, r.Header, body, h.now()); err != nil {
+		h.writeError(w, http.StatusUnauthorized, PlatformSlack, err)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformSlack, err)
+		return
+	}
+	var payload slackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformSlack, fmt.Errorf("failed to parse payload: %w", err))
+		return
+	}
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 || payload.Actions[0].BlockID != slack.ActionsBlockID {
+		// Not one of our buttons.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	action, err := decodeAction(payload.Actions[0].Value, payload.Actions[0].ActionID)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformSlack, err)
+		return
+	}
+
+	user := payload.User.Username
+	if user == "" {
+		user = payload.User.ID
+	}
+	status := h.execute(r.Context(), callback{platform: PlatformSlack, user: user, action: action})
+
+	// Replace the buttons of the original message with the status.
+	blocks := make([]map[string]interface{}, 0, len(payload.Message.Blocks))
+	for _, b := range payload.Message.Blocks {
+		if b["block_id"] == slack.ActionsBlockID {
+			b = map[string]interface{}{
+				"type":     "context",
+				"elements": []map[string]interface{}{{"type": "mrkdwn", "text": status}},
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	if payload.ResponseURL != "" {
+		err := h.postJSON(r.Context(), payload.ResponseURL, map[string]interface{}{
+			"replace_original": true,
+			"text":             payload.Message.Text,
+			"blocks":           blocks,
+			"attachments":      payload.Message.Attachments,
+		})
+		if err != nil {
+			level.Warn(h.logger).Log("msg", "Failed to update the message", "platform", PlatformSlack, "err", err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifySlackSignature verifies the signature of a callback, https://api.slack.com/authentication/verifying-requests-from-slack.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sig := header.Get("X-Slack-Signature")
+	if ts == "" || sig == "" {
+		return fmt.Errorf("%w: missing signature", ErrUnauthorized)
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrUnauthorized)
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > slackMaxRequestAge || age < -slackMaxRequestAge {
+		return fmt.Errorf("%w: request is too old", ErrUnauthorized)
+	}
+	if !hmac.Equal([]byte(sig), []byte(slackSignature(secret, ts, body))) {
+		return fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+	return nil
+}
+
+func slackSignature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + ts + ":"))
+	_, _ = mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package interaction
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/receivers/slack"
+)
+
+func TestHandleSlack(t *testing.T) {
+	var updates []map[string]interface{}
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var update map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &update))
+		updates = append(updates, update)
+	}))
+	t.Cleanup(responseServer.Close)
+
+	am := newTestAlertmanager()
+	h := newTestHandler(t, am, Config{Slack: &SlackConfig{SigningSecret: "secret"}})
+
+	action := receivers.Action{Kind: receivers.ActionSilence, GroupID: "01234567", Fingerprints: []string{"aaaa"}}
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U1", "username": "user"},
+		"actions":      []map[string]string{{"action_id": string(action.Kind), "block_id": slack.ActionsBlockID, "value": action.Encode()}},
+		"response_url": responseServer.URL,
+		"message": map[string]interface{}{
+			"text":        "",
+			"attachments": []map[string]string{{"title": "[FIRING:1]"}},
+			"blocks":      []map[string]interface{}{{"type": "actions", "block_id": slack.ActionsBlockID}},
+		},
+	})
+	require.NoError(t, err)
+	body := url.Values{"payload": {string(payload)}}.Encode()
+
+	request := func(ts time.Time, secret string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		timestamp := strconv.FormatInt(ts.Unix(), 10)
+		r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		r.Header.Set("X-Slack-Signature", slackSignature(secret, timestamp, []byte(body)))
+		return r
+	}
+
+	t.Run("rejects invalid signatures", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request(testNow, "wrong"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, request(testNow.Add(-10*time.Minute), "secret"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, am.silences)
+	})
+
+	t.Run("silences the alerts and updates the message", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request(testNow, "secret"))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, am.silences, 1)
+		require.Equal(t, "user", *am.silences[0].CreatedBy)
+
+		require.Len(t, updates, 1)
+		require.Equal(t, true, updates[0]["replace_original"])
+		require.Len(t, updates[0]["attachments"], 1)
+		require.Equal(t, []interface{}{map[string]interface{}{
+			"type":     "context",
+			"elements": []interface{}{map[string]interface{}{"type": "mrkdwn", "text": "Silenced 1 alert(s) until 01 Oct 24 13:00 UTC by user"}},
+		}}, updates[0]["blocks"])
+	})
+}
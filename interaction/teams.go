@@ -0,0 +1,70 @@
+package interaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/alerting/receivers/teams"
+)
+
+// TeamsConfig configures the messaging endpoint of the Microsoft Teams bot.
+type TeamsConfig struct {
+	// VerifyToken verifies the bearer token of the activities, https://learn.microsoft.com/en-us/azure/bot-service/rest-api/bot-framework-rest-connector-authentication.
+	VerifyToken TokenVerifier
+}
+
+// teamsActivity is an invoke activity of an Action.Execute, https://learn.microsoft.com/en-us/adaptive-cards/authoring-cards/universal-action-model.
+type teamsActivity struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	From struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"from"`
+	Value struct {
+		Action struct {
+			Type string            `json:"type"`
+			Verb string            `json:"verb"`
+			Data map[string]string `json:"data"`
+		} `json:"action"`
+	} `json:"value"`
+}
+
+func (h *Handler) handleTeams(w http.ResponseWriter, r *http.Request) {
+	if err := h.cfg.Teams.VerifyToken(r.Context(), bearerToken(r)); err != nil {
+		h.writeError(w, http.StatusUnauthorized, PlatformTeams, fmt.Errorf("%w: %w", ErrUnauthorized, err))
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformTeams, err)
+		return
+	}
+	var activity teamsActivity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformTeams, fmt.Errorf("failed to parse activity: %w", err))
+		return
+	}
+	if activity.Type != "invoke" || activity.Name != "adaptiveCard/action" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	action, err := decodeAction(activity.Value.Action.Data[teams.ActionDataKey], activity.Value.Action.Verb)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformTeams, err)
+		return
+	}
+
+	status := h.execute(r.Context(), callback{platform: PlatformTeams, user: activity.From.Name, action: action})
+
+	// The card in the response replaces the original card. The activity does not contain the original card,
+	// so it is replaced with the status.
+	card := teams.NewAdaptiveCard()
+	card.AppendItem(teams.AdaptiveCardTextBlockItem{Text: status, Wrap: true})
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"statusCode": http.StatusOK,
+		"type":       "application/vnd.microsoft.card.adaptive",
+		"value":      &card,
+	})
+}
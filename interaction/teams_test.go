@@ -0,0 +1,62 @@
+package interaction
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+func TestHandleTeams(t *testing.T) {
+	ack := &fakeAcknowledger{}
+	h := newTestHandler(t, newTestAlertmanager(), Config{Acknowledger: ack, Teams: &TeamsConfig{VerifyToken: verifyTestToken}})
+
+	action := receivers.Action{Kind: receivers.ActionAcknowledge, GroupID: "01234567", Fingerprints: []string{"aaaa"}}
+	activity := `{
+		"type": "invoke",
+		"name": "adaptiveCard/action",
+		"from": {"id": "29:1", "name": "User"},
+		"value": {"action": {"type": "Action.Execute", "verb": "ack", "data": {"action": "` + action.Encode() + `"}}}
+	}`
+
+	request := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/teams", strings.NewReader(activity))
+		r.Header.Set("Authorization", "Bearer "+token)
+		return r
+	}
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("invalid"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, ack.acks)
+	})
+
+	t.Run("acknowledges the alerts and replaces the card", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("valid"))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []Acknowledgement{{GroupID: "01234567", Fingerprints: []string{"aaaa"}, User: "User", Platform: PlatformTeams}}, ack.acks)
+
+		var res struct {
+			StatusCode int    `json:"statusCode"`
+			Type       string `json:"type"`
+			Value      struct {
+				Type string `json:"type"`
+				Body []struct {
+					Text string `json:"text"`
+				} `json:"body"`
+			} `json:"value"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "application/vnd.microsoft.card.adaptive", res.Type)
+		require.Equal(t, "AdaptiveCard", res.Value.Type)
+		require.Equal(t, "Acknowledged by User", res.Value.Body[0].Text)
+	})
+}
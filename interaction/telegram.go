@@ -0,0 +1,99 @@
+package interaction
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/alerting/receivers/telegram"
+)
+
+// TelegramConfig configures the webhook of a Telegram bot.
+type TelegramConfig struct {
+	// SecretToken is the secret_token of the webhook of the bot, used to verify the updates.
+	SecretToken string
+	// BotToken is the token of the bot. If set, the buttons are removed from the original message.
+	BotToken string
+	// APIURL is the format of the URLs of the methods of the Bot API. Defaults to telegram.APIURL.
+	APIURL string
+}
+
+// telegramUpdate is an update of the webhook, https://core.telegram.org/bots/api#update.
+// Only callback queries are handled.
+type telegramUpdate struct {
+	CallbackQuery *struct {
+		ID   string `json:"id"`
+		From struct {
+			ID        int64  `json:"id"`
+			Username  string `json:"username"`
+			FirstName string `json:"first_name"`
+		} `json:"from"`
+		Message *struct {
+			MessageID int64 `json:"message_id"`
+			Chat      struct {
+				ID int64 `json:"id"`
+			} `json:"chat"`
+		} `json:"message"`
+		Data string `json:"data"`
+	} `json:"callback_query"`
+}
+
+func (h *Handler) handleTelegram(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.Telegram.SecretToken)) != 1 {
+		h.writeError(w, http.StatusUnauthorized, PlatformTelegram, fmt.Errorf("%w: invalid secret token", ErrUnauthorized))
+		return
+	}
+	body, err := readBody(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformTelegram, err)
+		return
+	}
+	var update telegramUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformTelegram, fmt.Errorf("failed to parse update: %w", err))
+		return
+	}
+	query := update.CallbackQuery
+	if query == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	action, err := decodeAction(query.Data, "")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, PlatformTelegram, err)
+		return
+	}
+
+	user := query.From.Username
+	if user == "" {
+		user = query.From.FirstName
+	}
+	status := h.execute(r.Context(), callback{platform: PlatformTelegram, user: user, action: action})
+
+	if h.cfg.Telegram.BotToken != "" && query.Message != nil {
+		apiURL := h.cfg.Telegram.APIURL
+		if apiURL == "" {
+			apiURL = telegram.APIURL
+		}
+		err := h.postJSON(r.Context(), fmt.Sprintf(apiURL, h.cfg.Telegram.BotToken, "editMessageReplyMarkup"), map[string]interface{}{
+			"chat_id":      query.Message.Chat.ID,
+			"message_id":   query.Message.MessageID,
+			"reply_markup": map[string]interface{}{"inline_keyboard": [][]interface{}{}},
+		})
+		if err != nil {
+			level.Warn(h.logger).Log("msg", "Failed to update the message", "platform", PlatformTelegram, "err", err)
+		}
+	}
+
+	// Answer the callback query in the response of the webhook, which shows the status to the user.
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"method":            "answerCallbackQuery",
+		"callback_query_id": query.ID,
+		"text":              status,
+		"show_alert":        true,
+	})
+}
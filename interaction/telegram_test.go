@@ -0,0 +1,69 @@
+package interaction
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+func TestHandleTelegram(t *testing.T) {
+	var edits []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		edits = append(edits, r.URL.Path+" "+string(b))
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(api.Close)
+
+	ack := &fakeAcknowledger{}
+	h := newTestHandler(t, newTestAlertmanager(), Config{
+		Acknowledger: ack,
+		Telegram:     &TelegramConfig{SecretToken: "secret", BotToken: "bot-token", APIURL: api.URL + "/bot%s/%s"},
+	})
+
+	action := receivers.Action{Kind: receivers.ActionAcknowledge, GroupID: "01234567", Fingerprints: []string{"aaaa", "bbbb"}}
+	update := `{"update_id":1,"callback_query":{"id":"query-1","from":{"id":42,"username":"user"},"message":{"message_id":7,"chat":{"id":-100}},"data":"` + action.Encode() + `"}}`
+
+	request := func(token, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+		r.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		return r
+	}
+
+	t.Run("rejects invalid secret tokens", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("wrong", update))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, ack.acks)
+	})
+
+	t.Run("ignores other updates", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("secret", `{"update_id":2,"message":{"text":"hello"}}`))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, ack.acks)
+	})
+
+	t.Run("acknowledges the alerts and removes the buttons", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request("secret", update))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []Acknowledgement{{GroupID: "01234567", Fingerprints: []string{"aaaa", "bbbb"}, User: "user", Platform: PlatformTelegram}}, ack.acks)
+
+		var answer map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &answer))
+		require.Equal(t, "answerCallbackQuery", answer["method"])
+		require.Equal(t, "query-1", answer["callback_query_id"])
+		require.Equal(t, "Acknowledged by user", answer["text"])
+
+		require.Equal(t, []string{`/botbot-token/editMessageReplyMarkup {"chat_id":-100,"message_id":7,"reply_markup":{"inline_keyboard":[]}}`}, edits)
+	})
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"log/slog"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// gokitLogger is a Logger that writes to a go-kit log.Logger.
+type gokitLogger struct {
+	logger log.Logger
+}
+
+// FromGoKit returns a Logger that writes to the go-kit logger, with the levels of the go-kit level package.
+func FromGoKit(logger log.Logger) Logger {
+	return &gokitLogger{logger: logger}
+}
+
+// New implements the Logger interface.
+func (l *gokitLogger) New(ctx ...interface{}) Logger {
+	return &gokitLogger{logger: log.With(l.logger, ctx...)}
+}
+
+// Log implements the Logger interface.
+func (l *gokitLogger) Log(keyvals ...interface{}) error {
+	return l.logger.Log(keyvals...)
+}
+
+// Debug implements the Logger interface.
+func (l *gokitLogger) Debug(msg string, ctx ...interface{}) {
+	_ = level.Debug(l.logger).Log(append([]interface{}{"msg", msg}, ctx...)...)
+}
+
+// Info implements the Logger interface.
+func (l *gokitLogger) Info(msg string, ctx ...interface{}) {
+	_ = level.Info(l.logger).Log(append([]interface{}{"msg", msg}, ctx...)...)
+}
+
+// Warn implements the Logger interface.
+func (l *gokitLogger) Warn(msg string, ctx ...interface{}) {
+	_ = level.Warn(l.logger).Log(append([]interface{}{"msg", msg}, ctx...)...)
+}
+
+// Error implements the Logger interface.
+func (l *gokitLogger) Error(msg string, ctx ...interface{}) {
+	_ = level.Error(l.logger).Log(append([]interface{}{"msg", msg}, ctx...)...)
+}
+
+// ToGoKit returns a go-kit log.Logger that writes to the Logger. The level and the message are taken
+// from the "level" and "msg" keys, so that level.Debug(logger).Log("msg", "...") writes a debug message.
+// Records without level are written at info level.
+func ToGoKit(logger Logger) log.Logger {
+	if l, ok := logger.(*gokitLogger); ok {
+		return l.logger
+	}
+	return log.LoggerFunc(func(keyvals ...interface{}) error {
+		lvl, msg, ctx := splitKeyvals(keyvals)
+		switch {
+		case lvl >= slog.LevelError:
+			logger.Error(msg, ctx...)
+		case lvl >= slog.LevelWarn:
+			logger.Warn(msg, ctx...)
+		case lvl >= slog.LevelInfo:
+			logger.Info(msg, ctx...)
+		default:
+			logger.Debug(msg, ctx...)
+		}
+		return nil
+	})
+}
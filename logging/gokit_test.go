@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoKit(t *testing.T) {
+	t.Run("to go-kit", func(t *testing.T) {
+		l := NewTestLogger()
+		gl := log.With(ToGoKit(l), "org", 1)
+
+		require.NoError(t, level.Debug(gl).Log("msg", "debug", "key", "value"))
+		require.NoError(t, level.Warn(gl).Log("msg", "warn"))
+		require.NoError(t, gl.Log("msg", "no level"))
+
+		require.Equal(t, []TestRecord{
+			{Level: slog.LevelDebug, Msg: "debug", Ctx: []interface{}{"org", 1, "key", "value"}},
+			{Level: slog.LevelWarn, Msg: "warn", Ctx: []interface{}{"org", 1}},
+			{Level: slog.LevelInfo, Msg: "no level", Ctx: []interface{}{"org", 1}},
+		}, l.Records())
+	})
+
+	t.Run("from go-kit", func(t *testing.T) {
+		l := NewTestLogger()
+		fl := FromGoKit(ToGoKit(l)).New("org", 1)
+
+		fl.Debug("debug", "key", "value")
+		fl.Error("error")
+
+		require.Equal(t, []TestRecord{
+			{Level: slog.LevelDebug, Msg: "debug", Ctx: []interface{}{"org", 1, "key", "value"}},
+			{Level: slog.LevelError, Msg: "error", Ctx: []interface{}{"org", 1}},
+		}, l.Records())
+	})
+
+	t.Run("round trip returns the go-kit logger", func(t *testing.T) {
+		gl := log.NewNopLogger()
+		require.Equal(t, gl, ToGoKit(FromGoKit(gl)))
+	})
+}
+
+func TestTestLogger(t *testing.T) {
+	l := NewTestLogger()
+	l.Factory()("ngalert.notifier.slack", "org", 1).New("uid", "abc").Info("sent", "status", 200)
+
+	records := l.Records()
+	require.Len(t, records, 1)
+	require.Equal(t, []string{"sent"}, l.Messages(slog.LevelInfo))
+	require.Empty(t, l.Messages(slog.LevelError))
+	v, ok := records[0].Value("logger")
+	require.True(t, ok)
+	require.Equal(t, "ngalert.notifier.slack", v)
+	v, ok = records[0].Value("status")
+	require.True(t, ok)
+	require.Equal(t, 200, v)
+	_, ok = records[0].Value("missing")
+	require.False(t, ok)
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Levels are the minimum levels of the loggers, by logger name. The level of a logger is the level of the
+// longest dot-separated prefix of its name that has one, so that the level of ngalert.notifier applies to
+// ngalert.notifier.slack unless ngalert.notifier.slack has its own level.
+type Levels struct {
+	// Default is the level of the loggers without a level of their own.
+	Default slog.Level
+	// Components are the levels by logger name.
+	Components map[string]slog.Level
+}
+
+// For returns the minimum level of the logger with the given name.
+func (l Levels) For(name string) slog.Level {
+	for n := name; n != ""; {
+		if lvl, ok := l.Components[n]; ok {
+			return lvl
+		}
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			break
+		}
+		n = n[:i]
+	}
+	return l.Default
+}
+
+// ParseLevels parses levels in the format "info,ngalert.notifier.slack=debug": an optional default level,
+// info if omitted, followed by levels by logger name. Levels are as accepted by slog.Level, such as debug or WARN.
+func ParseLevels(s string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			name, value = "", name
+		}
+		var lvl slog.Level
+		if err := lvl.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+			return Levels{}, fmt.Errorf("invalid level %q: %w", part, err)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			levels.Default = lvl
+			continue
+		}
+		if levels.Components == nil {
+			levels.Components = map[string]slog.Level{}
+		}
+		levels.Components[name] = lvl
+	}
+	return levels, nil
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// slogLogger is a Logger that writes to a slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewSlogLogger returns a Logger that writes to the slog.Logger, at all levels enabled by its handler.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger, level: slog.LevelDebug}
+}
+
+// NewSlogLoggerFactory returns a LoggerFactory of Loggers that write to the slog.Logger, with the name of the logger
+// in the attribute "logger". The Loggers only write the records at or above the level of their name in levels.
+// Note that the handler of the slog.Logger must enable the lowest of the levels for them to be written.
+func NewSlogLoggerFactory(logger *slog.Logger, levels Levels) LoggerFactory {
+	return func(loggerName string, ctx ...interface{}) Logger {
+		return &slogLogger{
+			logger: logger.With("logger", loggerName).With(ctx...),
+			level:  levels.For(loggerName),
+		}
+	}
+}
+
+// New implements the Logger interface.
+func (l *slogLogger) New(ctx ...interface{}) Logger {
+	return &slogLogger{logger: l.logger.With(ctx...), level: l.level}
+}
+
+// Log implements the Logger interface. The level and the message are taken from the "level" and "msg" keys,
+// as written by go-kit loggers. Records without level are written at info level.
+func (l *slogLogger) Log(keyvals ...interface{}) error {
+	lvl, msg, ctx := splitKeyvals(keyvals)
+	l.log(lvl, msg, ctx)
+	return nil
+}
+
+// Debug implements the Logger interface.
+func (l *slogLogger) Debug(msg string, ctx ...interface{}) {
+	l.log(slog.LevelDebug, msg, ctx)
+}
+
+// Info implements the Logger interface.
+func (l *slogLogger) Info(msg string, ctx ...interface{}) {
+	l.log(slog.LevelInfo, msg, ctx)
+}
+
+// Warn implements the Logger interface.
+func (l *slogLogger) Warn(msg string, ctx ...interface{}) {
+	l.log(slog.LevelWarn, msg, ctx)
+}
+
+// Error implements the Logger interface.
+func (l *slogLogger) Error(msg string, ctx ...interface{}) {
+	l.log(slog.LevelError, msg, ctx)
+}
+
+func (l *slogLogger) log(lvl slog.Level, msg string, ctx []interface{}) {
+	if lvl < l.level {
+		return
+	}
+	l.logger.Log(context.Background(), lvl, msg, ctx...)
+}
+
+// splitKeyvals returns the level and the message of go-kit style key/value pairs, and the other pairs.
+// The level is info if the pairs do not have one.
+func splitKeyvals(keyvals []interface{}) (slog.Level, string, []interface{}) {
+	lvl := slog.LevelInfo
+	var msg string
+	ctx := make([]interface{}, 0, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 >= len(keyvals) {
+			ctx = append(ctx, keyvals[i])
+			break
+		}
+		k, v := keyvals[i], keyvals[i+1]
+		switch fmt.Sprint(k) {
+		case "level":
+			var parsed slog.Level
+			if err := parsed.UnmarshalText([]byte(fmt.Sprint(v))); err == nil {
+				lvl = parsed
+				continue
+			}
+		case "msg":
+			if msg == "" {
+				msg = fmt.Sprint(v)
+				continue
+			}
+		}
+		ctx = append(ctx, k, v)
+	}
+	return lvl, msg, ctx
+}
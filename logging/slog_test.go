@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var res []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var r map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		delete(r, "time")
+		res = append(res, r)
+	}
+	return res
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	l.New("org", 1).Debug("debug message", "key", "value")
+	l.Warn("warn message")
+	require.NoError(t, l.Log("level", "error", "msg", "log message", "key", "value"))
+	require.NoError(t, l.Log("key", "value"))
+
+	require.Equal(t, []map[string]interface{}{
+		{"level": "DEBUG", "msg": "debug message", "org": float64(1), "key": "value"},
+		{"level": "WARN", "msg": "warn message"},
+		{"level": "ERROR", "msg": "log message", "key": "value"},
+		{"level": "INFO", "msg": "", "key": "value"},
+	}, decodeRecords(t, buf))
+}
+
+func TestSlogLoggerFactory(t *testing.T) {
+	buf := &bytes.Buffer{}
+	levels, err := ParseLevels("warn, ngalert.notifier=info ,ngalert.notifier.slack=debug")
+	require.NoError(t, err)
+	factory := NewSlogLoggerFactory(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), levels)
+
+	for _, name := range []string{"ngalert", "ngalert.notifier", "ngalert.notifier.slack", "ngalert.notifier.email"} {
+		l := factory(name, "org", 1)
+		l.Debug("debug")
+		l.Info("info")
+	}
+
+	require.Equal(t, []map[string]interface{}{
+		{"level": "INFO", "msg": "info", "logger": "ngalert.notifier", "org": float64(1)},
+		{"level": "DEBUG", "msg": "debug", "logger": "ngalert.notifier.slack", "org": float64(1)},
+		{"level": "INFO", "msg": "info", "logger": "ngalert.notifier.slack", "org": float64(1)},
+		{"level": "INFO", "msg": "info", "logger": "ngalert.notifier.email", "org": float64(1)},
+	}, decodeRecords(t, buf))
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("")
+	require.NoError(t, err)
+	require.Equal(t, Levels{Default: slog.LevelInfo}, levels)
+
+	levels, err = ParseLevels("DEBUG,a.b=error")
+	require.NoError(t, err)
+	require.Equal(t, Levels{Default: slog.LevelDebug, Components: map[string]slog.Level{"a.b": slog.LevelError}}, levels)
+	require.Equal(t, slog.LevelError, levels.For("a.b.c"))
+	require.Equal(t, slog.LevelDebug, levels.For("a.bc"))
+	require.Equal(t, slog.LevelDebug, levels.For("a"))
+
+	_, err = ParseLevels("a=verbose")
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// TestRecord is a record captured by a TestLogger.
+type TestRecord struct {
+	Level slog.Level
+	Msg   string
+	// Ctx are the key/value pairs of the record, including those of the logger.
+	Ctx []interface{}
+}
+
+// Value returns the value of the first pair of the record with the key.
+func (r TestRecord) Value(key string) (interface{}, bool) {
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		if fmt.Sprint(r.Ctx[i]) == key {
+			return r.Ctx[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// TestLogger is a Logger that captures its records, and those of the loggers created with New, for assertions in tests.
+type TestLogger struct {
+	ctx []interface{}
+
+	mtx     *sync.Mutex
+	records *[]TestRecord
+}
+
+// NewTestLogger returns a TestLogger without records.
+func NewTestLogger() *TestLogger {
+	return &TestLogger{mtx: &sync.Mutex{}, records: &[]TestRecord{}}
+}
+
+// Factory returns a LoggerFactory of loggers that capture their records in the TestLogger,
+// with the name of the logger in the key "logger".
+func (l *TestLogger) Factory() LoggerFactory {
+	return func(loggerName string, ctx ...interface{}) Logger {
+		return l.New(append([]interface{}{"logger", loggerName}, ctx...)...)
+	}
+}
+
+// Records returns the captured records, in order.
+func (l *TestLogger) Records() []TestRecord {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return append([]TestRecord(nil), *l.records...)
+}
+
+// Messages returns the messages of the captured records at the level, in order.
+func (l *TestLogger) Messages(lvl slog.Level) []string {
+	var res []string
+	for _, r := range l.Records() {
+		if r.Level == lvl {
+			res = append(res, r.Msg)
+		}
+	}
+	return res
+}
+
+// New implements the Logger interface.
+func (l *TestLogger) New(ctx ...interface{}) Logger {
+	return &TestLogger{
+		ctx:     append(append([]interface{}(nil), l.ctx...), ctx...),
+		mtx:     l.mtx,
+		records: l.records,
+	}
+}
+
+// Log implements the Logger interface.
+func (l *TestLogger) Log(keyvals ...interface{}) error {
+	lvl, msg, ctx := splitKeyvals(keyvals)
+	l.log(lvl, msg, ctx)
+	return nil
+}
+
+// Debug implements the Logger interface.
+func (l *TestLogger) Debug(msg string, ctx ...interface{}) {
+	l.log(slog.LevelDebug, msg, ctx)
+}
+
+// Info implements the Logger interface.
+func (l *TestLogger) Info(msg string, ctx ...interface{}) {
+	l.log(slog.LevelInfo, msg, ctx)
+}
+
+// Warn implements the Logger interface.
+func (l *TestLogger) Warn(msg string, ctx ...interface{}) {
+	l.log(slog.LevelWarn, msg, ctx)
+}
+
+// Error implements the Logger interface.
+func (l *TestLogger) Error(msg string, ctx ...interface{}) {
+	l.log(slog.LevelError, msg, ctx)
+}
+
+func (l *TestLogger) log(lvl slog.Level, msg string, ctx []interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	*l.records = append(*l.records, TestRecord{
+		Level: lvl,
+		Msg:   msg,
+		Ctx:   append(append([]interface{}(nil), l.ctx...), ctx...),
+	})
+}
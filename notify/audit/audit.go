@@ -0,0 +1,83 @@
+// Package audit records every notification attempt of the Alertmanager, for compliance.
+// The records identify the receiver, the integration, the alerts and the outcome of the attempt,
+// but never the settings of the integration, so that secrets never appear in the records.
+package audit
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record is the audit record of a notification attempt.
+type Record struct {
+	Timestamp        time.Time `json:"timestamp"`
+	TenantID         int64     `json:"tenantId"`
+	Receiver         string    `json:"receiver"`
+	IntegrationType  string    `json:"integrationType"`
+	IntegrationUID   string    `json:"integrationUid,omitempty"`
+	IntegrationIndex int       `json:"integrationIndex"`
+	GroupKey         string    `json:"groupKey"`
+	Alerts           []Alert   `json:"alerts"`
+	Outcome          string    `json:"outcome"`
+	// Error is the error of the attempt, if it failed, with the secrets of the integration and the paths and queries
+	// of the URLs redacted.
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Alert is an alert of a notification.
+type Alert struct {
+	Fingerprint string            `json:"fingerprint"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// Sink receives the audit records of the notification attempts.
+type Sink interface {
+	// Write records the notification attempt. It is called after each attempt, and must not block.
+	Write(ctx context.Context, r Record) error
+}
+
+var urlRegexp = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"']+`)
+
+// RedactError returns the message of the error with the secrets, and the credentials, paths and queries of the URLs
+// redacted, as webhook URLs are often secrets, or include secrets such as tokens. The secrets are the decrypted secure
+// values of the integration, which the error can echo, for example in the response of the service.
+func RedactError(err error, secrets ...string) string {
+	if err == nil {
+		return ""
+	}
+	msg := err.Error()
+	// Longer secrets are redacted first, so that secrets that contain other secrets are fully redacted.
+	sorted := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret != "" {
+			sorted = append(sorted, secret)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		msg = strings.ReplaceAll(msg, secret, "[REDACTED]")
+		// Secrets are often part of URLs, where they can be escaped.
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			msg = strings.ReplaceAll(msg, escaped, "[REDACTED]")
+		}
+	}
+	return urlRegexp.ReplaceAllStringFunc(msg, func(s string) string {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return "[REDACTED]"
+		}
+		redacted := u.Scheme + "://" + u.Host
+		if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			redacted += "/[REDACTED]"
+		}
+		return redacted
+	})
+}
@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedSuffixFormat is the format of the suffix of the rotated files, which sorts chronologically.
+const rotatedSuffixFormat = "20060102T150405.000000000Z"
+
+// FileSinkOptions configures a FileSink.
+type FileSinkOptions struct {
+	// Path is the path of the file the records are appended to.
+	Path string
+	// MaxSizeBytes is the size of the file above which it is rotated. If 0, the file is never rotated.
+	MaxSizeBytes int64
+	// MaxBackups is the number of rotated files to keep, the oldest are deleted. If 0, all rotated files are kept.
+	MaxBackups int
+}
+
+// FileSink writes the records to a file as JSON lines. The file is only ever appended to. When it grows
+// above the maximum size, it is rotated: it is renamed with the time of the rotation as suffix and a new file is created.
+type FileSink struct {
+	opts FileSinkOptions
+	now  func() time.Time
+
+	mtx  sync.Mutex
+	file *os.File
+	size int64
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink returns a FileSink that appends to the file at opts.Path, creating it if it does not exist.
+func NewFileSink(opts FileSinkOptions) (*FileSink, error) {
+	if opts.Path == "" {
+		return nil, errors.New("path of the audit file must be set")
+	}
+	if opts.MaxSizeBytes < 0 || opts.MaxBackups < 0 {
+		return nil, errors.New("maximum size and backups of the audit file must not be negative")
+	}
+	s := &FileSink{opts: opts, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write implements the Sink interface.
+func (s *FileSink) Write(_ context.Context, r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	b = append(b, '\n')
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.file == nil {
+		return errors.New("audit file is closed")
+	}
+	if s.opts.MaxSizeBytes > 0 && s.size > 0 && s.size+int64(len(b)) > s.opts.MaxSizeBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat audit file: %w", err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit file: %w", err)
+	}
+	s.file = nil
+	rotated := s.opts.Path + "." + s.now().UTC().Format(rotatedSuffixFormat)
+	if err := os.Rename(s.opts.Path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit file: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.deleteOldBackups()
+}
+
+func (s *FileSink) deleteOldBackups() error {
+	if s.opts.MaxBackups == 0 {
+		return nil
+	}
+	backups, err := s.Backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to delete rotated audit file: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Backups returns the paths of the rotated files, from the oldest to the newest.
+func (s *FileSink) Backups() ([]string, error) {
+	dir, base := filepath.Split(s.opts.Path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated audit files: %w", err)
+	}
+	var backups []string
+	for _, e := range entries {
+		suffix, ok := strings.CutPrefix(e.Name(), base+".")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(rotatedSuffixFormat, suffix); err == nil {
+			backups = append(backups, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/alerting/logging"
+)
+
+// LoggerSink writes the records to a logging.Logger, at info level.
+type LoggerSink struct {
+	logger logging.Logger
+}
+
+var _ Sink = (*LoggerSink)(nil)
+
+// NewLoggerSink returns a LoggerSink that writes to the logger.
+func NewLoggerSink(logger logging.Logger) *LoggerSink {
+	return &LoggerSink{logger: logger}
+}
+
+// Write implements the Sink interface.
+func (s *LoggerSink) Write(_ context.Context, r Record) error {
+	fingerprints := make([]string, 0, len(r.Alerts))
+	for _, a := range r.Alerts {
+		fingerprints = append(fingerprints, a.Fingerprint)
+	}
+	ctx := []interface{}{
+		"tenant", r.TenantID,
+		"receiver", r.Receiver,
+		"integration", r.IntegrationType,
+		"integrationUid", r.IntegrationUID,
+		"integrationIndex", r.IntegrationIndex,
+		"groupKey", r.GroupKey,
+		"alerts", strings.Join(fingerprints, ","),
+		"outcome", r.Outcome,
+		"duration", r.Duration,
+	}
+	if r.Error != "" {
+		ctx = append(ctx, "error", r.Error)
+	}
+	s.logger.Info("Notification attempt", ctx...)
+	return nil
+}
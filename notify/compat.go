@@ -16,6 +16,9 @@ func PostableAPIReceiverToAPIReceiver(r *definition.PostableApiReceiver) *APIRec
 			Name:                  p.Name,
 			Type:                  p.Type,
 			DisableResolveMessage: p.DisableResolveMessage,
+			SplitByAlert:          p.SplitByAlert,
+			MuteTimeIntervals:     p.MuteTimeIntervals,
+			ActiveTimeIntervals:   p.ActiveTimeIntervals,
 			Settings:              json.RawMessage(p.Settings),
 			SecureSettings:        p.SecureSettings,
 		})
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/templates"
+)
+
+// PostableConfiguration is the Configuration of a PostableApiAlertingConfig. It implements the optional
+// GrafanaRoutingTreeConfiguration and EnrichmentConfiguration interfaces, so that the repeat backoffs of its routes
+// and its enrichment tables are applied.
+type PostableConfiguration struct {
+	cfg       *definition.PostableApiAlertingConfig
+	templates []templates.TemplateDefinition
+	limits    DispatcherLimits
+	build     func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error)
+	raw       []byte
+	hash      [16]byte
+}
+
+// NewPostableConfiguration returns the Configuration of cfg, whose receivers are built with build.
+// The templates and the limits of the dispatcher are optional.
+func NewPostableConfiguration(cfg *definition.PostableApiAlertingConfig, tmpls []templates.TemplateDefinition, limits DispatcherLimits, build func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error)) (*PostableConfiguration, error) {
+	if cfg.Route == nil {
+		return nil, fmt.Errorf("the configuration has no routing tree")
+	}
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the configuration: %w", err)
+	}
+	return &PostableConfiguration{
+		cfg:       cfg,
+		templates: tmpls,
+		limits:    limits,
+		build:     build,
+		raw:       raw,
+		hash:      md5.Sum(raw),
+	}, nil
+}
+
+func (c *PostableConfiguration) DispatcherLimits() DispatcherLimits { return c.limits }
+
+func (c *PostableConfiguration) InhibitRules() []InhibitRule { return c.cfg.InhibitRules }
+
+func (c *PostableConfiguration) TimeIntervals() []TimeInterval { return c.cfg.TimeIntervals }
+
+// Deprecated: MuteTimeIntervals are deprecated in Alertmanager and will be removed in future versions.
+func (c *PostableConfiguration) MuteTimeIntervals() []MuteTimeInterval {
+	return c.cfg.MuteTimeIntervals
+}
+
+func (c *PostableConfiguration) Receivers() []*APIReceiver {
+	receivers := make([]*APIReceiver, 0, len(c.cfg.Receivers))
+	for _, r := range c.cfg.Receivers {
+		receivers = append(receivers, PostableAPIReceiverToAPIReceiver(r))
+	}
+	return receivers
+}
+
+func (c *PostableConfiguration) BuildReceiverIntegrationsFunc() func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error) {
+	return c.build
+}
+
+func (c *PostableConfiguration) RoutingTree() *Route { return c.cfg.Route.AsAMRoute() }
+
+func (c *PostableConfiguration) GrafanaRoutingTree() *definition.Route { return c.cfg.Route }
+
+func (c *PostableConfiguration) Templates() []templates.TemplateDefinition { return c.templates }
+
+func (c *PostableConfiguration) Enrichments() []EnrichmentTable { return c.cfg.Enrichments }
+
+func (c *PostableConfiguration) Hash() [16]byte { return c.hash }
+
+func (c *PostableConfiguration) Raw() []byte { return c.raw }
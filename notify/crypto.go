@@ -1 +1,123 @@
 package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/envelope"
+)
+
+// EnvelopeDecryptFn returns a GetDecryptedValueFn that decrypts the secure settings encrypted by EncryptSecureSettings.
+// It returns the fallback if the key is not present. If the value cannot be decrypted, it returns an empty string and
+// records a DecryptionError, which fails BuildReceiverConfiguration, so that the integration is not built with the
+// encrypted value as if it was the secret.
+func EnvelopeDecryptFn(e *envelope.Envelope, logger log.Logger) GetDecryptedValueFn {
+	return func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
+		v, ok := sjd[key]
+		if !ok {
+			return fallback
+		}
+		decrypted, err := e.Decrypt(ctx, v)
+		if err != nil {
+			if !RecordDecryptionError(ctx, key, err) {
+				level.Error(logger).Log("msg", "Failed to decrypt secure setting", "key", key, "err", err)
+			}
+			return ""
+		}
+		return string(decrypted)
+	}
+}
+
+// EnvelopeDecryptPayloadFn returns a function that decrypts the payloads encrypted by EncryptSecureSettings, to be used
+// with definition.PostableGrafanaReceiver.DecryptSecureSettings.
+func EnvelopeDecryptPayloadFn(ctx context.Context, e *envelope.Envelope) func(payload []byte) ([]byte, error) {
+	return func(payload []byte) ([]byte, error) {
+		return e.Decrypt(ctx, payload)
+	}
+}
+
+// DecryptionError is returned by BuildReceiverConfiguration when a secure setting of an integration cannot be decrypted.
+type DecryptionError struct {
+	Key string
+	Err error
+}
+
+func (e DecryptionError) Error() string {
+	return fmt.Sprintf("secureSettings.%s: failed to decrypt secure setting: %s", e.Key, e.Err)
+}
+
+func (e DecryptionError) Unwrap() error {
+	return e.Err
+}
+
+// decryptionErrorsKey is the key of the first error of the decryption of the secure settings of an integration.
+type decryptionErrorsKey struct{}
+
+// RecordDecryptionError records that the secure setting with the key cannot be decrypted, so that
+// BuildReceiverConfiguration fails with a DecryptionError. It is meant to be called by the GetDecryptedValueFn,
+// with its context. It returns false if the context is not the one of BuildReceiverConfiguration.
+func RecordDecryptionError(ctx context.Context, key string, err error) bool {
+	errp, ok := ctx.Value(decryptionErrorsKey{}).(*error)
+	if !ok {
+		return false
+	}
+	if *errp == nil {
+		*errp = DecryptionError{Key: key, Err: err}
+	}
+	return true
+}
+
+// EncryptSecureSettings encrypts the values of the secure settings, and encodes them in base64 so that they can be
+// used as the SecureSettings of a GrafanaIntegrationConfig or a definition.PostableGrafanaReceiver.
+func EncryptSecureSettings(ctx context.Context, e *envelope.Envelope, settings map[string]string) (map[string]string, error) {
+	res := make(map[string]string, len(settings))
+	for k, v := range settings {
+		encrypted, err := e.Encrypt(ctx, []byte(v))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt secure setting %s: %w", k, err)
+		}
+		res[k] = base64.StdEncoding.EncodeToString(encrypted)
+	}
+	return res, nil
+}
+
+// RotateSecureSettings re-encrypts the secure settings of the Grafana receivers of the configuration that are not
+// encrypted with the active key encryption key. It returns the number of secure settings that were re-encrypted.
+// The configuration is only modified if all of them are re-encrypted.
+func RotateSecureSettings(ctx context.Context, e *envelope.Envelope, cfg *definition.PostableApiAlertingConfig) (int, error) {
+	rotated := map[*definition.PostableGrafanaReceiver]map[string]string{}
+	count := 0
+	for _, r := range cfg.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			if len(gr.SecureSettings) == 0 {
+				continue
+			}
+			settings := make(map[string]string, len(gr.SecureSettings))
+			for k, v := range gr.SecureSettings {
+				decoded, err := base64.StdEncoding.DecodeString(v)
+				if err != nil {
+					return 0, fmt.Errorf("failed to decode secure setting %s of integration %s of receiver %s: %w", k, gr.UID, r.Name, err)
+				}
+				encrypted, ok, err := e.Rotate(ctx, decoded)
+				if err != nil {
+					return 0, fmt.Errorf("failed to rotate secure setting %s of integration %s of receiver %s: %w", k, gr.UID, r.Name, err)
+				}
+				if ok {
+					v = base64.StdEncoding.EncodeToString(encrypted)
+					count++
+				}
+				settings[k] = v
+			}
+			rotated[gr] = settings
+		}
+	}
+	for gr, settings := range rotated {
+		gr.SecureSettings = settings
+	}
+	return count, nil
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/envelope"
+	"github.com/grafana/alerting/receivers/discord"
+)
+
+var testKeys = map[string]string{}
+
+func writeTestKeys(t *testing.T, path string, ids ...string) {
+	t.Helper()
+	content := ""
+	for _, id := range ids {
+		if _, ok := testKeys[id]; !ok {
+			key := make([]byte, 32)
+			_, err := rand.Read(key)
+			require.NoError(t, err)
+			testKeys[id] = base64.StdEncoding.EncodeToString(key)
+		}
+		content += id + ":" + testKeys[id] + "\n"
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	later := time.Now().Add(time.Duration(len(ids)) * time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys")
+	writeTestKeys(t, path, "1")
+	kms, err := envelope.NewFileKMS(path)
+	require.NoError(t, err)
+	e := envelope.New(kms)
+
+	secureSettings, err := EncryptSecureSettings(ctx, e, map[string]string{"url": "http://localhost/secret"})
+	require.NoError(t, err)
+
+	t.Run("secure settings are decrypted when building receivers", func(t *testing.T) {
+		parsed, err := BuildReceiverConfiguration(ctx, &APIReceiver{
+			ConfigReceiver: ConfigReceiver{Name: "receiver"},
+			GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+				UID:            "discord",
+				Type:           "discord",
+				Settings:       json.RawMessage(`{}`),
+				SecureSettings: secureSettings,
+			}}},
+		}, EnvelopeDecryptFn(e, log.NewNopLogger()))
+		require.NoError(t, err)
+		require.Len(t, ConfigsOf[discord.Config](parsed), 1)
+		require.Equal(t, "http://localhost/secret", ConfigsOf[discord.Config](parsed)[0].Settings.WebhookURL)
+
+		decrypt := EnvelopeDecryptFn(e, log.NewNopLogger())
+		require.Equal(t, "fallback", decrypt(ctx, map[string][]byte{}, "url", "fallback"))
+		require.Empty(t, decrypt(ctx, map[string][]byte{"url": []byte("plain")}, "url", "fallback"))
+	})
+
+	t.Run("secure settings that cannot be decrypted fail the build", func(t *testing.T) {
+		_, err := BuildReceiverConfiguration(ctx, &APIReceiver{
+			ConfigReceiver: ConfigReceiver{Name: "receiver"},
+			GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+				UID:            "discord",
+				Type:           "discord",
+				Settings:       json.RawMessage(`{"url": "http://localhost/fallback"}`),
+				SecureSettings: map[string]string{"url": base64.StdEncoding.EncodeToString([]byte("not an envelope"))},
+			}}},
+		}, EnvelopeDecryptFn(e, log.NewNopLogger()))
+		var decryptErr DecryptionError
+		require.ErrorAs(t, err, &decryptErr)
+		require.Equal(t, "url", decryptErr.Key)
+		require.ErrorContains(t, err, "secureSettings.url: failed to decrypt secure setting")
+	})
+
+	t.Run("secure settings of Grafana receivers are decrypted", func(t *testing.T) {
+		gr := &definition.PostableGrafanaReceiver{UID: "discord", Type: "discord", SecureSettings: secureSettings}
+		decrypted, err := gr.DecryptSecureSettings(EnvelopeDecryptPayloadFn(ctx, e))
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"url": "http://localhost/secret"}, decrypted)
+
+		gr.SecureSettings = map[string]string{"url": base64.StdEncoding.EncodeToString([]byte("not an envelope"))}
+		_, err = gr.DecryptSecureSettings(EnvelopeDecryptPayloadFn(ctx, e))
+		require.ErrorContains(t, err, "failed to decrypt value for key 'url'")
+	})
+
+	t.Run("secure settings are re-encrypted with the active key", func(t *testing.T) {
+		cfg := &definition.PostableApiAlertingConfig{
+			Receivers: []*definition.PostableApiReceiver{{
+				Receiver: config.Receiver{Name: "receiver"},
+				PostableGrafanaReceivers: definition.PostableGrafanaReceivers{GrafanaManagedReceivers: []*definition.PostableGrafanaReceiver{
+					{UID: "discord", Type: "discord", SecureSettings: secureSettings},
+					{UID: "email", Type: "email"},
+				}},
+			}},
+		}
+		count, err := RotateSecureSettings(ctx, e, cfg)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		require.Equal(t, secureSettings, cfg.Receivers[0].GrafanaManagedReceivers[0].SecureSettings)
+
+		writeTestKeys(t, path, "1", "2")
+		count, err = RotateSecureSettings(ctx, e, cfg)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Nil(t, cfg.Receivers[0].GrafanaManagedReceivers[1].SecureSettings)
+
+		rotated := cfg.Receivers[0].GrafanaManagedReceivers[0]
+		decrypted, err := rotated.DecryptSecureSettings(func(payload []byte) ([]byte, error) {
+			keyID, err := envelope.KeyID(payload)
+			require.NoError(t, err)
+			require.Equal(t, "2", keyID)
+			return e.Decrypt(ctx, payload)
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"url": "http://localhost/secret"}, decrypted)
+
+		// The configuration is not modified if a secure setting cannot be re-encrypted.
+		rotated.SecureSettings["invalid"] = "not base64"
+		before := rotated.SecureSettings["url"]
+		writeTestKeys(t, path, "1", "2", "3")
+		_, err = RotateSecureSettings(ctx, e, cfg)
+		require.Error(t, err)
+		require.Equal(t, before, rotated.SecureSettings["url"])
+	})
+}
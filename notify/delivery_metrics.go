@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/notify/audit"
+)
+
+// The outcomes of notifications, see GrafanaAlertmanagerMetrics.
+const (
+	NotificationOutcomeSuccess             = "success"
+	NotificationOutcomeRetryableFailure    = "retryable_failure"
+	NotificationOutcomePermanentFailure    = "permanent_failure"
+	NotificationOutcomeSuppressedSilence   = "suppressed_silence"
+	NotificationOutcomeSuppressedInhibited = "suppressed_inhibition"
+	NotificationOutcomeSuppressedMuteTime  = "suppressed_mute_time"
+)
+
+// instrumentedNotifier wraps an integration to observe the duration and the outcome of the notification attempts,
+// and the time from the start of the alerts to their first successful notification. Every attempt is recorded
+// by the audit sink, if any.
+type instrumentedNotifier struct {
+	integration  *notify.Integration
+	uid          string
+	secrets      []string
+	receiverName string
+	nflog        notify.NotificationLog
+	recv         *nflogpb.Receiver
+
+	duration    prometheus.Observer
+	latency     prometheus.Observer
+	payloadSize prometheus.Observer
+	outcomes    *prometheus.CounterVec
+
+	tenantID  int64
+	auditSink audit.Sink
+	logger    log.Logger
+}
+
+// instrumentIntegration returns an integration that notifies the integration and observes the delivery metrics.
+func (am *GrafanaAlertmanager) instrumentIntegration(i *Integration, receiverName string, notificationLog notify.NotificationLog, recv *nflogpb.Receiver) *notify.Integration {
+	integration := i.Integration()
+	labels := prometheus.Labels{"org": am.tenantString(), "type": integration.Name()}
+	n := &instrumentedNotifier{
+		integration:  integration,
+		uid:          i.UID(),
+		secrets:      i.Secrets(),
+		receiverName: receiverName,
+		nflog:        notificationLog,
+		recv:         recv,
+		duration:     am.Metrics.notifyDuration.With(labels),
+		latency:      am.Metrics.alertNotificationLatency.With(labels),
+		payloadSize:  am.Metrics.payloadSize.With(labels),
+		outcomes:     am.Metrics.notifications.MustCurryWith(labels),
+		tenantID:     am.tenantID,
+		auditSink:    am.auditSink,
+		logger:       am.logger,
+	}
+	return notify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiverName)
+}
+
+// Notify implements the notify.Notifier interface.
+func (n *instrumentedNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	start := time.Now()
+	retry, err := n.integration.Notify(withPayloadSizeObserver(ctx, n.payloadSize), alerts...)
+	duration := time.Since(start)
+	n.duration.Observe(duration.Seconds())
+
+	var outcome string
+	switch {
+	case err == nil:
+		outcome = NotificationOutcomeSuccess
+		n.observeLatency(ctx, time.Now(), alerts)
+	case retry:
+		outcome = NotificationOutcomeRetryableFailure
+	default:
+		outcome = NotificationOutcomePermanentFailure
+	}
+	n.outcomes.WithLabelValues(outcome).Inc()
+	n.audit(ctx, start, duration, outcome, err, alerts)
+	return retry, err
+}
+
+// audit writes the record of the attempt to the audit sink, if any.
+func (n *instrumentedNotifier) audit(ctx context.Context, start time.Time, duration time.Duration, outcome string, err error, alerts []*types.Alert) {
+	if n.auditSink == nil {
+		return
+	}
+	gkey, _ := notify.GroupKey(ctx)
+	r := audit.Record{
+		Timestamp:        start,
+		TenantID:         n.tenantID,
+		Receiver:         n.receiverName,
+		IntegrationType:  n.integration.Name(),
+		IntegrationUID:   n.uid,
+		IntegrationIndex: n.integration.Index(),
+		GroupKey:         gkey,
+		Alerts:           make([]audit.Alert, 0, len(alerts)),
+		Outcome:          outcome,
+		Error:            audit.RedactError(err, n.secrets...),
+		Duration:         duration,
+	}
+	for _, a := range alerts {
+		labels := make(map[string]string, len(a.Labels))
+		for k, v := range a.Labels {
+			labels[string(k)] = string(v)
+		}
+		r.Alerts = append(r.Alerts, audit.Alert{
+			Fingerprint: a.Fingerprint().String(),
+			Status:      string(a.Status()),
+			Labels:      labels,
+			StartsAt:    a.StartsAt,
+			EndsAt:      a.EndsAt,
+		})
+	}
+	if err := n.auditSink.Write(ctx, r); err != nil {
+		level.Warn(n.logger).Log("msg", "Failed to write the audit record of the notification", "integration", n.integration.String(), "err", err)
+	}
+}
+
+// SendResolved implements the notify.ResolvedSender interface.
+func (n *instrumentedNotifier) SendResolved() bool {
+	return n.integration.SendResolved()
+}
+
+// observeLatency observes the time from the start of the firing alerts that were not in the previous notification
+// of the group, according to the notification log.
+func (n *instrumentedNotifier) observeLatency(ctx context.Context, now time.Time, alerts []*types.Alert) {
+	gkey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return
+	}
+	notified := map[uint64]struct{}{}
+	entries, err := n.nflog.Query(nflog.QGroupKey(gkey), nflog.QReceiver(n.recv))
+	if err != nil && !errors.Is(err, nflog.ErrNotFound) {
+		return
+	}
+	for _, e := range entries {
+		for _, h := range e.FiringAlerts {
+			notified[h] = struct{}{}
+		}
+	}
+	for _, a := range alerts {
+		if a.Resolved() {
+			continue
+		}
+		if _, ok := notified[hashAlert(a)]; ok {
+			continue
+		}
+		n.latency.Observe(now.Sub(a.StartsAt).Seconds())
+	}
+}
+
+// hashAlert returns the hash of the alert in the notification log.
+func hashAlert(a *types.Alert) uint64 {
+	const sep = '\xff'
+
+	names := make(model.LabelNames, 0, len(a.Labels))
+	for ln := range a.Labels {
+		names = append(names, ln)
+	}
+	sort.Sort(names)
+
+	b := make([]byte, 0, 1024)
+	for _, ln := range names {
+		b = append(b, string(ln)...)
+		b = append(b, sep)
+		b = append(b, string(a.Labels[ln])...)
+		b = append(b, sep)
+	}
+	return xxhash.Sum64(b)
+}
+
+type payloadSizeObserverKey struct{}
+
+func withPayloadSizeObserver(ctx context.Context, o prometheus.Observer) context.Context {
+	return context.WithValue(ctx, payloadSizeObserverKey{}, o)
+}
+
+// observePayloadSize observes the size of a payload sent by the integration notified with the context, if any.
+func observePayloadSize(ctx context.Context, size int) {
+	if o, ok := ctx.Value(payloadSizeObserverKey{}).(prometheus.Observer); ok {
+		o.Observe(float64(size))
+	}
+}
+
+// suppressionStage counts a suppressed notification for each integration of the receiver
+// if the stage suppresses all alerts of the group.
+type suppressionStage struct {
+	stage      notify.Stage
+	suppressed []prometheus.Counter
+}
+
+// newSuppressionStage returns a stage that counts the notifications of the integrations suppressed by the stage with the given outcome.
+func (am *GrafanaAlertmanager) newSuppressionStage(stage notify.Stage, outcome string, integrations ...*Integration) *suppressionStage {
+	suppressed := make([]prometheus.Counter, 0, len(integrations))
+	for _, i := range integrations {
+		suppressed = append(suppressed, am.Metrics.notifications.WithLabelValues(am.tenantString(), i.Name(), outcome))
+	}
+	return &suppressionStage{stage: stage, suppressed: suppressed}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *suppressionStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	ctx, res, err := s.stage.Exec(ctx, l, alerts...)
+	if err == nil && len(alerts) > 0 && len(res) == 0 {
+		for _, c := range s.suppressed {
+			c.Inc()
+		}
+	}
+	return ctx, res, err
+}
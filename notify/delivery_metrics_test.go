@@ -0,0 +1,242 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/notify/audit"
+	"github.com/grafana/alerting/notify/nfstatus"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+type outcomeNotifier struct {
+	fakeNotifier
+	retry bool
+	err   error
+}
+
+func (n *outcomeNotifier) Notify(context.Context, ...*types.Alert) (bool, error) {
+	return n.retry, n.err
+}
+
+func histogramSampleCount(t *testing.T, reg *prometheus.Registry, name string) uint64 {
+	t.Helper()
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	var count uint64
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			count += m.GetHistogram().GetSampleCount()
+		}
+	}
+	return count
+}
+
+func TestDeliveryMetrics(t *testing.T) {
+	am, reg := setupAMTest(t)
+
+	sender := &receivers.NotificationServiceMock{}
+	n := &webhookNotifier{sender: &instrumentedWebhookSender{sender: sender, meta: receivers.Metadata{Type: "webhook"}}}
+	failing := &outcomeNotifier{err: errors.New("failed")}
+	integrations := []*Integration{
+		NewIntegration(n, n, "webhook", 0, "receiver"),
+		NewIntegration(failing, failing, "slack", 1, "receiver"),
+	}
+	stage := am.createReceiverStage("receiver", integrations, func() time.Duration { return 0 }, am.notificationLog, nil, nil)
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "{}:{}")
+	ctx = notify.WithRepeatInterval(ctx, time.Hour)
+	ctx = notify.WithNow(ctx, now)
+
+	_, _, err := stage.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.Error(t, err)
+	require.Equal(t, uint64(2), histogramSampleCount(t, reg, "grafana_alerting_alertmanager_notify_duration_seconds"))
+	require.Equal(t, uint64(2), histogramSampleCount(t, reg, "grafana_alerting_alertmanager_alert_notification_latency_seconds"))
+	require.Equal(t, uint64(1), histogramSampleCount(t, reg, "grafana_alerting_alertmanager_notification_payload_size_bytes"))
+
+	// Only the alerts that were not notified before are observed.
+	alerts = append(alerts, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "c"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}})
+	_, _, err = stage.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.Error(t, err)
+	require.Equal(t, uint64(4), histogramSampleCount(t, reg, "grafana_alerting_alertmanager_notify_duration_seconds"))
+	require.Equal(t, uint64(3), histogramSampleCount(t, reg, "grafana_alerting_alertmanager_alert_notification_latency_seconds"))
+	require.Equal(t, uint64(2), histogramSampleCount(t, reg, "grafana_alerting_alertmanager_notification_payload_size_bytes"))
+
+	// Retryable failures are retried until the context is done.
+	retrying := &outcomeNotifier{retry: true, err: errors.New("failed")}
+	stage = am.createReceiverStage("receiver", []*Integration{NewIntegration(retrying, retrying, "email", 0, "receiver")}, func() time.Duration { return 0 }, am.notificationLog, nil, nil)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, _, err = stage.Exec(timeoutCtx, log.NewNopLogger(), alerts...)
+	require.Error(t, err)
+	require.GreaterOrEqual(t, testutil.ToFloat64(am.Metrics.notifications.WithLabelValues("1", "email", NotificationOutcomeRetryableFailure)), float64(1))
+	am.Metrics.notifications.DeleteLabelValues("1", "email", NotificationOutcomeRetryableFailure)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+# HELP grafana_alerting_alertmanager_notifications_total Number of notifications by integration type and outcome.
+# TYPE grafana_alerting_alertmanager_notifications_total counter
+grafana_alerting_alertmanager_notifications_total{org="1",outcome="permanent_failure",type="slack"} 2
+grafana_alerting_alertmanager_notifications_total{org="1",outcome="success",type="webhook"} 2
+`), "grafana_alerting_alertmanager_notifications_total"))
+}
+
+func TestSuppressionStage(t *testing.T) {
+	am, reg := setupAMTest(t)
+
+	n := &fakeNotifier{}
+	integrations := []*Integration{
+		NewIntegration(n, n, "webhook", 0, "receiver"),
+		NewIntegration(n, n, "slack", 1, "receiver"),
+	}
+	filter := func(keep int) notify.Stage {
+		return notify.StageFunc(func(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+			return ctx, alerts[:keep], nil
+		})
+	}
+	alerts := []*types.Alert{{}, {}}
+
+	// Notifications are suppressed only if all alerts are removed.
+	_, _, err := am.newSuppressionStage(filter(1), NotificationOutcomeSuppressedSilence, integrations...).Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	_, _, err = am.newSuppressionStage(filter(0), NotificationOutcomeSuppressedSilence, integrations...).Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	_, _, err = am.newSuppressionStage(filter(0), NotificationOutcomeSuppressedInhibited, integrations[0]).Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	_, _, err = am.newSuppressionStage(filter(0), NotificationOutcomeSuppressedInhibited, integrations[0]).Exec(context.Background(), log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+# HELP grafana_alerting_alertmanager_notifications_total Number of notifications by integration type and outcome.
+# TYPE grafana_alerting_alertmanager_notifications_total counter
+grafana_alerting_alertmanager_notifications_total{org="1",outcome="suppressed_inhibition",type="webhook"} 1
+grafana_alerting_alertmanager_notifications_total{org="1",outcome="suppressed_silence",type="slack"} 1
+grafana_alerting_alertmanager_notifications_total{org="1",outcome="suppressed_silence",type="webhook"} 1
+`), "grafana_alerting_alertmanager_notifications_total"))
+}
+
+type fakeAuditSink struct {
+	mtx     sync.Mutex
+	records []audit.Record
+}
+
+func (s *fakeAuditSink) Write(_ context.Context, r audit.Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.records = append(s.records, r)
+	return nil
+}
+
+func TestAuditSink(t *testing.T) {
+	am, _ := setupAMTest(t)
+	sink := &fakeAuditSink{}
+	am.auditSink = sink
+
+	ok := &outcomeNotifier{}
+	failing := &outcomeNotifier{err: errors.New(`Post "https://hooks.slack.com/services/secret": connection refused`)}
+	integrations := []*Integration{
+		NewIntegration(ok, ok, "webhook", 0, "receiver", nfstatus.WithUID("webhook-uid")),
+		NewIntegration(failing, failing, "slack", 1, "receiver", nfstatus.WithUID("slack-uid")),
+	}
+	stage := am.createReceiverStage("receiver", integrations, func() time.Duration { return 0 }, am.notificationLog, nil, nil)
+
+	now := time.Now()
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}}
+	ctx := notify.WithGroupKey(context.Background(), "{}:{}")
+	ctx = notify.WithRepeatInterval(ctx, time.Hour)
+	ctx = notify.WithNow(ctx, now)
+
+	_, _, err := stage.Exec(ctx, log.NewNopLogger(), alert)
+	require.Error(t, err)
+
+	require.Len(t, sink.records, 2)
+	sort.Slice(sink.records, func(i, j int) bool { return sink.records[i].IntegrationIndex < sink.records[j].IntegrationIndex })
+	for _, r := range sink.records {
+		require.Equal(t, int64(1), r.TenantID)
+		require.Equal(t, "receiver", r.Receiver)
+		require.Equal(t, "{}:{}", r.GroupKey)
+		require.Equal(t, []audit.Alert{{
+			Fingerprint: alert.Fingerprint().String(),
+			Status:      "firing",
+			Labels:      map[string]string{"alertname": "a"},
+			StartsAt:    alert.StartsAt,
+			EndsAt:      alert.EndsAt,
+		}}, r.Alerts)
+	}
+	require.Equal(t, "webhook", sink.records[0].IntegrationType)
+	require.Equal(t, "webhook-uid", sink.records[0].IntegrationUID)
+	require.Equal(t, NotificationOutcomeSuccess, sink.records[0].Outcome)
+	require.Empty(t, sink.records[0].Error)
+
+	require.Equal(t, "slack", sink.records[1].IntegrationType)
+	require.Equal(t, "slack-uid", sink.records[1].IntegrationUID)
+	require.Equal(t, NotificationOutcomePermanentFailure, sink.records[1].Outcome)
+	require.Equal(t, `Post "https://hooks.slack.com/[REDACTED]": connection refused`, sink.records[1].Error)
+
+	t.Run("secrets echoed by the error are redacted", func(t *testing.T) {
+		sink.records = nil
+		cfg, err := BuildReceiverConfiguration(context.Background(), &APIReceiver{
+			ConfigReceiver: ConfigReceiver{Name: "receiver"},
+			GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+				UID:            "webhook-uid",
+				Type:           "webhook",
+				Settings:       json.RawMessage(`{"url": "http://localhost/hook", "username": "user"}`),
+				SecureSettings: map[string]string{"password": base64.StdEncoding.EncodeToString([]byte("s3cr3t"))},
+			}}},
+		}, NoopDecrypt)
+		require.NoError(t, err)
+		tmpl := templates.ForTests(t)
+		tmpl.ExternalURL, err = url.Parse("http://localhost")
+		require.NoError(t, err)
+		integrations, err := BuildReceiverIntegrations(cfg, tmpl, &images.FakeProvider{},
+			func(string, ...interface{}) logging.Logger { return &logging.FakeLogger{} },
+			func(receivers.Metadata) (receivers.WebhookSender, error) { return echoingWebhookSender{}, nil },
+			func(receivers.Metadata) (receivers.EmailSender, error) { return nil, nil },
+			1, "test",
+		)
+		require.NoError(t, err)
+		require.Len(t, integrations, 1)
+
+		_, err = am.instrumentIntegration(integrations[0], "receiver", am.notificationLog, &nflogpb.Receiver{}).Notify(ctx, alert)
+		require.ErrorContains(t, err, "s3cr3t")
+		require.Len(t, sink.records, 1)
+		require.Equal(t, "webhook-uid", sink.records[0].IntegrationUID)
+		require.Contains(t, sink.records[0].Error, "rejected credentials user:[REDACTED]")
+		require.NotContains(t, sink.records[0].Error, "s3cr3t")
+	})
+}
+
+// echoingWebhookSender fails with an error that echoes the credentials of the webhook.
+type echoingWebhookSender struct{}
+
+func (echoingWebhookSender) SendWebhook(_ context.Context, cmd *receivers.SendWebhookSettings) error {
+	return fmt.Errorf("rejected credentials %s:%s", cmd.User, cmd.Password)
+}
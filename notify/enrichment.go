@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/definition"
+)
+
+type EnrichmentTable = definition.EnrichmentTable
+
+// enricher adds labels and annotations to alerts from a list of lookup tables.
+// Tables are applied in order, so labels added by a table can be used as keys by the tables that follow it.
+type enricher struct {
+	tables []*enrichmentTable
+}
+
+type enrichmentTable struct {
+	name     string
+	override bool
+	keys     []model.LabelName
+	// exact indexes the rows by the values of their keys. It is only used when the table has no regex keys.
+	exact map[string]*enrichmentRow
+	// rows holds the rows in order. It is only used when the table has regex keys.
+	rows []*enrichmentRow
+}
+
+type enrichmentRow struct {
+	values      map[model.LabelName]string
+	regexes     map[model.LabelName]*regexp.Regexp
+	labels      model.LabelSet
+	annotations model.LabelSet
+}
+
+// newEnricher builds an enricher from the provided tables. It returns an error if any of the tables is invalid.
+func newEnricher(tables []EnrichmentTable) (*enricher, error) {
+	e := &enricher{tables: make([]*enrichmentTable, 0, len(tables))}
+	for i := range tables {
+		t, err := newEnrichmentTable(&tables[i])
+		if err != nil {
+			return nil, err
+		}
+		e.tables = append(e.tables, t)
+	}
+	return e, nil
+}
+
+func newEnrichmentTable(cfg *EnrichmentTable) (*enrichmentTable, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	rows, err := cfg.Rows()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &enrichmentTable{
+		name:     cfg.Name,
+		override: cfg.Override,
+		keys:     make([]model.LabelName, 0, len(cfg.Keys)),
+	}
+	for _, k := range cfg.Keys {
+		t.keys = append(t.keys, model.LabelName(k))
+	}
+	if len(cfg.RegexKeys) == 0 {
+		t.exact = make(map[string]*enrichmentRow, len(rows))
+	}
+
+	for _, r := range rows {
+		row := &enrichmentRow{
+			values:      make(map[model.LabelName]string, len(cfg.Keys)),
+			regexes:     make(map[model.LabelName]*regexp.Regexp, len(cfg.RegexKeys)),
+			labels:      model.LabelSet{},
+			annotations: model.LabelSet{},
+		}
+		for column, value := range r {
+			if cfg.IsRegexKey(column) {
+				re, err := regexp.Compile("^(?:" + value + ")$")
+				if err != nil {
+					return nil, fmt.Errorf("invalid regex %q in enrichment table %q: %w", value, cfg.Name, err)
+				}
+				row.regexes[model.LabelName(column)] = re
+				continue
+			}
+			if isEnrichmentKey(cfg, column) {
+				row.values[model.LabelName(column)] = value
+				continue
+			}
+			if value == "" {
+				continue
+			}
+			if name, ok := strings.CutPrefix(column, definition.EnrichmentAnnotationPrefix); ok {
+				row.annotations[model.LabelName(name)] = model.LabelValue(value)
+				continue
+			}
+			row.labels[model.LabelName(column)] = model.LabelValue(value)
+		}
+
+		if t.exact != nil {
+			key := t.lookupKey(row.values)
+			// The first row wins in case of duplicates, the same as for tables with regex keys.
+			if _, ok := t.exact[key]; !ok {
+				t.exact[key] = row
+			}
+			continue
+		}
+		t.rows = append(t.rows, row)
+	}
+
+	return t, nil
+}
+
+func isEnrichmentKey(cfg *EnrichmentTable, column string) bool {
+	for _, k := range cfg.Keys {
+		if k == column {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupKey builds the index key of a row, or of an alert, from the values of the table keys.
+func (t *enrichmentTable) lookupKey(values map[model.LabelName]string) string {
+	var sb strings.Builder
+	for _, k := range t.keys {
+		sb.WriteString(values[k])
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
+
+// lookup returns the first row that matches the labels, or nil if there is none.
+func (t *enrichmentTable) lookup(ls model.LabelSet) *enrichmentRow {
+	if t.exact != nil {
+		values := make(map[model.LabelName]string, len(t.keys))
+		for _, k := range t.keys {
+			v, ok := ls[k]
+			if !ok {
+				return nil
+			}
+			values[k] = string(v)
+		}
+		return t.exact[t.lookupKey(values)]
+	}
+
+	for _, row := range t.rows {
+		if row.matches(ls) {
+			return row
+		}
+	}
+	return nil
+}
+
+func (r *enrichmentRow) matches(ls model.LabelSet) bool {
+	for k, v := range r.values {
+		if lv, ok := ls[k]; !ok || string(lv) != v {
+			return false
+		}
+	}
+	for k, re := range r.regexes {
+		lv, ok := ls[k]
+		if !ok || !re.MatchString(string(lv)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Enrich adds labels and annotations to the alerts in place.
+func (e *enricher) Enrich(alerts ...*types.Alert) {
+	if e == nil {
+		return
+	}
+	for _, a := range alerts {
+		for _, t := range e.tables {
+			row := t.lookup(a.Labels)
+			if row == nil {
+				continue
+			}
+			if a.Labels == nil {
+				a.Labels = model.LabelSet{}
+			}
+			if a.Annotations == nil {
+				a.Annotations = model.LabelSet{}
+			}
+			mergeEnrichment(a.Labels, row.labels, t.override)
+			mergeEnrichment(a.Annotations, row.annotations, t.override)
+		}
+	}
+}
+
+func mergeEnrichment(dst, src model.LabelSet, override bool) {
+	for k, v := range src {
+		if _, ok := dst[k]; ok && !override {
+			continue
+		}
+		dst[k] = v
+	}
+}
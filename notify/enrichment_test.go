@@ -0,0 +1,259 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestEnricher(t *testing.T) {
+	tables := []EnrichmentTable{
+		{
+			Name: "services",
+			Keys: []string{"service"},
+			Entries: []map[string]string{
+				{"service": "api", "team": "backend", "annotation:runbook_url": "http://runbooks/api"},
+			},
+			CSV: "service,team,slack_channel\nweb,frontend,#web\n",
+		},
+		{
+			Name:      "teams",
+			Keys:      []string{"team", "env"},
+			RegexKeys: []string{"env"},
+			JSON:      `[{"team": "backend", "env": "prod|staging", "slack_channel": "#backend-alerts"}, {"team": "backend", "env": ".*", "slack_channel": "#backend"}]`,
+		},
+	}
+
+	e, err := newEnricher(tables)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name           string
+		labels         model.LabelSet
+		annotations    model.LabelSet
+		expLabels      model.LabelSet
+		expAnnotations model.LabelSet
+	}{
+		{
+			name:           "no matching row leaves the alert untouched",
+			labels:         model.LabelSet{"alertname": "a", "service": "db"},
+			annotations:    model.LabelSet{},
+			expLabels:      model.LabelSet{"alertname": "a", "service": "db"},
+			expAnnotations: model.LabelSet{},
+		},
+		{
+			name:           "exact key from inline entries adds labels and annotations",
+			labels:         model.LabelSet{"alertname": "a", "service": "api"},
+			annotations:    model.LabelSet{},
+			expLabels:      model.LabelSet{"alertname": "a", "service": "api", "team": "backend"},
+			expAnnotations: model.LabelSet{"runbook_url": "http://runbooks/api"},
+		},
+		{
+			name:           "exact key from csv",
+			labels:         model.LabelSet{"alertname": "a", "service": "web"},
+			expLabels:      model.LabelSet{"alertname": "a", "service": "web", "team": "frontend", "slack_channel": "#web"},
+			expAnnotations: nil,
+		},
+		{
+			name:           "labels added by a table are used by the next one",
+			labels:         model.LabelSet{"alertname": "a", "service": "api", "env": "prod"},
+			annotations:    model.LabelSet{},
+			expLabels:      model.LabelSet{"alertname": "a", "service": "api", "env": "prod", "team": "backend", "slack_channel": "#backend-alerts"},
+			expAnnotations: model.LabelSet{"runbook_url": "http://runbooks/api"},
+		},
+		{
+			name:           "first matching regex row wins",
+			labels:         model.LabelSet{"alertname": "a", "team": "backend", "env": "dev"},
+			annotations:    model.LabelSet{},
+			expLabels:      model.LabelSet{"alertname": "a", "team": "backend", "env": "dev", "slack_channel": "#backend"},
+			expAnnotations: model.LabelSet{},
+		},
+		{
+			name:           "existing labels and annotations are not overridden",
+			labels:         model.LabelSet{"alertname": "a", "service": "api", "team": "dba"},
+			annotations:    model.LabelSet{"runbook_url": "http://custom"},
+			expLabels:      model.LabelSet{"alertname": "a", "service": "api", "team": "dba"},
+			expAnnotations: model.LabelSet{"runbook_url": "http://custom"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &types.Alert{Alert: model.Alert{Labels: tc.labels, Annotations: tc.annotations}}
+			e.Enrich(a)
+			require.Equal(t, tc.expLabels, a.Labels)
+			if tc.expAnnotations == nil {
+				require.Empty(t, a.Annotations)
+				return
+			}
+			require.Equal(t, tc.expAnnotations, a.Annotations)
+		})
+	}
+
+	t.Run("override replaces existing labels", func(t *testing.T) {
+		e, err := newEnricher([]EnrichmentTable{{
+			Name:     "override",
+			Keys:     []string{"service"},
+			Override: true,
+			Entries:  []map[string]string{{"service": "api", "team": "backend"}},
+		}})
+		require.NoError(t, err)
+		a := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"service": "api", "team": "dba"}}}
+		e.Enrich(a)
+		require.Equal(t, model.LabelSet{"service": "api", "team": "backend"}, a.Labels)
+	})
+
+	t.Run("invalid table returns an error", func(t *testing.T) {
+		_, err := newEnricher([]EnrichmentTable{{
+			Name:      "invalid",
+			Keys:      []string{"service"},
+			RegexKeys: []string{"service"},
+			Entries:   []map[string]string{{"service": "(api"}},
+		}})
+		require.Error(t, err)
+	})
+
+	t.Run("nil enricher is a no-op", func(t *testing.T) {
+		var e *enricher
+		a := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"service": "api"}}}
+		e.Enrich(a)
+		require.Equal(t, model.LabelSet{"service": "api"}, a.Labels)
+	})
+}
+
+func TestPutAlertsEnrichment(t *testing.T) {
+	am, _ := setupAMTest(t)
+
+	r := prometheus.NewRegistry()
+	am.marker = types.NewMarker(r)
+	var err error
+	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, 15*time.Minute, nil, am.logger, r)
+	require.NoError(t, err)
+
+	am.enricher, err = newEnricher([]EnrichmentTable{{
+		Name:    "services",
+		Keys:    []string{"service"},
+		Entries: []map[string]string{{"service": "api", "team": "backend"}},
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, am.PutAlerts(amv2.PostableAlerts{{
+		Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "a", "service": "api"}},
+	}}))
+
+	iter := am.alerts.GetPending()
+	defer iter.Close()
+	var alerts []*types.Alert
+	for a := range iter.Next() {
+		alerts = append(alerts, a)
+	}
+	require.Len(t, alerts, 1)
+	require.Equal(t, model.LabelSet{"alertname": "a", "service": "api", "team": "backend"}, alerts[0].Labels)
+}
+
+// enrichmentTestConfig is a timeIntervalsTestConfig that also has enrichment tables.
+type enrichmentTestConfig struct {
+	timeIntervalsTestConfig
+	tables []EnrichmentTable
+}
+
+func (c *enrichmentTestConfig) Enrichments() []EnrichmentTable { return c.tables }
+
+func TestApplyConfig_Enrichments(t *testing.T) {
+	teamBackend, err := labels.NewMatcher(labels.MatchEqual, "team", "backend")
+	require.NoError(t, err)
+
+	// apply applies the configuration and returns the labels of an alert put after it, once it is notified.
+	apply := func(t *testing.T, cfg func(n *countingNotifier) Configuration) model.LabelSet {
+		am, _ := setupAMTest(t)
+		t.Cleanup(am.StopAndWait)
+
+		n := &countingNotifier{}
+		am.WithLock(func() {
+			require.NoError(t, am.ApplyConfig(cfg(n)))
+		})
+		now := time.Now()
+		require.NoError(t, am.PutAlerts(amv2.PostableAlerts{{
+			Alert:    amv2.Alert{Labels: amv2.LabelSet{"alertname": "a", "service": "api"}},
+			StartsAt: strfmt.DateTime(now),
+			EndsAt:   strfmt.DateTime(now.Add(time.Hour)),
+		}}))
+		require.Eventually(t, func() bool { return n.notified.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		iter := am.alerts.GetPending()
+		defer iter.Close()
+		var alerts []*types.Alert
+		for a := range iter.Next() {
+			alerts = append(alerts, a)
+		}
+		require.Len(t, alerts, 1)
+		return alerts[0].Labels
+	}
+
+	labels := apply(t, func(n *countingNotifier) Configuration {
+		return &enrichmentTestConfig{
+			timeIntervalsTestConfig: timeIntervalsTestConfig{notifier: n},
+			tables: []EnrichmentTable{{
+				Name:    "services",
+				Keys:    []string{"service"},
+				Entries: []map[string]string{{"service": "api", "team": "backend"}},
+			}},
+		}
+	})
+	require.Equal(t, model.LabelSet{"alertname": "a", "service": "api", "team": "backend"}, labels)
+
+	t.Run("enrichment tables of a PostableConfiguration are applied and usable in route matchers", func(t *testing.T) {
+		groupWait := model.Duration(0)
+		enriched := apply(t, func(n *countingNotifier) Configuration {
+			other := &countingNotifier{}
+			cfg, err := NewPostableConfiguration(&definition.PostableApiAlertingConfig{
+				Config: definition.Config{
+					Route: &definition.Route{
+						Receiver:  "default",
+						GroupWait: &groupWait,
+						Routes:    []*definition.Route{{Receiver: "backend", Matchers: config.Matchers{teamBackend}}},
+					},
+					Enrichments: []EnrichmentTable{{
+						Name: "services",
+						Keys: []string{"service"},
+						CSV:  "service,team\napi,backend\n",
+					}},
+				},
+				Receivers: []*definition.PostableApiReceiver{
+					{Receiver: config.Receiver{Name: "default"}},
+					{Receiver: config.Receiver{Name: "backend"}},
+				},
+			}, nil, nil, func(next *APIReceiver, _ *templates.Template) ([]*Integration, error) {
+				// Only the receiver of the route that matches the enriched label notifies n.
+				notifier := other
+				if next.Name == "backend" {
+					notifier = n
+				}
+				return []*Integration{NewIntegration(notifier, &fakeNotifier{}, "webhook", 0, next.Name)}, nil
+			})
+			require.NoError(t, err)
+			return cfg
+		})
+		require.Equal(t, model.LabelSet{"alertname": "a", "service": "api", "team": "backend"}, enriched)
+	})
+
+	t.Run("configurations without enrichment tables are applied without enrichments", func(t *testing.T) {
+		labels := apply(t, func(n *countingNotifier) Configuration {
+			return &timeIntervalsTestConfig{notifier: n}
+		})
+		require.Equal(t, model.LabelSet{"alertname": "a", "service": "api"}, labels)
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/alerting/images"
 	"github.com/grafana/alerting/logging"
 	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/receivers/webhook"
 	"github.com/grafana/alerting/templates"
 )
 
@@ -71,7 +72,7 @@ func TestBuildReceiverIntegrations(t *testing.T) {
 			require.Len(t, loggerNames, qty)
 		})
 		t.Run("should call webhook factory for each config that needs it", func(t *testing.T) {
-			require.Len(t, webhooks, 17) // we have 17 notifiers that support webhook
+			require.Len(t, webhooks, 18) // we have 18 notifiers that support webhook
 		})
 		t.Run("should call email factory for each config that needs it", func(t *testing.T) {
 			require.Len(t, emails, 1) // we have only email notifier that needs sender
@@ -107,6 +108,31 @@ func TestBuildReceiverIntegrations(t *testing.T) {
 		require.ErrorContains(t, err, "bad-test")
 		require.Greater(t, calls, 0)
 	})
+	t.Run("should build the integrations of the typed fields", func(t *testing.T) {
+		fullCfg, qty := getFullConfig(t)
+		require.Len(t, fullCfg.Integrations, qty)
+		// The configurations created with the typed fields only, as before the integrations were registered.
+		typed := fullCfg
+		typed.Integrations = nil
+		require.Len(t, typed.integrations(), qty)
+		require.Equal(t, fullCfg.WebhookConfigs, ConfigsOf[webhook.Config](typed))
+
+		integrations, err := BuildReceiverIntegrations(typed, tmpl, imageProvider, loggerFactory, webhookFactory, emailFactory, orgID, version)
+		require.NoError(t, err)
+		require.Len(t, integrations, qty)
+
+		cfg := GrafanaReceiverConfig{
+			Name: "test",
+			WebhookConfigs: []*NotifierConfig[webhook.Config]{{
+				Metadata: receivers.Metadata{UID: "webhook"},
+				Settings: webhook.Config{URL: "http://localhost"},
+			}},
+		}
+		integrations, err = BuildReceiverIntegrations(cfg, tmpl, imageProvider, loggerFactory, webhookFactory, emailFactory, orgID, version)
+		require.NoError(t, err)
+		require.Len(t, integrations, 1)
+		require.Equal(t, "webhook", integrations[0].Name())
+	})
 	t.Run("should not produce any integration if config is empty", func(t *testing.T) {
 		cfg := GrafanaReceiverConfig{Name: "test"}
 
@@ -19,6 +19,9 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/alerting/cluster"
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/notify/audit"
+	"github.com/grafana/alerting/notify/nfstate"
 	"github.com/grafana/alerting/notify/nfstatus"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
@@ -36,6 +39,8 @@ import (
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/grafana/alerting/models"
 	"github.com/grafana/alerting/templates"
@@ -71,11 +76,16 @@ type GrafanaAlertmanager struct {
 
 	tenantID int64
 
-	marker      types.Marker
-	alerts      *mem.Alerts
-	route       *dispatch.Route
-	peer        ClusterPeer
-	peerTimeout time.Duration
+	marker types.Marker
+	alerts *mem.Alerts
+	route  *dispatch.Route
+	peer   ClusterPeer
+
+	// clusterStates records the synchronization of the states of the Alertmanager with the other peers.
+	clusterStates *cluster.StateTracker
+
+	// sendingStrategy decides when the replica sends the notifications, and if it sends them at all.
+	sendingStrategy SendingStrategy
 
 	// wg is for dispatcher, inhibitor, silences and notifications
 	// Across configuration changes dispatcher and inhibitor are completely replaced, however, silences, notification log and alerts remain the same.
@@ -89,17 +99,55 @@ type GrafanaAlertmanager struct {
 	silencer        *silence.Silencer
 	silences        *silence.Silences
 
+	// notificationState stores the identifiers of the messages sent for each group. It is nil if disabled.
+	notificationState *nfstate.Store
+	// repeatCounts stores the counts of repeat notifications of the groups of routes with a repeat backoff.
+	repeatCounts *repeatCounts
+
+	// tracerProvider traces the flushes of the aggregation groups through the notification pipeline.
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+
+	// auditSink records every notification attempt. It is nil if disabled.
+	auditSink audit.Sink
+
+	// splitByAlertConcurrency is the maximum number of alerts of a group notified at the same time by each integration
+	// that notifies the alerts on their own.
+	splitByAlertConcurrency int
+
 	// timeIntervals is the set of all time_intervals and mute_time_intervals from
 	// the configuration.
 	timeIntervals map[string][]timeinterval.TimeInterval
 
+	// enricher adds labels and annotations to incoming alerts. It is replaced on every configuration change.
+	enricher *enricher
+
 	stageMetrics      *notify.Metrics
 	dispatcherMetrics *dispatch.DispatcherMetrics
+	// integrationSuppressed counts the notifications suppressed by the time intervals of integrations, with the metric
+	// of the stages of the Alertmanager.
+	integrationSuppressed prometheus.Counter
+
+	// startedAt is the time the Alertmanager was created.
+	startedAt time.Time
 
 	reloadConfigMtx sync.RWMutex
 	configHash      [16]byte
 	config          []byte
+	configAppliedAt time.Time
 	receivers       []*nfstatus.Receiver
+	// appliedConfig is the last configuration applied, whose receivers are rebuilt when the referenced secrets change.
+	appliedConfig Configuration
+	// tmpl is the template the integrations of the applied configuration are built with.
+	tmpl *templates.Template
+	// receiverStages are the stages of the receivers in the routing stage, keyed by receiver name.
+	receiverStages map[string]*swappableStage
+	// newReceiverStage creates the stage of a receiver with the stages shared by the receivers of the applied configuration.
+	newReceiverStage func(name string, integrations []*Integration) notify.Stage
+	// secretResolver resolves the references to secrets in the settings of the integrations. It is optional.
+	secretResolver *SecretResolver
+	// stopSecretRefresh stops the resolution of the referenced secrets. It is nil if there is no SecretResolver.
+	stopSecretRefresh func()
 
 	// buildReceiverIntegrationsFunc builds the integrations for a receiver based on its APIReceiver configuration and the current parsed template.
 	buildReceiverIntegrationsFunc func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error)
@@ -158,6 +206,25 @@ type Configuration interface {
 	Raw() []byte
 }
 
+// EnrichmentConfiguration is implemented by the configurations that have lookup tables to enrich alerts with.
+// Configurations that do not implement it are applied without enrichments. PostableConfiguration implements it with
+// the enrichment tables of the PostableApiAlertingConfig.
+type EnrichmentConfiguration interface {
+	Configuration
+	// Enrichments returns the lookup tables used to add labels and annotations to alerts before they are routed.
+	Enrichments() []EnrichmentTable
+}
+
+// GrafanaRoutingTreeConfiguration is implemented by the configurations that also have the Grafana routing tree, whose
+// routes have settings that the routing tree of the Alertmanager does not have, such as repeat backoffs.
+// The dispatcher of these configurations routes the alerts with the Grafana routing tree instead of RoutingTree.
+// Configurations that do not implement it are applied without these settings. See PostableConfiguration.
+type GrafanaRoutingTreeConfiguration interface {
+	Configuration
+	// GrafanaRoutingTree returns the routing tree of the configuration, with the settings of Grafana.
+	GrafanaRoutingTree() *definition.Route
+}
+
 type Limits struct {
 	MaxSilences         int
 	MaxSilenceSizeBytes int
@@ -170,6 +237,30 @@ type GrafanaAlertmanagerConfig struct {
 
 	Silences MaintenanceOptions
 	Nflog    MaintenanceOptions
+	// NotificationState configures the store of the identifiers of the messages sent for each group, which notifiers
+	// use to reply in thread to, or to edit, the messages of the group. It is optional, if nil the store is disabled.
+	NotificationState MaintenanceOptions
+	// TracerProvider provides the tracer of the notification pipeline. Every flush of an aggregation group is traced,
+	// with child spans for each stage and each notification attempt. It is optional, if nil tracing is disabled.
+	TracerProvider trace.TracerProvider
+	// AuditSink receives a record of every notification attempt. It is optional, if nil attempts are not recorded.
+	AuditSink audit.Sink
+	// SendingStrategy decides which replicas send the notifications. It is optional, if nil every replica sends
+	// the notifications after waiting its position in the cluster times the peer timeout.
+	SendingStrategy SendingStrategy
+	// SecretResolver resolves the references to secrets in the settings of the integrations. It is optional, if set
+	// the referenced secrets are resolved again every SecretRefreshInterval, and the receivers that reference the
+	// secrets that changed are built again and swapped into the notification pipeline, without restarting the
+	// dispatcher. A receiver that cannot be built keeps its previous integrations. BuildReceiverIntegrationsFunc must
+	// build the receivers with BuildReceiverConfiguration and a context that has the same SecretResolver, see
+	// WithSecretResolver.
+	SecretResolver *SecretResolver
+	// SecretRefreshInterval is the interval between the resolutions of the referenced secrets. Defaults to
+	// DefaultSecretRefreshInterval.
+	SecretRefreshInterval time.Duration
+	// SplitByAlertConcurrency is the maximum number of alerts of a group notified at the same time by each integration
+	// that notifies the alerts on their own. Defaults to DefaultSplitByAlertConcurrency.
+	SplitByAlertConcurrency int
 
 	Limits Limits
 }
@@ -193,18 +284,32 @@ func NewGrafanaAlertmanager(tenantKey string, tenantID int64, config *GrafanaAle
 		stopc:             make(chan struct{}),
 		logger:            log.With(logger, "component", "alertmanager", tenantKey, tenantID),
 		marker:            types.NewMarker(m.Registerer),
-		stageMetrics:      notify.NewMetrics(m.Registerer, featurecontrol.NoopFlags{}),
 		dispatcherMetrics: dispatch.NewDispatcherMetrics(false, m.Registerer),
 		peer:              peer,
-		peerTimeout:       config.PeerTimeout,
+		clusterStates:     cluster.NewStateTracker(),
+		sendingStrategy:   config.SendingStrategy,
 		Metrics:           m,
 		tenantID:          tenantID,
 		externalURL:       config.ExternalURL,
+		startedAt:         time.Now(),
+		auditSink:         config.AuditSink,
+
+		splitByAlertConcurrency: config.SplitByAlertConcurrency,
 	}
 
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
+	am.tracerProvider = config.TracerProvider
+	if am.sendingStrategy == nil {
+		am.sendingStrategy = NewPositionSendingStrategy(peer, config.PeerTimeout)
+	}
+	if am.tracerProvider == nil {
+		am.tracerProvider = noop.NewTracerProvider()
+	}
+	am.tracer = am.tracerProvider.Tracer(tracerName)
+	am.stageMetrics = notify.NewMetrics(m.Registerer, featurecontrol.NoopFlags{})
+	am.integrationSuppressed = newIntegrationSuppressedCounter(m.Registerer)
 
 	var err error
 
@@ -222,9 +327,14 @@ func NewGrafanaAlertmanager(tenantKey string, tenantID int64, config *GrafanaAle
 		return nil, fmt.Errorf("unable to initialize the silencing component of alerting: %w", err)
 	}
 
-	// Initialize the notification log
+	// Initialize the notification log. Its snapshots also have the counts of repeat notifications, so that the repeat
+	// backoffs survive the restart of every replica.
+	nflogSnapshot, repeatCountsSnapshot, err := splitNflogSnapshot(config.Nflog.InitialState())
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the notification log snapshot: %w", err)
+	}
 	am.notificationLog, err = nflog.New(nflog.Options{
-		SnapshotReader: strings.NewReader(config.Nflog.InitialState()),
+		SnapshotReader: strings.NewReader(nflogSnapshot),
 		Retention:      config.Nflog.Retention(),
 		Logger:         logger,
 		Metrics:        m.Registerer,
@@ -233,20 +343,45 @@ func NewGrafanaAlertmanager(tenantKey string, tenantID int64, config *GrafanaAle
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize the notification log component of alerting: %w", err)
 	}
-	c := am.peer.AddState(fmt.Sprintf("notificationlog:%d", am.tenantID), am.notificationLog, m.Registerer)
+	c := am.clusterStates.AddState(am.peer, fmt.Sprintf("notificationlog:%d", am.tenantID), am.notificationLog, m.Registerer)
 	am.notificationLog.SetBroadcast(c.Broadcast)
 
-	c = am.peer.AddState(fmt.Sprintf("silences:%d", am.tenantID), am.silences, m.Registerer)
+	c = am.clusterStates.AddState(am.peer, fmt.Sprintf("silences:%d", am.tenantID), am.silences, m.Registerer)
 	am.silences.SetBroadcast(c.Broadcast)
 
+	am.repeatCounts = newRepeatCounts()
+	if repeatCountsSnapshot != nil {
+		if err := am.repeatCounts.Merge(repeatCountsSnapshot); err != nil {
+			return nil, fmt.Errorf("unable to initialize the repeat counts component of alerting: %w", err)
+		}
+	}
+	c = am.clusterStates.AddState(am.peer, fmt.Sprintf("repeatcounts:%d", am.tenantID), am.repeatCounts, m.Registerer)
+	am.repeatCounts.SetBroadcast(c.Broadcast)
+
+	if config.NotificationState != nil {
+		am.notificationState, err = nfstate.New(config.NotificationState.Retention(), strings.NewReader(config.NotificationState.InitialState()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize the notification state component of alerting: %w", err)
+		}
+		c = am.clusterStates.AddState(am.peer, fmt.Sprintf("notificationstate:%d", am.tenantID), am.notificationState, m.Registerer)
+		am.notificationState.SetBroadcast(c.Broadcast)
+
+		am.wg.Add(1)
+		go func() {
+			am.maintenance("notification state", config.NotificationState, am.notificationState, func() { am.notificationState.GC() })
+			am.wg.Done()
+		}()
+	}
+
 	am.wg.Add(1)
 	go func() {
 		am.notificationLog.Maintenance(config.Nflog.MaintenanceFrequency(), snapshotPlaceholder, am.stopc, func() (int64, error) {
 			if _, err := am.notificationLog.GC(); err != nil {
 				level.Error(am.logger).Log("notification log garbage collection", "err", err)
 			}
+			am.repeatCounts.GC()
 
-			return config.Nflog.MaintenanceFunc(am.notificationLog)
+			return config.Nflog.MaintenanceFunc(&nflogSnapshotState{nflog: am.notificationLog, counts: am.repeatCounts})
 		})
 		am.wg.Done()
 	}()
@@ -266,15 +401,60 @@ func NewGrafanaAlertmanager(tenantKey string, tenantID int64, config *GrafanaAle
 		am.wg.Done()
 	}()
 
+	if config.SecretResolver != nil {
+		am.secretResolver = config.SecretResolver
+		interval := config.SecretRefreshInterval
+		if interval <= 0 {
+			interval = DefaultSecretRefreshInterval
+		}
+		stopc, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			am.refreshSecrets(config.SecretResolver, interval, stopc)
+			close(done)
+		}()
+		am.stopSecretRefresh = func() {
+			close(stopc)
+			<-done
+		}
+	}
+
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, config.AlertStoreCallback, am.logger, m.Registerer)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize the alert provider component of alerting: %w", err)
 	}
 
+	m.cluster.set(am.tenantString(), am.ClusterStatus)
 	return am, nil
 }
 
+// maintenance periodically garbage collects and snapshots the state, until the Alertmanager is stopped.
+// The state is snapshotted one last time when stopping.
+func (am *GrafanaAlertmanager) maintenance(name string, opts MaintenanceOptions, state State, gc func()) {
+	run := func() {
+		gc()
+		if _, err := opts.MaintenanceFunc(state); err != nil {
+			level.Error(am.logger).Log("msg", name+" maintenance failed", "err", err)
+		}
+	}
+
+	var tick <-chan time.Time
+	if opts.MaintenanceFrequency() > 0 {
+		t := time.NewTicker(opts.MaintenanceFrequency())
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-am.stopc:
+			run()
+			return
+		case <-tick:
+			run()
+		}
+	}
+}
+
 func (am *GrafanaAlertmanager) Ready() bool {
 	// We consider AM as ready only when the config has been
 	// applied at least once successfully. Until then, some objects
@@ -290,6 +470,11 @@ func (am *GrafanaAlertmanager) ready() bool {
 }
 
 func (am *GrafanaAlertmanager) StopAndWait() {
+	// The configuration must not be applied again once the dispatcher is stopped.
+	if am.stopSecretRefresh != nil {
+		am.stopSecretRefresh()
+	}
+
 	if am.dispatcher != nil {
 		am.dispatcher.Stop()
 	}
@@ -303,6 +488,7 @@ func (am *GrafanaAlertmanager) StopAndWait() {
 	close(am.stopc)
 
 	am.wg.Wait()
+	am.Metrics.cluster.remove(am.tenantString())
 }
 
 // GetReceivers returns the receivers configured as part of the current configuration.
@@ -352,6 +538,7 @@ type job struct {
 	Config       *GrafanaIntegrationConfig
 	ReceiverName string
 	Notifier     notify.Notifier
+	SendResolved bool
 }
 
 // result contains the receiver that was tested and a non-nil error if the test failed
@@ -359,6 +546,8 @@ type result struct {
 	Config       *GrafanaIntegrationConfig
 	ReceiverName string
 	Error        error
+	// Skipped is true if the integration was not notified, e.g. because it does not send resolved notifications.
+	Skipped bool
 }
 
 func newTestReceiversResult(alert types.Alert, results []result, receivers []*APIReceiver, notifiedAt time.Time) (*TestReceiversResult, int) {
@@ -378,6 +567,8 @@ func newTestReceiversResult(alert types.Alert, results []result, receivers []*AP
 		status := "ok"
 		if next.Error != nil {
 			status = "failed"
+		} else if next.Skipped {
+			status = "skipped"
 		}
 
 		var invalidReceiverErr IntegrationValidationError
@@ -407,6 +598,7 @@ func newTestReceiversResult(alert types.Alert, results []result, receivers []*AP
 	}
 	v := new(TestReceiversResult)
 	v.Alert = alert
+	v.Alerts = []types.Alert{alert}
 	v.Receivers = make([]TestReceiverResult, 0, len(receivers))
 	v.NotifedAt = notifiedAt
 	for _, next := range m {
@@ -435,6 +627,13 @@ func newTestReceiversResult(alert types.Alert, results []result, receivers []*AP
 	return v, returnCode
 }
 
+// testStep is a single notification in a sequence of test notifications.
+type testStep struct {
+	Status model.AlertStatus
+	Alerts []*types.Alert
+	Now    time.Time
+}
+
 func TestReceivers(
 	ctx context.Context,
 	c TestReceiversConfigBodyParams,
@@ -443,7 +642,10 @@ func TestReceivers(
 	externalURL string) (*TestReceiversResult, int, error) {
 
 	now := time.Now() // The start time of the test
-	testAlert := newTestAlert(c, now, now)
+	testAlerts, err := newTestAlerts(c, now)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	tmpl, err := templateFromContent(tmpls, externalURL)
 	if err != nil {
@@ -476,6 +678,7 @@ func TestReceivers(
 					Config:       intg,
 					ReceiverName: receiver.Name,
 					Notifier:     integrations[0],
+					SendResolved: integrations[0].SendResolved(),
 				})
 			}
 		}
@@ -485,35 +688,103 @@ func TestReceivers(
 		return nil, 0, ErrNoReceivers
 	}
 
-	if len(jobs) == 0 {
-		res, status := newTestReceiversResult(testAlert, invalid, c.Receivers, now)
-		return res, status, nil
+	groupLabels := c.GroupLabels
+	if groupLabels == nil {
+		groupLabels = commonLabels(testAlerts)
+	}
+
+	statuses := []model.AlertStatus{""}
+	if c.ResolveAfterFiring {
+		statuses = []model.AlertStatus{model.AlertFiring, model.AlertResolved}
+	}
+
+	var (
+		res    *TestReceiversResult
+		status int
+	)
+	for _, s := range statuses {
+		step := testStep{Status: s, Alerts: testAlerts, Now: now}
+		switch s {
+		case model.AlertFiring:
+			step.Alerts = firingTestAlerts(testAlerts)
+		case model.AlertResolved:
+			step.Now = time.Now()
+			step.Alerts = resolvedTestAlerts(testAlerts, step.Now)
+		}
+
+		results, err := runTestJobs(ctx, jobs, step, c.GroupKey, groupLabels, now)
+		if err != nil {
+			return nil, 0, err
+		}
+		stepRes, stepStatus := newTestReceiversResult(*step.Alerts[0], append(invalid, results...), c.Receivers, step.Now)
+		stepRes.Alerts = make([]types.Alert, 0, len(step.Alerts))
+		for _, a := range step.Alerts {
+			stepRes.Alerts = append(stepRes.Alerts, *a)
+		}
+
+		if res == nil {
+			res, status = stepRes, stepStatus
+		} else if status != stepStatus {
+			// The steps had different outcomes.
+			status = http.StatusMultiStatus
+		}
+		if c.ResolveAfterFiring {
+			res.Steps = append(res.Steps, TestReceiversStepResult{
+				Status:     step.Status,
+				Alerts:     stepRes.Alerts,
+				Receivers:  stepRes.Receivers,
+				NotifiedAt: stepRes.NotifedAt,
+			})
+		}
+	}
+	return res, status, nil
+}
+
+// runTestJobs sends the notification of the step to all jobs, and returns the results in no particular order.
+func runTestJobs(ctx context.Context, jobs []job, step testStep, groupKey string, groupLabels model.LabelSet, startedAt time.Time) ([]result, error) {
+	results := make([]result, 0, len(jobs))
+	pending := make([]job, 0, len(jobs))
+	for _, j := range jobs {
+		// Resolved notifications are not sent to integrations with disableResolveMessage.
+		if step.Status == model.AlertResolved && !j.SendResolved {
+			results = append(results, result{Config: j.Config, ReceiverName: j.ReceiverName, Skipped: true})
+			continue
+		}
+		pending = append(pending, j)
+	}
+	if len(pending) == 0 {
+		return results, nil
 	}
 
 	numWorkers := maxTestReceiversWorkers
-	if numWorkers > len(jobs) {
-		numWorkers = len(jobs)
+	if numWorkers > len(pending) {
+		numWorkers = len(pending)
 	}
 
-	resultCh := make(chan result, len(jobs))
-	workCh := make(chan job, len(jobs))
-	for _, job := range jobs {
+	resultCh := make(chan result, len(pending))
+	workCh := make(chan job, len(pending))
+	for _, job := range pending {
 		workCh <- job
 	}
 	close(workCh)
 
-	g, ctx := errgroup.WithContext(ctx)
+	g, gCtx := errgroup.WithContext(ctx)
 	for i := 0; i < numWorkers; i++ {
 		g.Go(func() error {
 			for next := range workCh {
-				ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-%s-%d", next.ReceiverName, testAlert.Labels.Fingerprint(), now.Unix()))
-				ctx = notify.WithGroupLabels(ctx, testAlert.Labels)
+				key := groupKey
+				if key == "" {
+					key = fmt.Sprintf("%s-%s-%d", next.ReceiverName, groupLabels.Fingerprint(), startedAt.Unix())
+				}
+				ctx := notify.WithGroupKey(gCtx, key)
+				ctx = notify.WithGroupLabels(ctx, groupLabels)
 				ctx = notify.WithReceiverName(ctx, next.ReceiverName)
+				ctx = notify.WithNow(ctx, step.Now)
 				v := result{
 					Config:       next.Config,
 					ReceiverName: next.ReceiverName,
 				}
-				if _, err := next.Notifier.Notify(ctx, &testAlert); err != nil {
+				if _, err := next.Notifier.Notify(ctx, step.Alerts...); err != nil {
 					v.Error = err
 				}
 				resultCh <- v
@@ -522,20 +793,40 @@ func TestReceivers(
 		})
 	}
 
-	err = g.Wait()
+	err := g.Wait()
 	close(resultCh)
 
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	results := make([]result, 0, len(jobs))
 	for next := range resultCh {
 		results = append(results, next)
 	}
+	return results, nil
+}
 
-	res, status := newTestReceiversResult(testAlert, append(invalid, results...), c.Receivers, now)
-	return res, status, nil
+// firingTestAlerts returns copies of the alerts that are firing.
+func firingTestAlerts(alerts []*types.Alert) []*types.Alert {
+	res := make([]*types.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		c := *a
+		c.EndsAt = time.Time{}
+		res = append(res, &c)
+	}
+	return res
+}
+
+// resolvedTestAlerts returns copies of the alerts that are resolved at the given time.
+func resolvedTestAlerts(alerts []*types.Alert, resolvedAt time.Time) []*types.Alert {
+	res := make([]*types.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		c := *a
+		c.EndsAt = resolvedAt
+		c.UpdatedAt = resolvedAt
+		res = append(res, &c)
+	}
+	return res
 }
 
 func TestTemplate(ctx context.Context, c TestTemplatesConfigBodyParams, tmpls []templates.TemplateDefinition, externalURL string, logger log.Logger) (*TestTemplatesResults, error) {
@@ -661,6 +952,23 @@ func (am *GrafanaAlertmanager) ApplyConfig(cfg Configuration) (err error) {
 		return err
 	}
 
+	var tables []EnrichmentTable
+	if c, ok := cfg.(EnrichmentConfiguration); ok {
+		tables = c.Enrichments()
+	}
+	enricher, err := newEnricher(tables)
+	if err != nil {
+		return fmt.Errorf("failed to build the enrichment tables: %w", err)
+	}
+
+	// The secrets referenced only by the previous configuration are dropped once the receivers are built.
+	if am.secretResolver != nil {
+		prev := am.secretResolver.beginApply()
+		defer func() {
+			am.secretResolver.endApply(prev, err == nil)
+		}()
+	}
+
 	// Finally, build the integrations map using the receiver configuration and templates.
 	apiReceivers := cfg.Receivers()
 	integrationsMap := make(map[string][]*Integration, len(apiReceivers))
@@ -671,10 +979,26 @@ func (am *GrafanaAlertmanager) ApplyConfig(cfg Configuration) (err error) {
 		}
 		integrationsMap[apiReceiver.Name] = integrations
 	}
+	// Validate before stopping the dispatcher and the inhibitor, so that an invalid configuration keeps the current one running.
+	if err := validateIntegrationTimeIntervals(integrationsMap, cfg.TimeIntervals(), cfg.MuteTimeIntervals()); err != nil {
+		return err
+	}
 
 	// Now, let's put together our notification pipeline
 	routingStage := make(notify.RoutingStage, len(integrationsMap))
 
+	// The routes of the dispatcher are built from the Grafana routing tree if the configuration has it, so that the
+	// repeat backoffs are keyed by the routes that the dispatcher installs.
+	var (
+		route    *dispatch.Route
+		backoffs map[string]RepeatBackoff
+	)
+	if c, ok := cfg.(GrafanaRoutingTreeConfiguration); ok {
+		route, backoffs = newGrafanaRoute(c.GrafanaRoutingTree())
+	} else {
+		route = dispatch.NewRoute(cfg.RoutingTree(), nil)
+	}
+
 	if am.inhibitor != nil {
 		am.inhibitor.Stop()
 	}
@@ -686,20 +1010,35 @@ func (am *GrafanaAlertmanager) ApplyConfig(cfg Configuration) (err error) {
 	am.timeIntervals = am.buildTimeIntervals(cfg.TimeIntervals(), cfg.MuteTimeIntervals())
 	am.silencer = silence.NewSilencer(am.silences, am.marker, am.logger)
 
-	meshStage := notify.NewGossipSettleStage(am.peer)
-	inhibitionStage := notify.NewMuteStage(am.inhibitor, am.stageMetrics)
-	timeMuteStage := notify.NewTimeMuteStage(timeinterval.NewIntervener(am.timeIntervals), am.stageMetrics)
-	silencingStage := notify.NewMuteStage(am.silencer, am.stageMetrics)
+	meshStage := newTracingStage(am.tracer, "notify.stage.gossip_settle", notify.NewGossipSettleStage(am.peer))
+	inhibitionStage := newTracingStage(am.tracer, "notify.stage.inhibit", notify.NewMuteStage(am.inhibitor, am.stageMetrics))
+	intervener := timeinterval.NewIntervener(am.timeIntervals)
+	timeMuteStage := newTracingStage(am.tracer, "notify.stage.time_mute", notify.NewTimeMuteStage(intervener, am.stageMetrics))
+	silencingStage := newTracingStage(am.tracer, "notify.stage.silence", notify.NewMuteStage(am.silencer, am.stageMetrics))
 
-	am.route = dispatch.NewRoute(cfg.RoutingTree(), nil)
+	am.route = route
 	am.dispatcher = dispatch.NewDispatcher(am.alerts, am.route, routingStage, am.marker, am.timeoutFunc, cfg.DispatcherLimits(), am.logger, am.dispatcherMetrics)
 
 	// TODO: This has not been upstreamed yet. Should be aligned when https://github.com/prometheus/alertmanager/pull/3016 is merged.
 	var receivers []*nfstatus.Receiver
 	activeReceivers := GetActiveReceiversMap(am.route)
+	newReceiverStage := func(name string, integrations []*Integration) notify.Stage {
+		stage := am.createReceiverStage(name, integrations, am.waitFunc, am.notificationLog, backoffs, intervener)
+		if am.notificationState != nil {
+			stage = notify.MultiStage{newNotificationStateStage(am.notificationState), stage}
+		}
+		return notify.MultiStage{
+			meshStage,
+			am.newSuppressionStage(silencingStage, NotificationOutcomeSuppressedSilence, integrations...),
+			am.newSuppressionStage(timeMuteStage, NotificationOutcomeSuppressedMuteTime, integrations...),
+			am.newSuppressionStage(inhibitionStage, NotificationOutcomeSuppressedInhibited, integrations...),
+			stage,
+		}
+	}
+	receiverStages := make(map[string]*swappableStage, len(integrationsMap))
 	for name := range integrationsMap {
-		stage := am.createReceiverStage(name, nfstatus.GetIntegrations(integrationsMap[name]), am.waitFunc, am.notificationLog)
-		routingStage[name] = notify.MultiStage{meshStage, silencingStage, timeMuteStage, inhibitionStage, stage}
+		receiverStages[name] = &swappableStage{stage: newReceiverStage(name, integrationsMap[name])}
+		routingStage[name] = newFlushTracingStage(am.tracerProvider, receiverStages[name])
 		_, isActive := activeReceivers[name]
 
 		receivers = append(receivers, nfstatus.NewReceiver(name, isActive, integrationsMap[name]))
@@ -709,7 +1048,11 @@ func (am *GrafanaAlertmanager) ApplyConfig(cfg Configuration) (err error) {
 	am.setInhibitionRulesMetrics(cfg.InhibitRules())
 
 	am.receivers = receivers
+	am.enricher = enricher
 	am.buildReceiverIntegrationsFunc = cfg.BuildReceiverIntegrationsFunc()
+	am.tmpl = tmpl
+	am.receiverStages = receiverStages
+	am.newReceiverStage = newReceiverStage
 
 	am.wg.Add(1)
 	go func() {
@@ -725,6 +1068,8 @@ func (am *GrafanaAlertmanager) ApplyConfig(cfg Configuration) (err error) {
 
 	am.configHash = cfg.Hash()
 	am.config = cfg.Raw()
+	am.configAppliedAt = time.Now()
+	am.appliedConfig = cfg
 
 	return nil
 }
@@ -754,6 +1099,11 @@ func (am *GrafanaAlertmanager) PutAlerts(postableAlerts amv2.PostableAlerts) err
 	now := time.Now()
 	alerts, validationErr := PostableAlertsToAlertmanagerAlerts(postableAlerts, now)
 
+	// Enrich the alerts before they are stored so that the added labels can be used for routing.
+	am.reloadConfigMtx.RLock()
+	am.enricher.Enrich(alerts...)
+	am.reloadConfigMtx.RUnlock()
+
 	// Register metrics.
 	for _, a := range alerts {
 		if a.EndsAt.After(now) {
@@ -865,19 +1215,39 @@ func (e AlertValidationError) Error() string {
 }
 
 // createReceiverStage creates a pipeline of stages for a receiver.
-func (am *GrafanaAlertmanager) createReceiverStage(name string, integrations []*notify.Integration, wait func() time.Duration, notificationLog notify.NotificationLog) notify.Stage {
+// Integrations that split by alert are notified once per alert, each with its own entry in the notification log.
+// The repeat interval of the groups of routes with a repeat backoff grows with every repeat notification.
+// Integrations with time intervals are not notified while muted.
+// Integrations are not notified after waiting if the sending strategy does not send from this replica.
+// The notification attempts of the integrations are observed by the delivery metrics, and recorded by the audit sink.
+func (am *GrafanaAlertmanager) createReceiverStage(name string, integrations []*Integration, wait func() time.Duration, notificationLog notify.NotificationLog, backoffs map[string]RepeatBackoff, intervener *timeinterval.Intervener) notify.Stage {
 	var fs notify.FanoutStage
 	for i := range integrations {
+		integration := integrations[i].Integration()
 		recv := &nflogpb.Receiver{
 			GroupName:   name,
-			Integration: integrations[i].Name(),
-			Idx:         uint32(integrations[i].Index()),
+			Integration: integration.Name(),
+			Idx:         uint32(integration.Index()),
 		}
+		attrs := integrationAttributes(integrations[i])
+		var notifyStage notify.MultiStage
+		notifyStage = append(notifyStage, newTracingStage(am.tracer, "notify.stage.dedup", notify.NewDedupStage(integration, notificationLog, recv), attrs...))
+		notifyStage = append(notifyStage, newTracingStage(am.tracer, "notify.stage.retry", notify.NewRetryStage(am.instrumentIntegration(integrations[i], name, notificationLog, recv), name, am.stageMetrics), attrs...))
+		notifyStage = append(notifyStage, newTracingStage(am.tracer, "notify.stage.set_notifies", notify.NewSetNotifiesStage(notificationLog, recv), attrs...))
+		backoffStage := newRepeatBackoffStage(notifyStage, am.repeatCounts, recv, backoffs)
+
 		var s notify.MultiStage
-		s = append(s, notify.NewWaitStage(wait))
-		s = append(s, notify.NewDedupStage(integrations[i], notificationLog, recv))
-		s = append(s, notify.NewRetryStage(integrations[i], name, am.stageMetrics))
-		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
+		s = append(s, newTracingStage(am.tracer, "notify.stage.wait", notify.NewWaitStage(wait), attrs...))
+		s = append(s, newSendingStage(am.sendingStrategy))
+		if mute, active := integrations[i].MuteTimeIntervals(), integrations[i].ActiveTimeIntervals(); len(mute) > 0 || len(active) > 0 {
+			timeStage := am.newSuppressionStage(newIntegrationTimeStage(intervener, mute, active, am.integrationSuppressed), NotificationOutcomeSuppressedMuteTime, integrations[i])
+			s = append(s, newTracingStage(am.tracer, "notify.stage.integration_time_mute", timeStage, attrs...))
+		}
+		if integrations[i].SplitByAlert() {
+			s = append(s, newSplitByAlertStage(backoffStage, am.splitByAlertConcurrency))
+		} else {
+			s = append(s, backoffStage)
+		}
 
 		fs = append(fs, s)
 	}
@@ -885,16 +1255,11 @@ func (am *GrafanaAlertmanager) createReceiverStage(name string, integrations []*
 }
 
 func (am *GrafanaAlertmanager) waitFunc() time.Duration {
-	return time.Duration(am.peer.Position()) * am.peerTimeout
+	return am.sendingStrategy.Wait()
 }
 
 func (am *GrafanaAlertmanager) timeoutFunc(d time.Duration) time.Duration {
-	// time.Duration d relates to the receiver's group_interval. Even with a group interval of 1s,
-	// we need to make sure (non-position-0) peers in the cluster wait before flushing the notifications.
-	if d < notify.MinTimeout {
-		d = notify.MinTimeout
-	}
-	return d + am.waitFunc()
+	return am.sendingStrategy.Timeout(d)
 }
 
 func (am *GrafanaAlertmanager) tenantString() string {
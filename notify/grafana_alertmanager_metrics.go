@@ -1,10 +1,16 @@
 package notify
 
 import (
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/go-kit/log"
 	"github.com/prometheus/alertmanager/api/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/alerting/cluster"
 )
 
 const namespace = "grafana"
@@ -18,11 +24,18 @@ type GrafanaAlertmanagerMetrics struct {
 	configuredReceivers       *prometheus.GaugeVec
 	configuredIntegrations    *prometheus.GaugeVec
 	configuredInhibitionRules *prometheus.GaugeVec
+
+	alertNotificationLatency *prometheus.HistogramVec
+	notifyDuration           *prometheus.HistogramVec
+	notifications            *prometheus.CounterVec
+	payloadSize              *prometheus.HistogramVec
+
+	cluster *clusterCollector
 }
 
 // NewGrafanaAlertmanagerMetrics creates a set of metrics for the Alertmanager.
 func NewGrafanaAlertmanagerMetrics(r prometheus.Registerer, l log.Logger) *GrafanaAlertmanagerMetrics {
-	return &GrafanaAlertmanagerMetrics{
+	m := &GrafanaAlertmanagerMetrics{
 		Registerer: r,
 		Alerts:     metrics.NewAlerts(r, l),
 		configuredReceivers: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
@@ -43,5 +56,157 @@ func NewGrafanaAlertmanagerMetrics(r prometheus.Registerer, l log.Logger) *Grafa
 			Name:      "alertmanager_inhibition_rules",
 			Help:      "Number of configured inhibition rules.",
 		}, []string{"org"}),
+		alertNotificationLatency: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "alertmanager_alert_notification_latency_seconds",
+			Help:      "Time from the start of alerts to their first successful notification, by integration type.",
+			Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600, 7200, 21600},
+		}, []string{"org", "type"}),
+		notifyDuration: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "alertmanager_notify_duration_seconds",
+			Help:      "Duration of the notification attempts, by integration type.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"org", "type"}),
+		notifications: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "alertmanager_notifications_total",
+			Help:      "Number of notifications by integration type and outcome.",
+		}, []string{"org", "type", "outcome"}),
+		payloadSize: promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "alertmanager_notification_payload_size_bytes",
+			Help:      "Size of the payloads of the webhooks sent by the integrations, by integration type.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"org", "type"}),
+		cluster: newClusterCollector(),
+	}
+	if r != nil {
+		r.MustRegister(m.cluster)
+	}
+	return m
+}
+
+var (
+	clusterMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_members"),
+		"Number of members of the cluster, as seen by the Alertmanager.",
+		[]string{"org"}, nil,
+	)
+	clusterPositionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_position"),
+		"Position of the Alertmanager in the cluster.",
+		[]string{"org"}, nil,
+	)
+	clusterStateMessagesSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_messages_sent_total"),
+		"Number of messages of the state sent to the other peers, as broadcasts or full states.",
+		[]string{"org", "state"}, nil,
+	)
+	clusterStateMessagesSentBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_messages_sent_bytes_total"),
+		"Size of the messages of the state sent to the other peers.",
+		[]string{"org", "state"}, nil,
+	)
+	clusterStateMessagesReceivedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_messages_received_total"),
+		"Number of messages of the state received from the other peers and merged.",
+		[]string{"org", "state"}, nil,
+	)
+	clusterStateMessagesReceivedBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_messages_received_bytes_total"),
+		"Size of the messages of the state received from the other peers and merged.",
+		[]string{"org", "state"}, nil,
+	)
+	clusterStateFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_failures_total"),
+		"Number of messages of the state that could not be sent or merged, by operation.",
+		[]string{"org", "state", "operation"}, nil,
+	)
+	clusterStateLastPushDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_last_push_timestamp_seconds"),
+		"Timestamp of the last push of the full state to the other peers.",
+		[]string{"org", "state"}, nil,
+	)
+	clusterStateLastPullDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "cluster_state_last_pull_timestamp_seconds"),
+		"Timestamp of the last message of the state merged from the other peers.",
+		[]string{"org", "state"}, nil,
+	)
+)
+
+// clusterCollector collects the cluster status of the Alertmanagers using the metrics, by tenant.
+type clusterCollector struct {
+	mtx     sync.Mutex
+	sources map[string]func() cluster.PeerStatus
+}
+
+func newClusterCollector() *clusterCollector {
+	return &clusterCollector{sources: map[string]func() cluster.PeerStatus{}}
+}
+
+// set sets the source of the cluster status of the tenant, replacing the previous one, if any.
+func (c *clusterCollector) set(org string, source func() cluster.PeerStatus) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.sources[org] = source
+}
+
+// remove removes the source of the cluster status of the tenant.
+func (c *clusterCollector) remove(org string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.sources, org)
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *clusterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterMembersDesc
+	ch <- clusterPositionDesc
+	ch <- clusterStateMessagesSentDesc
+	ch <- clusterStateMessagesSentBytesDesc
+	ch <- clusterStateMessagesReceivedDesc
+	ch <- clusterStateMessagesReceivedBytesDesc
+	ch <- clusterStateFailuresDesc
+	ch <- clusterStateLastPushDesc
+	ch <- clusterStateLastPullDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *clusterCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mtx.Lock()
+	sources := make(map[string]func() cluster.PeerStatus, len(c.sources))
+	for org, source := range c.sources {
+		sources[org] = source
+	}
+	c.mtx.Unlock()
+
+	for org, source := range sources {
+		status := source()
+		ch <- prometheus.MustNewConstMetric(clusterMembersDesc, prometheus.GaugeValue, float64(len(status.Peers)), org)
+		ch <- prometheus.MustNewConstMetric(clusterPositionDesc, prometheus.GaugeValue, float64(status.Position), org)
+		for _, s := range status.States {
+			// The key of the state is suffixed with the tenant, which is already a label.
+			state, _, _ := strings.Cut(s.Key, ":")
+			ch <- prometheus.MustNewConstMetric(clusterStateMessagesSentDesc, prometheus.CounterValue, float64(s.MessagesSent), org, state)
+			ch <- prometheus.MustNewConstMetric(clusterStateMessagesSentBytesDesc, prometheus.CounterValue, float64(s.MessagesSentBytes), org, state)
+			ch <- prometheus.MustNewConstMetric(clusterStateMessagesReceivedDesc, prometheus.CounterValue, float64(s.MessagesReceived), org, state)
+			ch <- prometheus.MustNewConstMetric(clusterStateMessagesReceivedBytesDesc, prometheus.CounterValue, float64(s.MessagesReceivedBytes), org, state)
+			ch <- prometheus.MustNewConstMetric(clusterStateFailuresDesc, prometheus.CounterValue, float64(s.SendFailures), org, state, "send")
+			ch <- prometheus.MustNewConstMetric(clusterStateFailuresDesc, prometheus.CounterValue, float64(s.ReceiveFailures), org, state, "receive")
+			ch <- prometheus.MustNewConstMetric(clusterStateLastPushDesc, prometheus.GaugeValue, timestampSeconds(s.LastPush), org, state)
+			ch <- prometheus.MustNewConstMetric(clusterStateLastPullDesc, prometheus.GaugeValue, timestampSeconds(s.LastPull), org, state)
+		}
+	}
+}
+
+func timestampSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
 	}
+	return float64(t.UnixNano()) / 1e9
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,14 +14,17 @@ import (
 	"github.com/go-openapi/strfmt"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/alerting/models"
 	"github.com/grafana/alerting/notify/nfstatus"
 )
 
@@ -835,3 +839,151 @@ func TestStatusForTestReceivers(t *testing.T) {
 		require.Equal(t, http.StatusOK, status)
 	})
 }
+
+type testReceiversNotifier struct {
+	sendResolved bool
+
+	mtx         sync.Mutex
+	alerts      [][]*types.Alert
+	keys        []string
+	groupLabels []model.LabelSet
+}
+
+func (n *testReceiversNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	key, _ := notify.GroupKey(ctx)
+	groupLabels, _ := notify.GroupLabels(ctx)
+	n.alerts = append(n.alerts, alerts)
+	n.keys = append(n.keys, key)
+	n.groupLabels = append(n.groupLabels, groupLabels)
+	return false, nil
+}
+
+func (n *testReceiversNotifier) SendResolved() bool {
+	return n.sendResolved
+}
+
+func TestTestReceivers(t *testing.T) {
+	setup := func(sendResolved bool) (*testReceiversNotifier, TestReceiversConfigBodyParams, func(*APIReceiver, *template.Template) ([]*nfstatus.Integration, error)) {
+		n := &testReceiversNotifier{sendResolved: sendResolved}
+		params := TestReceiversConfigBodyParams{
+			Receivers: []*APIReceiver{{
+				ConfigReceiver: ConfigReceiver{Name: "receiver"},
+				GrafanaIntegrations: GrafanaIntegrations{
+					Integrations: []*GrafanaIntegrationConfig{{Name: "integration", UID: "uid"}},
+				},
+			}},
+		}
+		build := func(*APIReceiver, *template.Template) ([]*nfstatus.Integration, error) {
+			return []*nfstatus.Integration{nfstatus.NewIntegration(n, n, "test", 0, "receiver")}, nil
+		}
+		return n, params, build
+	}
+
+	t.Run("default test alert", func(t *testing.T) {
+		n, params, build := setup(true)
+		res, status, err := TestReceivers(context.Background(), params, nil, build, "http://localhost")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, n.alerts, 1)
+		require.Len(t, n.alerts[0], 1)
+		require.Equal(t, model.LabelSet{"alertname": "TestAlert", "instance": "Grafana"}, n.alerts[0][0].Labels)
+		require.Equal(t, n.alerts[0][0].Labels, n.groupLabels[0])
+		require.Equal(t, *n.alerts[0][0], res.Alert)
+		require.Equal(t, []types.Alert{res.Alert}, res.Alerts)
+		require.Empty(t, res.Steps)
+	})
+
+	t.Run("multiple alerts with group labels and group key", func(t *testing.T) {
+		n, params, build := setup(true)
+		now := time.Now()
+		params.Alerts = []*TestReceiversConfigAlertParams{
+			{Labels: model.LabelSet{"instance": "a"}, StartsAt: now.Add(-time.Hour), ImageToken: "token"},
+			{Labels: model.LabelSet{"instance": "b"}, Status: model.AlertResolved},
+			{Labels: model.LabelSet{"instance": "c"}, EndsAt: now.Add(-time.Minute), StartsAt: now.Add(-time.Hour)},
+		}
+		params.GroupLabels = model.LabelSet{"alertname": "TestAlert"}
+		params.GroupKey = "{}:{alertname=\"TestAlert\"}"
+
+		res, status, err := TestReceivers(context.Background(), params, nil, build, "http://localhost")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, res.Alerts, 3)
+		require.Equal(t, []string{params.GroupKey}, n.keys)
+		require.Equal(t, []model.LabelSet{params.GroupLabels}, n.groupLabels)
+
+		alerts := n.alerts[0]
+		require.Len(t, alerts, 3)
+		require.Equal(t, model.LabelValue("token"), alerts[0].Annotations[models.ImageTokenAnnotation])
+		require.Equal(t, now.Add(-time.Hour), alerts[0].StartsAt)
+		require.False(t, alerts[0].Resolved())
+		require.True(t, alerts[1].Resolved())
+		require.True(t, alerts[2].Resolved())
+		require.Equal(t, model.AlertResolved, types.Alerts(alerts[1:]...).Status())
+	})
+
+	t.Run("group labels default to the common labels", func(t *testing.T) {
+		n, params, build := setup(true)
+		params.Alerts = []*TestReceiversConfigAlertParams{
+			{Labels: model.LabelSet{"instance": "a"}},
+			{Labels: model.LabelSet{"instance": "b"}},
+		}
+		_, _, err := TestReceivers(context.Background(), params, nil, build, "http://localhost")
+		require.NoError(t, err)
+		require.Equal(t, []model.LabelSet{{"alertname": "TestAlert"}}, n.groupLabels)
+	})
+
+	t.Run("invalid alerts", func(t *testing.T) {
+		now := time.Now()
+		for _, a := range []*TestReceiversConfigAlertParams{
+			{Status: model.AlertFiring, EndsAt: now.Add(-time.Minute)},
+			{Status: model.AlertResolved, EndsAt: now.Add(time.Hour)},
+			{Status: "unknown"},
+			{StartsAt: now.Add(-time.Minute), EndsAt: now.Add(-time.Hour)},
+		} {
+			_, params, build := setup(true)
+			params.Alerts = []*TestReceiversConfigAlertParams{a}
+			_, _, err := TestReceivers(context.Background(), params, nil, build, "http://localhost")
+			require.ErrorIs(t, err, ErrInvalidTestAlert)
+		}
+	})
+
+	t.Run("firing then resolved", func(t *testing.T) {
+		n, params, build := setup(true)
+		params.Alerts = []*TestReceiversConfigAlertParams{
+			{Labels: model.LabelSet{"instance": "a"}},
+			{Labels: model.LabelSet{"instance": "b"}, Status: model.AlertResolved},
+		}
+		params.ResolveAfterFiring = true
+
+		res, status, err := TestReceivers(context.Background(), params, nil, build, "http://localhost")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, n.alerts, 2)
+		require.Equal(t, model.AlertFiring, types.Alerts(n.alerts[0]...).Status())
+		require.Equal(t, model.AlertResolved, types.Alerts(n.alerts[1]...).Status())
+		require.Equal(t, n.keys[0], n.keys[1])
+
+		require.Len(t, res.Steps, 2)
+		require.Equal(t, model.AlertFiring, res.Steps[0].Status)
+		require.Equal(t, model.AlertResolved, res.Steps[1].Status)
+		for _, step := range res.Steps {
+			require.Len(t, step.Alerts, 2)
+			require.Equal(t, "ok", step.Receivers[0].Configs[0].Status)
+		}
+	})
+
+	t.Run("resolved notifications are skipped if the integration does not send them", func(t *testing.T) {
+		n, params, build := setup(false)
+		params.ResolveAfterFiring = true
+
+		res, status, err := TestReceivers(context.Background(), params, nil, build, "http://localhost")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, n.alerts, 1)
+		require.Len(t, res.Steps, 2)
+		require.Equal(t, "ok", res.Steps[0].Receivers[0].Configs[0].Status)
+		require.Equal(t, "skipped", res.Steps[1].Receivers[0].Configs[0].Status)
+	})
+}
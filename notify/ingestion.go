@@ -0,0 +1,17 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/grafana/alerting/ingestion"
+)
+
+// IngestAlerts converts a third-party payload into alerts using the adapter and puts them as PutAlerts does.
+// Alerts that fail validation are reported in an AlertValidationError.
+func (am *GrafanaAlertmanager) IngestAlerts(adapter ingestion.Adapter, payload []byte) error {
+	alerts, err := adapter.Convert(payload)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s payload: %w", adapter.Name(), err)
+	}
+	return am.PutAlerts(alerts)
+}
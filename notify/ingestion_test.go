@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/ingestion"
+)
+
+func TestIngestAlerts(t *testing.T) {
+	am, _ := setupAMTest(t)
+	r := prometheus.NewRegistry()
+	am.marker = types.NewMarker(r)
+	var err error
+	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, 15*time.Minute, nil, am.logger, r)
+	require.NoError(t, err)
+
+	adapter, err := ingestion.NewJSONPathAdapter(ingestion.JSONPathMapping{
+		Name:   "custom",
+		Alerts: "$[*]",
+		Labels: map[string]string{"alertname": "$.name"},
+	})
+	require.NoError(t, err)
+
+	t.Run("valid alerts are put", func(t *testing.T) {
+		require.NoError(t, am.IngestAlerts(adapter, []byte(`[{"name": "a"}, {"name": "b"}]`)))
+
+		iter := am.alerts.GetPending()
+		defer iter.Close()
+		var names []string
+		for a := range iter.Next() {
+			names = append(names, string(a.Labels["alertname"]))
+			require.Equal(t, "custom", string(a.Labels[ingestion.SourceLabel]))
+		}
+		require.ElementsMatch(t, []string{"a", "b"}, names)
+	})
+
+	t.Run("tags that are not valid label names are put", func(t *testing.T) {
+		require.NoError(t, am.IngestAlerts(ingestion.NewDatadogAdapter(), []byte(`{"title": "c", "tags": "availability-zone:us-east-1a,kube.namespace:default"}`)))
+
+		iter := am.alerts.GetPending()
+		defer iter.Close()
+		var found bool
+		for a := range iter.Next() {
+			if a.Labels["alertname"] == "c" {
+				found = true
+				require.Equal(t, "us-east-1a", string(a.Labels["availability_zone"]))
+				require.Equal(t, "default", string(a.Labels["kube_namespace"]))
+			}
+		}
+		require.True(t, found)
+	})
+
+	t.Run("validation errors are returned", func(t *testing.T) {
+		invalid, err := ingestion.NewJSONPathAdapter(ingestion.JSONPathMapping{
+			Name:     "custom",
+			Labels:   map[string]string{"alertname": "$.name"},
+			StartsAt: "$.start",
+			EndsAt:   "$.end",
+		})
+		require.NoError(t, err)
+		err = am.IngestAlerts(invalid, []byte(`{"name": "e", "start": 1700000100, "end": 1700000000}`))
+		var validationErr *AlertValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Len(t, validationErr.Alerts, 1)
+	})
+
+	t.Run("conversion errors are returned", func(t *testing.T) {
+		err := am.IngestAlerts(adapter, []byte(`not json`))
+		require.ErrorContains(t, err, "failed to convert custom payload")
+	})
+}
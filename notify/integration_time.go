@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SuppressedReasonIntegrationTimeInterval is the reason of notifications suppressed because the integration
+// is within one of its mute time intervals, or outside all of its active time intervals.
+const SuppressedReasonIntegrationTimeInterval = "integration_time_interval"
+
+// integrationSuppressedCounter counts the notifications suppressed by the time intervals of the integrations.
+// It has the name and the help of the metric of the Alertmanager that counts the suppressed notifications by reason,
+// and the reason as a constant label, so that both are gathered as the same metric family.
+type integrationSuppressedCounter struct {
+	prometheus.Counter
+}
+
+// Describe implements the prometheus.Collector interface. It sends no descriptor, which registers the counter as an
+// unchecked collector: the descriptor of the metric of the Alertmanager has the reason as a variable label, and
+// registering another descriptor with the same name would fail.
+func (integrationSuppressedCounter) Describe(chan<- *prometheus.Desc) {}
+
+func newIntegrationSuppressedCounter(r prometheus.Registerer) prometheus.Counter {
+	c := integrationSuppressedCounter{prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   "alertmanager",
+		Name:        "notifications_suppressed_total",
+		Help:        "The total number of notifications suppressed for being silenced, inhibited, outside of active time intervals or within muted time intervals.",
+		ConstLabels: prometheus.Labels{"reason": SuppressedReasonIntegrationTimeInterval},
+	})}
+	if r != nil {
+		r.MustRegister(c)
+	}
+	return c
+}
+
+// integrationTimeStage drops all alerts if the integration is muted at the time of the notification.
+type integrationTimeStage struct {
+	intervener *timeinterval.Intervener
+	mute       []string
+	active     []string
+	suppressed prometheus.Counter
+}
+
+func newIntegrationTimeStage(intervener *timeinterval.Intervener, mute, active []string, suppressed prometheus.Counter) *integrationTimeStage {
+	return &integrationTimeStage{
+		intervener: intervener,
+		mute:       mute,
+		active:     active,
+		suppressed: suppressed,
+	}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *integrationTimeStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	now, ok := notify.Now(ctx)
+	if !ok {
+		return ctx, alerts, errors.New("missing now timestamp")
+	}
+
+	muted, err := s.muted(now)
+	if err != nil {
+		return ctx, alerts, err
+	}
+	if muted {
+		s.suppressed.Add(float64(len(alerts)))
+		level.Debug(l).Log("msg", "Notifications not sent, integration is muted", "alerts", len(alerts))
+		return ctx, nil, nil
+	}
+	return ctx, alerts, nil
+}
+
+func (s *integrationTimeStage) muted(now time.Time) (bool, error) {
+	if len(s.mute) > 0 {
+		muted, err := s.intervener.Mutes(s.mute, now)
+		if err != nil || muted {
+			return muted, err
+		}
+	}
+	if len(s.active) > 0 {
+		// Intervener.Mutes returns true if now is within any of the intervals, that is, if the integration is active.
+		active, err := s.intervener.Mutes(s.active, now)
+		if err != nil {
+			return false, err
+		}
+		return !active, nil
+	}
+	return false, nil
+}
+
+// validateIntegrationTimeIntervals returns an error if an integration references a time interval that does not exist.
+func validateIntegrationTimeIntervals(integrations map[string][]*Integration, timeIntervals []TimeInterval, muteTimeIntervals []MuteTimeInterval) error {
+	names := make(map[string]struct{}, len(timeIntervals)+len(muteTimeIntervals))
+	for _, ti := range timeIntervals {
+		names[ti.Name] = struct{}{}
+	}
+	for _, mt := range muteTimeIntervals {
+		names[mt.Name] = struct{}{}
+	}
+	for receiver, ints := range integrations {
+		for _, i := range ints {
+			for _, name := range append(i.MuteTimeIntervals(), i.ActiveTimeIntervals()...) {
+				if _, ok := names[name]; !ok {
+					return fmt.Errorf("integration %s of receiver %q references undefined time interval %q", i.String(), receiver, name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/notify/nfstatus"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestIntegrationTimeStage(t *testing.T) {
+	am, reg := setupAMTest(t)
+
+	intervener := timeinterval.NewIntervener(map[string][]timeinterval.TimeInterval{
+		"day":   {{Times: []timeinterval.TimeRange{{StartMinute: 8 * 60, EndMinute: 22 * 60}}}},
+		"lunch": {{Times: []timeinterval.TimeRange{{StartMinute: 12 * 60, EndMinute: 13 * 60}}}},
+	})
+
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}}},
+	}
+	at := func(hour int) context.Context {
+		return notify.WithNow(context.Background(), time.Date(2024, 1, 1, hour, 30, 0, 0, time.UTC))
+	}
+
+	cases := []struct {
+		name     string
+		mute     []string
+		active   []string
+		hour     int
+		expMuted bool
+	}{
+		{name: "within active time interval", active: []string{"day"}, hour: 10},
+		{name: "outside active time interval", active: []string{"day"}, hour: 23, expMuted: true},
+		{name: "within mute time interval", mute: []string{"lunch"}, hour: 12, expMuted: true},
+		{name: "outside mute time interval", mute: []string{"lunch"}, hour: 14},
+		{name: "within both active and mute time intervals", mute: []string{"lunch"}, active: []string{"day"}, hour: 12, expMuted: true},
+	}
+
+	var suppressed int
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newIntegrationTimeStage(intervener, tc.mute, tc.active, am.integrationSuppressed)
+			_, res, err := s.Exec(at(tc.hour), log.NewNopLogger(), alerts...)
+			require.NoError(t, err)
+			if tc.expMuted {
+				suppressed += len(alerts)
+				require.Empty(t, res)
+				return
+			}
+			require.Equal(t, alerts, res)
+		})
+	}
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+# HELP alertmanager_notifications_suppressed_total The total number of notifications suppressed for being silenced, inhibited, outside of active time intervals or within muted time intervals.
+# TYPE alertmanager_notifications_suppressed_total counter
+alertmanager_notifications_suppressed_total{reason="integration_time_interval"} 6
+`), "alertmanager_notifications_suppressed_total"))
+	require.Equal(t, 6, suppressed)
+
+	t.Run("undefined time interval returns an error", func(t *testing.T) {
+		s := newIntegrationTimeStage(intervener, []string{"night"}, nil, am.integrationSuppressed)
+		_, _, err := s.Exec(at(10), log.NewNopLogger(), alerts...)
+		require.Error(t, err)
+	})
+}
+
+func TestValidateIntegrationTimeIntervals(t *testing.T) {
+	fn := &fakeNotifier{}
+	integrations := map[string][]*Integration{
+		"receiver": {
+			NewIntegration(fn, fn, "slack", 0, "receiver"),
+			NewIntegration(fn, fn, "pushover", 0, "receiver", nfstatus.WithTimeIntervals(nil, []string{"day"})),
+		},
+	}
+
+	require.NoError(t, validateIntegrationTimeIntervals(integrations, []TimeInterval{{Name: "day"}}, nil))
+	require.NoError(t, validateIntegrationTimeIntervals(integrations, nil, []MuteTimeInterval{{Name: "day"}}))
+	require.EqualError(t, validateIntegrationTimeIntervals(integrations, nil, nil), `integration pushover[0] of receiver "receiver" references undefined time interval "day"`)
+}
+
+// timeIntervalsTestConfig is a Configuration with a single receiver whose integration has the mute time intervals.
+type timeIntervalsTestConfig struct {
+	notifier notify.Notifier
+	mute     []string
+}
+
+func (c *timeIntervalsTestConfig) DispatcherLimits() DispatcherLimits        { return nil }
+func (c *timeIntervalsTestConfig) InhibitRules() []InhibitRule               { return nil }
+func (c *timeIntervalsTestConfig) TimeIntervals() []TimeInterval             { return nil }
+func (c *timeIntervalsTestConfig) MuteTimeIntervals() []MuteTimeInterval     { return nil }
+func (c *timeIntervalsTestConfig) Templates() []templates.TemplateDefinition { return nil }
+func (c *timeIntervalsTestConfig) Hash() [16]byte                            { return [16]byte{} }
+func (c *timeIntervalsTestConfig) Raw() []byte                               { return nil }
+func (c *timeIntervalsTestConfig) Receivers() []*APIReceiver {
+	return []*APIReceiver{{ConfigReceiver: ConfigReceiver{Name: "receiver"}}}
+}
+
+func (c *timeIntervalsTestConfig) RoutingTree() *Route {
+	groupWait := model.Duration(0)
+	return &Route{Receiver: "receiver", GroupBy: []model.LabelName{"alertname"}, GroupWait: &groupWait}
+}
+
+func (c *timeIntervalsTestConfig) BuildReceiverIntegrationsFunc() func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error) {
+	return func(next *APIReceiver, _ *templates.Template) ([]*Integration, error) {
+		return []*Integration{NewIntegration(c.notifier, &fakeNotifier{}, "webhook", 0, next.Name, nfstatus.WithTimeIntervals(c.mute, nil))}, nil
+	}
+}
+
+type countingNotifier struct {
+	notified atomic.Int64
+}
+
+func (n *countingNotifier) Notify(context.Context, ...*types.Alert) (bool, error) {
+	n.notified.Add(1)
+	return false, nil
+}
+
+func TestApplyConfig_UndefinedIntegrationTimeInterval(t *testing.T) {
+	am, _ := setupAMTest(t)
+	t.Cleanup(am.StopAndWait)
+
+	n := &countingNotifier{}
+	put := func(name string) {
+		now := time.Now()
+		require.NoError(t, am.PutAlerts(amv2.PostableAlerts{{
+			Alert:    amv2.Alert{Labels: amv2.LabelSet{"alertname": name}},
+			StartsAt: strfmt.DateTime(now),
+			EndsAt:   strfmt.DateTime(now.Add(time.Hour)),
+		}}))
+	}
+
+	am.WithLock(func() {
+		require.NoError(t, am.ApplyConfig(&timeIntervalsTestConfig{notifier: n}))
+	})
+	put("a")
+	require.Eventually(t, func() bool { return n.notified.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	am.WithLock(func() {
+		err := am.ApplyConfig(&timeIntervalsTestConfig{notifier: n, mute: []string{"undefined"}})
+		require.EqualError(t, err, `integration webhook[0] of receiver "receiver" references undefined time interval "undefined"`)
+	})
+
+	// The previous configuration keeps routing the alerts.
+	put("b")
+	require.Eventually(t, func() bool { return n.notified.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,101 @@
+package notify
+
+// The integrations register themselves when they are imported, see receivers.RegisterIntegration.
+import (
+	"github.com/grafana/alerting/receivers/alertmanager"
+	"github.com/grafana/alerting/receivers/dinding"
+	"github.com/grafana/alerting/receivers/discord"
+	"github.com/grafana/alerting/receivers/email"
+	"github.com/grafana/alerting/receivers/googlechat"
+	"github.com/grafana/alerting/receivers/kafka"
+	"github.com/grafana/alerting/receivers/line"
+	"github.com/grafana/alerting/receivers/mqtt"
+	"github.com/grafana/alerting/receivers/oncall"
+	"github.com/grafana/alerting/receivers/opsgenie"
+	"github.com/grafana/alerting/receivers/pagerduty"
+	"github.com/grafana/alerting/receivers/pushover"
+	"github.com/grafana/alerting/receivers/sensugo"
+	"github.com/grafana/alerting/receivers/slack"
+	"github.com/grafana/alerting/receivers/sns"
+	"github.com/grafana/alerting/receivers/teams"
+	"github.com/grafana/alerting/receivers/telegram"
+	"github.com/grafana/alerting/receivers/threema"
+	"github.com/grafana/alerting/receivers/victorops"
+	"github.com/grafana/alerting/receivers/webex"
+	"github.com/grafana/alerting/receivers/webhook"
+	"github.com/grafana/alerting/receivers/wecom"
+)
+
+// setTypedConfigs sets the typed fields of the configuration from its integrations.
+func (c *GrafanaReceiverConfig) setTypedConfigs() {
+	c.AlertmanagerConfigs = ConfigsOf[alertmanager.Config](*c)
+	c.DingdingConfigs = ConfigsOf[dinding.Config](*c)
+	c.DiscordConfigs = ConfigsOf[discord.Config](*c)
+	c.EmailConfigs = ConfigsOf[email.Config](*c)
+	c.GooglechatConfigs = ConfigsOf[googlechat.Config](*c)
+	c.KafkaConfigs = ConfigsOf[kafka.Config](*c)
+	c.LineConfigs = ConfigsOf[line.Config](*c)
+	c.OpsgenieConfigs = ConfigsOf[opsgenie.Config](*c)
+	c.MqttConfigs = ConfigsOf[mqtt.Config](*c)
+	c.PagerdutyConfigs = ConfigsOf[pagerduty.Config](*c)
+	c.OnCallConfigs = ConfigsOf[oncall.Config](*c)
+	c.PushoverConfigs = ConfigsOf[pushover.Config](*c)
+	c.SensugoConfigs = ConfigsOf[sensugo.Config](*c)
+	c.SlackConfigs = ConfigsOf[slack.Config](*c)
+	c.SNSConfigs = ConfigsOf[sns.Config](*c)
+	c.TeamsConfigs = ConfigsOf[teams.Config](*c)
+	c.TelegramConfigs = ConfigsOf[telegram.Config](*c)
+	c.ThreemaConfigs = ConfigsOf[threema.Config](*c)
+	c.VictoropsConfigs = ConfigsOf[victorops.Config](*c)
+	c.WebhookConfigs = ConfigsOf[webhook.Config](*c)
+	c.WecomConfigs = ConfigsOf[wecom.Config](*c)
+	c.WebexConfigs = ConfigsOf[webex.Config](*c)
+}
+
+// integrations returns the integrations of the configuration, or the integrations of its typed fields if it has none.
+func (c GrafanaReceiverConfig) integrations() []*NotifierConfig[any] {
+	if len(c.Integrations) > 0 {
+		return c.Integrations
+	}
+	var res []*NotifierConfig[any]
+	res = appendIntegrations(res, "prometheus-alertmanager", c.AlertmanagerConfigs)
+	res = appendIntegrations(res, "dingding", c.DingdingConfigs)
+	res = appendIntegrations(res, "discord", c.DiscordConfigs)
+	res = appendIntegrations(res, "email", c.EmailConfigs)
+	res = appendIntegrations(res, "googlechat", c.GooglechatConfigs)
+	res = appendIntegrations(res, "kafka", c.KafkaConfigs)
+	res = appendIntegrations(res, "line", c.LineConfigs)
+	res = appendIntegrations(res, "opsgenie", c.OpsgenieConfigs)
+	res = appendIntegrations(res, "mqtt", c.MqttConfigs)
+	res = appendIntegrations(res, "pagerduty", c.PagerdutyConfigs)
+	res = appendIntegrations(res, "oncall", c.OnCallConfigs)
+	res = appendIntegrations(res, "pushover", c.PushoverConfigs)
+	res = appendIntegrations(res, "sensugo", c.SensugoConfigs)
+	res = appendIntegrations(res, "slack", c.SlackConfigs)
+	res = appendIntegrations(res, "sns", c.SNSConfigs)
+	res = appendIntegrations(res, "teams", c.TeamsConfigs)
+	res = appendIntegrations(res, "telegram", c.TelegramConfigs)
+	res = appendIntegrations(res, "threema", c.ThreemaConfigs)
+	res = appendIntegrations(res, "victorops", c.VictoropsConfigs)
+	res = appendIntegrations(res, "webhook", c.WebhookConfigs)
+	res = appendIntegrations(res, "wecom", c.WecomConfigs)
+	res = appendIntegrations(res, "webex", c.WebexConfigs)
+	return res
+}
+
+// appendIntegrations appends the configurations of the type of integration to res. Their type is set if it is empty.
+func appendIntegrations[T any](res []*NotifierConfig[any], typ string, cfgs []*NotifierConfig[T]) []*NotifierConfig[any] {
+	for _, cfg := range cfgs {
+		meta := cfg.Metadata
+		if meta.Type == "" {
+			meta.Type = typ
+		}
+		res = append(res, &NotifierConfig[any]{
+			Metadata:            meta,
+			Settings:            cfg.Settings,
+			MuteTimeIntervals:   cfg.MuteTimeIntervals,
+			ActiveTimeIntervals: cfg.ActiveTimeIntervals,
+		})
+	}
+	return res
+}
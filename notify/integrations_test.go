@@ -0,0 +1,272 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/receivers/email"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestRegisteredIntegrations(t *testing.T) {
+	var names []string
+	for _, it := range receivers.Integrations() {
+		names = append(names, it.Name)
+	}
+	var known []string
+	for _, c := range AllKnownConfigsForTesting {
+		known = append(known, c.NotifierType)
+	}
+	sort.Strings(known)
+	require.Subset(t, names, known)
+	require.Len(t, known, len(names), "all integrations must have a configuration in AllKnownConfigsForTesting")
+
+	t.Run("secure fields include all decrypted settings", func(t *testing.T) {
+		for _, c := range AllKnownConfigsForTesting {
+			it, ok := receivers.GetIntegration(c.NotifierType)
+			require.True(t, ok)
+			var decrypted []string
+			_, err := it.NewConfig(json.RawMessage(c.Config), func(key string, fallback string) string {
+				decrypted = append(decrypted, key)
+				return fallback
+			})
+			require.NoError(t, err)
+			require.Subsetf(t, it.SecureFields, decrypted, "integration %s", it.Name)
+		}
+	})
+
+	t.Run("integrations that send with their own clients are not previewable", func(t *testing.T) {
+		var unpreviewable []string
+		for _, it := range receivers.Integrations() {
+			if it.Previewable == nil {
+				unpreviewable = append(unpreviewable, it.Name)
+			}
+		}
+		require.Equal(t, []string{"mqtt", "prometheus-alertmanager", "sns"}, unpreviewable)
+	})
+}
+
+// configSettings are the keys in the settings of the fields of the configurations that have no JSON tags.
+// The fields that are not set from the settings have an empty key.
+var configSettings = map[string]map[string]string{
+	"alertmanager.Config": {"URLs": "url", "User": "basicAuthUser", "Password": "basicAuthPassword"},
+	"email.Config":        {"SingleEmail": "singleEmail", "Addresses": "addresses", "Message": "message", "Subject": "subject"},
+	"oncall.Config":       webhookConfigSettings,
+	"opsgenie.Config": {
+		"APIKey": "apiKey", "APIUrl": "apiUrl", "Message": "message", "Description": "description", "AutoClose": "autoClose",
+		"OverridePriority": "overridePriority", "SendTagsAs": "sendTagsAs", "Responders": "responders",
+	},
+	"pushover.Config": {
+		"UserKey": "userKey", "APIToken": "apiToken", "AlertingPriority": "priority", "OkPriority": "okPriority", "Retry": "retry",
+		"Expire": "expire", "Device": "device", "AlertingSound": "sound", "OkSound": "okSound", "Upload": "uploadImage",
+		"Title": "title", "Message": "message",
+	},
+	"webhook.Config":      webhookConfigSettings,
+	"receivers.TLSConfig": {"ServerName": ""},
+}
+
+var webhookConfigSettings = map[string]string{
+	"URL": "url", "HTTPMethod": "httpMethod", "MaxAlerts": "maxAlerts", "AuthorizationScheme": "authorization_scheme",
+	"AuthorizationCredentials": "authorization_credentials", "User": "username", "Password": "password",
+	"Title": "title", "Message": "message", "TLSConfig": "tlsConfig",
+}
+
+// TestIntegrationFields fails if the configuration of an integration changes without its fields.
+func TestIntegrationFields(t *testing.T) {
+	for _, c := range AllKnownConfigsForTesting {
+		t.Run(c.NotifierType, func(t *testing.T) {
+			it, ok := receivers.GetIntegration(c.NotifierType)
+			require.True(t, ok)
+
+			cfg, err := it.NewConfig(json.RawMessage(c.Config), func(_, fallback string) string { return fallback })
+			require.NoError(t, err)
+			requireConfigFields(t, reflect.TypeOf(cfg), it.Fields)
+
+			var settings map[string]any
+			require.NoError(t, json.Unmarshal([]byte(c.Config), &settings))
+			requireSettingsFields(t, "", settings, it.Fields)
+			if c.Secrets != "" {
+				var secrets map[string]string
+				require.NoError(t, json.Unmarshal([]byte(c.Secrets), &secrets))
+				for key := range secrets {
+					require.Containsf(t, it.SecureFields, key, "secret %s is not a secure field", key)
+				}
+			}
+
+			_, err = json.Marshal(it.JSONSchema())
+			require.NoError(t, err)
+		})
+	}
+}
+
+// requireConfigFields requires that the fields describe all the settings of the configuration of type typ.
+func requireConfigFields(t *testing.T, typ reflect.Type, fields []receivers.Field) {
+	t.Helper()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	described := make(map[string]bool, len(fields))
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key, ok := configSettings[typ.String()][sf.Name]
+		if !ok {
+			tag := sf.Tag.Get("json")
+			require.NotEmptyf(t, tag, "field %s of %s has no JSON tag, add its setting to configSettings", sf.Name, typ)
+			key, _, _ = strings.Cut(tag, ",")
+		}
+		if key == "" || key == "-" {
+			continue
+		}
+		idx := slices.IndexFunc(fields, func(f receivers.Field) bool { return f.Name == key })
+		require.GreaterOrEqualf(t, idx, 0, "setting %s of field %s of %s is not described by the fields of the integration", key, sf.Name, typ)
+		f := fields[idx]
+		described[f.Name] = true
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch f.Type {
+		case receivers.FieldTypeString:
+			// Lists are set from comma-separated strings.
+			require.Truef(t, ft.Kind() == reflect.String || ft.Kind() == reflect.Slice, "setting %s of %s is a %s", key, typ, ft)
+		case receivers.FieldTypeBool:
+			require.Equalf(t, reflect.Bool, ft.Kind(), "setting %s of %s", key, typ)
+		case receivers.FieldTypeInteger:
+			require.Truef(t, ft.Kind() == reflect.String || ft.ConvertibleTo(reflect.TypeOf(int64(0))), "setting %s of %s is a %s", key, typ, ft)
+		case receivers.FieldTypeMap:
+			require.Equalf(t, reflect.Map, ft.Kind(), "setting %s of %s", key, typ)
+		case receivers.FieldTypeObject:
+			require.Equalf(t, reflect.Struct, ft.Kind(), "setting %s of %s", key, typ)
+			requireConfigFields(t, ft, f.Fields)
+		case receivers.FieldTypeArray:
+			require.Equalf(t, reflect.Slice, ft.Kind(), "setting %s of %s", key, typ)
+			requireConfigFields(t, ft.Elem(), f.Fields)
+		default:
+			require.Failf(t, "unknown field type", "setting %s of %s has type %s", key, typ, f.Type)
+		}
+	}
+	for _, f := range fields {
+		require.Truef(t, described[f.Name], "setting %s is described but is not in %s", f.Name, typ)
+	}
+}
+
+// requireSettingsFields requires that the settings are described by the fields.
+func requireSettingsFields(t *testing.T, path string, settings map[string]any, fields []receivers.Field) {
+	t.Helper()
+	for key, value := range settings {
+		idx := slices.IndexFunc(fields, func(f receivers.Field) bool { return f.Name == key })
+		require.GreaterOrEqualf(t, idx, 0, "setting %s%s is not described", path, key)
+		f := fields[idx]
+		switch v := value.(type) {
+		case string:
+			require.Containsf(t, []receivers.FieldType{receivers.FieldTypeString, receivers.FieldTypeInteger}, f.Type, "setting %s%s", path, key)
+		case bool:
+			require.Equalf(t, receivers.FieldTypeBool, f.Type, "setting %s%s", path, key)
+		case float64:
+			require.Equalf(t, receivers.FieldTypeInteger, f.Type, "setting %s%s", path, key)
+		case map[string]any:
+			if f.Type == receivers.FieldTypeMap {
+				continue
+			}
+			require.Equalf(t, receivers.FieldTypeObject, f.Type, "setting %s%s", path, key)
+			requireSettingsFields(t, path+key+".", v, f.Fields)
+		case []any:
+			require.Equalf(t, receivers.FieldTypeArray, f.Type, "setting %s%s", path, key)
+			for i, item := range v {
+				requireSettingsFields(t, fmt.Sprintf("%s%s[%d].", path, key, i), item.(map[string]any), f.Fields)
+			}
+		}
+		if len(f.Enum) > 0 && f.Type == receivers.FieldTypeString {
+			// Some values, such as the parse modes of Telegram, are case-insensitive.
+			require.Truef(t, slices.ContainsFunc(f.Enum, func(e any) bool {
+				return strings.EqualFold(e.(string), value.(string))
+			}), "setting %s%s must be one of %v", path, key, f.Enum)
+		}
+	}
+}
+
+type externalSettings struct {
+	Channel string `json:"channel"`
+}
+
+type externalNotifier struct {
+	settings externalSettings
+	deps     receivers.NotifierDependencies
+}
+
+func (n *externalNotifier) Notify(context.Context, ...*types.Alert) (bool, error) { return true, nil }
+func (n *externalNotifier) SendResolved() bool                                    { return true }
+
+func TestBuildReceiverIntegrations_ExternalIntegration(t *testing.T) {
+	receivers.RegisterIntegration(receivers.IntegrationType[externalSettings]{
+		Name:         "external-test",
+		Dependencies: receivers.TemplateDependency | receivers.WebhookSenderDependency,
+		NewConfig: func(settings json.RawMessage, _ receivers.DecryptFunc) (externalSettings, error) {
+			var s externalSettings
+			return s, json.Unmarshal(settings, &s)
+		},
+		NewNotifier: func(settings externalSettings, _ receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return &externalNotifier{settings: settings, deps: deps}
+		},
+	})
+	t.Cleanup(func() { receivers.UnregisterIntegration("external-test") })
+
+	api := &APIReceiver{
+		ConfigReceiver: ConfigReceiver{Name: "receiver"},
+		GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{
+			{UID: "a", Name: "a", Type: "external-test", Settings: json.RawMessage(`{"channel": "a"}`)},
+			{UID: "b", Name: "b", Type: "email", Settings: json.RawMessage(`{"addresses": "test@grafana.com"}`)},
+			{UID: "c", Name: "c", Type: "External-Test", Settings: json.RawMessage(`{"channel": "c"}`)},
+		}},
+	}
+	cfg, err := BuildReceiverConfiguration(context.Background(), api, NoopDecrypt)
+	require.NoError(t, err)
+	require.Len(t, cfg.Integrations, 3)
+	external := ConfigsOf[externalSettings](cfg)
+	require.Len(t, external, 2)
+	require.Equal(t, "a", external[0].Settings.Channel)
+	require.Equal(t, "c", external[1].Settings.Channel)
+	require.Equal(t, ConfigsOf[email.Config](cfg), cfg.EmailConfigs)
+
+	tmpl := templates.ForTests(t)
+	integrations, err := BuildReceiverIntegrations(
+		cfg,
+		tmpl,
+		&images.FakeProvider{},
+		func(string, ...interface{}) logging.Logger { return &logging.FakeLogger{} },
+		func(receivers.Metadata) (receivers.WebhookSender, error) {
+			return receivers.MockNotificationService(), nil
+		},
+		func(receivers.Metadata) (receivers.EmailSender, error) {
+			return receivers.MockNotificationService(), nil
+		},
+		1,
+		"version",
+	)
+	require.NoError(t, err)
+	require.Len(t, integrations, 3)
+
+	// The integrations are in the order of the configuration, and indexed by type.
+	require.Equal(t, "external-test", integrations[0].Name())
+	require.Equal(t, 0, integrations[0].Index())
+	require.Equal(t, "email", integrations[1].Name())
+	require.Equal(t, 0, integrations[1].Index())
+	require.Equal(t, "External-Test", integrations[2].Name())
+	require.Equal(t, 1, integrations[2].Index())
+}
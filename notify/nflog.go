@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+)
+
+// NflogState returns the state of the notification log.
+func (am *GrafanaAlertmanager) NflogState() (NflogState, error) {
+	b, err := am.notificationLog.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeNflogState(bytes.NewReader(b))
+}
+
+// NflogState copied from state in prometheus-alertmanager/nflog/nflog.go.
+type NflogState map[string]*nflogpb.MeshEntry
+
+func (s NflogState) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, e := range s {
+		if _, err := pbutil.WriteDelimited(&buf, e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeNflogState copied from decodeState in prometheus-alertmanager/nflog/nflog.go.
+func DecodeNflogState(r io.Reader) (NflogState, error) {
+	st := NflogState{}
+	for {
+		var e nflogpb.MeshEntry
+		_, err := pbutil.ReadDelimited(r, &e)
+		if err == nil {
+			if e.Entry == nil || e.Entry.Receiver == nil {
+				return nil, nflog.ErrInvalidState
+			}
+			st[nflogStateKey(string(e.Entry.GroupKey), e.Entry.Receiver)] = &e
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		return nil, err
+	}
+	return st, nil
+}
+
+// nflogStateKey copied from stateKey in prometheus-alertmanager/nflog/nflog.go.
+func nflogStateKey(k string, r *nflogpb.Receiver) string {
+	return fmt.Sprintf("%s:%s", k, fmt.Sprintf("%s/%s/%d", r.GroupName, r.Integration, r.Idx))
+}
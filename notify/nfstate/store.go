@@ -0,0 +1,167 @@
+// Package nfstate stores the notification states of the integrations, the identifiers of the messages
+// sent for each aggregation group, and replicates them across the cluster like the notification log.
+package nfstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+// Entry is the notification state of an integration for an aggregation group.
+// Deleted entries are kept as tombstones until they expire, so that deletes are replicated.
+type Entry struct {
+	GroupKey       string                      `json:"groupKey"`
+	IntegrationUID string                      `json:"integrationUid"`
+	State          receivers.NotificationState `json:"state,omitempty"`
+	Deleted        bool                        `json:"deleted,omitempty"`
+	UpdatedAt      time.Time                   `json:"updatedAt"`
+	ExpiresAt      time.Time                   `json:"expiresAt"`
+}
+
+func (e Entry) key() receivers.NotificationStateKey {
+	return receivers.NotificationStateKey{GroupKey: e.GroupKey, IntegrationUID: e.IntegrationUID}
+}
+
+// Store is an in-memory receivers.NotificationStateStore. It implements cluster.State so that it can be
+// replicated with a ClusterPeer, and State so that it can be snapshotted with MaintenanceOptions.
+type Store struct {
+	mtx       sync.RWMutex
+	entries   map[receivers.NotificationStateKey]Entry
+	retention time.Duration
+	broadcast func([]byte)
+	now       func() time.Time
+}
+
+var _ receivers.NotificationStateStore = (*Store)(nil)
+
+// New returns a Store that keeps the states for the retention after their last update.
+// The store is initialized from the snapshot, as returned by MarshalBinary, if not empty.
+func New(retention time.Duration, snapshot io.Reader) (*Store, error) {
+	s := &Store{
+		entries:   map[receivers.NotificationStateKey]Entry{},
+		retention: retention,
+		broadcast: func([]byte) {},
+		now:       time.Now,
+	}
+	if snapshot == nil {
+		return s, nil
+	}
+	b, err := io.ReadAll(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if len(b) == 0 {
+		return s, nil
+	}
+	if err := s.Merge(b); err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// SetBroadcast sets the function that replicates the updates to the other peers.
+func (s *Store) SetBroadcast(f func([]byte)) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.broadcast = f
+}
+
+// Get returns the state for the key, if any.
+func (s *Store) Get(key receivers.NotificationStateKey) (receivers.NotificationState, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	e, ok := s.entries[key]
+	if !ok || e.Deleted || !e.ExpiresAt.After(s.now()) {
+		return nil, false
+	}
+	res := make(receivers.NotificationState, len(e.State))
+	for k, v := range e.State {
+		res[k] = v
+	}
+	return res, true
+}
+
+// Set sets the state for the key and replicates it.
+func (s *Store) Set(key receivers.NotificationStateKey, state receivers.NotificationState) error {
+	return s.update(key, state, false)
+}
+
+// Delete deletes the state for the key and replicates the delete.
+func (s *Store) Delete(key receivers.NotificationStateKey) error {
+	return s.update(key, nil, true)
+}
+
+func (s *Store) update(key receivers.NotificationStateKey, state receivers.NotificationState, deleted bool) error {
+	now := s.now()
+	e := Entry{
+		GroupKey:       key.GroupKey,
+		IntegrationUID: key.IntegrationUID,
+		State:          state,
+		Deleted:        deleted,
+		UpdatedAt:      now,
+		ExpiresAt:      now.Add(s.retention),
+	}
+	b, err := json.Marshal([]Entry{e})
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	s.entries[key] = e
+	broadcast := s.broadcast
+	s.mtx.Unlock()
+	broadcast(b)
+	return nil
+}
+
+// GC deletes the expired states, and returns how many were deleted.
+func (s *Store) GC() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	now := s.now()
+	n := 0
+	for k, e := range s.entries {
+		if !e.ExpiresAt.After(now) {
+			delete(s.entries, k)
+			n++
+		}
+	}
+	return n
+}
+
+// MarshalBinary returns all the states, including the tombstones of deleted states. It implements cluster.State.
+func (s *Store) MarshalBinary() ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	return json.Marshal(entries)
+}
+
+// Merge merges the states of another peer, as returned by MarshalBinary. The most recently updated state wins.
+// It implements cluster.State.
+func (s *Store) Merge(b []byte) error {
+	var entries []Entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	now := s.now()
+	for _, e := range entries {
+		if !e.ExpiresAt.After(now) {
+			continue
+		}
+		if prev, ok := s.entries[e.key()]; ok && !e.UpdatedAt.After(prev.UpdatedAt) {
+			continue
+		}
+		s.entries[e.key()] = e
+	}
+	return nil
+}
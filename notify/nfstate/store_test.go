@@ -0,0 +1,99 @@
+package nfstate
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	t.Helper()
+	s, err := New(time.Hour, nil)
+	require.NoError(t, err)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestStore(t *testing.T) {
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+	var broadcasts [][]byte
+	s.SetBroadcast(func(b []byte) { broadcasts = append(broadcasts, b) })
+	key := receivers.NotificationStateKey{GroupKey: "{}:{alertname=\"a\"}", IntegrationUID: "uid"}
+
+	_, ok := s.Get(key)
+	require.False(t, ok)
+
+	require.NoError(t, s.Set(key, receivers.NotificationState{"ts": "1"}))
+	state, ok := s.Get(key)
+	require.True(t, ok)
+	require.Equal(t, receivers.NotificationState{"ts": "1"}, state)
+	require.Len(t, broadcasts, 1)
+
+	t.Run("state is a copy", func(t *testing.T) {
+		state["ts"] = "2"
+		state, ok := s.Get(key)
+		require.True(t, ok)
+		require.Equal(t, "1", state["ts"])
+	})
+
+	t.Run("other integrations have their own state", func(t *testing.T) {
+		_, ok := s.Get(receivers.NotificationStateKey{GroupKey: key.GroupKey, IntegrationUID: "other"})
+		require.False(t, ok)
+	})
+
+	t.Run("deletes are replicated as tombstones", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		require.NoError(t, s.Delete(key))
+		_, ok := s.Get(key)
+		require.False(t, ok)
+		require.Len(t, broadcasts, 2)
+
+		peer := newTestStore(t, &now)
+		require.NoError(t, peer.Merge(broadcasts[0]))
+		_, ok = peer.Get(key)
+		require.True(t, ok)
+		require.NoError(t, peer.Merge(broadcasts[1]))
+		_, ok = peer.Get(key)
+		require.False(t, ok)
+
+		// An older update does not override the delete.
+		require.NoError(t, peer.Merge(broadcasts[0]))
+		_, ok = peer.Get(key)
+		require.False(t, ok)
+	})
+
+	t.Run("states expire after the retention", func(t *testing.T) {
+		require.NoError(t, s.Set(key, receivers.NotificationState{"ts": "3"}))
+		now = now.Add(time.Hour)
+		_, ok := s.Get(key)
+		require.False(t, ok)
+		require.Equal(t, 1, s.GC())
+		b, err := s.MarshalBinary()
+		require.NoError(t, err)
+		require.JSONEq(t, `[]`, string(b))
+	})
+}
+
+func TestStore_Snapshot(t *testing.T) {
+	now := time.Now()
+	s := newTestStore(t, &now)
+	key := receivers.NotificationStateKey{GroupKey: "group", IntegrationUID: "uid"}
+	require.NoError(t, s.Set(key, receivers.NotificationState{"message_id": "42"}))
+
+	b, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	restored, err := New(time.Hour, bytes.NewReader(b))
+	require.NoError(t, err)
+	state, ok := restored.Get(key)
+	require.True(t, ok)
+	require.Equal(t, receivers.NotificationState{"message_id": "42"}, state)
+
+	_, err = New(time.Hour, bytes.NewReader([]byte("invalid")))
+	require.Error(t, err)
+}
@@ -8,26 +8,94 @@ import (
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// tracerName is the name of the tracer of the notification attempts.
+const tracerName = "github.com/grafana/alerting/notify/nfstatus"
+
+type tracerProviderKey struct{}
+
+// WithTracerProvider returns a context with the TracerProvider of the notification attempts.
+// The TracerProvider is not taken from the span in the context, as the upstream integrations
+// add spans of the global TracerProvider.
+func WithTracerProvider(ctx context.Context, tp trace.TracerProvider) context.Context {
+	return context.WithValue(ctx, tracerProviderKey{}, tp)
+}
+
+// TracerProviderFromContext returns the TracerProvider in the context, or a no-op TracerProvider if none.
+func TracerProviderFromContext(ctx context.Context) trace.TracerProvider {
+	if tp, ok := ctx.Value(tracerProviderKey{}).(trace.TracerProvider); ok {
+		return tp
+	}
+	return noop.NewTracerProvider()
+}
+
 // Integration wraps an upstream notify.Integration, adding the ability to
 // capture notification status.
 type Integration struct {
-	status      *statusCaptureNotifier
-	integration *notify.Integration
+	status       *statusCaptureNotifier
+	integration  *notify.Integration
+	uid          string
+	splitByAlert bool
+
+	muteTimeIntervals   []string
+	activeTimeIntervals []string
+	secrets             []string
+}
+
+// IntegrationOption configures optional behaviour of an Integration.
+type IntegrationOption func(*Integration)
+
+// WithSplitByAlert sets whether the integration is notified once per alert instead of once per aggregation group.
+func WithSplitByAlert(split bool) IntegrationOption {
+	return func(i *Integration) {
+		i.splitByAlert = split
+	}
+}
+
+// WithUID sets the UID of the integration, which is added to the spans of the notification attempts.
+func WithUID(uid string) IntegrationOption {
+	return func(i *Integration) {
+		i.uid = uid
+		i.status.uid = uid
+	}
+}
+
+// WithTimeIntervals sets the names of the time intervals during which, and outside of which, the integration is not notified.
+func WithTimeIntervals(mute, active []string) IntegrationOption {
+	return func(i *Integration) {
+		i.muteTimeIntervals = mute
+		i.activeTimeIntervals = active
+	}
+}
+
+// WithSecrets sets the decrypted secure values of the integration, which are redacted from the audit records of
+// the notification attempts.
+func WithSecrets(secrets []string) IntegrationOption {
+	return func(i *Integration) {
+		i.secrets = secrets
+	}
 }
 
 // NewIntegration returns a new integration.
-func NewIntegration(notifier notify.Notifier, rs notify.ResolvedSender, name string, idx int, receiverName string) *Integration {
+func NewIntegration(notifier notify.Notifier, rs notify.ResolvedSender, name string, idx int, receiverName string, opts ...IntegrationOption) *Integration {
 	// Wrap the provided Notifier with our own, which will capture notification attempt errors.
-	status := &statusCaptureNotifier{upstream: notifier}
+	status := &statusCaptureNotifier{upstream: notifier, name: name, receiverName: receiverName}
 
 	integration := notify.NewIntegration(status, rs, name, idx, receiverName)
 
-	return &Integration{
+	i := &Integration{
 		status:      status,
 		integration: integration,
 	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
 }
 
 // Integration returns the wrapped notify.Integration
@@ -57,6 +125,31 @@ func (i *Integration) Index() int {
 	return i.integration.Index()
 }
 
+// UID returns the UID of the integration, if set.
+func (i *Integration) UID() string {
+	return i.uid
+}
+
+// SplitByAlert returns true if the integration must be notified once per alert.
+func (i *Integration) SplitByAlert() bool {
+	return i.splitByAlert
+}
+
+// MuteTimeIntervals returns the names of the time intervals during which the integration is not notified.
+func (i *Integration) MuteTimeIntervals() []string {
+	return i.muteTimeIntervals
+}
+
+// ActiveTimeIntervals returns the names of the time intervals outside of which the integration is not notified.
+func (i *Integration) ActiveTimeIntervals() []string {
+	return i.activeTimeIntervals
+}
+
+// Secrets returns the decrypted secure values of the integration, if set.
+func (i *Integration) Secrets() []string {
+	return i.secrets
+}
+
 // String implements the Stringer interface.
 func (i *Integration) String() string {
 	return i.integration.String()
@@ -78,8 +171,12 @@ func GetIntegrations(integrations []*Integration) []*notify.Integration {
 }
 
 // statusCaptureNotifier is used to wrap a notify.Notifer and capture information about attempts.
+// If the context carries a TracerProvider, every attempt is traced with a child span of the span in the context.
 type statusCaptureNotifier struct {
-	upstream notify.Notifier
+	upstream     notify.Notifier
+	name         string
+	uid          string
+	receiverName string
 
 	mtx                       sync.RWMutex
 	lastNotifyAttempt         time.Time
@@ -89,10 +186,24 @@ type statusCaptureNotifier struct {
 
 // Notify implements the Notifier interface.
 func (n *statusCaptureNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	ctx, span := TracerProviderFromContext(ctx).Tracer(tracerName).Start(ctx, "notify.integration.attempt", trace.WithAttributes(
+		attribute.String("alerting.receiver", n.receiverName),
+		attribute.String("alerting.integration.type", n.name),
+		attribute.String("alerting.integration.uid", n.uid),
+		attribute.Int("alerting.alerts.count", len(alerts)),
+	))
+	defer span.End()
+
 	start := time.Now()
 	retry, err := n.upstream.Notify(ctx, alerts...)
 	duration := time.Since(start)
 
+	span.SetAttributes(attribute.Bool("alerting.notify.retry", retry))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
 	n.mtx.Lock()
 	defer n.mtx.Unlock()
 
@@ -40,6 +40,7 @@ func TestIntegration(t *testing.T) {
 	assert.Equal(t, false, integration.SendResolved())
 	rs.sendResolved = true
 	assert.Equal(t, true, integration.SendResolved())
+	assert.False(t, integration.SplitByAlert())
 
 	// Check that status is empty if no notifications have happened.
 	lastAttempt, lastDuration, lastError := integration.GetReport()
@@ -76,3 +77,18 @@ func TestIntegration(t *testing.T) {
 	assert.NotEqual(t, model.Duration(0), lastDuration)
 	assert.Equal(t, "An error", lastError.Error())
 }
+
+func TestIntegrationWithSplitByAlert(t *testing.T) {
+	notifier := &fakeNotifier{}
+	rs := &fakeResolvedSender{}
+	assert.True(t, NewIntegration(notifier, rs, "foo", 0, "bar", WithSplitByAlert(true)).SplitByAlert())
+	assert.False(t, NewIntegration(notifier, rs, "foo", 0, "bar", WithSplitByAlert(false)).SplitByAlert())
+}
+
+func TestIntegrationWithTimeIntervals(t *testing.T) {
+	notifier := &fakeNotifier{}
+	rs := &fakeResolvedSender{}
+	integration := NewIntegration(notifier, rs, "foo", 0, "bar", WithTimeIntervals([]string{"night"}, []string{"day"}))
+	assert.Equal(t, []string{"night"}, integration.MuteTimeIntervals())
+	assert.Equal(t, []string{"day"}, integration.ActiveTimeIntervals())
+}
@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+// notificationStateStage adds the store of the notification states to the context of the notifications,
+// so that notifiers can reply in thread to, or edit, the messages of the group.
+type notificationStateStage struct {
+	store receivers.NotificationStateStore
+}
+
+func newNotificationStateStage(store receivers.NotificationStateStore) *notificationStateStage {
+	return &notificationStateStage{store: store}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *notificationStateStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	return receivers.WithNotificationStateStore(ctx, s.store), alerts, nil
+}
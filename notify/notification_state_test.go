@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/cluster"
+	"github.com/grafana/alerting/notify/nfstate"
+	"github.com/grafana/alerting/receivers"
+)
+
+type recordingPeer struct {
+	NilPeer
+	states []string
+}
+
+func (p *recordingPeer) AddState(key string, _ cluster.State, _ prometheus.Registerer) cluster.ClusterChannel {
+	p.states = append(p.states, key)
+	return &NilChannel{}
+}
+
+type snapshotMaintenanceOptions struct {
+	fakeMaintenanceOptions
+	mtx       sync.Mutex
+	snapshots []State
+}
+
+func (o *snapshotMaintenanceOptions) MaintenanceFunc(state State) (int64, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.snapshots = append(o.snapshots, state)
+	return 0, nil
+}
+
+func TestNotificationState(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		peer := &recordingPeer{}
+		am, err := NewGrafanaAlertmanager("org", 1, &GrafanaAlertmanagerConfig{
+			Silences: newFakeMaintanenceOptions(t),
+			Nflog:    newFakeMaintanenceOptions(t),
+		}, peer, log.NewNopLogger(), NewGrafanaAlertmanagerMetrics(prometheus.NewPedanticRegistry(), log.NewNopLogger()))
+		require.NoError(t, err)
+		t.Cleanup(am.StopAndWait)
+		require.Nil(t, am.notificationState)
+		require.Equal(t, []string{"notificationlog:1", "silences:1", "repeatcounts:1"}, peer.states)
+	})
+
+	t.Run("replicated and snapshotted when enabled", func(t *testing.T) {
+		peer := &recordingPeer{}
+		opts := &snapshotMaintenanceOptions{}
+		am, err := NewGrafanaAlertmanager("org", 1, &GrafanaAlertmanagerConfig{
+			Silences:          newFakeMaintanenceOptions(t),
+			Nflog:             newFakeMaintanenceOptions(t),
+			NotificationState: opts,
+		}, peer, log.NewNopLogger(), NewGrafanaAlertmanagerMetrics(prometheus.NewPedanticRegistry(), log.NewNopLogger()))
+		require.NoError(t, err)
+		require.NotNil(t, am.notificationState)
+		require.Equal(t, []string{"notificationlog:1", "silences:1", "repeatcounts:1", "notificationstate:1"}, peer.states)
+
+		am.StopAndWait()
+		opts.mtx.Lock()
+		defer opts.mtx.Unlock()
+		require.NotEmpty(t, opts.snapshots)
+		require.Same(t, am.notificationState, opts.snapshots[len(opts.snapshots)-1])
+	})
+}
+
+func TestNotificationStateStage(t *testing.T) {
+	store, err := nfstate.New(time.Hour, nil)
+	require.NoError(t, err)
+
+	ctx, _, err := newNotificationStateStage(store).Exec(context.Background(), log.NewNopLogger())
+	require.NoError(t, err)
+	s, ok := receivers.NotificationStateStoreFromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, store, s)
+}
@@ -0,0 +1,349 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// redactedValue replaces secrets in the rendered previews.
+const redactedValue = "[REDACTED]"
+
+var (
+	// ErrPreviewNoAlerts is returned when a preview is requested without alerts.
+	ErrPreviewNoAlerts = errors.New("at least one alert is required to preview notifications")
+	// ErrPreviewNotSupported is returned for integrations that are not previewable, as they do not send
+	// notifications through a WebhookSender or an EmailSender and therefore cannot be previewed without sending them.
+	ErrPreviewNotSupported = errors.New("preview is not supported for this integration type")
+)
+
+type PreviewReceiverParams struct {
+	Receiver *APIReceiver
+	// Alerts are the alerts of the notification. Alerts with EndsAt in the past are resolved.
+	Alerts amv2.PostableAlerts
+}
+
+// PreviewOptions are the dependencies used to build the integrations of a preview.
+type PreviewOptions struct {
+	// Decrypt decrypts the secure settings of the integrations. Decrypted values are redacted from the previews.
+	Decrypt GetDecryptedValueFn
+	// EmailSenderConfig is used to render emails.
+	EmailSenderConfig receivers.EmailSenderConfig
+	// Images provides the images of alerts. Defaults to no images.
+	Images images.Provider
+	// Logger defaults to a no-op logger.
+	Logger  logging.LoggerFactory
+	OrgID   int64
+	Version string
+}
+
+type PreviewReceiverResult struct {
+	Name         string                     `json:"name"`
+	Integrations []PreviewIntegrationResult `json:"integrations"`
+}
+
+type PreviewIntegrationResult struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Requests are the HTTP requests the integration would make, in order.
+	Requests []PreviewRequest `json:"requests,omitempty"`
+	// Emails are the rendered MIME messages the integration would send.
+	Emails []string `json:"emails,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+type PreviewRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// PreviewReceiver renders the notifications that each integration of the receiver would send for the alerts, without sending them.
+func (am *GrafanaAlertmanager) PreviewReceiver(ctx context.Context, c PreviewReceiverParams, opts PreviewOptions) (*PreviewReceiverResult, error) {
+	am.reloadConfigMtx.RLock()
+	tmpls := make([]string, 0, len(am.templates))
+	for _, tc := range am.templates {
+		tmpls = append(tmpls, tc.Template)
+	}
+	am.reloadConfigMtx.RUnlock()
+
+	return PreviewReceiver(ctx, c, tmpls, am.ExternalURL(), opts)
+}
+
+// PreviewReceiver renders the notifications that each integration of the receiver would send for the alerts, without sending them.
+// Integrations are built with a WebhookSender and an EmailSender that capture the notifications instead of delivering them.
+// Integration types that are not previewable return ErrPreviewNotSupported, see receivers.IntegrationType.
+func PreviewReceiver(ctx context.Context, c PreviewReceiverParams, tmpls []string, externalURL string, opts PreviewOptions) (*PreviewReceiverResult, error) {
+	if c.Receiver == nil {
+		return nil, ErrNoReceivers
+	}
+	now := time.Now()
+	alerts, validationErr := PostableAlertsToAlertmanagerAlerts(c.Alerts, now)
+	if validationErr != nil {
+		return nil, validationErr
+	}
+	if len(alerts) == 0 {
+		return nil, ErrPreviewNoAlerts
+	}
+
+	tmpl, err := templateFromContent(tmpls, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	if opts.Decrypt == nil {
+		opts.Decrypt = NoopDecrypt
+	}
+	if opts.Images == nil {
+		opts.Images = &images.UnavailableProvider{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = func(string, ...interface{}) logging.Logger { return logging.FakeLogger{} }
+	}
+	renderer, err := receivers.NewEmailRenderer(opts.EmailSenderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the email renderer: %w", err)
+	}
+
+	// The references to secrets are resolved by a detached resolver, so that the receivers of the preview are not
+	// recorded by the SecretResolver, and their secrets are not cached and refreshed.
+	if resolver := SecretResolverFromContext(ctx); resolver != nil {
+		ctx = WithSecretResolver(ctx, resolver.detached())
+	}
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-preview-%d", c.Receiver.Name, now.Unix()))
+	ctx = notify.WithGroupLabels(ctx, commonLabels(alerts))
+	ctx = notify.WithReceiverName(ctx, c.Receiver.Name)
+	ctx = notify.WithNow(ctx, now)
+
+	res := &PreviewReceiverResult{
+		Name:         c.Receiver.Name,
+		Integrations: make([]PreviewIntegrationResult, 0, len(c.Receiver.Integrations)),
+	}
+	for _, intg := range c.Receiver.Integrations {
+		res.Integrations = append(res.Integrations, previewIntegration(ctx, c.Receiver.Name, intg, alerts, tmpl, renderer, opts))
+	}
+	return res, nil
+}
+
+func previewIntegration(ctx context.Context, receiverName string, intg *GrafanaIntegrationConfig, alerts []*types.Alert, tmpl *templates.Template, renderer receivers.EmailRenderer, opts PreviewOptions) PreviewIntegrationResult {
+	res := PreviewIntegrationResult{
+		UID:  intg.UID,
+		Name: intg.Name,
+		Type: intg.Type,
+	}
+
+	// Capture the decrypted secrets so that they can be redacted from the previews.
+	var secrets []string
+	resolver := SecretResolverFromContext(ctx)
+	decrypt := func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
+		v := opts.Decrypt(ctx, sjd, key, fallback)
+		if _, ok := sjd[key]; ok && v != "" {
+			secrets = append(secrets, v)
+		}
+		// The referenced secrets are redacted too, they are resolved from the cache of the detached resolver when
+		// building the receiver.
+		if resolver != nil && v != "" {
+			if resolved, err := resolver.Resolve(ctx, v); err == nil && resolved != v {
+				secrets = append(secrets, resolved)
+			}
+		}
+		return v
+	}
+
+	api := &APIReceiver{
+		ConfigReceiver: ConfigReceiver{Name: receiverName},
+		GrafanaIntegrations: GrafanaIntegrations{
+			Integrations: []*GrafanaIntegrationConfig{intg},
+		},
+	}
+	cfg, err := BuildReceiverConfiguration(ctx, api, decrypt)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if err := checkPreviewSupported(cfg); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	capture := &previewSender{renderer: renderer}
+	integrations, err := BuildReceiverIntegrations(
+		cfg,
+		tmpl,
+		opts.Images,
+		opts.Logger,
+		func(receivers.Metadata) (receivers.WebhookSender, error) { return capture, nil },
+		func(receivers.Metadata) (receivers.EmailSender, error) { return capture, nil },
+		opts.OrgID,
+		opts.Version,
+	)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	for _, i := range integrations {
+		if _, err := i.Notify(ctx, alerts...); err != nil {
+			res.Error = redact(err.Error(), secrets)
+		}
+	}
+
+	res.Requests = make([]PreviewRequest, 0, len(capture.requests))
+	for _, r := range capture.requests {
+		res.Requests = append(res.Requests, r.redacted(secrets))
+	}
+	for _, e := range capture.emails {
+		res.Emails = append(res.Emails, redact(e, secrets))
+	}
+	return res
+}
+
+// checkPreviewSupported returns an error if any integration of the configuration is not previewable, because
+// previewing it would send the notification, see receivers.IntegrationType.
+func checkPreviewSupported(cfg GrafanaReceiverConfig) error {
+	for _, c := range cfg.integrations() {
+		it, ok := receivers.GetIntegration(c.Type)
+		if !ok || it.Previewable == nil || !it.Previewable(c.Settings) {
+			return fmt.Errorf("%w: %q", ErrPreviewNotSupported, c.Type)
+		}
+	}
+	return nil
+}
+
+// previewSender implements WebhookSender and EmailSender. It captures the notifications instead of sending them.
+type previewSender struct {
+	renderer receivers.EmailRenderer
+
+	mtx      sync.Mutex
+	requests []PreviewRequest
+	emails   []string
+}
+
+// SendWebhook implements the receivers.WebhookSender interface.
+func (s *previewSender) SendWebhook(_ context.Context, cmd *receivers.SendWebhookSettings) error {
+	method := cmd.HTTPMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+	contentType := cmd.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	headers := make(map[string]string, len(cmd.HTTPHeader)+2)
+	for k, v := range cmd.HTTPHeader {
+		headers[k] = v
+	}
+	headers["Content-Type"] = contentType
+	if cmd.User != "" && cmd.Password != "" {
+		headers["Authorization"] = "Basic " + redactedValue
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.requests = append(s.requests, PreviewRequest{
+		URL:     cmd.URL,
+		Method:  method,
+		Headers: headers,
+		Body:    cmd.Body,
+	})
+	return nil
+}
+
+// CapturesWebhooks implements the receivers.CapturingWebhookSender interface, so that the integrations that send
+// requests with their own clients send them through the previewSender instead.
+func (s *previewSender) CapturesWebhooks() {}
+
+// SendEmail implements the receivers.EmailSender interface.
+func (s *previewSender) SendEmail(ctx context.Context, cmd *receivers.SendEmailSettings) error {
+	b, err := s.renderer.RenderEmail(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.emails = append(s.emails, string(b))
+	return nil
+}
+
+// redacted returns a copy of the request where the secrets, the Authorization header and the URL password are redacted.
+func (r PreviewRequest) redacted(secrets []string) PreviewRequest {
+	headers := make(map[string]string, len(r.Headers))
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, "Authorization") {
+			// Keep the scheme, e.g. Bearer, as it is useful to know how the request is authenticated.
+			if scheme, _, ok := strings.Cut(v, " "); ok {
+				v = scheme + " " + redactedValue
+			} else {
+				v = redactedValue
+			}
+		}
+		headers[k] = redact(v, secrets)
+	}
+
+	rawURL := r.URL
+	if u, err := url.Parse(rawURL); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+			rawURL = u.String()
+		}
+	}
+
+	return PreviewRequest{
+		URL:     redact(rawURL, secrets),
+		Method:  r.Method,
+		Headers: headers,
+		Body:    redact(r.Body, secrets),
+	}
+}
+
+// redact replaces all occurrences of the secrets in s. Longer secrets are replaced first, so that
+// secrets that contain other secrets are fully redacted.
+func redact(s string, secrets []string) string {
+	sorted := make([]string, len(secrets))
+	copy(sorted, secrets)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+		// Secrets are often part of URLs, where they can be escaped.
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			s = strings.ReplaceAll(s, escaped, redactedValue)
+		}
+	}
+	return s
+}
+
+// commonLabels returns the labels that have the same value in all alerts.
+func commonLabels(alerts []*types.Alert) model.LabelSet {
+	if len(alerts) == 0 {
+		return model.LabelSet{}
+	}
+	common := alerts[0].Labels.Clone()
+	for _, a := range alerts[1:] {
+		for k, v := range common {
+			if a.Labels[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+	return common
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/prometheus/alertmanager/config"
@@ -15,6 +14,8 @@ import (
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/alerting/notify/nfstatus"
 	"github.com/grafana/alerting/receivers"
 	"github.com/grafana/alerting/receivers/alertmanager"
 	"github.com/grafana/alerting/receivers/dinding"
@@ -46,12 +47,26 @@ const (
 
 var (
 	ErrNoReceivers = errors.New("no receivers")
+	// ErrInvalidTestAlert is returned when the alerts of a test notification are invalid.
+	ErrInvalidTestAlert = errors.New("invalid test alert")
 )
 
 type TestReceiversResult struct {
+	// Alert is the first alert of the test notification.
 	Alert     types.Alert          `json:"alert"`
+	Alerts    []types.Alert        `json:"alerts"`
 	Receivers []TestReceiverResult `json:"receivers"`
 	NotifedAt time.Time            `json:"notifiedAt"`
+	// Steps contains the results of each notification when the firing notification is followed by a resolved one.
+	Steps []TestReceiversStepResult `json:"steps,omitempty"`
+}
+
+// TestReceiversStepResult contains the results of a single notification in a sequence of test notifications.
+type TestReceiversStepResult struct {
+	Status     model.AlertStatus    `json:"status"`
+	Alerts     []types.Alert        `json:"alerts"`
+	Receivers  []TestReceiverResult `json:"receivers"`
+	NotifiedAt time.Time            `json:"notifiedAt"`
 }
 
 type TestReceiverResult struct {
@@ -71,6 +86,9 @@ type GrafanaIntegrationConfig struct {
 	Name                  string            `json:"name" yaml:"name"`
 	Type                  string            `json:"type" yaml:"type"`
 	DisableResolveMessage bool              `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+	SplitByAlert          bool              `json:"splitByAlert,omitempty" yaml:"splitByAlert,omitempty"`
+	MuteTimeIntervals     []string          `json:"muteTimeIntervals,omitempty" yaml:"muteTimeIntervals,omitempty"`
+	ActiveTimeIntervals   []string          `json:"activeTimeIntervals,omitempty" yaml:"activeTimeIntervals,omitempty"`
 	Settings              json.RawMessage   `json:"settings" yaml:"settings"`
 	SecureSettings        map[string]string `json:"secureSettings" yaml:"secureSettings"`
 }
@@ -87,13 +105,30 @@ type GrafanaIntegrations struct {
 }
 
 type TestReceiversConfigBodyParams struct {
-	Alert     *TestReceiversConfigAlertParams `yaml:"alert,omitempty" json:"alert,omitempty"`
-	Receivers []*APIReceiver                  `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+	Alert *TestReceiversConfigAlertParams `yaml:"alert,omitempty" json:"alert,omitempty"`
+	// Alerts are the alerts of the test notification. If empty, a single firing alert is built from Alert.
+	Alerts []*TestReceiversConfigAlertParams `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+	// GroupLabels are the labels of the aggregation group. Defaults to the labels common to all alerts.
+	GroupLabels model.LabelSet `yaml:"groupLabels,omitempty" json:"groupLabels,omitempty"`
+	// GroupKey is the key of the aggregation group. Defaults to a key unique to the test.
+	GroupKey string `yaml:"groupKey,omitempty" json:"groupKey,omitempty"`
+	// ResolveAfterFiring sends a notification where all alerts are firing, followed by a notification
+	// where all alerts are resolved. The results of each notification are reported in TestReceiversResult.Steps.
+	ResolveAfterFiring bool           `yaml:"resolveAfterFiring,omitempty" json:"resolveAfterFiring,omitempty"`
+	Receivers          []*APIReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
 }
 
 type TestReceiversConfigAlertParams struct {
 	Annotations model.LabelSet `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	Labels      model.LabelSet `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Status is either firing or resolved. If empty, it is derived from EndsAt.
+	Status model.AlertStatus `yaml:"status,omitempty" json:"status,omitempty"`
+	// StartsAt defaults to the start of the test.
+	StartsAt time.Time `yaml:"startsAt,omitempty" json:"startsAt,omitempty"`
+	// EndsAt defaults to the start of the test for resolved alerts.
+	EndsAt time.Time `yaml:"endsAt,omitempty" json:"endsAt,omitempty"`
+	// ImageToken is the token of the image of the alert in the images.Provider of the integrations.
+	ImageToken string `yaml:"imageToken,omitempty" json:"imageToken,omitempty"`
 }
 
 type IntegrationTimeoutError struct {
@@ -118,7 +153,27 @@ func (am *GrafanaAlertmanager) TestReceivers(ctx context.Context, c TestReceiver
 	return TestReceivers(ctx, c, tmpls, am.buildReceiverIntegrationsFunc, am.ExternalURL())
 }
 
-func newTestAlert(c TestReceiversConfigBodyParams, startsAt, updatedAt time.Time) types.Alert {
+// newTestAlerts returns the alerts of the test notification.
+func newTestAlerts(c TestReceiversConfigBodyParams, now time.Time) ([]*types.Alert, error) {
+	if len(c.Alerts) == 0 {
+		alert, err := newTestAlert(c.Alert, now)
+		if err != nil {
+			return nil, err
+		}
+		return []*types.Alert{alert}, nil
+	}
+	alerts := make([]*types.Alert, 0, len(c.Alerts))
+	for i, p := range c.Alerts {
+		alert, err := newTestAlert(p, now)
+		if err != nil {
+			return nil, fmt.Errorf("alert %d: %w", i, err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func newTestAlert(p *TestReceiversConfigAlertParams, now time.Time) (*types.Alert, error) {
 	var (
 		defaultAnnotations = model.LabelSet{
 			"summary":          "Notification test",
@@ -130,29 +185,57 @@ func newTestAlert(c TestReceiversConfigBodyParams, startsAt, updatedAt time.Time
 		}
 	)
 
-	alert := types.Alert{
+	alert := &types.Alert{
 		Alert: model.Alert{
 			Labels:      defaultLabels,
 			Annotations: defaultAnnotations,
-			StartsAt:    startsAt,
+			StartsAt:    now,
 		},
-		UpdatedAt: updatedAt,
+		UpdatedAt: now,
 	}
 
-	if c.Alert != nil {
-		if c.Alert.Annotations != nil {
-			for k, v := range c.Alert.Annotations {
-				alert.Annotations[k] = v
-			}
+	if p == nil {
+		return alert, nil
+	}
+
+	if p.Annotations != nil {
+		for k, v := range p.Annotations {
+			alert.Annotations[k] = v
 		}
-		if c.Alert.Labels != nil {
-			for k, v := range c.Alert.Labels {
-				alert.Labels[k] = v
-			}
+	}
+	if p.Labels != nil {
+		for k, v := range p.Labels {
+			alert.Labels[k] = v
 		}
 	}
+	if p.ImageToken != "" {
+		alert.Annotations[models.ImageTokenAnnotation] = model.LabelValue(p.ImageToken)
+	}
+	if !p.StartsAt.IsZero() {
+		alert.StartsAt = p.StartsAt
+	}
+	alert.EndsAt = p.EndsAt
 
-	return alert
+	switch p.Status {
+	case model.AlertFiring:
+		if alert.ResolvedAt(now) {
+			return nil, fmt.Errorf("%w: firing alert ends before the test at %s", ErrInvalidTestAlert, alert.EndsAt)
+		}
+	case model.AlertResolved:
+		if alert.EndsAt.IsZero() {
+			alert.EndsAt = now
+		} else if alert.EndsAt.After(now) {
+			return nil, fmt.Errorf("%w: resolved alert ends after the test at %s", ErrInvalidTestAlert, alert.EndsAt)
+		}
+	case "":
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidTestAlert, p.Status)
+	}
+	if !alert.EndsAt.IsZero() && alert.EndsAt.Before(alert.StartsAt) {
+		return nil, fmt.Errorf("%w: endsAt %s is before startsAt %s", ErrInvalidTestAlert, alert.EndsAt, alert.StartsAt)
+	}
+
+	return alert, nil
 }
 
 func ProcessIntegrationError(config *GrafanaIntegrationConfig, err error) error {
@@ -182,7 +265,14 @@ func ProcessIntegrationError(config *GrafanaIntegrationConfig, err error) error
 
 // GrafanaReceiverConfig represents a parsed and validated APIReceiver
 type GrafanaReceiverConfig struct {
-	Name                string
+	Name string
+	// Integrations are the parsed integrations of the receiver, in the order of the APIReceiver. Their settings are
+	// the settings returned by the NewConfig of their type of integration, see ConfigsOf.
+	Integrations []*NotifierConfig[any]
+
+	// The typed fields are the integrations of the built-in types, which BuildReceiverConfiguration sets along with
+	// Integrations. If Integrations is empty, the integrations of the receiver are those of the typed fields, so that
+	// the configurations created before the integrations were registered keep working.
 	AlertmanagerConfigs []*NotifierConfig[alertmanager.Config]
 	DingdingConfigs     []*NotifierConfig[dinding.Config]
 	DiscordConfigs      []*NotifierConfig[discord.Config]
@@ -207,10 +297,42 @@ type GrafanaReceiverConfig struct {
 	WebexConfigs        []*NotifierConfig[webex.Config]
 }
 
+// ConfigsOf returns the configurations of the integrations of the receiver whose settings are of type T.
+func ConfigsOf[T any](cfg GrafanaReceiverConfig) []*NotifierConfig[T] {
+	var res []*NotifierConfig[T]
+	for _, c := range cfg.integrations() {
+		if settings, ok := c.Settings.(T); ok {
+			res = append(res, &NotifierConfig[T]{
+				Metadata:            c.Metadata,
+				Settings:            settings,
+				MuteTimeIntervals:   c.MuteTimeIntervals,
+				ActiveTimeIntervals: c.ActiveTimeIntervals,
+			})
+		}
+	}
+	return res
+}
+
 // NotifierConfig represents parsed GrafanaIntegrationConfig.
 type NotifierConfig[T interface{}] struct {
 	receivers.Metadata
 	Settings T
+	// MuteTimeIntervals are the names of the time intervals during which the integration is not notified.
+	MuteTimeIntervals []string
+	// ActiveTimeIntervals are the names of the time intervals outside of which the integration is not notified.
+	ActiveTimeIntervals []string
+	// secrets are the decrypted secure values and the resolved references to secrets of the settings.
+	secrets []string
+}
+
+// integrationOptions returns the options of the integration built from the configuration.
+func (c *NotifierConfig[T]) integrationOptions() []nfstatus.IntegrationOption {
+	return []nfstatus.IntegrationOption{
+		nfstatus.WithUID(c.UID),
+		nfstatus.WithSplitByAlert(c.SplitByAlert),
+		nfstatus.WithTimeIntervals(c.MuteTimeIntervals, c.ActiveTimeIntervals),
+		nfstatus.WithSecrets(c.secrets),
+	}
 }
 
 // GetDecryptedValueFn is a function that returns the decrypted value of
@@ -230,6 +352,8 @@ func BuildReceiverConfiguration(ctx context.Context, api *APIReceiver, decrypt G
 	result := GrafanaReceiverConfig{
 		Name: api.Name,
 	}
+	// The SecretResolver records the receivers that reference each secret, so that only they are rebuilt when it changes.
+	ctx = context.WithValue(ctx, secretReceiverKey{}, api.Name)
 	for _, receiver := range api.Integrations {
 		err := parseNotifier(ctx, &result, receiver, decrypt)
 		if err != nil {
@@ -239,6 +363,7 @@ func BuildReceiverConfiguration(ctx context.Context, api *APIReceiver, decrypt G
 			}
 		}
 	}
+	result.setTypedConfigs()
 	return result, nil
 }
 
@@ -253,146 +378,70 @@ func parseNotifier(ctx context.Context, result *GrafanaReceiverConfig, receiver
 		}
 	}
 
+	// The decrypt function records the secure settings that cannot be decrypted, see RecordDecryptionError.
+	var decryptErr error
+	ctx = context.WithValue(ctx, decryptionErrorsKey{}, &decryptErr)
+	// The references to secrets in the decrypted settings are resolved if the context has a SecretResolver.
+	resolver := SecretResolverFromContext(ctx)
+	var refErr error
+	// The secrets are collected so that they can be redacted from the audit records of the integration.
+	var secrets []string
 	decryptFn := func(key string, fallback string) string {
-		return decrypt(ctx, secureSettings, key, fallback)
-	}
-
-	switch strings.ToLower(receiver.Type) {
-	case "prometheus-alertmanager":
-		cfg, err := alertmanager.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
+		v := decrypt(ctx, secureSettings, key, fallback)
+		if _, ok := secureSettings[key]; ok && v != "" {
+			secrets = append(secrets, v)
 		}
-		result.AlertmanagerConfigs = append(result.AlertmanagerConfigs, newNotifierConfig(receiver, cfg))
-	case "dingding":
-		cfg, err := dinding.NewConfig(receiver.Settings)
-		if err != nil {
-			return err
-		}
-		result.DingdingConfigs = append(result.DingdingConfigs, newNotifierConfig(receiver, cfg))
-	case "discord":
-		cfg, err := discord.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.DiscordConfigs = append(result.DiscordConfigs, newNotifierConfig(receiver, cfg))
-	case "email":
-		cfg, err := email.NewConfig(receiver.Settings)
-		if err != nil {
-			return err
+		if resolver == nil {
+			return v
 		}
-		result.EmailConfigs = append(result.EmailConfigs, newNotifierConfig(receiver, cfg))
-	case "googlechat":
-		cfg, err := googlechat.NewConfig(receiver.Settings, decryptFn)
+		resolved, err := resolver.Resolve(ctx, v)
 		if err != nil {
-			return err
-		}
-		result.GooglechatConfigs = append(result.GooglechatConfigs, newNotifierConfig(receiver, cfg))
-	case "kafka":
-		cfg, err := kafka.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.KafkaConfigs = append(result.KafkaConfigs, newNotifierConfig(receiver, cfg))
-	case "line":
-		cfg, err := line.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.LineConfigs = append(result.LineConfigs, newNotifierConfig(receiver, cfg))
-	case "mqtt":
-		cfg, err := mqtt.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.MqttConfigs = append(result.MqttConfigs, newNotifierConfig(receiver, cfg))
-	case "opsgenie":
-		cfg, err := opsgenie.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.OpsgenieConfigs = append(result.OpsgenieConfigs, newNotifierConfig(receiver, cfg))
-	case "pagerduty":
-		cfg, err := pagerduty.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.PagerdutyConfigs = append(result.PagerdutyConfigs, newNotifierConfig(receiver, cfg))
-	case "oncall":
-		cfg, err := oncall.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.OnCallConfigs = append(result.OnCallConfigs, newNotifierConfig(receiver, cfg))
-	case "pushover":
-		cfg, err := pushover.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.PushoverConfigs = append(result.PushoverConfigs, newNotifierConfig(receiver, cfg))
-	case "sensugo":
-		cfg, err := sensugo.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.SensugoConfigs = append(result.SensugoConfigs, newNotifierConfig(receiver, cfg))
-	case "slack":
-		cfg, err := slack.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.SlackConfigs = append(result.SlackConfigs, newNotifierConfig(receiver, cfg))
-	case "sns":
-		cfg, err := sns.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.SNSConfigs = append(result.SNSConfigs, newNotifierConfig(receiver, cfg))
-	case "teams":
-		cfg, err := teams.NewConfig(receiver.Settings)
-		if err != nil {
-			return err
-		}
-		result.TeamsConfigs = append(result.TeamsConfigs, newNotifierConfig(receiver, cfg))
-	case "telegram":
-		cfg, err := telegram.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.TelegramConfigs = append(result.TelegramConfigs, newNotifierConfig(receiver, cfg))
-	case "threema":
-		cfg, err := threema.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.ThreemaConfigs = append(result.ThreemaConfigs, newNotifierConfig(receiver, cfg))
-	case "victorops":
-		cfg, err := victorops.NewConfig(receiver.Settings)
-		if err != nil {
-			return err
-		}
-		result.VictoropsConfigs = append(result.VictoropsConfigs, newNotifierConfig(receiver, cfg))
-	case "webhook":
-		cfg, err := webhook.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
-		}
-		result.WebhookConfigs = append(result.WebhookConfigs, newNotifierConfig(receiver, cfg))
-	case "wecom":
-		cfg, err := wecom.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
+			var e SecretReferenceError
+			if errors.As(err, &e) {
+				e.Path = "settings." + key
+				if _, ok := secureSettings[key]; ok {
+					e.Path = "secureSettings." + key
+				}
+				err = e
+			}
+			if refErr == nil {
+				refErr = err
+			}
+			return ""
 		}
-		result.WecomConfigs = append(result.WecomConfigs, newNotifierConfig(receiver, cfg))
-	case "webex":
-		cfg, err := webex.NewConfig(receiver.Settings, decryptFn)
-		if err != nil {
-			return err
+		if resolved != v {
+			secrets = append(secrets, resolved)
 		}
-		result.WebexConfigs = append(result.WebexConfigs, newNotifierConfig(receiver, cfg))
-	default:
+		return resolved
+	}
+
+	err = parseNotifierSettings(result, receiver, decryptFn)
+	if err == nil {
+		result.Integrations[len(result.Integrations)-1].secrets = secrets
+	}
+	// Secure settings that cannot be decrypted and unresolved references take precedence, as the settings are likely
+	// invalid because of them.
+	if decryptErr != nil {
+		return decryptErr
+	}
+	if refErr != nil {
+		return refErr
+	}
+	return err
+}
+
+// parseNotifierSettings parses the settings of the receiver with the decryptFn using its registered type of integration,
+// and appends it to the integrations of the GrafanaReceiverConfig.
+func parseNotifierSettings(result *GrafanaReceiverConfig, receiver *GrafanaIntegrationConfig, decryptFn receivers.DecryptFunc) error {
+	t, ok := receivers.GetIntegration(receiver.Type)
+	if !ok {
 		return fmt.Errorf("notifier %s is not supported", receiver.Type)
 	}
+	cfg, err := t.NewConfig(receiver.Settings, decryptFn)
+	if err != nil {
+		return err
+	}
+	result.Integrations = append(result.Integrations, newNotifierConfig(receiver, cfg))
 	return nil
 }
 
@@ -429,8 +478,11 @@ func newNotifierConfig[T interface{}](receiver *GrafanaIntegrationConfig, settin
 			Name:                  receiver.Name,
 			Type:                  receiver.Type,
 			DisableResolveMessage: receiver.DisableResolveMessage,
+			SplitByAlert:          receiver.SplitByAlert,
 		},
-		Settings: settings,
+		Settings:            settings,
+		MuteTimeIntervals:   receiver.MuteTimeIntervals,
+		ActiveTimeIntervals: receiver.ActiveTimeIntervals,
 	}
 }
 
@@ -13,6 +13,26 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/receivers/alertmanager"
+	"github.com/grafana/alerting/receivers/dinding"
+	"github.com/grafana/alerting/receivers/discord"
+	"github.com/grafana/alerting/receivers/email"
+	"github.com/grafana/alerting/receivers/googlechat"
+	"github.com/grafana/alerting/receivers/kafka"
+	"github.com/grafana/alerting/receivers/line"
+	"github.com/grafana/alerting/receivers/opsgenie"
+	"github.com/grafana/alerting/receivers/pagerduty"
+	"github.com/grafana/alerting/receivers/pushover"
+	"github.com/grafana/alerting/receivers/sensugo"
+	"github.com/grafana/alerting/receivers/slack"
+	"github.com/grafana/alerting/receivers/sns"
+	"github.com/grafana/alerting/receivers/teams"
+	"github.com/grafana/alerting/receivers/telegram"
+	"github.com/grafana/alerting/receivers/threema"
+	"github.com/grafana/alerting/receivers/victorops"
+	"github.com/grafana/alerting/receivers/webex"
+	"github.com/grafana/alerting/receivers/webhook"
+	"github.com/grafana/alerting/receivers/wecom"
 )
 
 func TestReceiverTimeoutError_Error(t *testing.T) {
@@ -134,7 +154,7 @@ func TestBuildReceiverConfiguration(t *testing.T) {
 		parsed, err := BuildReceiverConfiguration(context.Background(), recCfg, NoopDecrypt)
 		require.NoError(t, err)
 		require.Equal(t, recCfg.Name, parsed.Name)
-		require.Equal(t, invalidBase64, parsed.AlertmanagerConfigs[0].Settings.Password)
+		require.Equal(t, invalidBase64, ConfigsOf[alertmanager.Config](parsed)[0].Settings.Password)
 	})
 	t.Run("should fail if notifier type is unknown", func(t *testing.T) {
 		recCfg := &APIReceiver{ConfigReceiver: ConfigReceiver{Name: "test-receiver"}}
@@ -166,49 +186,49 @@ func TestBuildReceiverConfiguration(t *testing.T) {
 		parsed, err := BuildReceiverConfiguration(context.Background(), recCfg, decrypt)
 		require.NoError(t, err)
 		require.Equal(t, recCfg.Name, parsed.Name)
-		require.Len(t, parsed.AlertmanagerConfigs, 1)
-		require.Len(t, parsed.DingdingConfigs, 1)
-		require.Len(t, parsed.DiscordConfigs, 1)
-		require.Len(t, parsed.EmailConfigs, 1)
-		require.Len(t, parsed.GooglechatConfigs, 1)
-		require.Len(t, parsed.KafkaConfigs, 1)
-		require.Len(t, parsed.LineConfigs, 1)
-		require.Len(t, parsed.OpsgenieConfigs, 1)
-		require.Len(t, parsed.PagerdutyConfigs, 1)
-		require.Len(t, parsed.PushoverConfigs, 1)
-		require.Len(t, parsed.SensugoConfigs, 1)
-		require.Len(t, parsed.SlackConfigs, 1)
-		require.Len(t, parsed.SNSConfigs, 1)
-		require.Len(t, parsed.TeamsConfigs, 1)
-		require.Len(t, parsed.TelegramConfigs, 1)
-		require.Len(t, parsed.ThreemaConfigs, 1)
-		require.Len(t, parsed.VictoropsConfigs, 1)
-		require.Len(t, parsed.WebhookConfigs, 1)
-		require.Len(t, parsed.WecomConfigs, 1)
-		require.Len(t, parsed.WebexConfigs, 1)
+		require.Len(t, ConfigsOf[alertmanager.Config](parsed), 1)
+		require.Len(t, ConfigsOf[dinding.Config](parsed), 1)
+		require.Len(t, ConfigsOf[discord.Config](parsed), 1)
+		require.Len(t, ConfigsOf[email.Config](parsed), 1)
+		require.Len(t, ConfigsOf[googlechat.Config](parsed), 1)
+		require.Len(t, ConfigsOf[kafka.Config](parsed), 1)
+		require.Len(t, ConfigsOf[line.Config](parsed), 1)
+		require.Len(t, ConfigsOf[opsgenie.Config](parsed), 1)
+		require.Len(t, ConfigsOf[pagerduty.Config](parsed), 1)
+		require.Len(t, ConfigsOf[pushover.Config](parsed), 1)
+		require.Len(t, ConfigsOf[sensugo.Config](parsed), 1)
+		require.Len(t, ConfigsOf[slack.Config](parsed), 1)
+		require.Len(t, ConfigsOf[sns.Config](parsed), 1)
+		require.Len(t, ConfigsOf[teams.Config](parsed), 1)
+		require.Len(t, ConfigsOf[telegram.Config](parsed), 1)
+		require.Len(t, ConfigsOf[threema.Config](parsed), 1)
+		require.Len(t, ConfigsOf[victorops.Config](parsed), 1)
+		require.Len(t, ConfigsOf[webhook.Config](parsed), 1)
+		require.Len(t, ConfigsOf[wecom.Config](parsed), 1)
+		require.Len(t, ConfigsOf[webex.Config](parsed), 1)
 
 		t.Run("should populate metadata", func(t *testing.T) {
 			var all []receivers.Metadata
-			all = append(all, getMetadata(parsed.AlertmanagerConfigs)...)
-			all = append(all, getMetadata(parsed.DingdingConfigs)...)
-			all = append(all, getMetadata(parsed.DiscordConfigs)...)
-			all = append(all, getMetadata(parsed.EmailConfigs)...)
-			all = append(all, getMetadata(parsed.GooglechatConfigs)...)
-			all = append(all, getMetadata(parsed.KafkaConfigs)...)
-			all = append(all, getMetadata(parsed.LineConfigs)...)
-			all = append(all, getMetadata(parsed.OpsgenieConfigs)...)
-			all = append(all, getMetadata(parsed.PagerdutyConfigs)...)
-			all = append(all, getMetadata(parsed.PushoverConfigs)...)
-			all = append(all, getMetadata(parsed.SensugoConfigs)...)
-			all = append(all, getMetadata(parsed.SlackConfigs)...)
-			all = append(all, getMetadata(parsed.SNSConfigs)...)
-			all = append(all, getMetadata(parsed.TeamsConfigs)...)
-			all = append(all, getMetadata(parsed.TelegramConfigs)...)
-			all = append(all, getMetadata(parsed.ThreemaConfigs)...)
-			all = append(all, getMetadata(parsed.VictoropsConfigs)...)
-			all = append(all, getMetadata(parsed.WebhookConfigs)...)
-			all = append(all, getMetadata(parsed.WecomConfigs)...)
-			all = append(all, getMetadata(parsed.WebexConfigs)...)
+			all = append(all, getMetadata(ConfigsOf[alertmanager.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[dinding.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[discord.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[email.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[googlechat.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[kafka.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[line.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[opsgenie.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[pagerduty.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[pushover.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[sensugo.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[slack.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[sns.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[teams.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[telegram.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[threema.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[victorops.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[webhook.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[wecom.Config](parsed))...)
+			all = append(all, getMetadata(ConfigsOf[webex.Config](parsed))...)
 
 			for idx, meta := range all {
 				require.NotEmptyf(t, meta.Type, "%s notifier (idx: %d) '%s' uid: '%s'.", meta.Type, idx, meta.Name, meta.UID)
@@ -235,26 +255,26 @@ func TestBuildReceiverConfiguration(t *testing.T) {
 		}
 		parsed, err := BuildReceiverConfiguration(context.Background(), recCfg, decrypt)
 		require.NoError(t, err)
-		require.Len(t, parsed.AlertmanagerConfigs, 1)
-		require.Len(t, parsed.DingdingConfigs, 1)
-		require.Len(t, parsed.DiscordConfigs, 1)
-		require.Len(t, parsed.EmailConfigs, 1)
-		require.Len(t, parsed.GooglechatConfigs, 1)
-		require.Len(t, parsed.KafkaConfigs, 1)
-		require.Len(t, parsed.LineConfigs, 1)
-		require.Len(t, parsed.OpsgenieConfigs, 1)
-		require.Len(t, parsed.PagerdutyConfigs, 1)
-		require.Len(t, parsed.PushoverConfigs, 1)
-		require.Len(t, parsed.SensugoConfigs, 1)
-		require.Len(t, parsed.SlackConfigs, 1)
-		require.Len(t, parsed.SNSConfigs, 1)
-		require.Len(t, parsed.TeamsConfigs, 1)
-		require.Len(t, parsed.TelegramConfigs, 1)
-		require.Len(t, parsed.ThreemaConfigs, 1)
-		require.Len(t, parsed.VictoropsConfigs, 1)
-		require.Len(t, parsed.WebhookConfigs, 1)
-		require.Len(t, parsed.WecomConfigs, 1)
-		require.Len(t, parsed.WebexConfigs, 1)
+		require.Len(t, ConfigsOf[alertmanager.Config](parsed), 1)
+		require.Len(t, ConfigsOf[dinding.Config](parsed), 1)
+		require.Len(t, ConfigsOf[discord.Config](parsed), 1)
+		require.Len(t, ConfigsOf[email.Config](parsed), 1)
+		require.Len(t, ConfigsOf[googlechat.Config](parsed), 1)
+		require.Len(t, ConfigsOf[kafka.Config](parsed), 1)
+		require.Len(t, ConfigsOf[line.Config](parsed), 1)
+		require.Len(t, ConfigsOf[opsgenie.Config](parsed), 1)
+		require.Len(t, ConfigsOf[pagerduty.Config](parsed), 1)
+		require.Len(t, ConfigsOf[pushover.Config](parsed), 1)
+		require.Len(t, ConfigsOf[sensugo.Config](parsed), 1)
+		require.Len(t, ConfigsOf[slack.Config](parsed), 1)
+		require.Len(t, ConfigsOf[sns.Config](parsed), 1)
+		require.Len(t, ConfigsOf[teams.Config](parsed), 1)
+		require.Len(t, ConfigsOf[telegram.Config](parsed), 1)
+		require.Len(t, ConfigsOf[threema.Config](parsed), 1)
+		require.Len(t, ConfigsOf[victorops.Config](parsed), 1)
+		require.Len(t, ConfigsOf[webhook.Config](parsed), 1)
+		require.Len(t, ConfigsOf[wecom.Config](parsed), 1)
+		require.Len(t, ConfigsOf[webex.Config](parsed), 1)
 
 	})
 }
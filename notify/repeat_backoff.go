@@ -0,0 +1,352 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/definition"
+)
+
+type RepeatBackoff = definition.RepeatBackoff
+
+// newGrafanaRoute returns the route of the dispatcher for the Grafana routing tree, and the repeat backoff of every
+// route of the tree keyed by the key of the route, which prefixes the group keys of its aggregation groups.
+// Routes without a repeat backoff inherit the one of their parent.
+func newGrafanaRoute(root *definition.Route) (*dispatch.Route, map[string]RepeatBackoff) {
+	route := dispatch.NewRoute(root.AsAMRoute(), nil)
+	backoffs := map[string]RepeatBackoff{}
+	var walk func(r *definition.Route, dr *dispatch.Route, parent *RepeatBackoff)
+	walk = func(r *definition.Route, dr *dispatch.Route, parent *RepeatBackoff) {
+		b := parent
+		if r.RepeatBackoff != nil {
+			b = r.RepeatBackoff
+		}
+		if b != nil {
+			// Sibling routes with the same matchers share the same key, and the same aggregation groups. The first one wins.
+			if _, ok := backoffs[dr.Key()]; !ok {
+				backoffs[dr.Key()] = *b
+			}
+		}
+		// AsAMRoute keeps the order of the child routes, and so does the dispatcher.
+		for i := range r.Routes {
+			walk(r.Routes[i], dr.Routes[i], b)
+		}
+	}
+	walk(root, route, nil)
+	return route, backoffs
+}
+
+// repeatBackoffStage grows the repeat interval of the aggregation groups of routes with a repeat backoff
+// before executing its stage. The stage is expected to deduplicate, notify and log the notification.
+type repeatBackoffStage struct {
+	stage    notify.Stage
+	counts   *repeatCounts
+	recv     *nflogpb.Receiver
+	backoffs map[string]RepeatBackoff
+}
+
+func newRepeatBackoffStage(s notify.Stage, counts *repeatCounts, recv *nflogpb.Receiver, backoffs map[string]RepeatBackoff) notify.Stage {
+	if len(backoffs) == 0 {
+		return s
+	}
+	return &repeatBackoffStage{
+		stage:    s,
+		counts:   counts,
+		recv:     recv,
+		backoffs: backoffs,
+	}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *repeatBackoffStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	gkey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return ctx, nil, errors.New("group key missing")
+	}
+	backoff, ok := s.backoffFor(gkey)
+	if !ok {
+		return s.stage.Exec(ctx, l, alerts...)
+	}
+	repeatInterval, ok := notify.RepeatInterval(ctx)
+	if !ok {
+		return ctx, nil, errors.New("repeat interval missing")
+	}
+
+	key := repeatCountKey{GroupKey: gkey, Receiver: receiverKey(s.recv)}
+	firing := firingFingerprints(alerts)
+	var repeats uint64
+	prev, ok := s.counts.get(key)
+	sameFiring := ok && equalFingerprints(prev.Firing, firing)
+	if sameFiring {
+		repeats = prev.Repeats
+	}
+
+	ctx = notify.WithRepeatInterval(ctx, backoff.Interval(repeatInterval, repeats))
+	ctx, sent, err := s.stage.Exec(ctx, l, alerts...)
+	if err != nil || len(sent) == 0 {
+		return ctx, sent, err
+	}
+
+	// A notification was sent. It is a repeat notification if the firing alerts did not change.
+	if sameFiring {
+		repeats++
+	} else {
+		repeats = 0
+	}
+	if err := s.counts.set(key, firing, repeats, 2*backoff.Interval(repeatInterval, repeats)); err != nil {
+		level.Warn(l).Log("msg", "Failed to store the repeat notifications of the group", "aggrGroup", gkey, "err", err)
+	}
+	return ctx, sent, nil
+}
+
+// backoffFor returns the backoff of the route with the longest key that the group key belongs to.
+func (s *repeatBackoffStage) backoffFor(gkey string) (RepeatBackoff, bool) {
+	var (
+		backoff RepeatBackoff
+		found   string
+	)
+	for key, b := range s.backoffs {
+		if strings.HasPrefix(gkey, key+":") && len(key) > len(found) {
+			backoff, found = b, key
+		}
+	}
+	return backoff, found != ""
+}
+
+// receiverKey returns the key of the receiver, in the same format as the notification log.
+func receiverKey(r *nflogpb.Receiver) string {
+	return fmt.Sprintf("%s/%s/%d", r.GroupName, r.Integration, r.Idx)
+}
+
+// repeatCountKey identifies the count of repeat notifications of an integration for an aggregation group.
+type repeatCountKey struct {
+	GroupKey string `json:"groupKey"`
+	Receiver string `json:"receiver"`
+}
+
+// repeatCount is the count of repeat notifications of an integration for an aggregation group, for its firing alerts.
+type repeatCount struct {
+	repeatCountKey
+	Firing    []uint64  `json:"firing,omitempty"`
+	Repeats   uint64    `json:"repeats"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// repeatCounts stores the counts of repeat notifications of the aggregation groups with a repeat backoff.
+// It implements cluster.State so that the counts are replicated like the notification log. The counts are snapshotted
+// with the notification log, see nflogSnapshotState.
+type repeatCounts struct {
+	mtx       sync.RWMutex
+	entries   map[repeatCountKey]repeatCount
+	broadcast func([]byte)
+	now       func() time.Time
+}
+
+func newRepeatCounts() *repeatCounts {
+	return &repeatCounts{
+		entries:   map[repeatCountKey]repeatCount{},
+		broadcast: func([]byte) {},
+		now:       time.Now,
+	}
+}
+
+// SetBroadcast sets the function that replicates the updates to the other peers.
+func (c *repeatCounts) SetBroadcast(f func([]byte)) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.broadcast = f
+}
+
+func (c *repeatCounts) get(key repeatCountKey) (repeatCount, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	e, ok := c.entries[key]
+	if !ok || !e.ExpiresAt.After(c.now()) {
+		return repeatCount{}, false
+	}
+	return e, true
+}
+
+func (c *repeatCounts) set(key repeatCountKey, firing []uint64, repeats uint64, expiry time.Duration) error {
+	now := c.now()
+	e := repeatCount{
+		repeatCountKey: key,
+		Firing:         firing,
+		Repeats:        repeats,
+		UpdatedAt:      now,
+		ExpiresAt:      now.Add(expiry),
+	}
+	b, err := json.Marshal([]repeatCount{e})
+	if err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	c.entries[key] = e
+	broadcast := c.broadcast
+	c.mtx.Unlock()
+	broadcast(b)
+	return nil
+}
+
+// GC deletes the expired counts, and returns how many were deleted.
+func (c *repeatCounts) GC() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	now := c.now()
+	n := 0
+	for k, e := range c.entries {
+		if !e.ExpiresAt.After(now) {
+			delete(c.entries, k)
+			n++
+		}
+	}
+	return n
+}
+
+// MarshalBinary returns all the counts. It implements cluster.State.
+func (c *repeatCounts) MarshalBinary() ([]byte, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	entries := make([]repeatCount, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	return json.Marshal(entries)
+}
+
+// Merge merges the counts of another peer, as returned by MarshalBinary. The most recently updated count wins.
+// It implements cluster.State.
+func (c *repeatCounts) Merge(b []byte) error {
+	var entries []repeatCount
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	now := c.now()
+	for _, e := range entries {
+		if !e.ExpiresAt.After(now) {
+			continue
+		}
+		if prev, ok := c.entries[e.repeatCountKey]; ok && !e.UpdatedAt.After(prev.UpdatedAt) {
+			continue
+		}
+		c.entries[e.repeatCountKey] = e
+	}
+	return nil
+}
+
+func firingFingerprints(alerts []*types.Alert) []uint64 {
+	firing := make([]uint64, 0, len(alerts))
+	for _, a := range alerts {
+		if !a.Resolved() {
+			firing = append(firing, uint64(a.Fingerprint()))
+		}
+	}
+	sort.Slice(firing, func(i, j int) bool { return firing[i] < firing[j] })
+	return firing
+}
+
+func equalFingerprints(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// repeatCountsIntegration is the integration of the entry of the notification log snapshots that has the repeat counts.
+const repeatCountsIntegration = "__repeat_counts__"
+
+// nflogSnapshotState is the State of the snapshots of the notification log. The snapshots have an additional entry
+// with the repeat counts, so that they are restored along with the notification log. The entry is in the format of
+// the notification log, so that the snapshots can still be read by the notification log alone.
+type nflogSnapshotState struct {
+	nflog  *nflog.Log
+	counts *repeatCounts
+}
+
+// MarshalBinary implements the State interface.
+func (s *nflogSnapshotState) MarshalBinary() ([]byte, error) {
+	b, err := s.nflog.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	s.counts.mtx.RLock()
+	var expiresAt time.Time
+	for _, e := range s.counts.entries {
+		if e.ExpiresAt.After(expiresAt) {
+			expiresAt = e.ExpiresAt
+		}
+	}
+	s.counts.mtx.RUnlock()
+	if expiresAt.IsZero() {
+		return b, nil
+	}
+	counts, err := s.counts.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(b)
+	if _, err := pbutil.WriteDelimited(buf, &nflogpb.MeshEntry{
+		Entry: &nflogpb.Entry{
+			GroupKey:  []byte(repeatCountsIntegration),
+			Receiver:  &nflogpb.Receiver{Integration: repeatCountsIntegration},
+			GroupHash: counts,
+			Timestamp: s.counts.now(),
+		},
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// splitNflogSnapshot returns the snapshot of the notification log without the entry of the repeat counts, and the
+// repeat counts of the entry, which are nil if the snapshot has none.
+func splitNflogSnapshot(snapshot string) (string, []byte, error) {
+	var (
+		r      = strings.NewReader(snapshot)
+		buf    bytes.Buffer
+		counts []byte
+	)
+	for {
+		var e nflogpb.MeshEntry
+		if _, err := pbutil.ReadDelimited(r, &e); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", nil, err
+		}
+		if e.Entry != nil && e.Entry.Receiver != nil && e.Entry.Receiver.Integration == repeatCountsIntegration {
+			counts = e.Entry.GroupHash
+			continue
+		}
+		if _, err := pbutil.WriteDelimited(&buf, &e); err != nil {
+			return "", nil, err
+		}
+	}
+	return buf.String(), counts, nil
+}
@@ -0,0 +1,327 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewGrafanaRoute(t *testing.T) {
+	maxInterval := model.Duration(time.Hour)
+	teamA, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	teamB, err := labels.NewMatcher(labels.MatchEqual, "team", "b")
+	require.NoError(t, err)
+	root := &definition.Route{
+		Receiver:      "default",
+		RepeatBackoff: &RepeatBackoff{Multiplier: 2},
+		Routes: []*definition.Route{
+			{
+				Receiver: "inherited",
+				Matchers: config.Matchers{teamA},
+			},
+			{
+				Receiver:      "own",
+				Matchers:      config.Matchers{teamB},
+				RepeatBackoff: &RepeatBackoff{Multiplier: 3, MaxRepeatInterval: &maxInterval},
+			},
+		},
+	}
+
+	dr, backoffs := newGrafanaRoute(root)
+	require.Equal(t, "default", dr.RouteOpts.Receiver)
+	require.Equal(t, "own", dr.Routes[1].RouteOpts.Receiver)
+	require.Equal(t, map[string]RepeatBackoff{
+		dr.Key():           {Multiplier: 2},
+		dr.Routes[0].Key(): {Multiplier: 2},
+		dr.Routes[1].Key(): {Multiplier: 3, MaxRepeatInterval: &maxInterval},
+	}, backoffs)
+
+	_, backoffs = newGrafanaRoute(&definition.Route{Receiver: "default"})
+	require.Empty(t, backoffs)
+}
+
+// fakeSendStage records the repeat interval it is executed with and sends the alerts if send is true.
+type fakeSendStage struct {
+	send      bool
+	intervals []time.Duration
+}
+
+func (s *fakeSendStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	repeat, _ := notify.RepeatInterval(ctx)
+	s.intervals = append(s.intervals, repeat)
+	if !s.send {
+		return ctx, nil, nil
+	}
+	return ctx, alerts, nil
+}
+
+func TestRepeatBackoffStage(t *testing.T) {
+	am, _ := setupAMTest(t)
+	recv := &nflogpb.Receiver{GroupName: "receiver", Integration: "pagerduty"}
+	maxInterval := model.Duration(5 * time.Hour)
+	backoffs := map[string]RepeatBackoff{
+		"{}/{team=\"a\"}": {Multiplier: 2, MaxRepeatInterval: &maxInterval},
+	}
+
+	inner := &fakeSendStage{send: true}
+	stage := newRepeatBackoffStage(inner, am.repeatCounts, recv, backoffs)
+
+	now := time.Now()
+	firing := func(name string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": model.LabelValue(name)}, StartsAt: now, EndsAt: now.Add(time.Hour)}}
+	}
+	exec := func(gkey string, alerts ...*types.Alert) {
+		ctx := notify.WithGroupKey(context.Background(), gkey)
+		ctx = notify.WithRepeatInterval(ctx, time.Hour)
+		_, _, err := stage.Exec(ctx, log.NewNopLogger(), alerts...)
+		require.NoError(t, err)
+	}
+
+	gkey := "{}/{team=\"a\"}:{}"
+	a, b := firing("a"), firing("b")
+
+	// The first notification and every repeat double the interval until the maximum.
+	for i := 0; i < 5; i++ {
+		exec(gkey, a)
+	}
+	require.Equal(t, []time.Duration{time.Hour, time.Hour, 2 * time.Hour, 4 * time.Hour, 5 * time.Hour}, inner.intervals)
+
+	// A change in the firing alerts resets the backoff.
+	inner.intervals = nil
+	exec(gkey, a, b)
+	exec(gkey, a, b)
+	require.Equal(t, []time.Duration{time.Hour, time.Hour}, inner.intervals)
+
+	// The count does not grow when nothing is sent.
+	inner.intervals = nil
+	inner.send = false
+	exec(gkey, a, b)
+	exec(gkey, a, b)
+	require.Equal(t, []time.Duration{2 * time.Hour, 2 * time.Hour}, inner.intervals)
+
+	// Groups of routes without a backoff keep the repeat interval.
+	inner.intervals = nil
+	inner.send = true
+	exec("{}/{team=\"b\"}:{}", a)
+	exec("{}/{team=\"b\"}:{}", a)
+	require.Equal(t, []time.Duration{time.Hour, time.Hour}, inner.intervals)
+
+	// The counts are not stored in the notification log.
+	nflogState, err := am.notificationLog.MarshalBinary()
+	require.NoError(t, err)
+	require.Empty(t, nflogState)
+
+	t.Run("without backoffs the stage is not wrapped", func(t *testing.T) {
+		require.Equal(t, notify.Stage(inner), newRepeatBackoffStage(inner, am.repeatCounts, recv, nil))
+	})
+}
+
+func TestRepeatCounts(t *testing.T) {
+	now := time.Now()
+	key := repeatCountKey{GroupKey: "{}:{}", Receiver: "receiver/pagerduty/0"}
+
+	var broadcast []byte
+	c := newRepeatCounts()
+	c.now = func() time.Time { return now }
+	c.SetBroadcast(func(b []byte) { broadcast = b })
+	require.NoError(t, c.set(key, []uint64{1, 2}, 3, time.Hour))
+
+	e, ok := c.get(key)
+	require.True(t, ok)
+	require.Equal(t, []uint64{1, 2}, e.Firing)
+	require.Equal(t, uint64(3), e.Repeats)
+
+	t.Run("updates are replicated and the most recent wins", func(t *testing.T) {
+		other := newRepeatCounts()
+		other.now = c.now
+		require.NoError(t, other.Merge(broadcast))
+		e, ok := other.get(key)
+		require.True(t, ok)
+		require.Equal(t, uint64(3), e.Repeats)
+
+		other.now = func() time.Time { return now.Add(time.Minute) }
+		require.NoError(t, other.set(key, []uint64{1, 2}, 4, time.Hour))
+		b, err := other.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, c.Merge(b))
+		e, _ = c.get(key)
+		require.Equal(t, uint64(4), e.Repeats)
+
+		// Older counts are ignored.
+		require.NoError(t, other.Merge(broadcast))
+		e, _ = other.get(key)
+		require.Equal(t, uint64(4), e.Repeats)
+	})
+
+	t.Run("expired counts are ignored and deleted", func(t *testing.T) {
+		c.now = func() time.Time { return now.Add(2 * time.Hour) }
+		_, ok := c.get(key)
+		require.False(t, ok)
+		require.Equal(t, 1, c.GC())
+		require.Equal(t, 0, c.GC())
+	})
+}
+
+func TestRepeatCounts_Restart(t *testing.T) {
+	newAM := func(nflogOpts MaintenanceOptions) *GrafanaAlertmanager {
+		am, err := NewGrafanaAlertmanager("org", 1, &GrafanaAlertmanagerConfig{
+			Silences: newFakeMaintanenceOptions(t),
+			Nflog:    nflogOpts,
+		}, &NilPeer{}, log.NewNopLogger(), NewGrafanaAlertmanagerMetrics(prometheus.NewPedanticRegistry(), log.NewNopLogger()))
+		require.NoError(t, err)
+		return am
+	}
+	key := repeatCountKey{GroupKey: "{}/{team=\"a\"}:{}", Receiver: "receiver/pagerduty/0"}
+	receiver := &nflogpb.Receiver{GroupName: "receiver", Integration: "pagerduty", Idx: 0}
+
+	opts := &snapshotMaintenanceOptions{}
+	am := newAM(opts)
+	require.NoError(t, am.repeatCounts.set(key, []uint64{1}, 3, time.Hour))
+	require.NoError(t, am.notificationLog.Log(receiver, key.GroupKey, []uint64{1}, nil, time.Hour))
+	am.StopAndWait()
+
+	// The Alertmanager restarts from the last snapshot of the notification log.
+	opts.mtx.Lock()
+	require.NotEmpty(t, opts.snapshots)
+	snapshot, err := opts.snapshots[len(opts.snapshots)-1].MarshalBinary()
+	opts.mtx.Unlock()
+	require.NoError(t, err)
+
+	restarted := newAM(&initialStateMaintenanceOptions{initialState: string(snapshot)})
+	t.Cleanup(restarted.StopAndWait)
+	e, ok := restarted.repeatCounts.get(key)
+	require.True(t, ok)
+	require.Equal(t, uint64(3), e.Repeats)
+	require.Equal(t, []uint64{1}, e.Firing)
+
+	entries, err := restarted.notificationLog.Query(nflog.QReceiver(receiver), nflog.QGroupKey(key.GroupKey))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, []uint64{1}, entries[0].FiringAlerts)
+
+	// The entry of the repeat counts is not an entry of the notification log.
+	_, err = restarted.notificationLog.Query(nflog.QReceiver(&nflogpb.Receiver{Integration: repeatCountsIntegration}), nflog.QGroupKey(repeatCountsIntegration))
+	require.ErrorIs(t, err, nflog.ErrNotFound)
+
+	t.Run("snapshots of the notification log alone", func(t *testing.T) {
+		l, err := nflog.New(nflog.Options{SnapshotReader: bytes.NewReader(snapshot)})
+		require.NoError(t, err)
+		_, err = l.Query(nflog.QReceiver(receiver), nflog.QGroupKey(key.GroupKey))
+		require.NoError(t, err)
+	})
+
+	t.Run("counts start over without snapshots", func(t *testing.T) {
+		am := newAM(newFakeMaintanenceOptions(t))
+		t.Cleanup(am.StopAndWait)
+		_, ok := am.repeatCounts.get(key)
+		require.False(t, ok)
+	})
+}
+
+// initialStateMaintenanceOptions are maintenance options that load the initial state.
+type initialStateMaintenanceOptions struct {
+	fakeMaintenanceOptions
+	initialState string
+}
+
+func (o *initialStateMaintenanceOptions) InitialState() string {
+	return o.initialState
+}
+
+// timestampNotifier records the time of every notification.
+type timestampNotifier struct {
+	mtx   sync.Mutex
+	times []time.Time
+}
+
+func (n *timestampNotifier) Notify(context.Context, ...*types.Alert) (bool, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.times = append(n.times, time.Now())
+	return false, nil
+}
+
+func (n *timestampNotifier) gaps() []time.Duration {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	var gaps []time.Duration
+	for i := 1; i < len(n.times); i++ {
+		gaps = append(gaps, n.times[i].Sub(n.times[i-1]))
+	}
+	return gaps
+}
+
+// retentionMaintenanceOptions are maintenance options that keep the state for an hour.
+type retentionMaintenanceOptions struct {
+	fakeMaintenanceOptions
+}
+
+func (o *retentionMaintenanceOptions) Retention() time.Duration {
+	return time.Hour
+}
+
+func TestApplyConfig_RepeatBackoff(t *testing.T) {
+	// The notification log must keep its entries for longer than the repeat intervals.
+	am, err := NewGrafanaAlertmanager("org", 1, &GrafanaAlertmanagerConfig{
+		Silences: newFakeMaintanenceOptions(t),
+		Nflog:    &retentionMaintenanceOptions{},
+	}, &NilPeer{}, log.NewNopLogger(), NewGrafanaAlertmanagerMetrics(prometheus.NewPedanticRegistry(), log.NewNopLogger()))
+	require.NoError(t, err)
+	t.Cleanup(am.StopAndWait)
+	n := &timestampNotifier{}
+
+	groupWait, groupInterval, repeatInterval := model.Duration(0), model.Duration(10*time.Millisecond), model.Duration(100*time.Millisecond)
+	cfg, err := NewPostableConfiguration(&definition.PostableApiAlertingConfig{
+		Config: definition.Config{
+			Route: &definition.Route{
+				Receiver:       "receiver",
+				GroupByStr:     []string{"alertname"},
+				GroupBy:        []model.LabelName{"alertname"},
+				GroupWait:      &groupWait,
+				GroupInterval:  &groupInterval,
+				RepeatInterval: &repeatInterval,
+				RepeatBackoff:  &RepeatBackoff{Multiplier: 2},
+			},
+		},
+		Receivers: []*definition.PostableApiReceiver{{Receiver: config.Receiver{Name: "receiver"}}},
+	}, nil, nil, func(next *APIReceiver, _ *templates.Template) ([]*Integration, error) {
+		return []*Integration{NewIntegration(n, &fakeNotifier{}, "webhook", 0, next.Name)}, nil
+	})
+	require.NoError(t, err)
+	am.WithLock(func() {
+		require.NoError(t, am.ApplyConfig(cfg))
+	})
+
+	now := time.Now()
+	require.NoError(t, am.PutAlerts(amv2.PostableAlerts{{
+		Alert:    amv2.Alert{Labels: amv2.LabelSet{"alertname": "a"}},
+		StartsAt: strfmt.DateTime(now),
+		EndsAt:   strfmt.DateTime(now.Add(time.Hour)),
+	}}))
+
+	// The repeat interval doubles after every repeat notification: 100ms, then 200ms, then 400ms.
+	require.Eventually(t, func() bool { return len(n.gaps()) >= 3 }, 5*time.Second, 10*time.Millisecond)
+	gaps := n.gaps()
+	require.GreaterOrEqual(t, gaps[0], 100*time.Millisecond)
+	require.GreaterOrEqual(t, gaps[1], 200*time.Millisecond)
+	require.GreaterOrEqual(t, gaps[2], 400*time.Millisecond)
+}
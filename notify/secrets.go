@@ -0,0 +1,424 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/notify/nfstatus"
+)
+
+// DefaultSecretRefreshInterval is the default interval between the resolutions of the referenced secrets.
+const DefaultSecretRefreshInterval = 5 * time.Minute
+
+// SecretProvider provides the secrets referenced by ${provider:name/key}, where name is the name of the provider.
+type SecretProvider interface {
+	// GetSecret returns the secret with the key.
+	GetSecret(ctx context.Context, key string) (string, error)
+}
+
+// secretReference matches the references to secrets, see SecretResolver.
+var secretReference = regexp.MustCompile(`\$\{(file|env|provider):([^}]+)\}`)
+
+// SecretReferenceError is returned by BuildReceiverConfiguration when a secret referenced by a setting
+// of an integration cannot be resolved.
+type SecretReferenceError struct {
+	// Path is the path of the setting, such as secureSettings.url or settings.url.
+	Path      string
+	Reference string
+	Err       error
+}
+
+func (e SecretReferenceError) Error() string {
+	msg := fmt.Sprintf("failed to resolve secret reference %q: %s", e.Reference, e.Err)
+	if e.Path == "" {
+		return msg
+	}
+	return e.Path + ": " + msg
+}
+
+func (e SecretReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// SecretResolver resolves the references to secrets in the secure settings of integrations, or in the settings
+// that can be secure. The references are:
+//
+//	${provider:vault/pd-key}    the secret pd-key of the SecretProvider named vault
+//	${file:/run/secrets/slack}  the content of the file, without the trailing newline, see WithFileSecrets
+//	${env:ALERTING_PD_KEY}      the value of the environment variable, see WithEnvSecrets
+//
+// Only the references to the named providers are resolved by default, as whoever can write the settings of an
+// integration could otherwise read any file or environment variable of the process.
+// A setting can contain several references, or a reference and other text, such as https://hooks.slack.com/${provider:vault/slack-path}.
+// The resolved secrets are cached until they are resolved again by Refresh.
+type SecretResolver struct {
+	providers map[string]SecretProvider
+	// fileDirs are the directories of the files that ${file:...} can read. Files cannot be read if it is empty.
+	fileDirs []string
+	// envPrefix is the prefix of the environment variables that ${env:...} can read. Environment variables cannot be
+	// read if it is empty.
+	envPrefix string
+	lookupEnv func(string) (string, bool)
+	readFile  func(string) ([]byte, error)
+
+	mtx   sync.Mutex
+	cache map[string]string
+	// receivers are the names of the receivers that reference each secret, see BuildReceiverConfiguration.
+	receivers map[string]map[string]struct{}
+}
+
+// SecretResolverOption configures optional behaviour of a SecretResolver.
+type SecretResolverOption func(*SecretResolver)
+
+// WithFileSecrets enables ${file:...} for the files in the directories, or in their subdirectories.
+// The paths are resolved with their symbolic links, so that a link cannot point outside of the directories.
+func WithFileSecrets(dirs ...string) SecretResolverOption {
+	return func(r *SecretResolver) {
+		for _, dir := range dirs {
+			if dir == "" {
+				continue
+			}
+			if abs, err := filepath.Abs(dir); err == nil {
+				dir = abs
+			}
+			if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+				dir = resolved
+			}
+			r.fileDirs = append(r.fileDirs, filepath.Clean(dir))
+		}
+	}
+}
+
+// WithEnvSecrets enables ${env:...} for the environment variables whose names start with the prefix, such as
+// ALERTING_. The prefix is required, ${env:...} stays disabled if it is empty.
+func WithEnvSecrets(prefix string) SecretResolverOption {
+	return func(r *SecretResolver) {
+		r.envPrefix = prefix
+	}
+}
+
+// NewSecretResolver returns a SecretResolver with the providers of ${provider:name/key} keyed by name.
+func NewSecretResolver(providers map[string]SecretProvider, opts ...SecretResolverOption) *SecretResolver {
+	r := &SecretResolver{
+		providers: providers,
+		lookupEnv: os.LookupEnv,
+		readFile:  os.ReadFile,
+		cache:     map[string]string{},
+		receivers: map[string]map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve replaces the references to secrets in the value with the secrets. The value is returned as is if it has
+// no references. It returns a SecretReferenceError for the first reference that cannot be resolved.
+func (r *SecretResolver) Resolve(ctx context.Context, value string) (string, error) {
+	receiver, _ := ctx.Value(secretReceiverKey{}).(string)
+	var refErr error
+	res := secretReference.ReplaceAllStringFunc(value, func(ref string) string {
+		if refErr != nil {
+			return ref
+		}
+		r.mtx.Lock()
+		if receiver != "" {
+			if r.receivers[ref] == nil {
+				r.receivers[ref] = map[string]struct{}{}
+			}
+			r.receivers[ref][receiver] = struct{}{}
+		}
+		secret, ok := r.cache[ref]
+		r.mtx.Unlock()
+		if ok {
+			return secret
+		}
+		secret, err := r.resolve(ctx, ref)
+		if err != nil {
+			refErr = SecretReferenceError{Reference: ref, Err: err}
+			return ref
+		}
+		r.mtx.Lock()
+		r.cache[ref] = secret
+		r.mtx.Unlock()
+		return secret
+	})
+	if refErr != nil {
+		return "", refErr
+	}
+	return res, nil
+}
+
+// detached returns a SecretResolver with the same providers, and a copy of the cached secrets, whose resolutions do
+// not change the cache or the recorded receivers of r, so that the configurations that are not applied, such as the
+// ones of previews, do not affect the secrets refreshed by r.
+func (r *SecretResolver) detached() *SecretResolver {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	cache := make(map[string]string, len(r.cache))
+	for ref, secret := range r.cache {
+		cache[ref] = secret
+	}
+	return &SecretResolver{
+		providers: r.providers,
+		fileDirs:  r.fileDirs,
+		envPrefix: r.envPrefix,
+		lookupEnv: r.lookupEnv,
+		readFile:  r.readFile,
+		cache:     cache,
+		receivers: map[string]map[string]struct{}{},
+	}
+}
+
+// Refresh resolves the cached references again. It returns the sorted references whose secrets changed. The previous
+// secrets are kept for the references that cannot be resolved.
+func (r *SecretResolver) Refresh(ctx context.Context) ([]string, error) {
+	r.mtx.Lock()
+	refs := make([]string, 0, len(r.cache))
+	for ref := range r.cache {
+		refs = append(refs, ref)
+	}
+	r.mtx.Unlock()
+	sort.Strings(refs)
+
+	var changed []string
+	var errs []error
+	for _, ref := range refs {
+		secret, err := r.resolve(ctx, ref)
+		if err != nil {
+			errs = append(errs, SecretReferenceError{Reference: ref, Err: err})
+			continue
+		}
+		r.mtx.Lock()
+		if r.cache[ref] != secret {
+			r.cache[ref] = secret
+			changed = append(changed, ref)
+		}
+		r.mtx.Unlock()
+	}
+	return changed, errors.Join(errs...)
+}
+
+// receiversOf returns the names of the receivers built with any of the references.
+func (r *SecretResolver) receiversOf(refs []string) map[string]struct{} {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	res := map[string]struct{}{}
+	for _, ref := range refs {
+		for name := range r.receivers[ref] {
+			res[name] = struct{}{}
+		}
+	}
+	return res
+}
+
+// beginApply starts recording the references of the receivers of a configuration being applied. It returns the
+// receivers of the references of the applied configuration, which endApply restores if the configuration is not applied.
+func (r *SecretResolver) beginApply() map[string]map[string]struct{} {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	prev := r.receivers
+	r.receivers = map[string]map[string]struct{}{}
+	return prev
+}
+
+// endApply keeps the references recorded since beginApply if the configuration was applied, or restores prev. The
+// secrets of the references that no receiver uses anymore are dropped, so that they are not refreshed.
+func (r *SecretResolver) endApply(prev map[string]map[string]struct{}, applied bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if !applied {
+		r.receivers = prev
+	}
+	for ref := range r.cache {
+		if _, ok := r.receivers[ref]; !ok {
+			delete(r.cache, ref)
+		}
+	}
+}
+
+func (r *SecretResolver) resolve(ctx context.Context, ref string) (string, error) {
+	m := secretReference.FindStringSubmatch(ref)
+	kind, key := m[1], m[2]
+	switch kind {
+	case "file":
+		path, err := r.secretFile(key)
+		if err != nil {
+			return "", err
+		}
+		b, err := r.readFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case "env":
+		if r.envPrefix == "" {
+			return "", errors.New("environment variable references are not enabled")
+		}
+		if !strings.HasPrefix(key, r.envPrefix) {
+			return "", fmt.Errorf("environment variable %s does not have the prefix %s", key, r.envPrefix)
+		}
+		v, ok := r.lookupEnv(key)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", key)
+		}
+		return v, nil
+	default:
+		name, key, ok := strings.Cut(key, "/")
+		if !ok || key == "" {
+			return "", errors.New("expected ${provider:name/key}")
+		}
+		p, ok := r.providers[name]
+		if !ok {
+			return "", fmt.Errorf("unknown secret provider %s", name)
+		}
+		return p.GetSecret(ctx, key)
+	}
+}
+
+// secretFile returns the path of the file referenced by ${file:path}, if it is in one of the allowed directories.
+// The path is checked before and after its symbolic links are resolved, so that the files outside of the directories
+// are not accessed at all, and links cannot point outside of them.
+func (r *SecretResolver) secretFile(path string) (string, error) {
+	if len(r.fileDirs) == 0 {
+		return "", errors.New("file references are not enabled")
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("file %s is not an absolute path", path)
+	}
+	path = filepath.Clean(path)
+	if !r.inFileDirs(path) {
+		return "", fmt.Errorf("file %s is not in an allowed directory", path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !r.inFileDirs(resolved) {
+		return "", fmt.Errorf("file %s is not in an allowed directory", path)
+	}
+	return resolved, nil
+}
+
+func (r *SecretResolver) inFileDirs(path string) bool {
+	for _, dir := range r.fileDirs {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+type secretResolverKey struct{}
+
+// secretReceiverKey is the key of the name of the receiver whose settings are resolved.
+type secretReceiverKey struct{}
+
+// WithSecretResolver returns a context with the SecretResolver, which BuildReceiverConfiguration uses to resolve
+// the references to secrets in the decrypted settings of the integrations.
+func WithSecretResolver(ctx context.Context, r *SecretResolver) context.Context {
+	return context.WithValue(ctx, secretResolverKey{}, r)
+}
+
+// SecretResolverFromContext returns the SecretResolver of the context, or nil if there is none.
+func SecretResolverFromContext(ctx context.Context) *SecretResolver {
+	r, _ := ctx.Value(secretResolverKey{}).(*SecretResolver)
+	return r
+}
+
+// refreshSecrets resolves the referenced secrets periodically, and rebuilds the integrations of the receivers that
+// reference the secrets that changed, so that they use the rotated secrets.
+func (am *GrafanaAlertmanager) refreshSecrets(r *SecretResolver, interval time.Duration, stopc <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+		}
+		changed, err := r.Refresh(context.Background())
+		if err != nil {
+			level.Warn(am.logger).Log("msg", "Failed to resolve secrets, keeping the previous values", "err", err)
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		names := r.receiversOf(changed)
+		am.WithLock(func() {
+			if err := am.rebuildReceivers(names); err != nil {
+				level.Error(am.logger).Log("msg", "Failed to rebuild the receivers with the new secrets, keeping the previous integrations", "err", err)
+			}
+		})
+	}
+}
+
+// rebuildReceivers builds the integrations of the receivers of the applied configuration with the names again, and
+// swaps them into the notification pipeline without restarting the dispatcher. The receivers that cannot be built
+// keep their previous integrations.
+func (am *GrafanaAlertmanager) rebuildReceivers(names map[string]struct{}) error {
+	if am.appliedConfig == nil {
+		return nil
+	}
+	var errs []error
+	for _, apiReceiver := range am.appliedConfig.Receivers() {
+		if _, ok := names[apiReceiver.Name]; !ok {
+			continue
+		}
+		stage, ok := am.receiverStages[apiReceiver.Name]
+		if !ok {
+			continue
+		}
+		integrations, err := am.buildReceiverIntegrationsFunc(apiReceiver, am.tmpl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("receiver %s: %w", apiReceiver.Name, err))
+			continue
+		}
+		level.Info(am.logger).Log("msg", "Referenced secrets changed, rebuilding the receiver", "receiver", apiReceiver.Name)
+		stage.set(am.newReceiverStage(apiReceiver.Name, integrations))
+		// The receivers are copied, as GetReceivers reads them without the lock.
+		receivers := make([]*nfstatus.Receiver, 0, len(am.receivers))
+		for _, r := range am.receivers {
+			if r.Name() == apiReceiver.Name {
+				r = nfstatus.NewReceiver(r.Name(), r.Active(), integrations)
+			}
+			receivers = append(receivers, r)
+		}
+		am.receivers = receivers
+	}
+	return errors.Join(errs...)
+}
+
+// swappableStage is the stage of a receiver, which is swapped when the integrations of the receiver are rebuilt.
+type swappableStage struct {
+	mtx   sync.RWMutex
+	stage notify.Stage
+}
+
+func (s *swappableStage) set(stage notify.Stage) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.stage = stage
+}
+
+// Exec implements the notify.Stage interface.
+func (s *swappableStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	s.mtx.RLock()
+	stage := s.stage
+	s.mtx.RUnlock()
+	return stage.Exec(ctx, l, alerts...)
+}
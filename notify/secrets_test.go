@@ -0,0 +1,387 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers/discord"
+	"github.com/grafana/alerting/templates"
+)
+
+type fakeSecretProvider map[string]string
+
+func (p fakeSecretProvider) GetSecret(_ context.Context, key string) (string, error) {
+	v, ok := p[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestSecretResolver(t *testing.T) {
+	ctx := context.Background()
+	dir, outside := t.TempDir(), t.TempDir()
+	path := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+	outsidePath := filepath.Join(outside, "secret")
+	require.NoError(t, os.WriteFile(outsidePath, []byte("outside"), 0o600))
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(outsidePath, link))
+	provider := fakeSecretProvider{"key": "from-provider"}
+	r := NewSecretResolver(map[string]SecretProvider{"vault": provider}, WithFileSecrets(dir), WithEnvSecrets("ALERTING_"))
+	env := map[string]string{"ALERTING_KEY": "from-env", "DB_PASSWORD": "password"}
+	r.lookupEnv = func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	cases := []struct {
+		value    string
+		expected string
+		err      string
+	}{
+		{value: "plain", expected: "plain"},
+		{value: "${unknown:key}", expected: "${unknown:key}"},
+		{value: "${file:" + path + "}", expected: "from-file"},
+		{value: "${env:ALERTING_KEY}", expected: "from-env"},
+		{value: "${provider:vault/key}", expected: "from-provider"},
+		{value: "https://example.com/${env:ALERTING_KEY}/${provider:vault/key}", expected: "https://example.com/from-env/from-provider"},
+		{value: "${env:ALERTING_MISSING}", err: `failed to resolve secret reference "${env:ALERTING_MISSING}": environment variable ALERTING_MISSING is not set`},
+		{value: "${env:DB_PASSWORD}", err: "environment variable DB_PASSWORD does not have the prefix ALERTING_"},
+		{value: "${file:" + filepath.Join(dir, "missing") + "}", err: "no such file or directory"},
+		{value: "${file:" + outsidePath + "}", err: "is not in an allowed directory"},
+		{value: "${file:" + filepath.Join(dir, "..", filepath.Base(outside), "secret") + "}", err: "is not in an allowed directory"},
+		{value: "${file:" + link + "}", err: "is not in an allowed directory"},
+		{value: "${file:secret}", err: "is not an absolute path"},
+		{value: "${provider:other/key}", err: "unknown secret provider other"},
+		{value: "${provider:vault}", err: "expected ${provider:name/key}"},
+		{value: "${provider:vault/missing}", err: "not found"},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			v, err := r.Resolve(ctx, c.value)
+			if c.err != "" {
+				require.ErrorContains(t, err, c.err)
+				var refErr SecretReferenceError
+				require.ErrorAs(t, err, &refErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, v)
+		})
+	}
+
+	t.Run("files and environment variables are disabled by default", func(t *testing.T) {
+		r := NewSecretResolver(map[string]SecretProvider{"vault": provider})
+		_, err := r.Resolve(ctx, "${file:"+path+"}")
+		require.ErrorContains(t, err, "file references are not enabled")
+		_, err = r.Resolve(ctx, "${env:ALERTING_KEY}")
+		require.ErrorContains(t, err, "environment variable references are not enabled")
+		v, err := r.Resolve(ctx, "${provider:vault/key}")
+		require.NoError(t, err)
+		require.Equal(t, "from-provider", v)
+
+		r = NewSecretResolver(nil, WithEnvSecrets(""))
+		_, err = r.Resolve(ctx, "${env:ALERTING_KEY}")
+		require.ErrorContains(t, err, "environment variable references are not enabled")
+	})
+
+	t.Run("secrets are cached until refreshed", func(t *testing.T) {
+		env["ALERTING_KEY"] = "rotated"
+		v, err := r.Resolve(ctx, "${env:ALERTING_KEY}")
+		require.NoError(t, err)
+		require.Equal(t, "from-env", v)
+
+		changed, err := r.Refresh(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"${env:ALERTING_KEY}"}, changed)
+		v, err = r.Resolve(ctx, "${env:ALERTING_KEY}")
+		require.NoError(t, err)
+		require.Equal(t, "rotated", v)
+
+		changed, err = r.Refresh(ctx)
+		require.NoError(t, err)
+		require.Empty(t, changed)
+
+		// The previous secrets are kept if they cannot be resolved.
+		delete(env, "ALERTING_KEY")
+		changed, err = r.Refresh(ctx)
+		require.Error(t, err)
+		require.Empty(t, changed)
+		v, err = r.Resolve(ctx, "${env:ALERTING_KEY}")
+		require.NoError(t, err)
+		require.Equal(t, "rotated", v)
+	})
+}
+
+func TestBuildReceiverConfiguration_SecretReferences(t *testing.T) {
+	r := NewSecretResolver(map[string]SecretProvider{"vault": fakeSecretProvider{"discord": "http://localhost/secret"}})
+	ctx := WithSecretResolver(context.Background(), r)
+	build := func(settings string, secureSettings map[string]string) (GrafanaReceiverConfig, error) {
+		return BuildReceiverConfiguration(ctx, &APIReceiver{
+			ConfigReceiver: ConfigReceiver{Name: "receiver"},
+			GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+				UID:            "discord",
+				Type:           "discord",
+				Settings:       json.RawMessage(settings),
+				SecureSettings: secureSettings,
+			}}},
+		}, NoopDecrypt)
+	}
+
+	cfg, err := build(`{}`, map[string]string{"url": "${provider:vault/discord}"})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost/secret", ConfigsOf[discord.Config](cfg)[0].Settings.WebhookURL)
+
+	cfg, err = build(`{"url": "${provider:vault/discord}"}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost/secret", ConfigsOf[discord.Config](cfg)[0].Settings.WebhookURL)
+
+	_, err = build(`{}`, map[string]string{"url": "${provider:vault/missing}"})
+	var refErr SecretReferenceError
+	require.ErrorAs(t, err, &refErr)
+	require.Equal(t, "secureSettings.url", refErr.Path)
+	require.ErrorContains(t, err, `integration (UID discord) of type "discord": secureSettings.url: failed to resolve secret reference "${provider:vault/missing}"`)
+
+	_, err = build(`{"url": "${env:DOES_NOT_EXIST}"}`, nil)
+	require.ErrorAs(t, err, &refErr)
+	require.Equal(t, "settings.url", refErr.Path)
+
+	// References are not resolved without a SecretResolver.
+	cfg, err = BuildReceiverConfiguration(context.Background(), &APIReceiver{
+		GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+			UID:      "discord",
+			Type:     "discord",
+			Settings: json.RawMessage(`{"url": "${env:DOES_NOT_EXIST}"}`),
+		}}},
+	}, NoopDecrypt)
+	require.NoError(t, err)
+	require.Equal(t, "${env:DOES_NOT_EXIST}", ConfigsOf[discord.Config](cfg)[0].Settings.WebhookURL)
+}
+
+// secretsTestConfig is a Configuration with a receiver that references a secret and a receiver that does not. It
+// records the URLs of the receivers it builds, and fails to build the receivers with the URL http://localhost/invalid.
+type secretsTestConfig struct {
+	ctx  context.Context
+	mtx  sync.Mutex
+	urls []string
+}
+
+func (c *secretsTestConfig) DispatcherLimits() DispatcherLimits        { return nil }
+func (c *secretsTestConfig) InhibitRules() []InhibitRule               { return nil }
+func (c *secretsTestConfig) TimeIntervals() []TimeInterval             { return nil }
+func (c *secretsTestConfig) MuteTimeIntervals() []MuteTimeInterval     { return nil }
+func (c *secretsTestConfig) RoutingTree() *Route                       { return &Route{Receiver: "receiver"} }
+func (c *secretsTestConfig) Templates() []templates.TemplateDefinition { return nil }
+func (c *secretsTestConfig) Hash() [16]byte                            { return [16]byte{} }
+func (c *secretsTestConfig) Raw() []byte                               { return nil }
+func (c *secretsTestConfig) Receivers() []*APIReceiver {
+	receiver := func(name, url string) *APIReceiver {
+		return &APIReceiver{
+			ConfigReceiver: ConfigReceiver{Name: name},
+			GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+				UID:            name,
+				Type:           "discord",
+				Settings:       json.RawMessage(`{}`),
+				SecureSettings: map[string]string{"url": url},
+			}}},
+		}
+	}
+	return []*APIReceiver{receiver("receiver", "${env:DISCORD_URL}"), receiver("other", "http://localhost/other")}
+}
+
+func (c *secretsTestConfig) BuildReceiverIntegrationsFunc() func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error) {
+	return func(next *APIReceiver, _ *templates.Template) ([]*Integration, error) {
+		cfg, err := BuildReceiverConfiguration(c.ctx, next, NoopDecrypt)
+		if err != nil {
+			return nil, err
+		}
+		url := ConfigsOf[discord.Config](cfg)[0].Settings.WebhookURL
+		if url == "http://localhost/invalid" {
+			return nil, errors.New("invalid url")
+		}
+		c.mtx.Lock()
+		c.urls = append(c.urls, url)
+		c.mtx.Unlock()
+		return []*Integration{NewIntegration(&fakeNotifier{}, &fakeNotifier{}, "discord", 0, next.Name)}, nil
+	}
+}
+
+func (c *secretsTestConfig) builtURLs() []string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]string{}, c.urls...)
+}
+
+func TestGrafanaAlertmanager_RefreshSecrets(t *testing.T) {
+	url := "http://localhost/1"
+	var mtx sync.Mutex
+	setURL := func(v string) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		url = v
+	}
+	r := NewSecretResolver(nil, WithEnvSecrets("DISCORD_"))
+	r.lookupEnv = func(string) (string, bool) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return url, true
+	}
+
+	am, err := NewGrafanaAlertmanager("org", 1, &GrafanaAlertmanagerConfig{
+		Silences:              newFakeMaintanenceOptions(t),
+		Nflog:                 newFakeMaintanenceOptions(t),
+		SecretResolver:        r,
+		SecretRefreshInterval: 10 * time.Millisecond,
+	}, &NilPeer{}, log.NewNopLogger(), NewGrafanaAlertmanagerMetrics(prometheus.NewRegistry(), log.NewNopLogger()))
+	require.NoError(t, err)
+	t.Cleanup(am.StopAndWait)
+
+	cfg := &secretsTestConfig{ctx: WithSecretResolver(context.Background(), r)}
+	integration := func() *Integration {
+		for _, r := range am.receivers {
+			if r.Name() == "receiver" {
+				return r.Integrations()[0]
+			}
+		}
+		return nil
+	}
+	var dispatcher *dispatch.Dispatcher
+	var previous *Integration
+	am.WithLock(func() {
+		require.NoError(t, am.ApplyConfig(cfg))
+		dispatcher = am.dispatcher
+		previous = integration()
+	})
+	require.Equal(t, []string{"http://localhost/1", "http://localhost/other"}, cfg.builtURLs())
+
+	// Only the receiver that references the secret is built again, and the dispatcher is not restarted.
+	setURL("http://localhost/2")
+	require.Eventually(t, func() bool {
+		urls := cfg.builtURLs()
+		return len(urls) == 3 && urls[2] == "http://localhost/2"
+	}, 5*time.Second, 10*time.Millisecond)
+	am.WithLock(func() {
+		require.Same(t, dispatcher, am.dispatcher)
+		require.NotSame(t, previous, integration())
+		previous = integration()
+	})
+
+	// The configuration is not built again while the secrets do not change.
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, cfg.builtURLs(), 3)
+
+	// The receivers that cannot be built keep their previous integrations.
+	setURL("http://localhost/invalid")
+	require.Eventually(t, func() bool {
+		v, err := r.Resolve(context.Background(), "${env:DISCORD_URL}")
+		return err == nil && v == "http://localhost/invalid"
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, cfg.builtURLs(), 3)
+	am.WithLock(func() {
+		require.Same(t, dispatcher, am.dispatcher)
+		require.Same(t, previous, integration())
+		require.Len(t, am.receivers, 2)
+	})
+}
+
+// secretRefsTestConfig is a timeIntervalsTestConfig whose receiver references a secret with its URL.
+type secretRefsTestConfig struct {
+	timeIntervalsTestConfig
+	ctx context.Context
+	ref string
+}
+
+func (c *secretRefsTestConfig) Receivers() []*APIReceiver {
+	return []*APIReceiver{{
+		ConfigReceiver: ConfigReceiver{Name: "receiver"},
+		GrafanaIntegrations: GrafanaIntegrations{Integrations: []*GrafanaIntegrationConfig{{
+			UID:            "receiver",
+			Type:           "discord",
+			Settings:       json.RawMessage(`{}`),
+			SecureSettings: map[string]string{"url": c.ref},
+		}}},
+	}}
+}
+
+func (c *secretRefsTestConfig) BuildReceiverIntegrationsFunc() func(next *APIReceiver, tmpl *templates.Template) ([]*Integration, error) {
+	return func(next *APIReceiver, _ *templates.Template) ([]*Integration, error) {
+		if _, err := BuildReceiverConfiguration(c.ctx, next, NoopDecrypt); err != nil {
+			return nil, err
+		}
+		return []*Integration{NewIntegration(c.notifier, &fakeNotifier{}, "discord", 0, next.Name)}, nil
+	}
+}
+
+func TestApplyConfig_DropsUnusedSecrets(t *testing.T) {
+	r := NewSecretResolver(map[string]SecretProvider{"vault": fakeSecretProvider{"a": "http://localhost/a", "b": "http://localhost/b"}})
+	am, err := NewGrafanaAlertmanager("org", 1, &GrafanaAlertmanagerConfig{
+		Silences:       newFakeMaintanenceOptions(t),
+		Nflog:          newFakeMaintanenceOptions(t),
+		SecretResolver: r,
+	}, &NilPeer{}, log.NewNopLogger(), NewGrafanaAlertmanagerMetrics(prometheus.NewRegistry(), log.NewNopLogger()))
+	require.NoError(t, err)
+	t.Cleanup(am.StopAndWait)
+
+	n := &countingNotifier{}
+	apply := func(ref string) error {
+		var err error
+		am.WithLock(func() {
+			err = am.ApplyConfig(&secretRefsTestConfig{
+				timeIntervalsTestConfig: timeIntervalsTestConfig{notifier: n},
+				ctx:                     WithSecretResolver(context.Background(), r),
+				ref:                     ref,
+			})
+		})
+		return err
+	}
+	// notified waits for an alert put after a configuration is applied to be notified.
+	notified := func(name string, count int64) {
+		now := time.Now()
+		require.NoError(t, am.PutAlerts(amv2.PostableAlerts{{
+			Alert:    amv2.Alert{Labels: amv2.LabelSet{"alertname": name}},
+			StartsAt: strfmt.DateTime(now),
+			EndsAt:   strfmt.DateTime(now.Add(time.Hour)),
+		}}))
+		require.Eventually(t, func() bool { return n.notified.Load() >= count }, 5*time.Second, 10*time.Millisecond)
+	}
+	cached := func() []string {
+		r.mtx.Lock()
+		defer r.mtx.Unlock()
+		refs := make([]string, 0, len(r.cache))
+		for ref := range r.cache {
+			refs = append(refs, ref)
+		}
+		return refs
+	}
+
+	require.NoError(t, apply("${provider:vault/a}"))
+	notified("a", 1)
+	require.Equal(t, []string{"${provider:vault/a}"}, cached())
+
+	// The secrets that the new configuration does not reference are dropped, so that they are not refreshed.
+	require.NoError(t, apply("${provider:vault/b}"))
+	notified("b", 2)
+	require.Equal(t, []string{"${provider:vault/b}"}, cached())
+	require.Empty(t, r.receiversOf([]string{"${provider:vault/a}"}))
+	require.Equal(t, map[string]struct{}{"receiver": {}}, r.receiversOf([]string{"${provider:vault/b}"}))
+
+	// A configuration that is not applied keeps the references of the applied one.
+	require.Error(t, apply("${provider:vault/missing}"))
+	require.Equal(t, []string{"${provider:vault/b}"}, cached())
+	require.Equal(t, map[string]struct{}{"receiver": {}}, r.receiversOf([]string{"${provider:vault/b}"}))
+}
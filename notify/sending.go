@@ -0,0 +1,295 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// SendingStrategy decides which replicas of the Alertmanager send the notifications, and when.
+type SendingStrategy interface {
+	// Wait returns how long the replica waits before sending the notifications of a flush.
+	Wait() time.Duration
+	// Timeout returns the timeout of the flushes of the aggregation groups with the group interval.
+	Timeout(groupInterval time.Duration) time.Duration
+	// Sends returns true if the replica sends the notifications. It is checked after waiting.
+	Sends() bool
+}
+
+// positionSendingStrategy is the default sending strategy: every replica sends the notifications, after waiting
+// proportionally to its position in the cluster so that the replicas do not send the notifications sent by the
+// replicas before them, according to the notification log replicated through the cluster.
+type positionSendingStrategy struct {
+	peer        ClusterPeer
+	peerTimeout time.Duration
+}
+
+// NewPositionSendingStrategy returns the default SendingStrategy: every replica sends the notifications after
+// waiting its position in the cluster times the peer timeout, unless a replica before it already sent them.
+func NewPositionSendingStrategy(peer ClusterPeer, peerTimeout time.Duration) SendingStrategy {
+	return &positionSendingStrategy{peer: peer, peerTimeout: peerTimeout}
+}
+
+// Wait implements the SendingStrategy interface.
+func (s *positionSendingStrategy) Wait() time.Duration {
+	return time.Duration(s.peer.Position()) * s.peerTimeout
+}
+
+// Timeout implements the SendingStrategy interface.
+func (s *positionSendingStrategy) Timeout(d time.Duration) time.Duration {
+	// time.Duration d relates to the receiver's group_interval. Even with a group interval of 1s,
+	// we need to make sure (non-position-0) peers in the cluster wait before flushing the notifications.
+	if d < notify.MinTimeout {
+		d = notify.MinTimeout
+	}
+	return d + s.Wait()
+}
+
+// Sends implements the SendingStrategy interface.
+func (s *positionSendingStrategy) Sends() bool {
+	return true
+}
+
+// Lease is a lease held by at most one holder at a time, used to elect the leader of the replicas.
+type Lease interface {
+	// TryAcquire acquires the lease for the holder, or renews it if the holder already holds it, for the duration.
+	// It returns false if another holder holds the lease.
+	TryAcquire(ctx context.Context, holder string, duration time.Duration) (bool, error)
+	// Release releases the lease if the holder holds it, so that another holder can acquire it without waiting
+	// for it to expire.
+	Release(ctx context.Context, holder string) error
+}
+
+// MemoryLease is an in-memory Lease, which can be shared by the replicas in the same process, such as in tests.
+type MemoryLease struct {
+	mtx       sync.Mutex
+	holder    string
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+var _ Lease = (*MemoryLease)(nil)
+
+// NewMemoryLease returns a MemoryLease that is not held.
+func NewMemoryLease() *MemoryLease {
+	return &MemoryLease{now: time.Now}
+}
+
+// TryAcquire implements the Lease interface.
+func (l *MemoryLease) TryAcquire(_ context.Context, holder string, duration time.Duration) (bool, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	now := l.now()
+	if l.holder != "" && l.holder != holder && l.expiresAt.After(now) {
+		return false, nil
+	}
+	l.holder = holder
+	l.expiresAt = now.Add(duration)
+	return true, nil
+}
+
+// Release implements the Lease interface.
+func (l *MemoryLease) Release(_ context.Context, holder string) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.holder == holder {
+		l.holder = ""
+		l.expiresAt = time.Time{}
+	}
+	return nil
+}
+
+// Holder returns the holder of the lease, if it has not expired.
+func (l *MemoryLease) Holder() (string, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.holder == "" || !l.expiresAt.After(l.now()) {
+		return "", false
+	}
+	return l.holder, true
+}
+
+// LeaderOptions configures a LeaderSendingStrategy.
+type LeaderOptions struct {
+	// ID identifies the replica as a holder of the lease. It must be unique among the replicas.
+	ID string
+	// LeaseDuration is the duration of the lease, after which a follower takes over if the leader did not renew it.
+	LeaseDuration time.Duration
+	// RenewInterval is the interval at which the leader renews the lease, and the followers try to acquire it.
+	// It must be shorter than the lease duration. Defaults to a third of the lease duration.
+	RenewInterval time.Duration
+	Logger        log.Logger
+}
+
+// LeaderSendingStrategy is a SendingStrategy where only the replica holding the lease, the leader, sends
+// the notifications, without waiting. The followers keep processing the alerts and merging the notification log
+// of the leader, so that when the lease of the leader expires, the follower that acquires it takes over
+// without sending again the notifications already sent. A follower takes over at most the lease duration
+// plus the renew interval after the last renewal of the leader.
+type LeaderSendingStrategy struct {
+	lease         Lease
+	id            string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	logger        log.Logger
+	now           func() time.Time
+
+	mtx sync.RWMutex
+	// leaderUntil is the time until which the replica holds the lease, as of its last acquisition.
+	leaderUntil time.Time
+
+	started  bool
+	stopOnce sync.Once
+	stopc    chan struct{}
+	done     chan struct{}
+}
+
+var _ SendingStrategy = (*LeaderSendingStrategy)(nil)
+
+// NewLeaderSendingStrategy returns a LeaderSendingStrategy. The replica is a follower until the strategy
+// is started and acquires the lease.
+func NewLeaderSendingStrategy(lease Lease, opts LeaderOptions) (*LeaderSendingStrategy, error) {
+	if lease == nil {
+		return nil, errors.New("lease must be set")
+	}
+	if opts.ID == "" {
+		return nil, errors.New("ID of the replica must be set")
+	}
+	if opts.LeaseDuration <= 0 {
+		return nil, errors.New("lease duration must be positive")
+	}
+	if opts.RenewInterval <= 0 {
+		opts.RenewInterval = opts.LeaseDuration / 3
+	}
+	if opts.RenewInterval >= opts.LeaseDuration {
+		return nil, errors.New("renew interval must be shorter than the lease duration")
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.NewNopLogger()
+	}
+	return &LeaderSendingStrategy{
+		lease:         lease,
+		id:            opts.ID,
+		leaseDuration: opts.LeaseDuration,
+		renewInterval: opts.RenewInterval,
+		logger:        log.With(opts.Logger, "component", "leader election", "id", opts.ID),
+		now:           time.Now,
+		stopc:         make(chan struct{}),
+		done:          make(chan struct{}),
+	}, nil
+}
+
+// Start starts acquiring and renewing the lease, until the strategy is stopped.
+func (s *LeaderSendingStrategy) Start() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	go s.run()
+}
+
+// Stop stops renewing the lease and releases it, so that a follower can take over without waiting for it to expire.
+func (s *LeaderSendingStrategy) Stop(ctx context.Context) error {
+	var err error
+	s.stopOnce.Do(func() {
+		close(s.stopc)
+		s.mtx.RLock()
+		started := s.started
+		s.mtx.RUnlock()
+		if started {
+			<-s.done
+		}
+		s.mtx.Lock()
+		s.leaderUntil = time.Time{}
+		s.mtx.Unlock()
+		err = s.lease.Release(ctx, s.id)
+	})
+	return err
+}
+
+func (s *LeaderSendingStrategy) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.renewInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for {
+		s.tryAcquire(ctx)
+		select {
+		case <-s.stopc:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *LeaderSendingStrategy) tryAcquire(ctx context.Context) {
+	wasLeader := s.Sends()
+	// The lease is held until the duration after the attempt, not after the response, so that the replica
+	// never considers itself the leader after the lease expired in the lease store.
+	start := s.now()
+	acquired, err := s.lease.TryAcquire(ctx, s.id, s.leaseDuration)
+	if err != nil {
+		// The replica remains the leader until the lease expires, in case the error is transient.
+		level.Warn(s.logger).Log("msg", "Failed to acquire the lease", "err", err)
+		return
+	}
+	s.mtx.Lock()
+	if acquired {
+		s.leaderUntil = start.Add(s.leaseDuration)
+	} else {
+		s.leaderUntil = time.Time{}
+	}
+	s.mtx.Unlock()
+
+	if acquired != wasLeader {
+		level.Info(s.logger).Log("msg", "Leadership changed", "leader", acquired)
+	}
+}
+
+// Wait implements the SendingStrategy interface. Only the leader sends, so there is no need to wait.
+func (s *LeaderSendingStrategy) Wait() time.Duration {
+	return 0
+}
+
+// Timeout implements the SendingStrategy interface.
+func (s *LeaderSendingStrategy) Timeout(d time.Duration) time.Duration {
+	if d < notify.MinTimeout {
+		d = notify.MinTimeout
+	}
+	return d
+}
+
+// Sends implements the SendingStrategy interface. It returns true if the replica holds the lease.
+func (s *LeaderSendingStrategy) Sends() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.leaderUntil.After(s.now())
+}
+
+// sendingStage drops the alerts if the replica does not send the notifications, according to the sending strategy.
+type sendingStage struct {
+	strategy SendingStrategy
+}
+
+func newSendingStage(strategy SendingStrategy) *sendingStage {
+	return &sendingStage{strategy: strategy}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *sendingStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	if !s.strategy.Sends() {
+		level.Debug(l).Log("msg", "Notifications not sent, the replica is not sending", "alerts", len(alerts))
+		return ctx, nil, nil
+	}
+	return ctx, alerts, nil
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/receivers"
+)
+
+type positionPeer struct {
+	NilPeer
+	position int
+}
+
+func (p *positionPeer) Position() int {
+	return p.position
+}
+
+func TestPositionSendingStrategy(t *testing.T) {
+	s := NewPositionSendingStrategy(&positionPeer{position: 2}, 15*time.Second)
+	require.Equal(t, 30*time.Second, s.Wait())
+	require.Equal(t, time.Minute+30*time.Second, s.Timeout(time.Minute))
+	require.Equal(t, notify.MinTimeout+30*time.Second, s.Timeout(time.Second))
+	require.True(t, s.Sends())
+}
+
+func TestMemoryLease(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	l := NewMemoryLease()
+	l.now = func() time.Time { return now }
+
+	ok, err := l.TryAcquire(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = l.TryAcquire(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// The holder renews the lease.
+	now = now.Add(50 * time.Second)
+	ok, err = l.TryAcquire(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	now = now.Add(50 * time.Second)
+	ok, err = l.TryAcquire(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Another holder acquires the lease once it expired.
+	now = now.Add(time.Minute)
+	_, held := l.Holder()
+	require.False(t, held)
+	ok, err = l.TryAcquire(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	holder, held := l.Holder()
+	require.True(t, held)
+	require.Equal(t, "b", holder)
+
+	// Only the holder releases the lease.
+	require.NoError(t, l.Release(ctx, "a"))
+	holder, _ = l.Holder()
+	require.Equal(t, "b", holder)
+	require.NoError(t, l.Release(ctx, "b"))
+	ok, err = l.TryAcquire(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestNewLeaderSendingStrategy(t *testing.T) {
+	lease := NewMemoryLease()
+	_, err := NewLeaderSendingStrategy(nil, LeaderOptions{ID: "a", LeaseDuration: time.Second})
+	require.Error(t, err)
+	_, err = NewLeaderSendingStrategy(lease, LeaderOptions{LeaseDuration: time.Second})
+	require.Error(t, err)
+	_, err = NewLeaderSendingStrategy(lease, LeaderOptions{ID: "a"})
+	require.Error(t, err)
+	_, err = NewLeaderSendingStrategy(lease, LeaderOptions{ID: "a", LeaseDuration: time.Second, RenewInterval: time.Second})
+	require.Error(t, err)
+
+	s, err := NewLeaderSendingStrategy(lease, LeaderOptions{ID: "a", LeaseDuration: 3 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, time.Second, s.renewInterval)
+	require.Equal(t, time.Duration(0), s.Wait())
+	require.Equal(t, time.Minute, s.Timeout(time.Minute))
+	require.Equal(t, notify.MinTimeout, s.Timeout(time.Second))
+	// The replica is a follower until it acquires the lease.
+	require.False(t, s.Sends())
+}
+
+func TestLeaderSendingStrategy_Takeover(t *testing.T) {
+	const (
+		leaseDuration = 200 * time.Millisecond
+		renewInterval = 20 * time.Millisecond
+	)
+	lease := NewMemoryLease()
+	newStrategy := func(id string) *LeaderSendingStrategy {
+		s, err := NewLeaderSendingStrategy(lease, LeaderOptions{ID: id, LeaseDuration: leaseDuration, RenewInterval: renewInterval})
+		require.NoError(t, err)
+		return s
+	}
+	a, b := newStrategy("a"), newStrategy("b")
+	a.Start()
+	require.Eventually(t, a.Sends, time.Second, renewInterval)
+	b.Start()
+	t.Cleanup(func() {
+		require.NoError(t, b.Stop(context.Background()))
+	})
+
+	// The leader keeps the lease while it renews it.
+	time.Sleep(2 * leaseDuration)
+	require.True(t, a.Sends())
+	require.False(t, b.Sends())
+
+	// The follower takes over within the lease duration and the renew interval once the leader stops renewing
+	// the lease without releasing it, as if it crashed.
+	close(a.stopc)
+	<-a.done
+	start := time.Now()
+	require.Eventually(t, b.Sends, 2*(leaseDuration+renewInterval), renewInterval/2)
+	require.Less(t, time.Since(start), leaseDuration+3*renewInterval)
+	// The former leader knows its lease expired, even though it could not renew it.
+	require.False(t, a.Sends())
+
+	// The follower takes over right away when the leader releases the lease.
+	c := newStrategy("c")
+	c.Start()
+	t.Cleanup(func() {
+		require.NoError(t, c.Stop(context.Background()))
+	})
+	require.NoError(t, b.Stop(context.Background()))
+	require.False(t, b.Sends())
+	require.Eventually(t, c.Sends, 4*renewInterval, renewInterval/2)
+}
+
+func TestLeaderSendingStrategy_StopWithoutStart(t *testing.T) {
+	s, err := NewLeaderSendingStrategy(NewMemoryLease(), LeaderOptions{ID: "a", LeaseDuration: time.Second})
+	require.NoError(t, err)
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- s.Stop(context.Background())
+	}()
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop blocked on a strategy that was not started")
+	}
+	require.False(t, s.Sends())
+	require.NoError(t, s.Stop(context.Background()))
+}
+
+func TestSendingStage(t *testing.T) {
+	am, _ := setupAMTest(t)
+	lease := NewMemoryLease()
+	ok, err := lease.TryAcquire(context.Background(), "leader", time.Hour)
+	require.NoError(t, err)
+	require.True(t, ok)
+	follower, err := NewLeaderSendingStrategy(lease, LeaderOptions{ID: "follower", LeaseDuration: time.Hour})
+	require.NoError(t, err)
+	follower.tryAcquire(context.Background())
+	am.sendingStrategy = follower
+
+	sender := &receivers.NotificationServiceMock{}
+	n := &webhookNotifier{sender: sender}
+	stage := am.createReceiverStage("receiver", []*Integration{NewIntegration(n, n, "webhook", 0, "receiver")}, am.waitFunc, am.notificationLog, nil, nil)
+
+	now := time.Now()
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}}
+	ctx := notify.WithGroupKey(context.Background(), "{}:{}")
+	ctx = notify.WithRepeatInterval(ctx, time.Hour)
+	ctx = notify.WithNow(ctx, now)
+
+	// The follower does not send the notifications.
+	_, _, err = stage.Exec(ctx, log.NewNopLogger(), alert)
+	require.NoError(t, err)
+	require.Empty(t, sender.WebhookCalls)
+
+	// The follower sends the notifications once it takes over.
+	require.NoError(t, lease.Release(context.Background(), "leader"))
+	follower.tryAcquire(context.Background())
+	_, _, err = stage.Exec(ctx, log.NewNopLogger(), alert)
+	require.NoError(t, err)
+	require.Len(t, sender.WebhookCalls, 1)
+}
@@ -35,7 +35,7 @@ func (am *GrafanaAlertmanager) ListSilences(filter []string) (GettableSilences,
 	matchers, err := parseFilter(filter)
 	if err != nil {
 		level.Error(am.logger).Log("msg", "failed to parse matchers", "err", err)
-		return nil, fmt.Errorf("%s: %w", ErrListSilencesBadPayload.Error(), err)
+		return nil, fmt.Errorf("%w: %w", ErrListSilencesBadPayload, err)
 	}
 
 	psils, _, err := am.silences.Query()
@@ -90,8 +90,8 @@ func (am *GrafanaAlertmanager) CreateSilence(ps *PostableSilence) (string, error
 	sil, err := v2.PostableSilenceToProto(ps)
 	if err != nil {
 		level.Error(am.logger).Log("msg", "marshaling to protobuf failed", "err", err)
-		return "", fmt.Errorf("%s: failed to convert API silence to internal silence: %w",
-			ErrCreateSilenceBadPayload.Error(), err)
+		return "", fmt.Errorf("%w: failed to convert API silence to internal silence: %w",
+			ErrCreateSilenceBadPayload, err)
 	}
 
 	if err := am.validateSilence(sil); err != nil {
@@ -111,8 +111,8 @@ func (am *GrafanaAlertmanager) UpsertSilence(ps *PostableSilence) (string, error
 	sil, err := v2.PostableSilenceToProto(ps)
 	if err != nil {
 		level.Error(am.logger).Log("msg", "marshaling to protobuf failed", "err", err)
-		return "", fmt.Errorf("%s: failed to convert API silence to internal silence: %w",
-			ErrCreateSilenceBadPayload.Error(), err)
+		return "", fmt.Errorf("%w: failed to convert API silence to internal silence: %w",
+			ErrCreateSilenceBadPayload, err)
 	}
 
 	if err := am.validateSilence(sil); err != nil {
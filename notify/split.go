@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultSplitByAlertConcurrency is the default maximum number of alerts of a group notified at the same time by an
+// integration that notifies each alert on its own.
+const DefaultSplitByAlertConcurrency = 10
+
+// splitByAlertStage executes its stage concurrently for each alert, as if each alert was its own aggregation group.
+// At most concurrency executions run at the same time, so that large groups do not exceed the rate limits of the
+// incident tools. The group key of every execution is derived from the group key and the fingerprint of the alert,
+// so that deduplication in the notification log and keys sent to incident tools are per alert.
+type splitByAlertStage struct {
+	stage       notify.Stage
+	concurrency int
+}
+
+func newSplitByAlertStage(s notify.Stage, concurrency int) *splitByAlertStage {
+	if concurrency <= 0 {
+		concurrency = DefaultSplitByAlertConcurrency
+	}
+	return &splitByAlertStage{stage: s, concurrency: concurrency}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *splitByAlertStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	gkey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return ctx, nil, errors.New("group key missing")
+	}
+
+	var (
+		g  errgroup.Group
+		me types.MultiError
+	)
+	g.SetLimit(s.concurrency)
+	for _, a := range alerts {
+		g.Go(func() error {
+			actx := notify.WithGroupKey(ctx, splitGroupKey(gkey, a))
+			if _, _, err := s.stage.Exec(actx, l, a); err != nil {
+				me.Add(err)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if me.Len() > 0 {
+		return ctx, alerts, &me
+	}
+	return ctx, alerts, nil
+}
+
+// splitGroupKey returns the group key of an alert notified on its own.
+func splitGroupKey(gkey string, a *types.Alert) string {
+	return gkey + "/" + a.Fingerprint().String()
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/notify/nfstatus"
+)
+
+type recordingNotifier struct {
+	mtx   sync.Mutex
+	calls [][]*types.Alert
+	keys  []string
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	key, _ := notify.GroupKey(ctx)
+	r.calls = append(r.calls, alerts)
+	r.keys = append(r.keys, key)
+	return false, nil
+}
+
+func (r *recordingNotifier) SendResolved() bool {
+	return true
+}
+
+func TestCreateReceiverStage_SplitByAlert(t *testing.T) {
+	am, _ := setupAMTest(t)
+
+	grouped := &recordingNotifier{}
+	split := &recordingNotifier{}
+	integrations := []*Integration{
+		NewIntegration(grouped, grouped, "slack", 0, "receiver"),
+		NewIntegration(split, split, "pagerduty", 1, "receiver", nfstatus.WithSplitByAlert(true)),
+	}
+	stage := am.createReceiverStage("receiver", integrations, func() time.Duration { return 0 }, am.notificationLog, nil, nil)
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}},
+	}
+
+	ctx := notify.WithGroupKey(context.Background(), "{}:{}")
+	ctx = notify.WithRepeatInterval(ctx, time.Hour)
+	ctx = notify.WithNow(ctx, now)
+
+	_, _, err := stage.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+
+	// The grouped integration is notified once with all alerts.
+	require.Len(t, grouped.calls, 1)
+	require.Len(t, grouped.calls[0], 2)
+	require.Equal(t, []string{"{}:{}"}, grouped.keys)
+
+	// The split integration is notified once per alert, each with its own group key.
+	require.Len(t, split.calls, 2)
+	for _, c := range split.calls {
+		require.Len(t, c, 1)
+	}
+	require.ElementsMatch(t, []string{splitGroupKey("{}:{}", alerts[0]), splitGroupKey("{}:{}", alerts[1])}, split.keys)
+
+	// Each alert has its own entry in the notification log.
+	for _, a := range alerts {
+		entries, err := am.notificationLog.Query(nflog.QGroupKey(splitGroupKey("{}:{}", a)), nflog.QReceiver(&nflogpb.Receiver{GroupName: "receiver", Integration: "pagerduty", Idx: 1}))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Len(t, entries[0].FiringAlerts, 1)
+	}
+
+	// Nothing changed, so nothing is sent again.
+	_, _, err = stage.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, grouped.calls, 1)
+	require.Len(t, split.calls, 2)
+
+	// A new alert is only sent on its own by the split integration.
+	alerts = append(alerts, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "c"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}})
+	_, _, err = stage.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, grouped.calls, 2)
+	require.Len(t, grouped.calls[1], 3)
+	require.Len(t, split.calls, 3)
+	require.Equal(t, alerts[2], split.calls[2][0])
+}
+
+// concurrencyStage records the maximum number of concurrent executions.
+type concurrencyStage struct {
+	running atomic.Int64
+	max     atomic.Int64
+	calls   atomic.Int64
+}
+
+func (s *concurrencyStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		m := s.max.Load()
+		if n <= m || s.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	s.calls.Add(1)
+	time.Sleep(time.Millisecond)
+	return ctx, alerts, nil
+}
+
+func TestSplitByAlertStage_Concurrency(t *testing.T) {
+	alerts := make([]*types.Alert, 0, 50)
+	for i := 0; i < cap(alerts); i++ {
+		alerts = append(alerts, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": model.LabelValue(fmt.Sprintf("%d", i))}}})
+	}
+	ctx := notify.WithGroupKey(context.Background(), "{}:{}")
+
+	for _, tc := range []struct {
+		concurrency int
+		expected    int64
+	}{
+		{concurrency: 3, expected: 3},
+		{concurrency: 0, expected: DefaultSplitByAlertConcurrency},
+	} {
+		inner := &concurrencyStage{}
+		_, _, err := newSplitByAlertStage(inner, tc.concurrency).Exec(ctx, log.NewNopLogger(), alerts...)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(alerts)), inner.calls.Load())
+		require.LessOrEqual(t, inner.max.Load(), tc.expected)
+	}
+}
@@ -0,0 +1,485 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v2 "github.com/prometheus/alertmanager/api/v2"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/common/model"
+)
+
+// StateExportVersion is the version of the JSON format of the exported state. It is incremented on every change
+// that is not backward compatible, and the imports of other versions are rejected.
+const StateExportVersion = 1
+
+var ErrUnsupportedStateExportVersion = errors.New("unsupported state export version")
+
+// StateExport is the state of the silences and the notification log of an Alertmanager, in a versioned JSON format
+// for migrating a tenant between clusters, or inspecting its state. For example:
+//
+//	{
+//	  "version": 1,
+//	  "exportedAt": "2024-01-01T00:00:00Z",
+//	  "silences": [{
+//	    "id": "6f7b3a1c-...",
+//	    "matchers": [{"name": "alertname", "type": "=", "value": "HighCPU"}],
+//	    "startsAt": "2024-01-01T00:00:00Z",
+//	    "endsAt": "2024-01-02T00:00:00Z",
+//	    "updatedAt": "2024-01-01T00:00:00Z",
+//	    "createdBy": "alice",
+//	    "comment": "maintenance",
+//	    "expiresAt": "2024-01-07T00:00:00Z"
+//	  }],
+//	  "notificationLog": [{
+//	    "groupKey": "{}/{}:{alertname=\"HighCPU\"}",
+//	    "receiver": {"groupName": "team", "integration": "slack", "index": 0},
+//	    "timestamp": "2024-01-01T00:00:00Z",
+//	    "firingAlerts": ["9b3c4fd1e5a2c4b0"],
+//	    "resolvedAlerts": [],
+//	    "expiresAt": "2024-01-06T00:00:00Z"
+//	  }]
+//	}
+//
+// The types of the matchers are "=", "!=", "=~" and "!~". The alerts of the notification log entries are
+// the hashes of their labels, as 16 hexadecimal digits. Timestamps are in RFC 3339 format.
+type StateExport struct {
+	Version         int                `json:"version"`
+	ExportedAt      time.Time          `json:"exportedAt"`
+	Silences        []ExportedSilence  `json:"silences"`
+	NotificationLog []ExportedLogEntry `json:"notificationLog"`
+}
+
+// ExportedSilence is a silence in a StateExport.
+type ExportedSilence struct {
+	ID        string            `json:"id"`
+	Matchers  []ExportedMatcher `json:"matchers"`
+	StartsAt  time.Time         `json:"startsAt"`
+	EndsAt    time.Time         `json:"endsAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	CreatedBy string            `json:"createdBy"`
+	Comment   string            `json:"comment"`
+	// ExpiresAt is the time after which the silence is garbage collected.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExportedMatcher is a matcher of an ExportedSilence.
+type ExportedMatcher struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ExportedLogEntry is an entry of the notification log in a StateExport.
+type ExportedLogEntry struct {
+	GroupKey       string           `json:"groupKey"`
+	Receiver       ExportedReceiver `json:"receiver"`
+	Timestamp      time.Time        `json:"timestamp"`
+	FiringAlerts   []string         `json:"firingAlerts"`
+	ResolvedAlerts []string         `json:"resolvedAlerts"`
+	// GroupHash and Resolved are deprecated in favor of FiringAlerts and ResolvedAlerts, and only kept
+	// for compatibility.
+	GroupHash []byte `json:"groupHash,omitempty"`
+	Resolved  bool   `json:"resolved,omitempty"`
+	// ExpiresAt is the time after which the entry is garbage collected.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExportedReceiver is the receiver of an ExportedLogEntry.
+type ExportedReceiver struct {
+	GroupName   string `json:"groupName"`
+	Integration string `json:"integration"`
+	Index       uint32 `json:"index"`
+}
+
+// StateFilter selects the silences and the notification log entries to export or import.
+type StateFilter struct {
+	// Matchers are in the same format as the filter of ListSilences. A silence is selected if it has all the matchers,
+	// and a notification log entry is selected if the labels of its group match all the matchers.
+	Matchers []string
+	// Since selects the silences that end after it, and the notification log entries sent after it. It is ignored if zero.
+	Since time.Time
+	// Until selects the silences that start before it, and the notification log entries sent before it. It is ignored if zero.
+	Until time.Time
+}
+
+type stateFilter struct {
+	matchers     []*labels.Matcher
+	since, until time.Time
+}
+
+func (f StateFilter) compile() (stateFilter, error) {
+	matchers, err := parseFilter(f.Matchers)
+	if err != nil {
+		return stateFilter{}, fmt.Errorf("invalid matchers: %w", err)
+	}
+	return stateFilter{matchers: matchers, since: f.Since, until: f.Until}, nil
+}
+
+func (f stateFilter) silence(s *silencepb.Silence) bool {
+	if !f.since.IsZero() && s.EndsAt.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && s.StartsAt.After(f.until) {
+		return false
+	}
+	return v2.CheckSilenceMatchesFilterLabels(s, f.matchers)
+}
+
+func (f stateFilter) entry(e *nflogpb.Entry) bool {
+	if !f.since.IsZero() && e.Timestamp.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && e.Timestamp.After(f.until) {
+		return false
+	}
+	if len(f.matchers) == 0 {
+		return true
+	}
+	groupLabels, ok := groupLabelsFromKey(string(e.GroupKey))
+	if !ok {
+		return false
+	}
+	for _, m := range f.matchers {
+		if !m.Matches(string(groupLabels[model.LabelName(m.Name)])) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupLabelsFromKey returns the labels of the group from its key, which is the key of the route followed by
+// a colon and the labels of the group.
+func groupLabelsFromKey(key string) (model.LabelSet, bool) {
+	i := strings.LastIndex(key, ":{")
+	if i < 0 {
+		return nil, false
+	}
+	matchers, err := labels.ParseMatchers(key[i+1:])
+	if err != nil {
+		return nil, false
+	}
+	res := make(model.LabelSet, len(matchers))
+	for _, m := range matchers {
+		res[model.LabelName(m.Name)] = model.LabelValue(m.Value)
+	}
+	return res, true
+}
+
+// ExportState converts the state of the silences and the notification log to a StateExport,
+// with the silences and the entries selected by the filter.
+func ExportState(silences SilenceState, nflog NflogState, filter StateFilter) (*StateExport, error) {
+	f, err := filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	res := &StateExport{
+		Version:         StateExportVersion,
+		ExportedAt:      time.Now().UTC(),
+		Silences:        []ExportedSilence{},
+		NotificationLog: []ExportedLogEntry{},
+	}
+	for _, s := range silences {
+		if !f.silence(s.Silence) {
+			continue
+		}
+		e, err := exportSilence(s)
+		if err != nil {
+			return nil, err
+		}
+		res.Silences = append(res.Silences, e)
+	}
+	for _, e := range nflog {
+		if !f.entry(e.Entry) {
+			continue
+		}
+		res.NotificationLog = append(res.NotificationLog, exportLogEntry(e))
+	}
+	res.sort()
+	return res, nil
+}
+
+// ParseStateExport reads a StateExport in JSON. It returns ErrUnsupportedStateExportVersion if the version
+// is not StateExportVersion.
+func ParseStateExport(r io.Reader) (*StateExport, error) {
+	var res StateExport
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return nil, fmt.Errorf("failed to decode state export: %w", err)
+	}
+	if res.Version != StateExportVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedStateExportVersion, res.Version)
+	}
+	return &res, nil
+}
+
+// SilenceState converts the silences of the export to a SilenceState, which can be used as the initial state
+// of the silences of an Alertmanager.
+func (e *StateExport) SilenceState() (SilenceState, error) {
+	res := make(SilenceState, len(e.Silences))
+	for i, s := range e.Silences {
+		ms, err := s.toProto()
+		if err != nil {
+			return nil, fmt.Errorf("invalid silence %d (%s): %w", i, s.ID, err)
+		}
+		res[s.ID] = ms
+	}
+	return res, nil
+}
+
+// NflogState converts the notification log entries of the export to a NflogState, which can be used as
+// the initial state of the notification log of an Alertmanager.
+func (e *StateExport) NflogState() (NflogState, error) {
+	res := make(NflogState, len(e.NotificationLog))
+	for i, entry := range e.NotificationLog {
+		me, err := entry.toProto()
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification log entry %d (%s): %w", i, entry.GroupKey, err)
+		}
+		res[nflogStateKey(entry.GroupKey, me.Entry.Receiver)] = me
+	}
+	return res, nil
+}
+
+func (e *StateExport) sort() {
+	sortByKey(e.Silences, func(s ExportedSilence) string { return s.ID })
+	sortByKey(e.NotificationLog, func(e ExportedLogEntry) string {
+		return nflogStateKey(e.GroupKey, &nflogpb.Receiver{GroupName: e.Receiver.GroupName, Integration: e.Receiver.Integration, Idx: e.Receiver.Index})
+	})
+}
+
+// ExportState exports the state of the silences and the notification log of the Alertmanager,
+// with the silences and the entries selected by the filter.
+func (am *GrafanaAlertmanager) ExportState(filter StateFilter) (*StateExport, error) {
+	silences, err := am.SilenceState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read silences: %w", err)
+	}
+	nflog, err := am.NflogState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification log: %w", err)
+	}
+	return ExportState(silences, nflog, filter)
+}
+
+// ImportResult is the number of silences and notification log entries imported.
+type ImportResult struct {
+	Silences        int `json:"silences"`
+	NotificationLog int `json:"notificationLog"`
+}
+
+// ImportState merges the silences and the notification log entries of the export selected by the filter into
+// the state of the running Alertmanager, and replicates them to the other peers. The silences and the entries
+// are merged as if they were received from another peer: they do not replace the silences updated after them,
+// or the entries of notifications sent after them, and they are dropped if they expired. To replace the state
+// instead, use the SilenceState and the NflogState of the export as the initial state of the Alertmanager.
+func (am *GrafanaAlertmanager) ImportState(e *StateExport, filter StateFilter) (ImportResult, error) {
+	if e.Version != StateExportVersion {
+		return ImportResult{}, fmt.Errorf("%w: %d", ErrUnsupportedStateExportVersion, e.Version)
+	}
+	f, err := filter.compile()
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	silences, err := e.SilenceState()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	nflog, err := e.NflogState()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	currentSilences, err := am.SilenceState()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to read silences: %w", err)
+	}
+	currentNflog, err := am.NflogState()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to read notification log: %w", err)
+	}
+
+	now := time.Now()
+	var res ImportResult
+	for id, s := range silences {
+		prev, ok := currentSilences[id]
+		if !f.silence(s.Silence) || s.ExpiresAt.Before(now) || ok && !prev.Silence.UpdatedAt.Before(s.Silence.UpdatedAt) {
+			delete(silences, id)
+		}
+	}
+	for key, entry := range nflog {
+		prev, ok := currentNflog[key]
+		if !f.entry(entry.Entry) || entry.ExpiresAt.Before(now) || ok && !prev.Entry.Timestamp.Before(entry.Entry.Timestamp) {
+			delete(nflog, key)
+		}
+	}
+
+	if len(silences) > 0 {
+		b, err := silences.MarshalBinary()
+		if err != nil {
+			return res, err
+		}
+		if err := am.silences.Merge(b); err != nil {
+			return res, fmt.Errorf("failed to merge silences: %w", err)
+		}
+		res.Silences = len(silences)
+	}
+	if len(nflog) > 0 {
+		b, err := nflog.MarshalBinary()
+		if err != nil {
+			return res, err
+		}
+		if err := am.notificationLog.Merge(b); err != nil {
+			return res, fmt.Errorf("failed to merge notification log: %w", err)
+		}
+		res.NotificationLog = len(nflog)
+	}
+	return res, nil
+}
+
+var matcherTypes = map[silencepb.Matcher_Type]string{
+	silencepb.Matcher_EQUAL:      "=",
+	silencepb.Matcher_NOT_EQUAL:  "!=",
+	silencepb.Matcher_REGEXP:     "=~",
+	silencepb.Matcher_NOT_REGEXP: "!~",
+}
+
+func exportSilence(ms *silencepb.MeshSilence) (ExportedSilence, error) {
+	s := ms.Silence
+	res := ExportedSilence{
+		ID:        s.Id,
+		Matchers:  make([]ExportedMatcher, 0, len(s.Matchers)),
+		StartsAt:  s.StartsAt.UTC(),
+		EndsAt:    s.EndsAt.UTC(),
+		UpdatedAt: s.UpdatedAt.UTC(),
+		CreatedBy: s.CreatedBy,
+		Comment:   s.Comment,
+		ExpiresAt: ms.ExpiresAt.UTC(),
+	}
+	for _, m := range s.Matchers {
+		t, ok := matcherTypes[m.Type]
+		if !ok {
+			return ExportedSilence{}, fmt.Errorf("silence %s has a matcher of unknown type %d", s.Id, m.Type)
+		}
+		res.Matchers = append(res.Matchers, ExportedMatcher{Name: m.Name, Type: t, Value: m.Pattern})
+	}
+	return res, nil
+}
+
+func (s ExportedSilence) toProto() (*silencepb.MeshSilence, error) {
+	if s.ID == "" {
+		return nil, errors.New("silence ID must be set")
+	}
+	if len(s.Matchers) == 0 {
+		return nil, errors.New("silence must have at least one matcher")
+	}
+	res := &silencepb.MeshSilence{
+		Silence: &silencepb.Silence{
+			Id:        s.ID,
+			Matchers:  make([]*silencepb.Matcher, 0, len(s.Matchers)),
+			StartsAt:  s.StartsAt,
+			EndsAt:    s.EndsAt,
+			UpdatedAt: s.UpdatedAt,
+			CreatedBy: s.CreatedBy,
+			Comment:   s.Comment,
+		},
+		ExpiresAt: s.ExpiresAt,
+	}
+	for _, m := range s.Matchers {
+		var t silencepb.Matcher_Type
+		found := false
+		for k, v := range matcherTypes {
+			if v == m.Type {
+				t, found = k, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("matcher %s has unknown type %q", m.Name, m.Type)
+		}
+		res.Silence.Matchers = append(res.Silence.Matchers, &silencepb.Matcher{Type: t, Name: m.Name, Pattern: m.Value})
+	}
+	return res, nil
+}
+
+func exportLogEntry(me *nflogpb.MeshEntry) ExportedLogEntry {
+	e := me.Entry
+	return ExportedLogEntry{
+		GroupKey: string(e.GroupKey),
+		Receiver: ExportedReceiver{
+			GroupName:   e.Receiver.GroupName,
+			Integration: e.Receiver.Integration,
+			Index:       e.Receiver.Idx,
+		},
+		Timestamp:      e.Timestamp.UTC(),
+		FiringAlerts:   exportAlertHashes(e.FiringAlerts),
+		ResolvedAlerts: exportAlertHashes(e.ResolvedAlerts),
+		GroupHash:      e.GroupHash,
+		Resolved:       e.Resolved,
+		ExpiresAt:      me.ExpiresAt.UTC(),
+	}
+}
+
+func (e ExportedLogEntry) toProto() (*nflogpb.MeshEntry, error) {
+	if e.GroupKey == "" {
+		return nil, errors.New("group key must be set")
+	}
+	firing, err := importAlertHashes(e.FiringAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid firing alerts: %w", err)
+	}
+	resolved, err := importAlertHashes(e.ResolvedAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolved alerts: %w", err)
+	}
+	return &nflogpb.MeshEntry{
+		Entry: &nflogpb.Entry{
+			GroupKey: []byte(e.GroupKey),
+			Receiver: &nflogpb.Receiver{
+				GroupName:   e.Receiver.GroupName,
+				Integration: e.Receiver.Integration,
+				Idx:         e.Receiver.Index,
+			},
+			GroupHash:      e.GroupHash,
+			Resolved:       e.Resolved,
+			Timestamp:      e.Timestamp,
+			FiringAlerts:   firing,
+			ResolvedAlerts: resolved,
+		},
+		ExpiresAt: e.ExpiresAt,
+	}, nil
+}
+
+func exportAlertHashes(hashes []uint64) []string {
+	res := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		res = append(res, fmt.Sprintf("%016x", h))
+	}
+	return res
+}
+
+func importAlertHashes(hashes []string) ([]uint64, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	res := make([]uint64, 0, len(hashes))
+	for _, h := range hashes {
+		v, err := strconv.ParseUint(h, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func sortByKey[T any](s []T, key func(T) string) {
+	sort.Slice(s, func(i, j int) bool { return key(s[i]) < key(s[j]) })
+}
@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestSilence(t *testing.T, am *GrafanaAlertmanager, name, value string, startsAt, endsAt time.Time) string {
+	t.Helper()
+	id, err := am.CreateSilence(&PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   ptr("comment"),
+			CreatedBy: ptr("test"),
+			StartsAt:  ptr(strfmt.DateTime(startsAt)),
+			EndsAt:    ptr(strfmt.DateTime(endsAt)),
+			Matchers: amv2.Matchers{{
+				IsEqual: ptr(true),
+				IsRegex: ptr(false),
+				Name:    ptr(name),
+				Value:   ptr(value),
+			}},
+		},
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func TestExportImportState(t *testing.T) {
+	now := time.Now()
+	am, _ := setupAMTest(t)
+	fooID := createTestSilence(t, am, "team", "foo", now, now.Add(time.Hour))
+	barID := createTestSilence(t, am, "team", "bar", now.Add(2*time.Hour), now.Add(3*time.Hour))
+	fooGroup := `{}/{team="foo"}:{team="foo"}`
+	barGroup := `{}/{team="bar"}:{team="bar"}`
+	receiver := &nflogpb.Receiver{GroupName: "team", Integration: "email", Idx: 0}
+	require.NoError(t, am.notificationLog.Log(receiver, fooGroup, []uint64{1, 2}, []uint64{3}, time.Hour))
+	require.NoError(t, am.notificationLog.Log(receiver, barGroup, []uint64{4}, nil, time.Hour))
+
+	t.Run("export round trips through JSON", func(t *testing.T) {
+		export, err := am.ExportState(StateFilter{})
+		require.NoError(t, err)
+		require.Equal(t, StateExportVersion, export.Version)
+		require.Len(t, export.Silences, 2)
+		require.Len(t, export.NotificationLog, 2)
+		require.Equal(t, []string{"0000000000000001", "0000000000000002"}, export.NotificationLog[1].FiringAlerts)
+
+		b, err := json.Marshal(export)
+		require.NoError(t, err)
+		parsed, err := ParseStateExport(bytes.NewReader(b))
+		require.NoError(t, err)
+
+		silences, err := parsed.SilenceState()
+		require.NoError(t, err)
+		expectedSilences, err := am.SilenceState()
+		require.NoError(t, err)
+		require.Len(t, silences, 2)
+		for id, s := range expectedSilences {
+			require.True(t, s.Silence.UpdatedAt.Equal(silences[id].Silence.UpdatedAt))
+			require.Equal(t, s.Silence.Matchers, silences[id].Silence.Matchers)
+		}
+
+		nflog, err := parsed.NflogState()
+		require.NoError(t, err)
+		expectedNflog, err := am.NflogState()
+		require.NoError(t, err)
+		require.Len(t, nflog, 2)
+		for key, e := range expectedNflog {
+			require.Equal(t, e.Entry.FiringAlerts, nflog[key].Entry.FiringAlerts)
+			require.Equal(t, e.Entry.ResolvedAlerts, nflog[key].Entry.ResolvedAlerts)
+			require.True(t, e.Entry.Timestamp.Equal(nflog[key].Entry.Timestamp))
+		}
+	})
+
+	t.Run("export is filtered by matchers and time", func(t *testing.T) {
+		export, err := am.ExportState(StateFilter{Matchers: []string{`team="foo"`}})
+		require.NoError(t, err)
+		require.Len(t, export.Silences, 1)
+		require.Equal(t, fooID, export.Silences[0].ID)
+		require.Len(t, export.NotificationLog, 1)
+		require.Equal(t, fooGroup, export.NotificationLog[0].GroupKey)
+
+		export, err = am.ExportState(StateFilter{Since: now.Add(90 * time.Minute)})
+		require.NoError(t, err)
+		require.Len(t, export.Silences, 1)
+		require.Equal(t, barID, export.Silences[0].ID)
+		require.Empty(t, export.NotificationLog)
+
+		export, err = am.ExportState(StateFilter{Until: now.Add(time.Minute)})
+		require.NoError(t, err)
+		require.Len(t, export.Silences, 1)
+		require.Equal(t, fooID, export.Silences[0].ID)
+		require.Len(t, export.NotificationLog, 2)
+
+		_, err = am.ExportState(StateFilter{Matchers: []string{`team=~"(`}})
+		require.Error(t, err)
+	})
+
+	t.Run("import merges without replacing newer entries", func(t *testing.T) {
+		export, err := am.ExportState(StateFilter{})
+		require.NoError(t, err)
+
+		target, _ := setupAMTest(t)
+		// The silence of foo is updated in the target after the export.
+		require.NoError(t, target.silences.Merge(mustMarshal(t, export, fooID)))
+		newer, err := target.SilenceState()
+		require.NoError(t, err)
+		newer[fooID].Silence.Comment = "updated"
+		newer[fooID].Silence.UpdatedAt = newer[fooID].Silence.UpdatedAt.Add(time.Minute)
+		b, err := newer.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, target.silences.Merge(b))
+
+		res, err := target.ImportState(export, StateFilter{})
+		require.NoError(t, err)
+		require.Equal(t, ImportResult{Silences: 1, NotificationLog: 2}, res)
+
+		sil, err := target.GetSilence(fooID)
+		require.NoError(t, err)
+		require.Equal(t, "updated", *sil.Comment)
+		sil, err = target.GetSilence(barID)
+		require.NoError(t, err)
+		require.Equal(t, "comment", *sil.Comment)
+		nflog, err := target.NflogState()
+		require.NoError(t, err)
+		require.Len(t, nflog, 2)
+
+		// Importing again does not change anything.
+		res, err = target.ImportState(export, StateFilter{})
+		require.NoError(t, err)
+		require.Equal(t, ImportResult{}, res)
+	})
+
+	t.Run("import is filtered", func(t *testing.T) {
+		export, err := am.ExportState(StateFilter{})
+		require.NoError(t, err)
+
+		target, _ := setupAMTest(t)
+		res, err := target.ImportState(export, StateFilter{Matchers: []string{`team="bar"`}})
+		require.NoError(t, err)
+		require.Equal(t, ImportResult{Silences: 1, NotificationLog: 1}, res)
+		_, err = target.GetSilence(fooID)
+		require.Error(t, err)
+	})
+}
+
+func mustMarshal(t *testing.T, export *StateExport, id string) []byte {
+	t.Helper()
+	silences, err := export.SilenceState()
+	require.NoError(t, err)
+	b, err := SilenceState{id: silences[id]}.MarshalBinary()
+	require.NoError(t, err)
+	return b
+}
+
+func TestParseStateExport(t *testing.T) {
+	_, err := ParseStateExport(bytes.NewReader([]byte(`{"version": 2}`)))
+	require.ErrorIs(t, err, ErrUnsupportedStateExportVersion)
+
+	_, err = ParseStateExport(bytes.NewReader([]byte(`{"version": 1`)))
+	require.Error(t, err)
+
+	export, err := ParseStateExport(bytes.NewReader([]byte(`{
+		"version": 1,
+		"silences": [{"id": "1", "matchers": [{"name": "a", "type": "<>", "value": "b"}]}],
+		"notificationLog": [{"groupKey": "{}:{}", "firingAlerts": ["xyz"]}]
+	}`)))
+	require.NoError(t, err)
+	_, err = export.SilenceState()
+	require.ErrorContains(t, err, `unknown type "<>"`)
+	_, err = export.NflogState()
+	require.ErrorContains(t, err, "invalid firing alerts")
+}
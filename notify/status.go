@@ -1,13 +1,202 @@
 package notify
 
-// TODO(gotjosh): I don't think this is right, make sure you evaluate it.
-func (am *GrafanaAlertmanager) GetStatus() []byte {
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/version"
+
+	"github.com/grafana/alerting/cluster"
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/alerting/notify/nfstatus"
+)
+
+// Health is the summarized health of a receiver or an integration, derived from the last notification attempts.
+type Health string
+
+const (
+	// HealthOK means that the last notification attempts succeeded.
+	HealthOK Health = "ok"
+	// HealthDegraded means that some, but not all, of the integrations of a receiver failed their last notification attempt.
+	HealthDegraded Health = "degraded"
+	// HealthFailing means that the last notification attempts failed.
+	HealthFailing Health = "failing"
+	// HealthUnknown means that no notification has been attempted yet.
+	HealthUnknown Health = "unknown"
+)
+
+// AlertmanagerStatus is the status of the Alertmanager. It extends the AlertmanagerStatus of the Alertmanager v2 API,
+// and its JSON representation is a superset of it.
+type AlertmanagerStatus struct {
+	amv2.AlertmanagerStatus
+
+	// Ready is true once a configuration has been applied.
+	Ready bool `json:"ready"`
+	// ConfigHash is the hex encoded hash of the applied configuration.
+	ConfigHash string `json:"configHash,omitempty"`
+	// ConfigAppliedAt is the time the configuration was applied.
+	ConfigAppliedAt *strfmt.DateTime `json:"configAppliedAt,omitempty"`
+	// ClusterPosition is the position of the Alertmanager in the cluster.
+	ClusterPosition int `json:"clusterPosition"`
+
+	// Alerts is the number of alerts that are not resolved.
+	Alerts int `json:"alerts"`
+	// Silences is the number of active silences.
+	Silences int `json:"silences"`
+	// AlertGroups is the number of aggregation groups.
+	AlertGroups int `json:"alertGroups"`
+
+	Receivers []ReceiverStatus `json:"receivers"`
+}
+
+// ReceiverStatus is the status of a receiver and its integrations.
+type ReceiverStatus struct {
+	Name string `json:"name"`
+	// Active is true if the receiver is used in a route.
+	Active       bool                `json:"active"`
+	Health       Health              `json:"health"`
+	Integrations []IntegrationStatus `json:"integrations"`
+}
+
+// IntegrationStatus is the status of an integration.
+type IntegrationStatus struct {
+	models.Integration
+	Health Health `json:"health"`
+}
+
+// GetStatus returns the status of the Alertmanager.
+func (am *GrafanaAlertmanager) GetStatus() AlertmanagerStatus {
+	now := time.Now()
+
 	am.reloadConfigMtx.RLock()
 	defer am.reloadConfigMtx.RUnlock()
 
+	original := string(am.config)
+	uptime := strfmt.DateTime(am.startedAt)
+	status := AlertmanagerStatus{
+		AlertmanagerStatus: amv2.AlertmanagerStatus{
+			Uptime: &uptime,
+			VersionInfo: &amv2.VersionInfo{
+				Version:   &version.Version,
+				Revision:  &version.Revision,
+				Branch:    &version.Branch,
+				BuildUser: &version.BuildUser,
+				BuildDate: &version.BuildDate,
+				GoVersion: &version.GoVersion,
+			},
+			Config: &amv2.AlertmanagerConfig{
+				Original: &original,
+			},
+			Cluster: am.clusterStatus(),
+		},
+		Ready:           am.ready(),
+		ClusterPosition: am.peer.Position(),
+		Receivers:       receiverStatuses(am.receivers),
+	}
+
 	if am.ready() {
-		return am.config
+		status.ConfigHash = fmt.Sprintf("%x", am.configHash)
+		appliedAt := strfmt.DateTime(am.configAppliedAt)
+		status.ConfigAppliedAt = &appliedAt
+	}
+
+	alerts := am.alerts.GetPending()
+	for a := range alerts.Next() {
+		if !a.ResolvedAt(now) {
+			status.Alerts++
+		}
 	}
+	alerts.Close()
 
-	return nil
+	if sils, _, err := am.silences.Query(silence.QState(types.SilenceStateActive)); err == nil {
+		status.Silences = len(sils)
+	}
+
+	if am.dispatcher != nil {
+		groups, _ := am.dispatcher.Groups(
+			func(*dispatch.Route) bool { return true },
+			func(*types.Alert, time.Time) bool { return true },
+		)
+		status.AlertGroups = len(groups)
+	}
+
+	return status
+}
+
+// clusterStatus returns the status of the cluster. Peers that do not expose their membership are reported as disabled.
+func (am *GrafanaAlertmanager) clusterStatus() *amv2.ClusterStatus {
+	peers := []*amv2.PeerStatus{}
+	p, ok := am.peer.(cluster.ClusterPeer)
+	if !ok || p.Status() == "" {
+		status := amv2.ClusterStatusStatusDisabled
+		return &amv2.ClusterStatus{Status: &status, Peers: peers}
+	}
+
+	status := p.Status()
+	for _, m := range p.Peers() {
+		name, address := m.Name(), m.Address()
+		peers = append(peers, &amv2.PeerStatus{Name: &name, Address: &address})
+	}
+	return &amv2.ClusterStatus{
+		Name:   p.Name(),
+		Status: &status,
+		Peers:  peers,
+	}
+}
+
+// ClusterStatus returns the membership of the Alertmanager in the cluster, and the synchronization of its states,
+// such as silences:<tenant> and notificationlog:<tenant>, with the other peers.
+func (am *GrafanaAlertmanager) ClusterStatus() cluster.PeerStatus {
+	return cluster.NewPeerStatus(am.peer, am.clusterStates.States())
+}
+
+// receiverStatuses summarizes the health of the receivers and their integrations.
+func receiverStatuses(receivers []*nfstatus.Receiver) []ReceiverStatus {
+	res := make([]ReceiverStatus, 0, len(receivers))
+	for _, r := range GetReceivers(receivers) {
+		rs := ReceiverStatus{
+			Name:         r.Name,
+			Active:       r.Active,
+			Integrations: make([]IntegrationStatus, 0, len(r.Integrations)),
+		}
+		var ok, failing int
+		for _, i := range r.Integrations {
+			health := integrationHealth(i)
+			switch health {
+			case HealthOK:
+				ok++
+			case HealthFailing:
+				failing++
+			}
+			rs.Integrations = append(rs.Integrations, IntegrationStatus{Integration: i, Health: health})
+		}
+		switch {
+		case failing > 0 && ok > 0:
+			rs.Health = HealthDegraded
+		case failing > 0:
+			rs.Health = HealthFailing
+		case ok > 0:
+			rs.Health = HealthOK
+		default:
+			rs.Health = HealthUnknown
+		}
+		res = append(res, rs)
+	}
+	return res
+}
+
+func integrationHealth(i models.Integration) Health {
+	switch {
+	case i.LastNotifyAttemptError != "":
+		return HealthFailing
+	case time.Time(i.LastNotifyAttempt).IsZero():
+		return HealthUnknown
+	default:
+		return HealthOK
+	}
 }
@@ -0,0 +1,209 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/cluster"
+	"github.com/grafana/alerting/notify/nfstatus"
+)
+
+type fakeMember struct {
+	name, address string
+}
+
+func (m fakeMember) Name() string    { return m.name }
+func (m fakeMember) Address() string { return m.address }
+
+type fakeMembershipPeer struct {
+	NilPeer
+}
+
+func (p *fakeMembershipPeer) Position() int  { return 1 }
+func (p *fakeMembershipPeer) Name() string   { return "peer-b" }
+func (p *fakeMembershipPeer) Status() string { return amv2.ClusterStatusStatusReady }
+func (p *fakeMembershipPeer) Peers() []cluster.ClusterMember {
+	return []cluster.ClusterMember{fakeMember{"peer-a", "10.0.0.1:9094"}, fakeMember{"peer-b", "10.0.0.2:9094"}}
+}
+
+func TestGetStatus(t *testing.T) {
+	am, _ := setupAMTest(t)
+
+	status := am.GetStatus()
+	require.False(t, status.Ready)
+	require.Empty(t, status.ConfigHash)
+	require.Nil(t, status.ConfigAppliedAt)
+	require.Equal(t, "", *status.Config.Original)
+	require.Equal(t, amv2.ClusterStatusStatusDisabled, *status.Cluster.Status)
+	require.NoError(t, status.AlertmanagerStatus.Validate(strfmt.Default))
+
+	now := time.Now()
+	require.NoError(t, am.PutAlerts(amv2.PostableAlerts{
+		{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "firing"}}, StartsAt: strfmt.DateTime(now.Add(-time.Hour))},
+		{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "resolved"}}, StartsAt: strfmt.DateTime(now.Add(-time.Hour)), EndsAt: strfmt.DateTime(now.Add(-time.Minute))},
+	}))
+	_, err := am.CreateSilence(&PostableSilence{Silence: amv2.Silence{
+		Comment:   ptr("comment"),
+		CreatedBy: ptr("test"),
+		StartsAt:  ptr(strfmt.DateTime(now)),
+		EndsAt:    ptr(strfmt.DateTime(now.Add(time.Hour))),
+		Matchers:  amv2.Matchers{{IsEqual: ptr(true), IsRegex: ptr(false), Name: ptr("foo"), Value: ptr("bar")}},
+	}})
+	require.NoError(t, err)
+
+	am.WithLock(func() {
+		am.config = []byte(`{}`)
+		am.configHash = [16]byte{1, 2}
+		am.configAppliedAt = now
+		am.peer = &fakeMembershipPeer{}
+	})
+
+	status = am.GetStatus()
+	require.True(t, status.Ready)
+	require.Equal(t, "01020000000000000000000000000000", status.ConfigHash)
+	require.Equal(t, strfmt.DateTime(now), *status.ConfigAppliedAt)
+	require.Equal(t, 1, status.Alerts)
+	require.Equal(t, 1, status.Silences)
+	require.Equal(t, 0, status.AlertGroups)
+	require.Equal(t, 1, status.ClusterPosition)
+	require.Equal(t, "peer-b", status.Cluster.Name)
+	require.Equal(t, amv2.ClusterStatusStatusReady, *status.Cluster.Status)
+	require.Len(t, status.Cluster.Peers, 2)
+	require.NoError(t, status.AlertmanagerStatus.Validate(strfmt.Default))
+
+	t.Run("JSON is compatible with the v2 AlertmanagerStatus", func(t *testing.T) {
+		b, err := json.Marshal(status)
+		require.NoError(t, err)
+		var v2 amv2.AlertmanagerStatus
+		require.NoError(t, json.Unmarshal(b, &v2))
+		require.NoError(t, v2.Validate(strfmt.Default))
+		expected, err := json.Marshal(status.AlertmanagerStatus)
+		require.NoError(t, err)
+		actual, err := json.Marshal(v2)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(actual))
+
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal(b, &fields))
+		require.Contains(t, fields, "configHash")
+		require.Contains(t, fields, "receivers")
+	})
+}
+
+func TestClusterStatus(t *testing.T) {
+	am, reg := setupAMTest(t)
+	am.WithLock(func() {
+		am.peer = &fakeMembershipPeer{}
+	})
+
+	now := time.Now()
+	_, err := am.CreateSilence(&PostableSilence{Silence: amv2.Silence{
+		Comment:   ptr("comment"),
+		CreatedBy: ptr("test"),
+		StartsAt:  ptr(strfmt.DateTime(now)),
+		EndsAt:    ptr(strfmt.DateTime(now.Add(time.Hour))),
+		Matchers:  amv2.Matchers{{IsEqual: ptr(true), IsRegex: ptr(false), Name: ptr("foo"), Value: ptr("bar")}},
+	}})
+	require.NoError(t, err)
+
+	status := am.ClusterStatus()
+	require.Equal(t, "peer-b", status.Name)
+	require.Equal(t, amv2.ClusterStatusStatusReady, status.Status)
+	require.Equal(t, 1, status.Position)
+	require.Equal(t, []cluster.MemberStatus{{Name: "peer-a", Address: "10.0.0.1:9094"}, {Name: "peer-b", Address: "10.0.0.2:9094"}}, status.Peers)
+	require.Len(t, status.States, 3)
+	require.Equal(t, "notificationlog:1", status.States[0].Key)
+	require.Zero(t, status.States[0].MessagesSent)
+	require.Equal(t, "repeatcounts:1", status.States[1].Key)
+	require.Zero(t, status.States[1].MessagesSent)
+	require.Equal(t, "silences:1", status.States[2].Key)
+	require.Equal(t, uint64(1), status.States[2].MessagesSent)
+	require.NotZero(t, status.States[2].MessagesSentBytes)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+# HELP grafana_alerting_cluster_members Number of members of the cluster, as seen by the Alertmanager.
+# TYPE grafana_alerting_cluster_members gauge
+grafana_alerting_cluster_members{org="1"} 2
+# HELP grafana_alerting_cluster_position Position of the Alertmanager in the cluster.
+# TYPE grafana_alerting_cluster_position gauge
+grafana_alerting_cluster_position{org="1"} 1
+# HELP grafana_alerting_cluster_state_messages_sent_total Number of messages of the state sent to the other peers, as broadcasts or full states.
+# TYPE grafana_alerting_cluster_state_messages_sent_total counter
+grafana_alerting_cluster_state_messages_sent_total{org="1",state="notificationlog"} 0
+grafana_alerting_cluster_state_messages_sent_total{org="1",state="repeatcounts"} 0
+grafana_alerting_cluster_state_messages_sent_total{org="1",state="silences"} 1
+# HELP grafana_alerting_cluster_state_failures_total Number of messages of the state that could not be sent or merged, by operation.
+# TYPE grafana_alerting_cluster_state_failures_total counter
+grafana_alerting_cluster_state_failures_total{operation="receive",org="1",state="notificationlog"} 0
+grafana_alerting_cluster_state_failures_total{operation="receive",org="1",state="repeatcounts"} 0
+grafana_alerting_cluster_state_failures_total{operation="receive",org="1",state="silences"} 0
+grafana_alerting_cluster_state_failures_total{operation="send",org="1",state="notificationlog"} 0
+grafana_alerting_cluster_state_failures_total{operation="send",org="1",state="repeatcounts"} 0
+grafana_alerting_cluster_state_failures_total{operation="send",org="1",state="silences"} 0
+`), "grafana_alerting_cluster_members", "grafana_alerting_cluster_position", "grafana_alerting_cluster_state_messages_sent_total", "grafana_alerting_cluster_state_failures_total"))
+
+	// The metrics of the tenant are removed once the Alertmanager is stopped.
+	am.StopAndWait()
+	count, err := testutil.GatherAndCount(reg, "grafana_alerting_cluster_members")
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+type failingNotifier struct {
+	fakeNotifier
+}
+
+func (f *failingNotifier) Notify(context.Context, ...*types.Alert) (bool, error) {
+	return false, errors.New("failed")
+}
+
+func TestReceiverStatuses(t *testing.T) {
+	ok := func() *nfstatus.Integration {
+		n := &fakeNotifier{}
+		return nfstatus.NewIntegration(n, n, "webhook", 0, "r")
+	}
+	failing := func() *nfstatus.Integration {
+		n := &failingNotifier{}
+		return nfstatus.NewIntegration(n, n, "slack", 1, "r")
+	}
+	notify := func(integrations ...*nfstatus.Integration) []*nfstatus.Integration {
+		for _, i := range integrations {
+			_, _ = i.Notify(context.Background())
+		}
+		return integrations
+	}
+
+	statuses := receiverStatuses([]*nfstatus.Receiver{
+		nfstatus.NewReceiver("ok", true, notify(ok(), ok())),
+		nfstatus.NewReceiver("degraded", true, notify(ok(), failing())),
+		nfstatus.NewReceiver("failing", true, notify(failing())),
+		nfstatus.NewReceiver("unknown", false, []*nfstatus.Integration{ok()}),
+	})
+
+	require.Len(t, statuses, 4)
+	expected := map[string]Health{
+		"ok":       HealthOK,
+		"degraded": HealthDegraded,
+		"failing":  HealthFailing,
+		"unknown":  HealthUnknown,
+	}
+	for _, s := range statuses {
+		require.Equal(t, expected[s.Name], s.Health, s.Name)
+	}
+
+	degraded := statuses[1]
+	require.Equal(t, HealthOK, degraded.Integrations[0].Health)
+	require.Equal(t, HealthFailing, degraded.Integrations[1].Health)
+	require.Equal(t, "failed", degraded.Integrations[1].LastNotifyAttemptError)
+	require.False(t, statuses[3].Active)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/alerting/receivers/kafka"
 	"github.com/grafana/alerting/receivers/line"
 	"github.com/grafana/alerting/receivers/mqtt"
+	"github.com/grafana/alerting/receivers/oncall"
 	"github.com/grafana/alerting/receivers/opsgenie"
 	"github.com/grafana/alerting/receivers/pagerduty"
 	"github.com/grafana/alerting/receivers/pushover"
@@ -151,6 +152,10 @@ var AllKnownConfigsForTesting = map[string]NotifierConfigTest{
 		Config:  mqtt.FullValidConfigForTesting,
 		Secrets: mqtt.FullValidSecretsForTesting,
 	},
+	"oncall": {NotifierType: "oncall",
+		Config:  oncall.FullValidConfigForTesting,
+		Secrets: oncall.FullValidSecretsForTesting,
+	},
 	"opsgenie": {NotifierType: "opsgenie",
 		Config:  opsgenie.FullValidConfigForTesting,
 		Secrets: opsgenie.FullValidSecretsForTesting,
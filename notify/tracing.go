@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/alerting/notify/nfstatus"
+	"github.com/grafana/alerting/receivers"
+)
+
+// tracerName is the name of the tracer of the notification pipeline.
+const tracerName = "github.com/grafana/alerting/notify"
+
+const (
+	attrReceiver          = attribute.Key("alerting.receiver")
+	attrGroupKey          = attribute.Key("alerting.group.key")
+	attrAlertsCount       = attribute.Key("alerting.alerts.count")
+	attrAlertsOutCount    = attribute.Key("alerting.alerts.out.count")
+	attrIntegrationType   = attribute.Key("alerting.integration.type")
+	attrIntegrationUID    = attribute.Key("alerting.integration.uid")
+	attrIntegrationIdx    = attribute.Key("alerting.integration.index")
+	attrEmailRecipients   = attribute.Key("alerting.email.recipients")
+	attrWebhookHTTPMethod = attribute.Key("http.request.method")
+)
+
+// tracingStage wraps a notify.Stage with a span. The stages that follow the wrapped stage are not children of its span.
+type tracingStage struct {
+	tracer trace.Tracer
+	name   string
+	attrs  []attribute.KeyValue
+	stage  notify.Stage
+}
+
+// newTracingStage returns a stage that traces the stage with a span of the given name.
+func newTracingStage(tracer trace.Tracer, name string, stage notify.Stage, attrs ...attribute.KeyValue) *tracingStage {
+	return &tracingStage{tracer: tracer, name: name, attrs: attrs, stage: stage}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *tracingStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	parent := trace.SpanFromContext(ctx)
+	ctx, span := s.tracer.Start(ctx, s.name, trace.WithAttributes(groupAttributes(ctx, len(alerts))...), trace.WithAttributes(s.attrs...))
+	defer span.End()
+
+	ctx, res, err := s.stage.Exec(ctx, l, alerts...)
+	span.SetAttributes(attrAlertsOutCount.Int(len(res)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	// The next stages are siblings of this stage, not its children.
+	return trace.ContextWithSpan(ctx, parent), res, err
+}
+
+// flushTracingStage traces a flush of an aggregation group. It adds the TracerProvider to the context
+// so that the notification attempts, and the webhooks and emails sent, are traced with the same TracerProvider.
+type flushTracingStage struct {
+	tp    trace.TracerProvider
+	stage *tracingStage
+}
+
+func newFlushTracingStage(tp trace.TracerProvider, stage notify.Stage) *flushTracingStage {
+	return &flushTracingStage{tp: tp, stage: newTracingStage(tp.Tracer(tracerName), "notify.flush", stage)}
+}
+
+// Exec implements the notify.Stage interface.
+func (s *flushTracingStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	return s.stage.Exec(nfstatus.WithTracerProvider(ctx, s.tp), l, alerts...)
+}
+
+// groupAttributes returns the attributes of the aggregation group in the context.
+func groupAttributes(ctx context.Context, alerts int) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attrAlertsCount.Int(alerts)}
+	if name, ok := notify.ReceiverName(ctx); ok {
+		attrs = append(attrs, attrReceiver.String(name))
+	}
+	if key, ok := notify.GroupKey(ctx); ok {
+		attrs = append(attrs, attrGroupKey.String(key))
+	}
+	return attrs
+}
+
+// integrationAttributes returns the attributes of the integration.
+func integrationAttributes(i *Integration) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attrIntegrationType.String(i.Name()),
+		attrIntegrationUID.String(i.UID()),
+		attrIntegrationIdx.Int(i.Index()),
+	}
+}
+
+// instrumentedWebhookSender traces the webhooks sent with a span child of the span in the context,
+// if the context carries a TracerProvider, and observes the size of their payloads.
+type instrumentedWebhookSender struct {
+	sender receivers.WebhookSender
+	meta   receivers.Metadata
+}
+
+// SendWebhook implements the receivers.WebhookSender interface.
+func (s *instrumentedWebhookSender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
+	method := cmd.HTTPMethod
+	if method == "" {
+		method = "POST"
+	}
+	ctx, span := startSenderSpan(ctx, "notify.webhook.send", s.meta, attrWebhookHTTPMethod.String(method))
+	defer span.End()
+	observePayloadSize(ctx, len(cmd.Body))
+	return endSenderSpan(span, s.sender.SendWebhook(ctx, cmd))
+}
+
+// capturingWebhookSender keeps the receivers.CapturingWebhookSender marker of the sender it instruments.
+type capturingWebhookSender struct {
+	receivers.WebhookSender
+}
+
+// CapturesWebhooks implements the receivers.CapturingWebhookSender interface.
+func (capturingWebhookSender) CapturesWebhooks() {}
+
+// instrumentedEmailSender traces the emails sent with a span child of the span in the context,
+// if the context carries a TracerProvider.
+type instrumentedEmailSender struct {
+	sender receivers.EmailSender
+	meta   receivers.Metadata
+}
+
+// SendEmail implements the receivers.EmailSender interface.
+func (s *instrumentedEmailSender) SendEmail(ctx context.Context, cmd *receivers.SendEmailSettings) error {
+	ctx, span := startSenderSpan(ctx, "notify.email.send", s.meta, attrEmailRecipients.Int(len(cmd.To)))
+	defer span.End()
+	return endSenderSpan(span, s.sender.SendEmail(ctx, cmd))
+}
+
+func startSenderSpan(ctx context.Context, name string, meta receivers.Metadata, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attrIntegrationType.String(meta.Type), attrIntegrationUID.String(meta.UID))
+	return nfstatus.TracerProviderFromContext(ctx).Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func endSenderSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
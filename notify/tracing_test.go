@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/grafana/alerting/notify/nfstatus"
+	"github.com/grafana/alerting/receivers"
+)
+
+type webhookNotifier struct {
+	sender receivers.WebhookSender
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	return false, n.sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: "http://localhost", Body: "{}"})
+}
+
+func (n *webhookNotifier) SendResolved() bool {
+	return true
+}
+
+func newTestTracerProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() {
+		require.NoError(t, tp.Shutdown(context.Background()))
+	})
+	return tp, exporter
+}
+
+func spansByName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	res := make(map[string]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		res[s.Name] = s
+	}
+	return res
+}
+
+func spanAttributes(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	res := make(map[attribute.Key]attribute.Value, len(s.Attributes))
+	for _, a := range s.Attributes {
+		res[a.Key] = a.Value
+	}
+	return res
+}
+
+func TestTracing_ReceiverStage(t *testing.T) {
+	am, _ := setupAMTest(t)
+	tp, exporter := newTestTracerProvider(t)
+	am.tracerProvider = tp
+	am.tracer = tp.Tracer(tracerName)
+
+	sender := &receivers.NotificationServiceMock{}
+	n := &webhookNotifier{sender: &instrumentedWebhookSender{sender: sender, meta: receivers.Metadata{UID: "uid", Type: "webhook"}}}
+	integrations := []*Integration{NewIntegration(n, n, "webhook", 0, "receiver", nfstatus.WithUID("uid"))}
+	stage := newFlushTracingStage(tp, am.createReceiverStage("receiver", integrations, func() time.Duration { return 0 }, am.notificationLog, nil, nil))
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "{}:{}")
+	ctx = notify.WithReceiverName(ctx, "receiver")
+	ctx = notify.WithRepeatInterval(ctx, time.Hour)
+	ctx = notify.WithNow(ctx, now)
+
+	_, _, err := stage.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, sender.WebhookCalls, 1)
+
+	spans := spansByName(exporter.GetSpans())
+	require.Len(t, spans, 7)
+
+	flush := spans["notify.flush"]
+	require.False(t, flush.Parent.IsValid())
+	attrs := spanAttributes(flush)
+	require.Equal(t, "receiver", attrs[attrReceiver].AsString())
+	require.Equal(t, "{}:{}", attrs[attrGroupKey].AsString())
+	require.Equal(t, int64(2), attrs[attrAlertsCount].AsInt64())
+
+	for _, name := range []string{"notify.stage.wait", "notify.stage.dedup", "notify.stage.retry", "notify.stage.set_notifies"} {
+		s, ok := spans[name]
+		require.True(t, ok, name)
+		require.Equal(t, flush.SpanContext.SpanID(), s.Parent.SpanID(), name)
+		attrs := spanAttributes(s)
+		require.Equal(t, "webhook", attrs[attrIntegrationType].AsString(), name)
+		require.Equal(t, "uid", attrs[attrIntegrationUID].AsString(), name)
+		require.Equal(t, "{}:{}", attrs[attrGroupKey].AsString(), name)
+	}
+
+	attempt := spans["notify.integration.attempt"]
+	require.Equal(t, spans["notify.stage.retry"].SpanContext.SpanID(), attempt.Parent.SpanID())
+	attrs = spanAttributes(attempt)
+	require.Equal(t, "receiver", attrs["alerting.receiver"].AsString())
+	require.Equal(t, "webhook", attrs["alerting.integration.type"].AsString())
+	require.Equal(t, "uid", attrs["alerting.integration.uid"].AsString())
+	require.Equal(t, int64(2), attrs["alerting.alerts.count"].AsInt64())
+
+	send := spans["notify.webhook.send"]
+	require.Equal(t, attempt.SpanContext.SpanID(), send.Parent.SpanID())
+	require.Equal(t, "POST", spanAttributes(send)[attrWebhookHTTPMethod].AsString())
+}
+
+func TestTracingStage(t *testing.T) {
+	tp, exporter := newTestTracerProvider(t)
+	tracer := tp.Tracer(tracerName)
+
+	errFailed := errors.New("failed")
+	stage := newTracingStage(tracer, "parent", notify.MultiStage{
+		newTracingStage(tracer, "first", notify.StageFunc(func(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+			return ctx, alerts[:1], nil
+		})),
+		newTracingStage(tracer, "second", notify.StageFunc(func(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+			return ctx, nil, errFailed
+		})),
+	})
+
+	_, _, err := stage.Exec(context.Background(), log.NewNopLogger(), &types.Alert{}, &types.Alert{})
+	require.ErrorIs(t, err, errFailed)
+
+	spans := spansByName(exporter.GetSpans())
+	require.Len(t, spans, 3)
+	parent := spans["parent"]
+
+	// Stages are siblings, children of the enclosing stage.
+	first := spans["first"]
+	require.Equal(t, parent.SpanContext.SpanID(), first.Parent.SpanID())
+	require.Equal(t, int64(2), spanAttributes(first)[attrAlertsCount].AsInt64())
+	require.Equal(t, int64(1), spanAttributes(first)[attrAlertsOutCount].AsInt64())
+	require.Equal(t, codes.Unset, first.Status.Code)
+
+	second := spans["second"]
+	require.Equal(t, parent.SpanContext.SpanID(), second.Parent.SpanID())
+	require.Equal(t, int64(1), spanAttributes(second)[attrAlertsCount].AsInt64())
+	require.Equal(t, codes.Error, second.Status.Code)
+	require.Equal(t, codes.Error, parent.Status.Code)
+}
+
+func TestTracing_DisabledByDefault(t *testing.T) {
+	am, _ := setupAMTest(t)
+
+	// Without a TracerProvider, the spans are not recorded.
+	_, span := am.tracer.Start(context.Background(), "test")
+	require.False(t, span.SpanContext().IsValid())
+	require.False(t, span.IsRecording())
+}
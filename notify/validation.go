@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/alerting/definition"
+	"github.com/grafana/alerting/receivers"
+)
+
+// ValidateAll parses and validates the configuration like definition.ValidateAll, and also validates the settings
+// of the Grafana integrations, decrypted with decrypt. It reports all the problems instead of stopping at the first one.
+func ValidateAll(ctx context.Context, rawCfg []byte, decrypt GetDecryptedValueFn) (*definition.PostableApiAlertingConfig, *definition.ValidationReport) {
+	cfg, report := definition.ValidateAll(rawCfg)
+	for i, r := range cfg.Receivers {
+		if r == nil {
+			continue
+		}
+		for j, p := range r.GrafanaManagedReceivers {
+			if p == nil {
+				continue
+			}
+			path := fmt.Sprintf("receivers[%d].grafana_managed_receiver_configs[%d]", i, j)
+			validateIntegration(ctx, path, &GrafanaIntegrationConfig{
+				UID:                   p.UID,
+				Name:                  p.Name,
+				Type:                  p.Type,
+				DisableResolveMessage: p.DisableResolveMessage,
+				SplitByAlert:          p.SplitByAlert,
+				MuteTimeIntervals:     p.MuteTimeIntervals,
+				ActiveTimeIntervals:   p.ActiveTimeIntervals,
+				Settings:              json.RawMessage(p.Settings),
+				SecureSettings:        p.SecureSettings,
+			}, decrypt, report)
+		}
+	}
+	return cfg, report
+}
+
+// validateIntegration adds the problems of the integration at path to the report. The settings are first validated
+// with the fields of the type of integration so that the problems have the paths of the settings, and are then
+// parsed like BuildReceiverConfiguration if they have no such problems.
+func validateIntegration(ctx context.Context, path string, integration *GrafanaIntegrationConfig, decrypt GetDecryptedValueFn, report *definition.ValidationReport) {
+	t, ok := receivers.GetIntegration(integration.Type)
+	if !ok {
+		report.AddError(path+".type", "notifier %s is not supported", integration.Type)
+		return
+	}
+
+	var settings map[string]any
+	if len(integration.Settings) > 0 {
+		if err := json.Unmarshal(integration.Settings, &settings); err != nil {
+			report.AddError(path+".settings", "failed to unmarshal settings: %s", err)
+			return
+		}
+	}
+	for _, key := range sortedKeys(integration.SecureSettings) {
+		if !slices.Contains(t.SecureFields, key) {
+			report.AddWarning(path+".secureSettings."+key, "unknown secure setting %s", key)
+		}
+	}
+	n := len(report.Issues)
+	validateSettings(path+".settings", "", settings, integration.SecureSettings, t.Fields, report)
+	if slices.ContainsFunc(report.Issues[n:], func(i definition.ValidationIssue) bool { return i.Severity == definition.SeverityError }) {
+		return
+	}
+
+	var result GrafanaReceiverConfig
+	if err := parseNotifier(ctx, &result, integration, decrypt); err != nil {
+		var refErr SecretReferenceError
+		if errors.As(err, &refErr) && refErr.Path != "" {
+			report.AddError(path+"."+refErr.Path, "failed to resolve secret reference %q: %s", refErr.Reference, refErr.Err)
+			return
+		}
+		report.AddError(path+".settings", "%s", err)
+	}
+}
+
+// validateSettings adds the problems of the settings at path to the report. The keys of the secure settings of
+// nested settings are prefixed with prefix.
+func validateSettings(path, prefix string, settings map[string]any, secureSettings map[string]string, fields []receivers.Field, report *definition.ValidationReport) {
+	for _, key := range sortedKeys(settings) {
+		if !slices.ContainsFunc(fields, func(f receivers.Field) bool { return f.Name == key }) {
+			report.AddWarning(path+"."+key, "unknown setting %s", key)
+		}
+	}
+	for _, f := range fields {
+		p := path + "." + f.Name
+		value, ok := settings[f.Name]
+		if !ok || value == nil || value == "" {
+			if f.Required && (!f.Secure || secureSettings[prefix+f.Name] == "") {
+				report.AddError(p, "%s is required", f.Name)
+			}
+			continue
+		}
+		if err := validateSetting(p, prefix+f.Name+".", value, secureSettings, f, report); err != nil {
+			report.AddError(p, "%s", err)
+		}
+	}
+}
+
+// validateSetting returns an error if the value does not match the field. The problems of the nested settings are
+// added to the report.
+func validateSetting(path, prefix string, value any, secureSettings map[string]string, f receivers.Field, report *definition.ValidationReport) error {
+	switch f.Type {
+	case receivers.FieldTypeString:
+		if _, ok := value.(string); !ok {
+			return errors.New("must be a string")
+		}
+	case receivers.FieldTypeBool:
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case receivers.FieldTypeInteger:
+		switch v := value.(type) {
+		case float64:
+			if v != float64(int64(v)) {
+				return errors.New("must be an integer")
+			}
+		case string:
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return errors.New("must be an integer")
+			}
+		default:
+			return errors.New("must be an integer")
+		}
+	case receivers.FieldTypeMap:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return errors.New("must be an object")
+		}
+		for _, k := range sortedKeys(m) {
+			if _, ok := m[k].(string); !ok {
+				report.AddError(path+"."+k, "must be a string")
+			}
+		}
+	case receivers.FieldTypeObject:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return errors.New("must be an object")
+		}
+		validateSettings(path, prefix, m, secureSettings, f.Fields, report)
+	case receivers.FieldTypeArray:
+		items, ok := value.([]any)
+		if !ok {
+			return errors.New("must be a list")
+		}
+		for i, item := range items {
+			m, ok := item.(map[string]any)
+			if !ok {
+				report.AddError(fmt.Sprintf("%s[%d]", path, i), "must be an object")
+				continue
+			}
+			validateSettings(fmt.Sprintf("%s[%d]", path, i), "", m, nil, f.Fields, report)
+		}
+	}
+
+	// Some values, such as the parse modes of Telegram, are case-insensitive.
+	if len(f.Enum) > 0 && !slices.ContainsFunc(f.Enum, func(e any) bool { return strings.EqualFold(fmt.Sprint(e), fmt.Sprint(value)) }) {
+		allowed := make([]string, 0, len(f.Enum))
+		for _, e := range f.Enum {
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/definition"
+)
+
+func TestValidateAll(t *testing.T) {
+	t.Run("valid configuration has no issues", func(t *testing.T) {
+		_, report := ValidateAll(context.Background(), []byte(`{
+			"route": {"receiver": "default"},
+			"receivers": [{
+				"name": "default",
+				"grafana_managed_receiver_configs": [
+					{"uid": "a", "name": "a", "type": "discord", "settings": {}, "secureSettings": {"url": "http://localhost"}},
+					{"uid": "b", "name": "b", "type": "telegram", "settings": {"chatid": "1", "parse_mode": "html"}, "secureSettings": {"bottoken": "token"}}
+				]
+			}]
+		}`), NoopDecrypt)
+		require.Empty(t, report.Issues)
+	})
+
+	t.Run("reports the problems of all the integrations", func(t *testing.T) {
+		_, report := ValidateAll(context.Background(), []byte(`{
+			"route": {"receiver": "default", "routes": [{"receiver": "missing"}]},
+			"receivers": [{
+				"name": "default",
+				"grafana_managed_receiver_configs": [
+					{"uid": "a", "name": "a", "type": "discord", "settings": {"title": 1, "avatar": "a"}},
+					{"uid": "b", "name": "b", "type": "unknown", "settings": {}},
+					{"uid": "c", "name": "c", "type": "mqtt", "settings": {"brokerUrl": "tcp://localhost", "topic": "t", "qos": "3", "tlsConfig": {"insecureSkipVerify": "yes"}}}
+				]
+			}, {
+				"name": "other",
+				"grafana_managed_receiver_configs": [
+					{"uid": "d", "name": "d", "type": "opsgenie", "settings": {"apiKey": "key", "responders": [{"id": "1"}]}},
+					{"uid": "e", "name": "e", "type": "threema", "settings": {"gateway_id": "invalid", "recipient_id": "12345678"}, "secureSettings": {"api_secret": "secret", "token": "t"}}
+				]
+			}]
+		}`), NoopDecrypt)
+		require.Equal(t, []definition.ValidationIssue{
+			{Path: "route.routes[0].receiver", Severity: definition.SeverityError, Message: "unexpected receiver (missing) is undefined"},
+			{Path: "receivers[0].grafana_managed_receiver_configs[0].settings.avatar", Severity: definition.SeverityWarning, Message: "unknown setting avatar"},
+			{Path: "receivers[0].grafana_managed_receiver_configs[0].settings.url", Severity: definition.SeverityError, Message: "url is required"},
+			{Path: "receivers[0].grafana_managed_receiver_configs[0].settings.title", Severity: definition.SeverityError, Message: "must be a string"},
+			{Path: "receivers[0].grafana_managed_receiver_configs[1].type", Severity: definition.SeverityError, Message: "notifier unknown is not supported"},
+			{Path: "receivers[0].grafana_managed_receiver_configs[2].settings.qos", Severity: definition.SeverityError, Message: "must be one of 0, 1, 2"},
+			{Path: "receivers[0].grafana_managed_receiver_configs[2].settings.tlsConfig.insecureSkipVerify", Severity: definition.SeverityError, Message: "must be a boolean"},
+			{Path: "receivers[1].grafana_managed_receiver_configs[0].settings.responders[0].type", Severity: definition.SeverityError, Message: "type is required"},
+			{Path: "receivers[1].grafana_managed_receiver_configs[1].secureSettings.token", Severity: definition.SeverityWarning, Message: "unknown secure setting token"},
+			{Path: "receivers[1].grafana_managed_receiver_configs[1].settings", Severity: definition.SeverityError, Message: "invalid Threema Gateway ID: Must start with a *"},
+		}, report.Issues)
+	})
+
+	t.Run("secret references", func(t *testing.T) {
+		ctx := WithSecretResolver(context.Background(), NewSecretResolver(nil))
+		_, report := ValidateAll(ctx, []byte(`{
+			"route": {"receiver": "default"},
+			"receivers": [{
+				"name": "default",
+				"grafana_managed_receiver_configs": [
+					{"uid": "a", "name": "a", "type": "discord", "settings": {}, "secureSettings": {"url": "${provider:vault/url}"}}
+				]
+			}]
+		}`), NoopDecrypt)
+		require.Equal(t, []definition.ValidationIssue{
+			{
+				Path:     "receivers[0].grafana_managed_receiver_configs[0].secureSettings.url",
+				Severity: definition.SeverityError,
+				Message:  `failed to resolve secret reference "${provider:vault/url}": unknown secret provider vault`,
+			},
+		}, report.Issues)
+	})
+}
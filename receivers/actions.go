@@ -0,0 +1,119 @@
+package receivers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// ActionKind is the kind of an interactive action, such as a button, attached to a notification.
+type ActionKind string
+
+const (
+	// ActionSilence silences the firing alerts of the notification.
+	ActionSilence ActionKind = "silence"
+	// ActionAcknowledge acknowledges the firing alerts of the notification.
+	ActionAcknowledge ActionKind = "ack"
+)
+
+// actionCodes are the short codes of the kinds in encoded actions, to fit the payload limits of the platforms.
+var actionCodes = map[ActionKind]string{
+	ActionSilence:     "s",
+	ActionAcknowledge: "a",
+}
+
+// groupIDLen is the length of the GroupID of an action.
+const groupIDLen = 8
+
+var ErrInvalidAction = errors.New("invalid action")
+
+// Action is an interactive action attached to a notification. It is encoded in the payload of the button,
+// and decoded by the handler of the platform callbacks when the button is clicked.
+type Action struct {
+	Kind ActionKind
+	// GroupID identifies the aggregation group of the notification. It is a prefix of the hash of the group key.
+	GroupID string
+	// Fingerprints are the fingerprints of the firing alerts of the notification.
+	Fingerprints []string
+}
+
+// NewActions returns the actions for the firing alerts of the notification.
+// It returns no actions if all alerts are resolved.
+func NewActions(ctx context.Context, alerts ...*types.Alert) ([]Action, error) {
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var fingerprints []string
+	for _, a := range alerts {
+		if !a.Resolved() {
+			fingerprints = append(fingerprints, a.Fingerprint().String())
+		}
+	}
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+	groupID := key.Hash()[:groupIDLen]
+	return []Action{
+		{Kind: ActionSilence, GroupID: groupID, Fingerprints: fingerprints},
+		{Kind: ActionAcknowledge, GroupID: groupID, Fingerprints: fingerprints},
+	}, nil
+}
+
+// Label returns the text of the button of the action.
+func (a Action) Label() string {
+	switch a.Kind {
+	case ActionSilence:
+		return "Silence"
+	case ActionAcknowledge:
+		return "Acknowledge"
+	default:
+		return string(a.Kind)
+	}
+}
+
+// Encode returns the action in the format <kind>:<group ID>:<fingerprint>,<fingerprint>...
+func (a Action) Encode() string {
+	return actionCodes[a.Kind] + ":" + a.GroupID + ":" + strings.Join(a.Fingerprints, ",")
+}
+
+// EncodeActions returns the encoded actions. It returns false if an encoded action is longer than maxLen,
+// in which case the actions should not be added to the notification.
+func EncodeActions(actions []Action, maxLen int) ([]string, bool) {
+	res := make([]string, 0, len(actions))
+	for _, a := range actions {
+		s := a.Encode()
+		if len(s) > maxLen {
+			return nil, false
+		}
+		res = append(res, s)
+	}
+	return res, true
+}
+
+// DecodeAction decodes an action encoded with Encode.
+func DecodeAction(s string) (Action, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return Action{}, fmt.Errorf("%w: %q", ErrInvalidAction, s)
+	}
+	var a Action
+	for kind, code := range actionCodes {
+		if parts[0] == code {
+			a.Kind = kind
+		}
+	}
+	if a.Kind == "" {
+		return Action{}, fmt.Errorf("%w: unknown kind %q", ErrInvalidAction, parts[0])
+	}
+	a.GroupID = parts[1]
+	if parts[2] == "" {
+		return Action{}, fmt.Errorf("%w: no fingerprints", ErrInvalidAction)
+	}
+	a.Fingerprints = strings.Split(parts[2], ",")
+	return a, nil
+}
@@ -0,0 +1,67 @@
+package receivers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewActions(t *testing.T) {
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "firing"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "resolved"}, EndsAt: time.Now().Add(-time.Minute)}}
+	ctx := notify.WithGroupKey(context.Background(), "group")
+
+	t.Run("returns an error without group key", func(t *testing.T) {
+		_, err := NewActions(context.Background(), firing)
+		require.Error(t, err)
+	})
+
+	t.Run("returns no actions if all alerts are resolved", func(t *testing.T) {
+		actions, err := NewActions(ctx, resolved)
+		require.NoError(t, err)
+		require.Empty(t, actions)
+	})
+
+	t.Run("returns actions for the firing alerts", func(t *testing.T) {
+		actions, err := NewActions(ctx, firing, resolved)
+		require.NoError(t, err)
+		require.Len(t, actions, 2)
+		groupID := notify.Key("group").Hash()[:groupIDLen]
+		require.Equal(t, Action{Kind: ActionSilence, GroupID: groupID, Fingerprints: []string{firing.Fingerprint().String()}}, actions[0])
+		require.Equal(t, Action{Kind: ActionAcknowledge, GroupID: groupID, Fingerprints: []string{firing.Fingerprint().String()}}, actions[1])
+		require.Equal(t, "Silence", actions[0].Label())
+		require.Equal(t, "Acknowledge", actions[1].Label())
+	})
+}
+
+func TestEncodeDecodeAction(t *testing.T) {
+	a := Action{Kind: ActionSilence, GroupID: "0123abcd", Fingerprints: []string{"a1b2c3d4e5f60718", "1122334455667788"}}
+	s := a.Encode()
+	require.Equal(t, "s:0123abcd:a1b2c3d4e5f60718,1122334455667788", s)
+
+	decoded, err := DecodeAction(s)
+	require.NoError(t, err)
+	require.Equal(t, a, decoded)
+
+	for _, invalid := range []string{"", "s:abc", "x:abc:def", "a:abc:", "s:a:b:c"} {
+		_, err := DecodeAction(invalid)
+		require.ErrorIs(t, err, ErrInvalidAction, invalid)
+	}
+}
+
+func TestEncodeActions(t *testing.T) {
+	a := Action{Kind: ActionAcknowledge, GroupID: "0123abcd", Fingerprints: []string{strings.Repeat("f", 16), strings.Repeat("e", 16), strings.Repeat("d", 16)}}
+	values, ok := EncodeActions([]Action{a}, 64)
+	require.True(t, ok)
+	require.Equal(t, []string{a.Encode()}, values)
+
+	a.Fingerprints = append(a.Fingerprints, strings.Repeat("c", 16))
+	_, ok = EncodeActions([]Action{a}, 64)
+	require.False(t, ok)
+}
@@ -0,0 +1,21 @@
+package alertmanager
+
+import (
+	"github.com/grafana/alerting/receivers"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "prometheus-alertmanager",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true},
+			{Name: "basicAuthUser", Type: receivers.FieldTypeString},
+			{Name: "basicAuthPassword", Type: receivers.FieldTypeString, Secure: true},
+		},
+		Dependencies: receivers.ImagesDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Images, deps.Logger)
+		},
+	})
+}
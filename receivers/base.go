@@ -18,6 +18,8 @@ type Metadata struct {
 	Name                  string
 	Type                  string
 	DisableResolveMessage bool
+	// SplitByAlert is true if the integration is notified once per alert instead of once per aggregation group.
+	SplitByAlert bool
 }
 
 func NewBase(cfg Metadata) *Base {
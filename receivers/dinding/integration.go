@@ -0,0 +1,28 @@
+package dinding
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "dingding",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true, Templated: true},
+			{Name: "msgType", Type: receivers.FieldTypeString, Default: defaultDingdingMsgType, Enum: []any{"link", "actionCard"}},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.WebhookSenderDependency,
+		NewConfig: func(settings json.RawMessage, _ receivers.DecryptFunc) (Config, error) {
+			return NewConfig(settings)
+		},
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
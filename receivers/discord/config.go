@@ -15,6 +15,9 @@ type Config struct {
 	AvatarURL          string `json:"avatar_url,omitempty" yaml:"avatar_url,omitempty"`
 	WebhookURL         string `json:"url,omitempty" yaml:"url,omitempty"`
 	UseDiscordUsername bool   `json:"use_discord_username,omitempty" yaml:"use_discord_username,omitempty"`
+	// InteractiveActions adds buttons to silence and acknowledge the firing alerts.
+	// Discord only shows them for webhooks owned by an application.
+	InteractiveActions bool `json:"interactiveActions,omitempty" yaml:"interactiveActions,omitempty"`
 }
 
 func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
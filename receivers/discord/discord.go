@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -29,8 +31,17 @@ type discordEmbedType string
 const (
 	discordRichEmbed discordEmbedType = "rich"
 
+	// stateMessageID is the key of the id of the first message of the group in the notification state.
+	stateMessageID = "id"
+
 	discordMaxEmbeds     = 10
 	discordMaxMessageLen = 2000
+	// https://discord.com/developers/docs/interactions/message-components#button-object
+	discordMaxCustomIDLen = 100
+
+	discordComponentActionRow = 1
+	discordComponentButton    = 2
+	discordButtonSecondary    = 2
 )
 
 type discordMessage struct {
@@ -38,6 +49,8 @@ type discordMessage struct {
 	Content   string             `json:"content"`
 	AvatarURL string             `json:"avatar_url,omitempty"`
 	Embeds    []discordLinkEmbed `json:"embeds,omitempty"`
+	// Components are the rows of buttons of the interactive actions.
+	Components []discordComponent `json:"components,omitempty"`
 }
 
 // discordLinkEmbed implements https://discord.com/developers/docs/resources/channel#embed-object
@@ -52,6 +65,15 @@ type discordLinkEmbed struct {
 	Image *discordImage `json:"image,omitempty"`
 }
 
+// discordComponent is an action row or a button.
+type discordComponent struct {
+	Type       int                `json:"type"`
+	Components []discordComponent `json:"components,omitempty"`
+	Style      int                `json:"style,omitempty"`
+	Label      string             `json:"label,omitempty"`
+	CustomID   string             `json:"custom_id,omitempty"`
+}
+
 // discordFooter implements https://discord.com/developers/docs/resources/channel#embed-object-embed-footer-structure
 type discordFooter struct {
 	Text    string `json:"text"`
@@ -175,6 +197,14 @@ func (d Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error)
 
 	msg.Embeds = embeds
 
+	if d.settings.InteractiveActions {
+		components, err := d.actionComponents(ctx, as)
+		if err != nil {
+			return false, err
+		}
+		msg.Components = components
+	}
+
 	if tmplErr != nil {
 		d.log.Warn("failed to template Discord message", "error", tmplErr.Error())
 		tmplErr = nil
@@ -191,11 +221,25 @@ func (d Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error)
 		return false, err
 	}
 
+	// Edit the first message of the group in place, if any.
+	state, hasState := d.GetNotificationState(ctx)
+	_, hasStore := receivers.NotificationStateStoreFromContext(ctx)
+	if hasStore {
+		u, err = webhookMessageURL(u, state[stateMessageID])
+		if err != nil {
+			return false, err
+		}
+	}
+
 	cmd, err := d.buildRequest(u, body, attachments)
 	if err != nil {
 		return false, err
 	}
+	if hasState {
+		cmd.HTTPMethod = http.MethodPatch
+	}
 
+	var messageID string
 	cmd.Validation = func(body []byte, statusCode int) error {
 		if statusCode/100 != 2 {
 			d.log.Error("failed to send notification to Discord", "statusCode", statusCode, "responseBody", string(body))
@@ -205,14 +249,63 @@ func (d Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error)
 			}
 			return fmt.Errorf("unexpected status code %d from Discord", statusCode)
 		}
+		if hasStore {
+			var res struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(body, &res); err == nil {
+				messageID = res.ID
+			}
+		}
 		return nil
 	}
 	if err := d.ns.SendWebhook(ctx, cmd); err != nil {
+		if hasState {
+			// The message might have been deleted, so the next attempt sends a new message.
+			if err := d.DeleteNotificationState(ctx); err != nil {
+				d.log.Warn("Failed to delete the notification state", "error", err)
+			}
+		}
 		return false, err
 	}
+	d.updateNotificationState(ctx, as, hasState, messageID)
 	return true, nil
 }
 
+// updateNotificationState records the id of the first message of the group, so that the following notifications
+// edit it. The edits end when the group is resolved.
+func (d Notifier) updateNotificationState(ctx context.Context, as []*types.Alert, hasState bool, messageID string) {
+	var err error
+	switch {
+	case types.Alerts(as...).Status() == model.AlertResolved:
+		if hasState {
+			err = d.DeleteNotificationState(ctx)
+		}
+	case !hasState && messageID != "":
+		err = d.SetNotificationState(ctx, receivers.NotificationState{stateMessageID: messageID})
+	}
+	if err != nil {
+		d.log.Warn("Failed to update the notification state", "error", err)
+	}
+}
+
+// webhookMessageURL returns the URL to edit the message of the webhook, or the URL to send a message
+// and get it in the response if messageID is empty.
+func webhookMessageURL(webhookURL, messageID string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse webhook URL: %w", err)
+	}
+	if messageID == "" {
+		q := u.Query()
+		q.Set("wait", "true")
+		u.RawQuery = q.Encode()
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/messages/" + messageID
+	}
+	return u.String(), nil
+}
+
 func (d Notifier) SendResolved() bool {
 	return !d.GetDisableResolveMessage()
 }
@@ -338,3 +431,29 @@ func (d Notifier) buildRequest(url string, body []byte, attachments []discordAtt
 	cmd.Body = b.String()
 	return cmd, nil
 }
+
+// actionComponents returns the action row with the buttons of the interactive actions, or nil if there are no actions.
+func (d Notifier) actionComponents(ctx context.Context, as []*types.Alert) ([]discordComponent, error) {
+	actions, err := receivers.NewActions(ctx, as...)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := receivers.EncodeActions(actions, discordMaxCustomIDLen)
+	if !ok {
+		d.log.Warn("Too many alerts to add interactive actions", "alerts", len(as))
+		return nil, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	row := discordComponent{Type: discordComponentActionRow}
+	for i, a := range actions {
+		row.Components = append(row.Components, discordComponent{
+			Type:     discordComponentButton,
+			Style:    discordButtonSecondary,
+			Label:    a.Label(),
+			CustomID: values[i],
+		})
+	}
+	return []discordComponent{row}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"mime"
@@ -628,3 +629,84 @@ func TestNotify_WithImages(t *testing.T) {
 		require.Equal(tt, expEmbeds, embeds)
 	})
 }
+
+func TestNotify_InteractiveActions(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	webhookSender := receivers.MockNotificationService()
+	dn := &Notifier{
+		Base:     &receivers.Base{},
+		log:      &logging.FakeLogger{},
+		ns:       webhookSender,
+		tmpl:     tmpl,
+		settings: Config{WebhookURL: "http://localhost", Title: templates.DefaultMessageTitleEmbed, Message: templates.DefaultMessageEmbed, InteractiveActions: true},
+		images:   &images.UnavailableProvider{},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+
+	ok, err := dn.Notify(ctx, alert)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var msg discordMessage
+	require.NoError(t, json.Unmarshal([]byte(webhookSender.Webhook.Body), &msg))
+	require.Len(t, msg.Components, 1)
+	row := msg.Components[0]
+	require.Equal(t, discordComponentActionRow, row.Type)
+	require.Len(t, row.Components, 2)
+	require.Equal(t, discordComponentButton, row.Components[1].Type)
+	require.Equal(t, "Acknowledge", row.Components[1].Label)
+	action, err := receivers.DecodeAction(row.Components[1].CustomID)
+	require.NoError(t, err)
+	require.Equal(t, receivers.ActionAcknowledge, action.Kind)
+	require.Equal(t, []string{alert.Fingerprint().String()}, action.Fingerprints)
+}
+
+func TestNotify_Edits(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	webhookSender := receivers.MockNotificationService()
+	webhookSender.ResponseBody = []byte(`{"id":"1234"}`)
+	dn := &Notifier{
+		Base:     &receivers.Base{UID: "discord-uid"},
+		log:      &logging.FakeLogger{},
+		ns:       webhookSender,
+		tmpl:     tmpl,
+		settings: Config{WebhookURL: "http://localhost/api/webhooks/1/token?thread_id=5", Title: templates.DefaultMessageTitleEmbed, Message: templates.DefaultMessageEmbed},
+		images:   &images.UnavailableProvider{},
+	}
+	store := receivers.NewFakeNotificationStateStore()
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = receivers.WithNotificationStateStore(ctx, store)
+	key := receivers.NotificationStateKey{GroupKey: "alertname", IntegrationUID: "discord-uid"}
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	_, err = dn.Notify(ctx, firing)
+	require.NoError(t, err)
+	require.Equal(t, "POST", webhookSender.Webhook.HTTPMethod)
+	require.Equal(t, "http://localhost/api/webhooks/1/token?thread_id=5&wait=true", webhookSender.Webhook.URL)
+	require.Equal(t, receivers.NotificationState{"id": "1234"}, store.States[key])
+
+	_, err = dn.Notify(ctx, resolved)
+	require.NoError(t, err)
+	require.Equal(t, "PATCH", webhookSender.Webhook.HTTPMethod)
+	require.Equal(t, "http://localhost/api/webhooks/1/token/messages/1234?thread_id=5", webhookSender.Webhook.URL)
+	require.NotContains(t, store.States, key)
+
+	t.Run("a failed edit starts a new message", func(t *testing.T) {
+		require.NoError(t, store.Set(key, receivers.NotificationState{"id": "1234"}))
+		webhookSender.ShouldError = errors.New("unknown message")
+		_, err = dn.Notify(ctx, firing)
+		require.Error(t, err)
+		require.NotContains(t, store.States, key)
+	})
+}
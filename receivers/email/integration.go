@@ -0,0 +1,28 @@
+package email
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "email",
+		Fields: []receivers.Field{
+			{Name: "addresses", Type: receivers.FieldTypeString, Required: true},
+			{Name: "singleEmail", Type: receivers.FieldTypeBool},
+			{Name: "subject", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.EmailSenderDependency,
+		NewConfig: func(settings json.RawMessage, _ receivers.DecryptFunc) (Config, error) {
+			return NewConfig(settings)
+		},
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.EmailSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
@@ -41,23 +41,37 @@ type defaultEmailSender struct {
 	tmpl *template.Template
 }
 
+// EmailRenderer renders emails into MIME messages without sending them.
+type EmailRenderer interface {
+	RenderEmail(ctx context.Context, cmd *SendEmailSettings) ([]byte, error)
+}
+
 // NewEmailSenderFactory takes a configuration and returns a new EmailSender factory function.
 func NewEmailSenderFactory(cfg EmailSenderConfig) func(Metadata) (EmailSender, error) {
 	return func(_ Metadata) (EmailSender, error) {
-		tmpl, err := template.New("templates").
-			Funcs(template.FuncMap{
-				"Subject":                 subjectTemplateFunc,
-				"__dangerouslyInjectHTML": __dangerouslyInjectHTML,
-			}).Funcs(sprig.FuncMap()).
-			ParseFS(defaultEmailTemplate, "templates/*")
-		if err != nil {
-			return nil, err
-		}
-		return &defaultEmailSender{
-			cfg:  cfg,
-			tmpl: tmpl,
-		}, nil
+		return newDefaultEmailSender(cfg)
+	}
+}
+
+// NewEmailRenderer takes a configuration and returns an EmailRenderer that builds emails the same way as the EmailSender.
+func NewEmailRenderer(cfg EmailSenderConfig) (EmailRenderer, error) {
+	return newDefaultEmailSender(cfg)
+}
+
+func newDefaultEmailSender(cfg EmailSenderConfig) (*defaultEmailSender, error) {
+	tmpl, err := template.New("templates").
+		Funcs(template.FuncMap{
+			"Subject":                 subjectTemplateFunc,
+			"__dangerouslyInjectHTML": __dangerouslyInjectHTML,
+		}).Funcs(sprig.FuncMap()).
+		ParseFS(defaultEmailTemplate, "templates/*")
+	if err != nil {
+		return nil, err
 	}
+	return &defaultEmailSender{
+		cfg:  cfg,
+		tmpl: tmpl,
+	}, nil
 }
 
 // Message representats an email message.
@@ -72,7 +86,11 @@ type Message struct {
 }
 
 // SendEmail implements the EmailSender interface.
-func (s *defaultEmailSender) SendEmail(_ context.Context, cmd *SendEmailSettings) error {
+func (s *defaultEmailSender) SendEmail(ctx context.Context, cmd *SendEmailSettings) error {
+	// The dialer does not support contexts, so at least do not send the email if the notification is canceled.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	message, err := s.buildEmailMessage(cmd)
 	if err != nil {
 		return err
@@ -82,6 +100,20 @@ func (s *defaultEmailSender) SendEmail(_ context.Context, cmd *SendEmailSettings
 	return err
 }
 
+// RenderEmail implements the EmailRenderer interface.
+func (s *defaultEmailSender) RenderEmail(_ context.Context, cmd *SendEmailSettings) ([]byte, error) {
+	message, err := s.buildEmailMessage(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.buildEmail(message).WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func (s *defaultEmailSender) buildEmailMessage(cmd *SendEmailSettings) (*Message, error) {
 	data := cmd.Data
 	if data == nil {
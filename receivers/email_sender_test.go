@@ -2,6 +2,7 @@ package receivers
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -225,3 +226,32 @@ func TestBuildEmail(t *testing.T) {
 	require.Contains(t, str, mCfg.Body["text/plain"])
 	require.Contains(t, str, mCfg.Body["text/html"])
 }
+
+func TestRenderEmail(t *testing.T) {
+	r, err := NewEmailRenderer(EmailSenderConfig{
+		ContentTypes: []string{"text/plain"},
+		FromAddress:  "from@grafana.com",
+		FromName:     "Grafana",
+		ExternalURL:  "http://test.org",
+	})
+	require.NoError(t, err)
+
+	ds, ok := r.(*defaultEmailSender)
+	require.True(t, ok)
+	_, err = ds.tmpl.Parse(fmt.Sprintf("{{ define %q -}} body {{ .AppUrl }} {{- end }}", "test_template.txt"))
+	require.NoError(t, err)
+
+	b, err := r.RenderEmail(context.Background(), &SendEmailSettings{
+		To:       []string{"to@grafana.com"},
+		Subject:  "test_subject",
+		Template: "test_template",
+	})
+	require.NoError(t, err)
+
+	msg := string(b)
+	require.Contains(t, msg, "From: \"Grafana\" <from@grafana.com>")
+	require.Contains(t, msg, "To: to@grafana.com")
+	require.Contains(t, msg, "Subject: test_subject")
+	require.Contains(t, msg, "Content-Type: text/plain; charset=UTF-8")
+	require.Contains(t, msg, "body http://test.org")
+}
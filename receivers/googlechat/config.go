@@ -13,6 +13,9 @@ type Config struct {
 	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
 	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
 	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// InteractiveActions adds buttons to silence and acknowledge the firing alerts.
+	// Google Chat only sends them to a Chat app.
+	InteractiveActions bool `json:"interactiveActions,omitempty" yaml:"interactiveActions,omitempty"`
 }
 
 func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
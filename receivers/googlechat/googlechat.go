@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/alerting/images"
 	"github.com/grafana/alerting/logging"
@@ -85,6 +88,16 @@ func (gcn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, erro
 		gcn.log.Warn("Grafana external URL setting is missing or invalid. Skipping 'open in grafana' button to prevent Google from displaying empty alerts.", "ruleURL", ruleURL)
 	}
 
+	if gcn.settings.InteractiveActions {
+		actions, err := gcn.actionsWidget(ctx, as)
+		if err != nil {
+			return false, err
+		}
+		if actions != nil {
+			widgets = append(widgets, *actions)
+		}
+	}
+
 	// Add text paragraph widget for the build version and timestamp.
 	widgets = append(widgets, textParagraphWidget{
 		Text: text{
@@ -126,6 +139,21 @@ func (gcn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, erro
 		return false, fmt.Errorf("marshal json: %w", err)
 	}
 
+	// Send the notifications of the group to the same thread.
+	state, hasState := gcn.GetNotificationState(ctx)
+	if _, ok := receivers.NotificationStateStoreFromContext(ctx); ok {
+		if !hasState {
+			key, err := notify.ExtractGroupKey(ctx)
+			if err != nil {
+				return false, err
+			}
+			state = receivers.NotificationState{stateThreadKey: fmt.Sprintf("%s-%d", key.Hash()[:16], timeNow().Unix())}
+		}
+		if u, err = threadURL(u, state[stateThreadKey]); err != nil {
+			return false, err
+		}
+	}
+
 	cmd := &receivers.SendWebhookSettings{
 		URL:        u,
 		HTTPMethod: "POST",
@@ -139,10 +167,41 @@ func (gcn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, erro
 		gcn.log.Error("Failed to send Google Hangouts Chat alert", "error", err, "webhook", gcn.Name)
 		return false, err
 	}
+	gcn.updateNotificationState(ctx, as, hasState, state)
 
 	return true, nil
 }
 
+// updateNotificationState records the thread key of the group, so that the following notifications are sent
+// to the same thread. The thread ends when the group is resolved.
+func (gcn *Notifier) updateNotificationState(ctx context.Context, as []*types.Alert, hasState bool, state receivers.NotificationState) {
+	var err error
+	switch {
+	case types.Alerts(as...).Status() == model.AlertResolved:
+		if hasState {
+			err = gcn.DeleteNotificationState(ctx)
+		}
+	case !hasState && state != nil:
+		err = gcn.SetNotificationState(ctx, state)
+	}
+	if err != nil {
+		gcn.log.Warn("Failed to update the notification state", "error", err)
+	}
+}
+
+// threadURL returns the URL of the webhook that replies in the thread with the key, or starts it.
+func threadURL(webhookURL, threadKey string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse webhook URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("threadKey", threadKey)
+	q.Set("messageReplyOption", "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 func (gcn *Notifier) SendResolved() bool {
 	return !gcn.GetDisableResolveMessage()
 }
@@ -190,6 +249,46 @@ func (gcn *Notifier) buildScreenshotCard(ctx context.Context, alerts []*types.Al
 	return &card
 }
 
+// stateThreadKey is the key of the thread key of the group in the notification state.
+const stateThreadKey = "threadKey"
+
+// ActionParameterKey is the key of the parameter with the encoded action of the buttons of the interactive actions.
+const ActionParameterKey = "action"
+
+// googleChatMaxActionLen keeps the parameters of the actions, which are sent back on every click, small.
+const googleChatMaxActionLen = 2000
+
+// actionsWidget returns the widget with the buttons of the interactive actions, or nil if there are no actions.
+func (gcn *Notifier) actionsWidget(ctx context.Context, as []*types.Alert) (*buttonWidget, error) {
+	actions, err := receivers.NewActions(ctx, as...)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := receivers.EncodeActions(actions, googleChatMaxActionLen)
+	if !ok {
+		gcn.log.Warn("Too many alerts to add interactive actions", "alerts", len(as))
+		return nil, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	w := buttonWidget{}
+	for i, a := range actions {
+		w.Buttons = append(w.Buttons, button{
+			TextButton: textButton{
+				Text: strings.ToUpper(a.Label()),
+				OnClick: onClick{
+					Action: &formAction{
+						ActionMethodName: string(a.Kind),
+						Parameters:       []actionParameter{{Key: ActionParameterKey, Value: values[i]}},
+					},
+				},
+			},
+		})
+	}
+	return &w, nil
+}
+
 // Structs used to build a custom Google Hangouts Chat message card.
 // https://developers.google.com/chat/api/guides/message-formats/cards
 type outerStruct struct {
@@ -244,7 +343,31 @@ type textButton struct {
 }
 
 type onClick struct {
-	OpenLink openLink `json:"openLink"`
+	OpenLink openLink    `json:"openLink"`
+	Action   *formAction `json:"action,omitempty"`
+}
+
+// MarshalJSON omits the link of the buttons of the interactive actions.
+func (o onClick) MarshalJSON() ([]byte, error) {
+	if o.Action != nil {
+		return json.Marshal(struct {
+			Action *formAction `json:"action"`
+		}{Action: o.Action})
+	}
+	return json.Marshal(struct {
+		OpenLink openLink `json:"openLink"`
+	}{OpenLink: o.OpenLink})
+}
+
+// formAction is sent to the Chat app when the button is clicked.
+type formAction struct {
+	ActionMethodName string            `json:"actionMethodName"`
+	Parameters       []actionParameter `json:"parameters,omitempty"`
+}
+
+type actionParameter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 type openLink struct {
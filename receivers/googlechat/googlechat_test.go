@@ -545,3 +545,94 @@ func mockTimeNow(constTime time.Time) func() {
 	}
 	return resetTimeNow
 }
+
+func TestNotify_InteractiveActions(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	webhookSender := receivers.MockNotificationService()
+	pn := &Notifier{
+		Base:     &receivers.Base{},
+		log:      &logging.FakeLogger{},
+		ns:       webhookSender,
+		tmpl:     tmpl,
+		settings: Config{URL: "http://localhost", Title: templates.DefaultMessageTitleEmbed, Message: templates.DefaultMessageEmbed, InteractiveActions: true},
+		images:   &images.UnavailableProvider{},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+
+	ok, err := pn.Notify(ctx, alert)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var msg struct {
+		Cards []struct {
+			Sections []struct {
+				Widgets []map[string]json.RawMessage `json:"widgets"`
+			} `json:"sections"`
+		} `json:"cards"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(webhookSender.Webhook.Body), &msg))
+	widgets := msg.Cards[0].Sections[0].Widgets
+	require.Len(t, widgets, 4)
+	require.JSONEq(t, `[{"textButton":{"text":"OPEN IN GRAFANA","onClick":{"openLink":{"url":"http://localhost/alerting/list"}}}}]`, string(widgets[1]["buttons"]))
+
+	var buttons []button
+	require.NoError(t, json.Unmarshal(widgets[2]["buttons"], &buttons))
+	require.Len(t, buttons, 2)
+	require.Equal(t, "SILENCE", buttons[0].TextButton.Text)
+	require.Equal(t, string(receivers.ActionSilence), buttons[0].TextButton.OnClick.Action.ActionMethodName)
+	params := buttons[0].TextButton.OnClick.Action.Parameters
+	require.Len(t, params, 1)
+	require.Equal(t, ActionParameterKey, params[0].Key)
+	action, err := receivers.DecodeAction(params[0].Value)
+	require.NoError(t, err)
+	require.Equal(t, []string{alert.Fingerprint().String()}, action.Fingerprints)
+	require.NotContains(t, string(widgets[2]["buttons"]), "openLink")
+}
+
+func TestNotify_Threads(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	webhookSender := receivers.MockNotificationService()
+	pn := &Notifier{
+		Base:     &receivers.Base{UID: "googlechat-uid"},
+		log:      &logging.FakeLogger{},
+		ns:       webhookSender,
+		tmpl:     tmpl,
+		settings: Config{URL: "http://localhost/v1/spaces/space/messages?key=k", Title: templates.DefaultMessageTitleEmbed, Message: templates.DefaultMessageEmbed},
+		images:   &images.UnavailableProvider{},
+	}
+	store := receivers.NewFakeNotificationStateStore()
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = receivers.WithNotificationStateStore(ctx, store)
+	key := receivers.NotificationStateKey{GroupKey: "alertname", IntegrationUID: "googlechat-uid"}
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	threadKey := func() string {
+		u, err := url.Parse(webhookSender.Webhook.URL)
+		require.NoError(t, err)
+		require.Equal(t, "k", u.Query().Get("key"))
+		require.Equal(t, "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD", u.Query().Get("messageReplyOption"))
+		return u.Query().Get("threadKey")
+	}
+
+	_, err = pn.Notify(ctx, firing)
+	require.NoError(t, err)
+	first := threadKey()
+	require.NotEmpty(t, first)
+	require.Equal(t, receivers.NotificationState{"threadKey": first}, store.States[key])
+
+	_, err = pn.Notify(ctx, resolved)
+	require.NoError(t, err)
+	require.Equal(t, first, threadKey())
+	require.NotContains(t, store.States, key)
+}
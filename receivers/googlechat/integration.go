@@ -0,0 +1,24 @@
+package googlechat
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "googlechat",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true, Secure: true, Templated: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "interactiveActions", Type: receivers.FieldTypeBool},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger, deps.Version)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
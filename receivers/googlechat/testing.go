@@ -4,9 +4,7 @@ package googlechat
 const FullValidConfigForTesting = `{
 	"url": "http://localhost", 
 	"title": "test-title", 
-	"message": "test-message", 
-	"avatar_url" : "http://avatar", 
-	"use_discord_username": true
+	"message": "test-message"
 }`
 
 // FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets.
@@ -0,0 +1,28 @@
+package kafka
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "kafka",
+		Fields: []receivers.Field{
+			{Name: "kafkaRestProxy", Type: receivers.FieldTypeString, Required: true},
+			{Name: "kafkaTopic", Type: receivers.FieldTypeString, Required: true, Templated: true},
+			{Name: "description", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "details", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "username", Type: receivers.FieldTypeString},
+			{Name: "password", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "apiVersion", Type: receivers.FieldTypeString, Default: apiVersionV2, Enum: []any{apiVersionV2, apiVersionV3}},
+			{Name: "kafkaClusterId", Type: receivers.FieldTypeString, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
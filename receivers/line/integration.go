@@ -0,0 +1,23 @@
+package line
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "line",
+		Fields: []receivers.Field{
+			{Name: "token", Type: receivers.FieldTypeString, Required: true, Secure: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "description", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
@@ -0,0 +1,29 @@
+package mqtt
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "mqtt",
+		Fields: []receivers.Field{
+			{Name: "brokerUrl", Type: receivers.FieldTypeString, Required: true},
+			{Name: "clientId", Type: receivers.FieldTypeString},
+			{Name: "topic", Type: receivers.FieldTypeString, Required: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "messageFormat", Type: receivers.FieldTypeString, Default: MessageFormatJSON, Enum: []any{MessageFormatJSON, MessageFormatText}},
+			{Name: "username", Type: receivers.FieldTypeString},
+			{Name: "password", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "qos", Type: receivers.FieldTypeInteger, Enum: []any{0, 1, 2}},
+			{Name: "retain", Type: receivers.FieldTypeBool},
+			{Name: "tlsConfig", Type: receivers.FieldTypeObject, Fields: receivers.TLSConfigFields},
+		},
+		Dependencies: receivers.TemplateDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.Logger, nil)
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package receivers
+
+import (
+	"context"
+
+	"github.com/prometheus/alertmanager/notify"
+)
+
+// NotificationState holds the identifiers of the message sent by an integration for an aggregation group,
+// such as the ts of a Slack message or the message_id of a Telegram message. Notifiers use it to reply
+// in thread to, or to edit, the message of the group in the following notifications.
+type NotificationState map[string]string
+
+// NotificationStateKey identifies the notification state of an integration for an aggregation group.
+type NotificationStateKey struct {
+	GroupKey       string
+	IntegrationUID string
+}
+
+// NotificationStateStore stores the notification states. Implementations must be safe for concurrent use.
+type NotificationStateStore interface {
+	Get(key NotificationStateKey) (NotificationState, bool)
+	Set(key NotificationStateKey, state NotificationState) error
+	Delete(key NotificationStateKey) error
+}
+
+type contextKey int
+
+const notificationStateStoreKey contextKey = iota
+
+// WithNotificationStateStore returns a context that carries the store of the notification states.
+func WithNotificationStateStore(ctx context.Context, s NotificationStateStore) context.Context {
+	return context.WithValue(ctx, notificationStateStoreKey, s)
+}
+
+// NotificationStateStoreFromContext returns the store of the notification states of the context, if any.
+func NotificationStateStoreFromContext(ctx context.Context) (NotificationStateStore, bool) {
+	s, ok := ctx.Value(notificationStateStoreKey).(NotificationStateStore)
+	return s, ok
+}
+
+// notificationStateKey returns the key of the notification state of the notifier for the group of the context.
+func (n *Base) notificationStateKey(ctx context.Context) (NotificationStateStore, NotificationStateKey, bool) {
+	s, ok := NotificationStateStoreFromContext(ctx)
+	if !ok {
+		return nil, NotificationStateKey{}, false
+	}
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, NotificationStateKey{}, false
+	}
+	return s, NotificationStateKey{GroupKey: string(key), IntegrationUID: n.UID}, true
+}
+
+// GetNotificationState returns the notification state of the notifier for the group of the context.
+// It returns false if there is no state, or if the context has no store.
+func (n *Base) GetNotificationState(ctx context.Context) (NotificationState, bool) {
+	s, key, ok := n.notificationStateKey(ctx)
+	if !ok {
+		return nil, false
+	}
+	return s.Get(key)
+}
+
+// SetNotificationState sets the notification state of the notifier for the group of the context.
+// It does nothing if the context has no store.
+func (n *Base) SetNotificationState(ctx context.Context, state NotificationState) error {
+	s, key, ok := n.notificationStateKey(ctx)
+	if !ok {
+		return nil
+	}
+	return s.Set(key, state)
+}
+
+// DeleteNotificationState deletes the notification state of the notifier for the group of the context,
+// so that the next notification of the group starts a new message. It does nothing if the context has no store.
+func (n *Base) DeleteNotificationState(ctx context.Context) error {
+	s, key, ok := n.notificationStateKey(ctx)
+	if !ok {
+		return nil
+	}
+	return s.Delete(key)
+}
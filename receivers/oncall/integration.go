@@ -0,0 +1,31 @@
+package oncall
+
+import (
+	"net/http"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "oncall",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true, Templated: true},
+			{Name: "httpMethod", Type: receivers.FieldTypeString, Default: http.MethodPost},
+			{Name: "maxAlerts", Type: receivers.FieldTypeInteger},
+			{Name: "authorization_scheme", Type: receivers.FieldTypeString},
+			{Name: "authorization_credentials", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "username", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "password", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger, deps.OrgID)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
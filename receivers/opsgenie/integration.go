@@ -0,0 +1,33 @@
+package opsgenie
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "opsgenie",
+		Fields: []receivers.Field{
+			{Name: "apiKey", Type: receivers.FieldTypeString, Required: true, Secure: true},
+			{Name: "apiUrl", Type: receivers.FieldTypeString, Default: DefaultAlertsURL, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "description", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "autoClose", Type: receivers.FieldTypeBool, Default: true},
+			{Name: "overridePriority", Type: receivers.FieldTypeBool, Default: true},
+			{Name: "sendTagsAs", Type: receivers.FieldTypeString, Default: SendTags, Enum: []any{SendTags, SendDetails, SendBoth}},
+			{Name: "responders", Type: receivers.FieldTypeArray, Fields: []receivers.Field{
+				{Name: "type", Type: receivers.FieldTypeString, Required: true, Templated: true},
+				{Name: "id", Type: receivers.FieldTypeString, Templated: true},
+				{Name: "name", Type: receivers.FieldTypeString, Templated: true},
+				{Name: "username", Type: receivers.FieldTypeString, Templated: true},
+			}},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
@@ -0,0 +1,31 @@
+package pagerduty
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "pagerduty",
+		Fields: []receivers.Field{
+			{Name: "integrationKey", Type: receivers.FieldTypeString, Required: true, Secure: true},
+			{Name: "severity", Type: receivers.FieldTypeString, Default: DefaultSeverity, Templated: true},
+			{Name: "details", Type: receivers.FieldTypeMap, Templated: true},
+			{Name: "class", Type: receivers.FieldTypeString, Default: DefaultClass, Templated: true},
+			{Name: "component", Type: receivers.FieldTypeString, Default: "Grafana", Templated: true},
+			{Name: "group", Type: receivers.FieldTypeString, Default: DefaultGroup, Templated: true},
+			{Name: "summary", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "source", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "client", Type: receivers.FieldTypeString, Default: DefaultClient, Templated: true},
+			{Name: "client_url", Type: receivers.FieldTypeString, Default: "{{ .ExternalURL }}", Templated: true},
+			{Name: "url", Type: receivers.FieldTypeString, Default: DefaultURL},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
@@ -0,0 +1,32 @@
+package pushover
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "pushover",
+		Fields: []receivers.Field{
+			{Name: "userKey", Type: receivers.FieldTypeString, Required: true, Secure: true, Templated: true},
+			{Name: "apiToken", Type: receivers.FieldTypeString, Required: true, Secure: true},
+			{Name: "priority", Type: receivers.FieldTypeInteger},
+			{Name: "okPriority", Type: receivers.FieldTypeInteger},
+			{Name: "retry", Type: receivers.FieldTypeInteger},
+			{Name: "expire", Type: receivers.FieldTypeInteger},
+			{Name: "device", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "sound", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "okSound", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "uploadImage", Type: receivers.FieldTypeBool, Default: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
@@ -0,0 +1,160 @@
+package receivers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/alertmanager/notify"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/templates"
+)
+
+// Dependency is a dependency that the notifiers of an integration are created with.
+type Dependency uint8
+
+const (
+	// TemplateDependency is the template of the notifications.
+	TemplateDependency Dependency = 1 << iota
+	// ImagesDependency is the provider of the images of the alerts.
+	ImagesDependency
+	// WebhookSenderDependency is the sender of the webhooks.
+	WebhookSenderDependency
+	// EmailSenderDependency is the sender of the emails.
+	EmailSenderDependency
+)
+
+// Has returns true if the dependencies include all the dependencies of d.
+func (deps Dependency) Has(d Dependency) bool {
+	return deps&d == d
+}
+
+// NotifierDependencies are the dependencies of a notifier. Only the dependencies of the integration are set,
+// the others are nil.
+type NotifierDependencies struct {
+	Template      *templates.Template
+	Images        images.Provider
+	WebhookSender WebhookSender
+	EmailSender   EmailSender
+	Logger        logging.Logger
+	OrgID         int64
+	Version       string
+}
+
+// Notifier is the notifier of an integration.
+type Notifier interface {
+	notify.Notifier
+	notify.ResolvedSender
+}
+
+// IntegrationType describes a type of integration with settings of type T.
+type IntegrationType[T any] struct {
+	// Name is the type of the integration, in the Type of the configuration of the integrations. It is case-insensitive.
+	Name string
+	// Fields describe the settings of the integration.
+	Fields []Field
+	// Dependencies are the dependencies that the notifiers are created with.
+	Dependencies Dependency
+	// NewConfig parses and validates the settings, decrypting the secure settings with decryptFn.
+	NewConfig func(settings json.RawMessage, decryptFn DecryptFunc) (T, error)
+	// NewNotifier creates a notifier with the settings returned by NewConfig.
+	NewNotifier func(settings T, meta Metadata, deps NotifierDependencies) Notifier
+	// Previewable returns true if the notifiers with the settings send all their notifications through the
+	// WebhookSender or the EmailSender of their dependencies, so that they can be previewed by capturing them instead.
+	// The integrations cannot be previewed if it is nil, as previewing them would send the notifications.
+	Previewable func(settings T) bool
+}
+
+// AlwaysPreviewable is the Previewable of the integrations whose notifiers always send their notifications through
+// the WebhookSender or the EmailSender of their dependencies.
+func AlwaysPreviewable[T any](T) bool {
+	return true
+}
+
+// RegisteredIntegration is an IntegrationType whose settings are of any type.
+type RegisteredIntegration struct {
+	Name   string
+	Fields []Field
+	// SecureFields are the paths of the settings that can be stored in the secure settings.
+	SecureFields []string
+	Dependencies Dependency
+	NewConfig    func(settings json.RawMessage, decryptFn DecryptFunc) (any, error)
+	NewNotifier  func(settings any, meta Metadata, deps NotifierDependencies) Notifier
+	// Previewable is nil if the integrations cannot be previewed, see IntegrationType.
+	Previewable func(settings any) bool
+}
+
+var (
+	registryMtx sync.RWMutex
+	registry    = map[string]RegisteredIntegration{}
+)
+
+// RegisterIntegration registers the type of integration, so that the integrations of this type can be parsed and built.
+// The integrations of this package register themselves when they are imported. It panics if the name is empty
+// or already registered, or if NewConfig or NewNotifier is nil.
+func RegisterIntegration[T any](t IntegrationType[T]) {
+	name := strings.ToLower(t.Name)
+	if name == "" {
+		panic("integration name must not be empty")
+	}
+	if t.NewConfig == nil || t.NewNotifier == nil {
+		panic(fmt.Sprintf("integration %s must have NewConfig and NewNotifier", name))
+	}
+
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("integration %s is already registered", name))
+	}
+	var previewable func(settings any) bool
+	if t.Previewable != nil {
+		previewable = func(settings any) bool {
+			return t.Previewable(settings.(T))
+		}
+	}
+	registry[name] = RegisteredIntegration{
+		Name:         name,
+		Fields:       t.Fields,
+		SecureFields: secureFields("", t.Fields),
+		Dependencies: t.Dependencies,
+		NewConfig: func(settings json.RawMessage, decryptFn DecryptFunc) (any, error) {
+			return t.NewConfig(settings, decryptFn)
+		},
+		NewNotifier: func(settings any, meta Metadata, deps NotifierDependencies) Notifier {
+			return t.NewNotifier(settings.(T), meta, deps)
+		},
+		Previewable: previewable,
+	}
+}
+
+// UnregisterIntegration removes the registered type of integration with the name, which is case-insensitive, so that
+// it can be registered again. It is meant for tests that register their own types of integrations.
+func UnregisterIntegration(name string) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	delete(registry, strings.ToLower(name))
+}
+
+// GetIntegration returns the registered type of integration with the name, which is case-insensitive.
+func GetIntegration(name string) (RegisteredIntegration, bool) {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	t, ok := registry[strings.ToLower(name)]
+	return t, ok
+}
+
+// Integrations returns the registered types of integrations sorted by name.
+func Integrations() []RegisteredIntegration {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	res := make([]RegisteredIntegration, 0, len(registry))
+	for _, t := range registry {
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
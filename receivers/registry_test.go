@@ -0,0 +1,81 @@
+package receivers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testSettings struct {
+	URL string `json:"url"`
+}
+
+type testNotifier struct {
+	settings testSettings
+	deps     NotifierDependencies
+}
+
+func (n *testNotifier) Notify(context.Context, ...*types.Alert) (bool, error) { return false, nil }
+func (n *testNotifier) SendResolved() bool                                    { return true }
+
+func TestRegisterIntegration(t *testing.T) {
+	RegisterIntegration(IntegrationType[testSettings]{
+		Name: "Registry-Test",
+		Fields: []Field{
+			{Name: "url", Type: FieldTypeString, Required: true, Secure: true},
+			{Name: "tls", Type: FieldTypeObject, Fields: TLSConfigFields},
+		},
+		Dependencies: WebhookSenderDependency,
+		NewConfig: func(settings json.RawMessage, decryptFn DecryptFunc) (testSettings, error) {
+			var s testSettings
+			if err := json.Unmarshal(settings, &s); err != nil {
+				return s, err
+			}
+			s.URL = decryptFn("url", s.URL)
+			return s, nil
+		},
+		NewNotifier: func(settings testSettings, _ Metadata, deps NotifierDependencies) Notifier {
+			return &testNotifier{settings: settings, deps: deps}
+		},
+	})
+	t.Cleanup(func() { UnregisterIntegration("registry-test") })
+
+	it, ok := GetIntegration("REGISTRY-test")
+	require.True(t, ok)
+	require.Equal(t, "registry-test", it.Name)
+	require.Equal(t, []string{"url", "tls.caCertificate", "tls.clientCertificate", "tls.clientKey"}, it.SecureFields)
+	require.True(t, it.Dependencies.Has(WebhookSenderDependency))
+	require.False(t, it.Dependencies.Has(WebhookSenderDependency|EmailSenderDependency))
+	// The integrations are not previewable unless they declare it.
+	require.Nil(t, it.Previewable)
+	var names []string
+	for _, it := range Integrations() {
+		names = append(names, it.Name)
+	}
+	require.Contains(t, names, "registry-test")
+
+	settings, err := it.NewConfig(json.RawMessage(`{"url": "plain"}`), func(key, fallback string) string {
+		return fallback + "-decrypted"
+	})
+	require.NoError(t, err)
+	require.Equal(t, testSettings{URL: "plain-decrypted"}, settings)
+	n := it.NewNotifier(settings, Metadata{}, NotifierDependencies{OrgID: 1})
+	require.Equal(t, &testNotifier{settings: testSettings{URL: "plain-decrypted"}, deps: NotifierDependencies{OrgID: 1}}, n)
+
+	_, ok = GetIntegration("unknown")
+	require.False(t, ok)
+
+	require.Panics(t, func() {
+		RegisterIntegration(IntegrationType[testSettings]{
+			Name:        "registry-test",
+			NewConfig:   func(json.RawMessage, DecryptFunc) (testSettings, error) { return testSettings{}, nil },
+			NewNotifier: func(testSettings, Metadata, NotifierDependencies) Notifier { return nil },
+		})
+	})
+	require.Panics(t, func() {
+		RegisterIntegration(IntegrationType[testSettings]{Name: "incomplete"})
+	})
+}
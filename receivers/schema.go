@@ -0,0 +1,125 @@
+package receivers
+
+import "fmt"
+
+// JSONSchemaDraft is the version of JSON Schema of the schemas of the integrations.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// FieldType is the type of the value of a setting.
+type FieldType string
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeBool   FieldType = "boolean"
+	// FieldTypeInteger is an integer. For compatibility, the integer can also be a string.
+	FieldTypeInteger FieldType = "integer"
+	// FieldTypeObject is an object whose settings are described by Fields.
+	FieldTypeObject FieldType = "object"
+	// FieldTypeArray is an array of objects whose settings are described by Fields.
+	FieldTypeArray FieldType = "array"
+	// FieldTypeMap is an object with any keys and string values.
+	FieldTypeMap FieldType = "map"
+)
+
+// Field describes a setting of an integration.
+type Field struct {
+	// Name is the key of the setting in the settings, and in the secure settings if Secure is true.
+	Name string    `json:"name"`
+	Type FieldType `json:"type"`
+	// Required is true if the integration cannot be created without the setting.
+	Required bool `json:"required,omitempty"`
+	// Default is the value that is used when the setting is not set.
+	Default any `json:"default,omitempty"`
+	// Secure is true if the setting can be stored in the secure settings.
+	Secure bool `json:"secure,omitempty"`
+	// Templated is true if the setting is executed as a template when the notifications are sent.
+	Templated bool `json:"templated,omitempty"`
+	// Enum are the allowed values of the setting.
+	Enum []any `json:"enum,omitempty"`
+	// Fields are the settings of the objects of a FieldTypeObject or FieldTypeArray.
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// JSONSchema is a JSON Schema that describes the settings of an integration.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+	// Templated is true if the value is executed as a template when the notifications are sent.
+	Templated bool `json:"x-templated,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of the settings of the integration. The secure fields are not required by
+// the schema because they can be set in the secure settings instead.
+func (it RegisteredIntegration) JSONSchema() *JSONSchema {
+	s := objectSchema(it.Fields)
+	s.Schema = JSONSchemaDraft
+	s.Title = it.Name
+	return s
+}
+
+func objectSchema(fields []Field) *JSONSchema {
+	s := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema, len(fields))}
+	for _, f := range fields {
+		s.Properties[f.Name] = fieldSchema(f)
+		if f.Required && !f.Secure {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
+
+func fieldSchema(f Field) *JSONSchema {
+	var s *JSONSchema
+	switch f.Type {
+	case FieldTypeObject:
+		s = objectSchema(f.Fields)
+	case FieldTypeArray:
+		s = &JSONSchema{Type: "array", Items: objectSchema(f.Fields)}
+	case FieldTypeMap:
+		s = &JSONSchema{Type: "object", AdditionalProperties: &JSONSchema{Type: "string"}}
+	case FieldTypeInteger:
+		s = &JSONSchema{Type: []string{"integer", "string"}, Pattern: "^-?[0-9]*$"}
+		// The values can also be strings.
+		for _, v := range f.Enum {
+			s.Enum = append(s.Enum, v, fmt.Sprint(v))
+		}
+	default:
+		s = &JSONSchema{Type: string(f.Type), Enum: f.Enum}
+	}
+	s.Default = f.Default
+	s.WriteOnly = f.Secure
+	s.Templated = f.Templated
+	return s
+}
+
+// secureFields returns the paths of the secure fields, the names of the fields of objects are prefixed with the
+// name of the object and a dot.
+func secureFields(prefix string, fields []Field) []string {
+	var res []string
+	for _, f := range fields {
+		if f.Secure {
+			res = append(res, prefix+f.Name)
+		}
+		if f.Type == FieldTypeObject {
+			res = append(res, secureFields(prefix+f.Name+".", f.Fields)...)
+		}
+	}
+	return res
+}
+
+// TLSConfigFields are the fields of a TLSConfig.
+var TLSConfigFields = []Field{
+	{Name: "insecureSkipVerify", Type: FieldTypeBool},
+	{Name: "caCertificate", Type: FieldTypeString, Secure: true},
+	{Name: "clientCertificate", Type: FieldTypeString, Secure: true},
+	{Name: "clientKey", Type: FieldTypeString, Secure: true},
+}
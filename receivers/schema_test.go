@@ -0,0 +1,49 @@
+package receivers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	it := RegisteredIntegration{
+		Name: "test",
+		Fields: []Field{
+			{Name: "url", Type: FieldTypeString, Required: true, Secure: true, Templated: true},
+			{Name: "topic", Type: FieldTypeString, Required: true},
+			{Name: "format", Type: FieldTypeString, Default: "json", Enum: []any{"json", "text"}},
+			{Name: "qos", Type: FieldTypeInteger, Enum: []any{0, 1}},
+			{Name: "retain", Type: FieldTypeBool, Default: true},
+			{Name: "labels", Type: FieldTypeMap, Templated: true},
+			{Name: "tls", Type: FieldTypeObject, Fields: []Field{
+				{Name: "key", Type: FieldTypeString, Secure: true},
+			}},
+			{Name: "responders", Type: FieldTypeArray, Fields: []Field{
+				{Name: "id", Type: FieldTypeString, Required: true},
+			}},
+		},
+	}
+
+	b, err := json.Marshal(it.JSONSchema())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "test",
+		"type": "object",
+		"required": ["topic"],
+		"properties": {
+			"url": {"type": "string", "writeOnly": true, "x-templated": true},
+			"topic": {"type": "string"},
+			"format": {"type": "string", "default": "json", "enum": ["json", "text"]},
+			"qos": {"type": ["integer", "string"], "pattern": "^-?[0-9]*$", "enum": [0, "0", 1, "1"]},
+			"retain": {"type": "boolean", "default": true},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}, "x-templated": true},
+			"tls": {"type": "object", "properties": {"key": {"type": "string", "writeOnly": true}}},
+			"responders": {"type": "array", "items": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}}}
+		}
+	}`, string(b))
+
+	require.Equal(t, []string{"url", "tls.key"}, secureFields("", it.Fields))
+}
@@ -0,0 +1,27 @@
+package sensugo
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "sensugo",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true},
+			{Name: "apikey", Type: receivers.FieldTypeString, Required: true, Secure: true},
+			{Name: "entity", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "check", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "namespace", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "handler", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
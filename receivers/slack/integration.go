@@ -0,0 +1,34 @@
+package slack
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "slack",
+		Fields: []receivers.Field{
+			{Name: "endpointUrl", Type: receivers.FieldTypeString, Default: APIURL},
+			{Name: "url", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "token", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "recipient", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "text", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "username", Type: receivers.FieldTypeString, Default: "Grafana", Templated: true},
+			{Name: "icon_emoji", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "icon_url", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "mentionChannel", Type: receivers.FieldTypeString, Enum: []any{"", "here", "channel"}},
+			{Name: "mentionUsers", Type: receivers.FieldTypeString},
+			{Name: "mentionGroups", Type: receivers.FieldTypeString},
+			{Name: "interactiveActions", Type: receivers.FieldTypeBool},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger, deps.Version)
+		},
+		// The messages are sent through the WebhookSender when it captures them, see New.
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
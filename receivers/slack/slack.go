@@ -21,6 +21,7 @@ import (
 	"github.com/prometheus/alertmanager/notify"
 
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/alerting/images"
 	"github.com/grafana/alerting/logging"
@@ -67,6 +68,15 @@ type completeFileUploadFunc func(ctx context.Context, req *http.Request, logger
 // https://api.slack.com/reference/messaging/attachments#legacy_fields - 1024, no units given, assuming runes or characters.
 const slackMaxTitleLenRunes = 1024
 
+// https://api.slack.com/reference/block-kit/block-elements#button - the value of a button is at most 2000 characters.
+const slackMaxActionValueLen = 2000
+
+// stateThreadTs is the key of the ts of the first message of the group in the notification state.
+const stateThreadTs = "ts"
+
+// ActionsBlockID is the ID of the block that contains the buttons of the interactive actions.
+const ActionsBlockID = "grafana_actions"
+
 // Notifier is responsible for sending
 // alert notification to Slack.
 type Notifier struct {
@@ -100,7 +110,7 @@ func endpointURL(s Config, apiMethod string) (string, error) {
 }
 
 func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger, appVersion string) *Notifier {
-	return &Notifier{
+	n := &Notifier{
 		Base:                 receivers.NewBase(meta),
 		settings:             cfg,
 		images:               images,
@@ -113,6 +123,34 @@ func New(cfg Config, meta receivers.Metadata, template *templates.Template, send
 		tmpl:                 template,
 		appVersion:           appVersion,
 	}
+	if _, ok := sender.(receivers.CapturingWebhookSender); ok {
+		// The messages are captured instead of sent, such as when they are previewed. The images are not uploaded,
+		// as the upload requests depend on the responses of Slack.
+		n.sendMessageFn = n.captureMessage
+		n.initFileUploadFn = func(context.Context, *http.Request, logging.Logger) (*FileUploadURLResponse, error) {
+			return nil, errors.New("images are not uploaded when the messages are captured")
+		}
+	}
+	return n
+}
+
+// captureMessage sends the request of the message through the WebhookSender, which captures it.
+func (sn *Notifier) captureMessage(ctx context.Context, req *http.Request, _ logging.Logger) (string, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	headers := make(map[string]string, len(req.Header))
+	for k := range req.Header {
+		headers[k] = req.Header.Get(k)
+	}
+	return "", sn.webhookSender.SendWebhook(ctx, &receivers.SendWebhookSettings{
+		URL:         req.URL.String(),
+		HTTPMethod:  req.Method,
+		HTTPHeader:  headers,
+		ContentType: req.Header.Get("Content-Type"),
+		Body:        string(body),
+	})
 }
 
 // slackMessage is the slackMessage for sending a slack notification.
@@ -180,11 +218,21 @@ func (sn *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, e
 		return false, fmt.Errorf("failed to create Slack message: %w", err)
 	}
 
+	// Reply in the thread of the first message of the group, if any.
+	state, hasState := sn.GetNotificationState(ctx)
+	if hasState {
+		m.ThreadTs = state[stateThreadTs]
+	}
+
 	threadTs, err := sn.sendSlackMessage(ctx, m)
 	if err != nil {
 		sn.log.Error("Failed to send Slack message", "err", err)
 		return false, fmt.Errorf("failed to send Slack message: %w", err)
 	}
+	if m.ThreadTs != "" {
+		threadTs = m.ThreadTs
+	}
+	sn.updateNotificationState(ctx, alerts, hasState, threadTs)
 
 	// Do not upload images if using an incoming webhook as incoming webhooks cannot upload files
 	if !isIncomingWebhook(sn.settings) {
@@ -212,6 +260,23 @@ func (sn *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, e
 	return true, nil
 }
 
+// updateNotificationState records the ts of the first message of the group, so that the following notifications
+// are replies in its thread. The thread ends when the group is resolved.
+func (sn *Notifier) updateNotificationState(ctx context.Context, alerts []*types.Alert, hasState bool, threadTs string) {
+	var err error
+	switch {
+	case types.Alerts(alerts...).Status() == model.AlertResolved:
+		if hasState {
+			err = sn.DeleteNotificationState(ctx)
+		}
+	case !hasState && threadTs != "":
+		err = sn.SetNotificationState(ctx, receivers.NotificationState{stateThreadTs: threadTs})
+	}
+	if err != nil {
+		sn.log.Warn("Failed to update the notification state", "err", err)
+	}
+}
+
 func (sn *Notifier) commonAlertGeneratorURL(_ context.Context, alerts []*types.Alert) bool {
 	if len(alerts[0].GeneratorURL) == 0 {
 		return false
@@ -318,9 +383,49 @@ func (sn *Notifier) createSlackMessage(ctx context.Context, alerts []*types.Aler
 		req.Attachments[0].Pretext = mentionsBuilder.String()
 	}
 
+	if sn.settings.InteractiveActions {
+		block, err := sn.actionsBlock(ctx, alerts)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			req.Blocks = append(req.Blocks, block)
+		}
+	}
+
 	return req, nil
 }
 
+// actionsBlock returns the block with the buttons of the interactive actions, or nil if there are no actions.
+func (sn *Notifier) actionsBlock(ctx context.Context, alerts []*types.Alert) (map[string]interface{}, error) {
+	actions, err := receivers.NewActions(ctx, alerts...)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := receivers.EncodeActions(actions, slackMaxActionValueLen)
+	if !ok {
+		sn.log.Warn("Too many alerts to add interactive actions", "alerts", len(alerts))
+		return nil, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	elements := make([]map[string]interface{}, 0, len(actions))
+	for i, a := range actions {
+		elements = append(elements, map[string]interface{}{
+			"type":      "button",
+			"action_id": string(a.Kind),
+			"text":      map[string]interface{}{"type": "plain_text", "text": a.Label()},
+			"value":     values[i],
+		})
+	}
+	return map[string]interface{}{
+		"type":     "actions",
+		"block_id": ActionsBlockID,
+		"elements": elements,
+	}, nil
+}
+
 func (sn *Notifier) sendSlackMessage(ctx context.Context, m *slackMessage) (string, error) {
 	b, err := json.Marshal(m)
 	if err != nil {
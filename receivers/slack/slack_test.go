@@ -12,6 +12,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
@@ -854,3 +855,120 @@ func TestSendSlackRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateSlackMessage_InteractiveActions(t *testing.T) {
+	notifier, _, err := setupSlackForTests(t, Config{
+		URL:                APIURL,
+		Token:              "1234",
+		Recipient:          "#test",
+		Text:               templates.DefaultMessageEmbed,
+		Title:              templates.DefaultMessageTitleEmbed,
+		InteractiveActions: true,
+	})
+	require.NoError(t, err)
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+
+	msg, err := notifier.createSlackMessage(ctx, []*types.Alert{alert})
+	require.NoError(t, err)
+	require.Len(t, msg.Blocks, 1)
+	block := msg.Blocks[0]
+	require.Equal(t, "actions", block["type"])
+	require.Equal(t, ActionsBlockID, block["block_id"])
+	elements := block["elements"].([]map[string]interface{})
+	require.Len(t, elements, 2)
+	for i, kind := range []receivers.ActionKind{receivers.ActionSilence, receivers.ActionAcknowledge} {
+		require.Equal(t, string(kind), elements[i]["action_id"])
+		action, err := receivers.DecodeAction(elements[i]["value"].(string))
+		require.NoError(t, err)
+		require.Equal(t, kind, action.Kind)
+		require.Equal(t, []string{alert.Fingerprint().String()}, action.Fingerprints)
+	}
+
+	t.Run("no actions for resolved alerts", func(t *testing.T) {
+		resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+		msg, err := notifier.createSlackMessage(ctx, []*types.Alert{resolved})
+		require.NoError(t, err)
+		require.Empty(t, msg.Blocks)
+	})
+}
+
+func TestNotify_Threads(t *testing.T) {
+	notifier, _, err := setupSlackForTests(t, Config{
+		URL:       APIURL,
+		Token:     "1234",
+		Recipient: "#test",
+		Text:      templates.DefaultMessageEmbed,
+		Title:     templates.DefaultMessageTitleEmbed,
+	})
+	require.NoError(t, err)
+	notifier.UID = "slack-uid"
+	var threadTss []string
+	notifier.sendMessageFn = func(_ context.Context, r *http.Request, _ logging.Logger) (string, error) {
+		var m slackMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		threadTss = append(threadTss, m.ThreadTs)
+		return fmt.Sprintf("100%d.0", len(threadTss)), nil
+	}
+
+	store := receivers.NewFakeNotificationStateStore()
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = receivers.WithNotificationStateStore(ctx, store)
+	key := receivers.NotificationStateKey{GroupKey: "alertname", IntegrationUID: "slack-uid"}
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	for _, alert := range []*types.Alert{firing, firing, resolved, firing} {
+		_, err := notifier.Notify(ctx, alert)
+		require.NoError(t, err)
+	}
+	// The first message starts the thread, the following notifications of the group reply in it until it is resolved.
+	require.Equal(t, []string{"", "1001.0", "1001.0", ""}, threadTss)
+	require.Equal(t, receivers.NotificationState{"ts": "1004.0"}, store.States[key])
+}
+
+// capturingSender is a receivers.CapturingWebhookSender.
+type capturingSender struct {
+	*receivers.NotificationServiceMock
+}
+
+func (capturingSender) CapturesWebhooks() {}
+
+func TestNotify_CapturingSender(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	image := t.TempDir() + "/test.png"
+	require.NoError(t, os.WriteFile(image, []byte("png"), 0o600))
+	sender := capturingSender{receivers.MockNotificationService()}
+	n := New(Config{
+		URL:       APIURL,
+		Token:     "1234",
+		Recipient: "#test",
+		Text:      templates.DefaultMessageEmbed,
+		Title:     templates.DefaultMessageTitleEmbed,
+	}, receivers.Metadata{}, tmpl, sender, &images.FakeProvider{
+		Images: []*images.Image{{Token: "image", Path: image}},
+	}, &logging.FakeLogger{}, appVersion)
+
+	alert := &types.Alert{Alert: model.Alert{
+		Labels:      model.LabelSet{"alertname": "alert1"},
+		Annotations: model.LabelSet{"__alertImageToken__": "image"},
+	}}
+	ok, err := n.Notify(context.Background(), alert)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The message is sent through the sender instead of the Slack client, and the image is not uploaded.
+	require.Len(t, sender.WebhookCalls, 1)
+	call := sender.WebhookCalls[0]
+	require.Equal(t, APIURL, call.URL)
+	require.Equal(t, http.MethodPost, call.HTTPMethod)
+	require.Equal(t, "Bearer 1234", call.HTTPHeader["Authorization"])
+	require.Equal(t, "application/json; charset=utf-8", call.ContentType)
+	var m slackMessage
+	require.NoError(t, json.Unmarshal([]byte(call.Body), &m))
+	require.Equal(t, "#test", m.Channel)
+}
@@ -0,0 +1,33 @@
+package sns
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "sns",
+		Fields: []receivers.Field{
+			{Name: "api_url", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "sigv4", Type: receivers.FieldTypeObject, Fields: []receivers.Field{
+				{Name: "region", Type: receivers.FieldTypeString},
+				{Name: "access_key", Type: receivers.FieldTypeString, Secure: true},
+				{Name: "secret_key", Type: receivers.FieldTypeString, Secure: true},
+				{Name: "profile", Type: receivers.FieldTypeString},
+				{Name: "role_arn", Type: receivers.FieldTypeString},
+			}},
+			{Name: "topic_arn", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "target_arn", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "phone_number", Type: receivers.FieldTypeString, Templated: true},
+			{Name: "subject", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "attributes", Type: receivers.FieldTypeMap, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.Logger)
+		},
+	})
+}
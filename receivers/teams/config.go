@@ -14,6 +14,9 @@ type Config struct {
 	Message      string `json:"message,omitempty" yaml:"message,omitempty"`
 	Title        string `json:"title,omitempty" yaml:"title,omitempty"`
 	SectionTitle string `json:"sectiontitle,omitempty" yaml:"sectiontitle,omitempty"`
+	// InteractiveActions adds buttons to silence and acknowledge the firing alerts.
+	// Teams only sends them to a bot that is registered for the card.
+	InteractiveActions bool `json:"interactiveActions,omitempty" yaml:"interactiveActions,omitempty"`
 }
 
 func NewConfig(jsonData json.RawMessage) (Config, error) {
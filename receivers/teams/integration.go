@@ -0,0 +1,29 @@
+package teams
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "teams",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true, Templated: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: `{{ template "teams.default.message" .}}`, Templated: true},
+			{Name: "sectiontitle", Type: receivers.FieldTypeString},
+			{Name: "interactiveActions", Type: receivers.FieldTypeBool},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig: func(settings json.RawMessage, _ receivers.DecryptFunc) (Config, error) {
+			return NewConfig(settings)
+		},
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
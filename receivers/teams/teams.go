@@ -227,6 +227,33 @@ func (i AdaptiveCardOpenURLActionItem) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// AdaptiveCardExecuteActionItem is an Action.Execute action. The data is sent to the bot of the card when the action is clicked.
+type AdaptiveCardExecuteActionItem struct {
+	Title string
+	Verb  string
+	Data  map[string]string
+}
+
+func (i AdaptiveCardExecuteActionItem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string            `json:"type"`
+		Title string            `json:"title"`
+		Verb  string            `json:"verb"`
+		Data  map[string]string `json:"data,omitempty"`
+	}{
+		Type:  "Action.Execute",
+		Title: i.Title,
+		Verb:  i.Verb,
+		Data:  i.Data,
+	})
+}
+
+// ActionDataKey is the key of the encoded action in the data of an Action.Execute action.
+const ActionDataKey = "action"
+
+// teamsMaxActionDataLen keeps the data of the actions, which is sent back on every click, small.
+const teamsMaxActionDataLen = 2000
+
 type Notifier struct {
 	*receivers.Base
 	tmpl     *templates.Template
@@ -282,14 +309,20 @@ func (tn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error
 		card.AppendItem(s)
 	}
 
-	card.AppendItem(AdaptiveCardActionSetItem{
-		Actions: []AdaptiveCardActionItem{
-			AdaptiveCardOpenURLActionItem{
-				Title: "View URL",
-				URL:   receivers.JoinURLPath(tn.tmpl.ExternalURL.String(), "/alerting/list", tn.log),
-			},
+	actions := []AdaptiveCardActionItem{
+		AdaptiveCardOpenURLActionItem{
+			Title: "View URL",
+			URL:   receivers.JoinURLPath(tn.tmpl.ExternalURL.String(), "/alerting/list", tn.log),
 		},
-	})
+	}
+	if tn.settings.InteractiveActions {
+		executeActions, err := tn.executeActions(ctx, as)
+		if err != nil {
+			return false, err
+		}
+		actions = append(actions, executeActions...)
+	}
+	card.AppendItem(AdaptiveCardActionSetItem{Actions: actions})
 
 	msg := NewAdaptiveCardsMessage(card)
 	msg.Summary = tmpl(tn.settings.Title)
@@ -357,6 +390,28 @@ func validateResponse(l logging.Logger) func(b []byte, statusCode int) error {
 	}
 }
 
+// executeActions returns the Action.Execute actions of the interactive actions.
+func (tn *Notifier) executeActions(ctx context.Context, as []*types.Alert) ([]AdaptiveCardActionItem, error) {
+	actions, err := receivers.NewActions(ctx, as...)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := receivers.EncodeActions(actions, teamsMaxActionDataLen)
+	if !ok {
+		tn.log.Warn("Too many alerts to add interactive actions", "alerts", len(as))
+		return nil, nil
+	}
+	res := make([]AdaptiveCardActionItem, 0, len(actions))
+	for i, a := range actions {
+		res = append(res, AdaptiveCardExecuteActionItem{
+			Title: a.Label(),
+			Verb:  string(a.Kind),
+			Data:  map[string]string{ActionDataKey: values[i]},
+		})
+	}
+	return res, nil
+}
+
 func (tn *Notifier) SendResolved() bool {
 	return !tn.GetDisableResolveMessage()
 }
@@ -302,3 +302,56 @@ func TestValidateWebhookResponse(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, "some error message", err.Error())
 }
+
+func TestNotify_InteractiveActions(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	webhookSender := receivers.MockNotificationService()
+	pn := &Notifier{
+		Base:     &receivers.Base{},
+		log:      &logging.FakeLogger{},
+		ns:       webhookSender,
+		tmpl:     tmpl,
+		settings: Config{URL: "http://localhost", Title: templates.DefaultMessageTitleEmbed, Message: `{{ template "teams.default.message" .}}`, InteractiveActions: true},
+		images:   &images.UnavailableProvider{},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+
+	ok, err := pn.Notify(ctx, alert)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var msg struct {
+		Attachments []struct {
+			Content struct {
+				Body []struct {
+					Type    string `json:"type"`
+					Actions []struct {
+						Type  string            `json:"type"`
+						Title string            `json:"title"`
+						Verb  string            `json:"verb"`
+						Data  map[string]string `json:"data"`
+					} `json:"actions"`
+				} `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(webhookSender.Webhook.Body), &msg))
+	body := msg.Attachments[0].Content.Body
+	actionSet := body[len(body)-1]
+	require.Equal(t, "ActionSet", actionSet.Type)
+	require.Len(t, actionSet.Actions, 3)
+	require.Equal(t, "Action.OpenUrl", actionSet.Actions[0].Type)
+	silence := actionSet.Actions[1]
+	require.Equal(t, "Action.Execute", silence.Type)
+	require.Equal(t, "Silence", silence.Title)
+	require.Equal(t, string(receivers.ActionSilence), silence.Verb)
+	action, err := receivers.DecodeAction(silence.Data[ActionDataKey])
+	require.NoError(t, err)
+	require.Equal(t, []string{alert.Fingerprint().String()}, action.Fingerprints)
+}
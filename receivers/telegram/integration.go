@@ -0,0 +1,29 @@
+package telegram
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "telegram",
+		Fields: []receivers.Field{
+			{Name: "bottoken", Type: receivers.FieldTypeString, Required: true, Secure: true},
+			{Name: "chatid", Type: receivers.FieldTypeString, Required: true},
+			{Name: "message_thread_id", Type: receivers.FieldTypeString},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "parse_mode", Type: receivers.FieldTypeString, Default: DefaultTelegramParseMode, Enum: []any{"Markdown", "MarkdownV2", "HTML", "None"}},
+			{Name: "disable_web_page_preview", Type: receivers.FieldTypeBool},
+			{Name: "protect_content", Type: receivers.FieldTypeBool},
+			{Name: "disable_notifications", Type: receivers.FieldTypeBool},
+			{Name: "interactiveActions", Type: receivers.FieldTypeBool},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
@@ -3,13 +3,16 @@ package telegram
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
+	"strconv"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/alerting/images"
 	"github.com/grafana/alerting/logging"
@@ -25,6 +28,12 @@ var (
 // Telegram supports 4096 chars max - from https://limits.tginfo.me/en.
 const telegramMaxMessageLenRunes = 4096
 
+// stateMessageID is the key of the message_id of the first message of the group in the notification state.
+const stateMessageID = "message_id"
+
+// The callback data of a button is at most 64 bytes - from https://core.telegram.org/bots/api#inlinekeyboardbutton.
+const telegramMaxCallbackDataLen = 64
+
 // Notifier is responsible for sending
 // alert notifications to Telegram.
 // It uses two API endpoints
@@ -53,12 +62,18 @@ func New(cfg Config, meta receivers.Metadata, template *templates.Template, send
 
 // Notify send an alert notification to Telegram.
 func (tn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	// Reply to the first message of the group, if any.
+	state, hasState := tn.GetNotificationState(ctx)
+
 	// Create the cmd for sendMessage
 	cmd, err := tn.newWebhookSyncCmd("sendMessage", func(w *multipart.Writer) error {
 		msg, err := tn.buildTelegramMessage(ctx, as)
 		if err != nil {
 			return fmt.Errorf("failed to build message: %w", err)
 		}
+		if id := state[stateMessageID]; id != "" {
+			msg["reply_parameters"] = fmt.Sprintf(`{"message_id":%s,"allow_sending_without_reply":true}`, id)
+		}
 		for k, v := range msg {
 			if err := w.WriteField(k, v); err != nil {
 				return fmt.Errorf("failed to create form field: %w", err)
@@ -69,9 +84,27 @@ func (tn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error
 	if err != nil {
 		return false, fmt.Errorf("failed to create telegram message: %w", err)
 	}
+	var messageID int64
+	if _, ok := receivers.NotificationStateStoreFromContext(ctx); ok {
+		cmd.Validation = func(body []byte, statusCode int) error {
+			if statusCode/100 != 2 {
+				return fmt.Errorf("unexpected status code %d: %s", statusCode, body)
+			}
+			var res struct {
+				Result struct {
+					MessageID int64 `json:"message_id"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal(body, &res); err == nil {
+				messageID = res.Result.MessageID
+			}
+			return nil
+		}
+	}
 	if err := tn.ns.SendWebhook(ctx, cmd); err != nil {
 		return false, fmt.Errorf("failed to send telegram message: %w", err)
 	}
+	tn.updateNotificationState(ctx, as, hasState, messageID)
 
 	// Create the cmd to upload each image
 	_ = images.WithStoredImages(ctx, tn.log, tn.images, func(_ int, image images.Image) error {
@@ -106,6 +139,23 @@ func (tn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error
 	return true, nil
 }
 
+// updateNotificationState records the message_id of the first message of the group, so that the following
+// notifications reply to it. The replies end when the group is resolved.
+func (tn *Notifier) updateNotificationState(ctx context.Context, as []*types.Alert, hasState bool, messageID int64) {
+	var err error
+	switch {
+	case types.Alerts(as...).Status() == model.AlertResolved:
+		if hasState {
+			err = tn.DeleteNotificationState(ctx)
+		}
+	case !hasState && messageID != 0:
+		err = tn.SetNotificationState(ctx, receivers.NotificationState{stateMessageID: strconv.FormatInt(messageID, 10)})
+	}
+	if err != nil {
+		tn.log.Warn("Failed to update the notification state", "error", err)
+	}
+}
+
 func (tn *Notifier) buildTelegramMessage(ctx context.Context, as []*types.Alert) (map[string]string, error) {
 	var tmplErr error
 	defer func() {
@@ -136,9 +186,44 @@ func (tn *Notifier) buildTelegramMessage(ctx context.Context, as []*types.Alert)
 	if tn.settings.ProtectContent {
 		m["protect_content"] = "true"
 	}
+	if tn.settings.InteractiveActions {
+		markup, err := tn.replyMarkup(ctx, as)
+		if err != nil {
+			return nil, err
+		}
+		if markup != "" {
+			m["reply_markup"] = markup
+		}
+	}
 	return m, nil
 }
 
+// replyMarkup returns the inline keyboard with the buttons of the interactive actions,
+// or an empty string if there are no actions.
+func (tn *Notifier) replyMarkup(ctx context.Context, as []*types.Alert) (string, error) {
+	actions, err := receivers.NewActions(ctx, as...)
+	if err != nil {
+		return "", err
+	}
+	values, ok := receivers.EncodeActions(actions, telegramMaxCallbackDataLen)
+	if !ok {
+		tn.log.Warn("Too many alerts to add interactive actions", "alerts", len(as))
+		return "", nil
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	buttons := make([]map[string]string, 0, len(actions))
+	for i, a := range actions {
+		buttons = append(buttons, map[string]string{"text": a.Label(), "callback_data": values[i]})
+	}
+	b, err := json.Marshal(map[string]interface{}{"inline_keyboard": [][]map[string]string{buttons}})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 func (tn *Notifier) newWebhookSyncCmd(action string, fn func(writer *multipart.Writer) error) (*receivers.SendWebhookSettings, error) {
 	b := bytes.Buffer{}
 	w := multipart.NewWriter(&b)
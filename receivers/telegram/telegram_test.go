@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"mime"
@@ -9,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
@@ -212,3 +214,88 @@ func TestNotify(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildTelegramMessage_InteractiveActions(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	n := &Notifier{
+		Base:     &receivers.Base{},
+		tmpl:     tmpl,
+		log:      &logging.FakeLogger{},
+		settings: Config{Message: templates.DefaultMessageEmbed, InteractiveActions: true},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+
+	m, err := n.buildTelegramMessage(ctx, []*types.Alert{alert})
+	require.NoError(t, err)
+	var markup struct {
+		InlineKeyboard [][]struct {
+			Text         string `json:"text"`
+			CallbackData string `json:"callback_data"`
+		} `json:"inline_keyboard"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(m["reply_markup"]), &markup))
+	require.Len(t, markup.InlineKeyboard, 1)
+	require.Len(t, markup.InlineKeyboard[0], 2)
+	require.Equal(t, "Silence", markup.InlineKeyboard[0][0].Text)
+	action, err := receivers.DecodeAction(markup.InlineKeyboard[0][0].CallbackData)
+	require.NoError(t, err)
+	require.Equal(t, receivers.ActionSilence, action.Kind)
+	require.Equal(t, []string{alert.Fingerprint().String()}, action.Fingerprints)
+
+	t.Run("no buttons if the callback data is too long", func(t *testing.T) {
+		alerts := make([]*types.Alert, 0, 4)
+		for _, name := range []model.LabelValue{"a", "b", "c", "d"} {
+			alerts = append(alerts, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": name}}})
+		}
+		m, err := n.buildTelegramMessage(ctx, alerts)
+		require.NoError(t, err)
+		require.NotContains(t, m, "reply_markup")
+	})
+}
+
+func TestNotify_Replies(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	notificationService := receivers.MockNotificationService()
+	notificationService.ResponseBody = []byte(`{"ok":true,"result":{"message_id":42}}`)
+	n := &Notifier{
+		Base:     &receivers.Base{UID: "telegram-uid"},
+		tmpl:     tmpl,
+		log:      &logging.FakeLogger{},
+		ns:       notificationService,
+		images:   &images2.UnavailableProvider{},
+		settings: Config{BotToken: "token", ChatID: "chat", Message: templates.DefaultMessageEmbed},
+	}
+	store := receivers.NewFakeNotificationStateStore()
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = receivers.WithNotificationStateStore(ctx, store)
+	key := receivers.NotificationStateKey{GroupKey: "alertname", IntegrationUID: "telegram-uid"}
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	replyTo := func() string {
+		_, params, err := mime.ParseMediaType(notificationService.Webhook.HTTPHeader["Content-Type"])
+		require.NoError(t, err)
+		form, err := multipart.NewReader(strings.NewReader(notificationService.Webhook.Body), params["boundary"]).ReadForm(1 << 20)
+		require.NoError(t, err)
+		return strings.Join(form.Value["reply_parameters"], "")
+	}
+
+	_, err = n.Notify(ctx, firing)
+	require.NoError(t, err)
+	require.Empty(t, replyTo())
+	require.Equal(t, receivers.NotificationState{"message_id": "42"}, store.States[key])
+
+	_, err = n.Notify(ctx, resolved)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"message_id":42,"allow_sending_without_reply":true}`, replyTo())
+	require.NotContains(t, store.States, key)
+}
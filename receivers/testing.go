@@ -2,6 +2,7 @@ package receivers
 
 import (
 	"context"
+	"sync"
 )
 
 type NotificationServiceMock struct {
@@ -9,11 +10,16 @@ type NotificationServiceMock struct {
 	Webhook      SendWebhookSettings
 	EmailSync    SendEmailSettings
 	ShouldError  error
+	// ResponseBody, if set, is passed to the Validation of the webhooks as the body of a response with status 200.
+	ResponseBody []byte
 }
 
 func (ns *NotificationServiceMock) SendWebhook(_ context.Context, cmd *SendWebhookSettings) error {
 	ns.WebhookCalls = append(ns.WebhookCalls, *cmd)
 	ns.Webhook = *cmd
+	if ns.ShouldError == nil && ns.ResponseBody != nil && cmd.Validation != nil {
+		return cmd.Validation(ns.ResponseBody, 200)
+	}
 	return ns.ShouldError
 }
 
@@ -23,3 +29,34 @@ func (ns *NotificationServiceMock) SendEmail(_ context.Context, cmd *SendEmailSe
 }
 
 func MockNotificationService() *NotificationServiceMock { return &NotificationServiceMock{} }
+
+// FakeNotificationStateStore is an in-memory NotificationStateStore for tests.
+type FakeNotificationStateStore struct {
+	mtx    sync.Mutex
+	States map[NotificationStateKey]NotificationState
+}
+
+func NewFakeNotificationStateStore() *FakeNotificationStateStore {
+	return &FakeNotificationStateStore{States: map[NotificationStateKey]NotificationState{}}
+}
+
+func (s *FakeNotificationStateStore) Get(key NotificationStateKey) (NotificationState, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	state, ok := s.States[key]
+	return state, ok
+}
+
+func (s *FakeNotificationStateStore) Set(key NotificationStateKey, state NotificationState) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.States[key] = state
+	return nil
+}
+
+func (s *FakeNotificationStateStore) Delete(key NotificationStateKey) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.States, key)
+	return nil
+}
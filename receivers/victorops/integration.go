@@ -0,0 +1,28 @@
+package victorops
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "victorops",
+		Fields: []receivers.Field{
+			{Name: "url", Type: receivers.FieldTypeString, Required: true, Templated: true},
+			{Name: "messageType", Type: receivers.FieldTypeString, Default: DefaultMessageType, Templated: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "description", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig: func(settings json.RawMessage, _ receivers.DecryptFunc) (Config, error) {
+			return NewConfig(settings)
+		},
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger, deps.Version)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
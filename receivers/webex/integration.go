@@ -0,0 +1,24 @@
+package webex
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "webex",
+		Fields: []receivers.Field{
+			{Name: "bot_token", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "room_id", Type: receivers.FieldTypeString},
+			{Name: "api_url", Type: receivers.FieldTypeString, Default: DefaultAPIURL, Templated: true},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.ImagesDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Images, deps.Logger, deps.OrgID)
+		},
+		Previewable: receivers.AlwaysPreviewable[Config],
+	})
+}
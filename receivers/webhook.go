@@ -20,3 +20,12 @@ type SendWebhookSettings struct {
 type WebhookSender interface {
 	SendWebhook(ctx context.Context, cmd *SendWebhookSettings) error
 }
+
+// CapturingWebhookSender is a WebhookSender that captures the webhooks instead of sending them, such as the sender
+// of the previews of the notifications. The notifiers that send their requests with their own clients send them
+// through it instead, as it would not capture them otherwise.
+type CapturingWebhookSender interface {
+	WebhookSender
+	// CapturesWebhooks only marks the senders that capture the webhooks.
+	CapturesWebhooks()
+}
@@ -23,7 +23,7 @@ const FullValidConfigForTesting = `{
 const FullValidSecretsForTesting = `{
 	"username": "test-secret-user",
 	"password": "test-secret-pass",
-	"clientCertificate": "test-client-certificate",
-	"clientKey": "test-client-key",
-	"caCertificate": "test-ca-certificate"
+	"tlsConfig.clientCertificate": "test-client-certificate",
+	"tlsConfig.clientKey": "test-client-key",
+	"tlsConfig.caCertificate": "test-ca-certificate"
 }`
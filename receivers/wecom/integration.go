@@ -0,0 +1,32 @@
+package wecom
+
+import (
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+func init() {
+	receivers.RegisterIntegration(receivers.IntegrationType[Config]{
+		Name: "wecom",
+		Fields: []receivers.Field{
+			{Name: "endpointUrl", Type: receivers.FieldTypeString, Default: weComEndpoint},
+			{Name: "url", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "secret", Type: receivers.FieldTypeString, Secure: true},
+			{Name: "agent_id", Type: receivers.FieldTypeString},
+			{Name: "corp_id", Type: receivers.FieldTypeString},
+			{Name: "msgtype", Type: receivers.FieldTypeString, Default: string(DefaultsgType), Enum: []any{string(MsgTypeMarkdown), string(MsgTypeText)}},
+			{Name: "message", Type: receivers.FieldTypeString, Default: templates.DefaultMessageEmbed, Templated: true},
+			{Name: "title", Type: receivers.FieldTypeString, Default: templates.DefaultMessageTitleEmbed, Templated: true},
+			{Name: "touser", Type: receivers.FieldTypeString, Default: DefaultToUser},
+		},
+		Dependencies: receivers.TemplateDependency | receivers.WebhookSenderDependency,
+		NewConfig:    NewConfig,
+		NewNotifier: func(settings Config, meta receivers.Metadata, deps receivers.NotifierDependencies) receivers.Notifier {
+			return New(settings, meta, deps.Template, deps.WebhookSender, deps.Logger)
+		},
+		// The APIAPP channel requests an access token with its own client before sending the notifications.
+		Previewable: func(settings Config) bool {
+			return settings.Channel == DefaultChannelType
+		},
+	})
+}